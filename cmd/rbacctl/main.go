@@ -0,0 +1,170 @@
+// Command rbacctl exports the RBAC policy as a document and applies policy documents to the
+// database, so roles and permissions can be kept in version control.
+//
+//	rbacctl export [-format yaml|json] [-o file]
+//	rbacctl apply -f file [-format yaml|json] [-prune] [-dry-run]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"boilerplate-be/internal/config"
+	"boilerplate-be/internal/database"
+	"boilerplate-be/internal/module/rbac"
+	"boilerplate-be/internal/shared/errors"
+	"boilerplate-be/internal/shared/security"
+	"boilerplate-be/internal/shared/utils"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+
+	switch os.Args[1] {
+	case "export":
+		runExport(os.Args[2:])
+	case "apply":
+		runApply(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage:")
+	fmt.Fprintln(os.Stderr, "  rbacctl export [-format yaml|json] [-o file]")
+	fmt.Fprintln(os.Stderr, "  rbacctl apply -f file [-format yaml|json] [-prune] [-dry-run]")
+	os.Exit(2)
+}
+
+func runExport(args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", rbac.PolicyFormatYAML, "document format (yaml or json)")
+	output := flags.String("o", "", "write to file instead of stdout")
+	_ = flags.Parse(args)
+
+	rbacUseCase, closeFn := newRBACUseCase()
+	defer closeFn()
+
+	document, err := rbacUseCase.ExportPolicy()
+	if err != nil {
+		fail(err)
+	}
+	data, err := document.Encode(*format)
+	if err != nil {
+		fail(err)
+	}
+
+	if *output == "" {
+		_, _ = os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		fail(err)
+	}
+}
+
+func runApply(args []string) {
+	flags := flag.NewFlagSet("apply", flag.ExitOnError)
+	file := flags.String("f", "", "policy document to apply")
+	format := flags.String("format", "", "document format (yaml or json); defaults to the file extension")
+	prune := flags.Bool("prune", false, "delete roles, permissions and mappings missing from the document")
+	dryRun := flags.Bool("dry-run", false, "print the changes without committing them")
+	_ = flags.Parse(args)
+
+	if *file == "" {
+		usage()
+	}
+	if *format == "" {
+		*format = formatFromExtension(*file)
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		fail(err)
+	}
+	document, err := rbac.ParsePolicyDocument(data, *format)
+	if err != nil {
+		fail(err)
+	}
+
+	rbacUseCase, closeFn := newRBACUseCase()
+	defer closeFn()
+
+	plan, err := rbacUseCase.ApplyPolicy(document, rbac.ApplyOptions{Prune: *prune, DryRun: *dryRun})
+	if err != nil {
+		fail(err)
+	}
+
+	for _, change := range plan.Changes {
+		fmt.Println(change)
+	}
+	switch {
+	case len(plan.Changes) == 0:
+		fmt.Println("No changes; the database already matches the document")
+	case plan.DryRun:
+		fmt.Printf("Dry run: %d change(s) planned, nothing committed\n", len(plan.Changes))
+	default:
+		fmt.Printf("Applied %d change(s)\n", len(plan.Changes))
+	}
+}
+
+// newRBACUseCase connects to the database and Redis the same way the server does, so cached
+// user permissions are invalidated and permission versions bumped when roles change
+func newRBACUseCase() (rbac.RBACUseCase, func()) {
+	cfg := config.New()
+
+	db, err := database.New(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	redisClient, err := database.NewRedis(cfg.Redis)
+	if err != nil {
+		db.Close()
+		log.Fatalf("Failed to connect to Redis: %v", err)
+	}
+
+	cacheHelper := utils.NewCacheHelper(redisClient, cfg.Redis.DefaultTTL)
+	permissionVersions := security.NewPermissionVersions(redisClient, max(cfg.JWT.Expiry, rbac.GrantCacheTTL))
+	rbacRepo := rbac.NewRBACRepository(db, cacheHelper, permissionVersions)
+
+	return rbac.NewRBACUseCase(rbacRepo), func() {
+		redisClient.Close()
+		db.Close()
+	}
+}
+
+func formatFromExtension(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return rbac.PolicyFormatJSON
+	}
+	return rbac.PolicyFormatYAML
+}
+
+// fail prints the error, including validation details of application errors, and exits
+func fail(err error) {
+	if appErr, ok := errors.IsAppError(err); ok {
+		fmt.Fprintf(os.Stderr, "error: %s\n", appErr.Message)
+		if details, ok := appErr.Details.([]errors.ValidationErrorDetails); ok {
+			for _, detail := range details {
+				fmt.Fprintf(os.Stderr, "  %s: %s\n", detail.Field, detail.Message)
+			}
+		}
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	os.Exit(1)
+}
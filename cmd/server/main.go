@@ -20,21 +20,30 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	_ "boilerplate-be/docs"
 	"boilerplate-be/internal/config"
 	"boilerplate-be/internal/database"
 	"boilerplate-be/internal/delivery/websocket"
 	"boilerplate-be/internal/middleware"
+	"boilerplate-be/internal/module/accessrequest"
 	"boilerplate-be/internal/module/auth"
+	"boilerplate-be/internal/module/organization"
+	"boilerplate-be/internal/module/policy"
 	"boilerplate-be/internal/module/rbac"
+	"boilerplate-be/internal/module/users"
+	"boilerplate-be/internal/shared/challenge"
 	"boilerplate-be/internal/shared/errors"
+	"boilerplate-be/internal/shared/geoip"
 	"boilerplate-be/internal/shared/response"
 	"boilerplate-be/internal/shared/security"
+	"boilerplate-be/internal/shared/sms"
 	"boilerplate-be/internal/shared/utils"
 	"boilerplate-be/web"
 
@@ -79,21 +88,95 @@ func main() {
 	// Initialize JWT manager
 	jwtManager := security.NewJWTManager(cfg.JWT.Secret, cfg.JWT.Expiry)
 
+	// Initialize permission versions, bumped whenever a user's roles or permissions change; they
+	// version both cached permissions and permissions embedded in access tokens
+	permissionVersions := security.NewPermissionVersions(redisClient, max(cfg.JWT.Expiry, rbac.GrantCacheTTL))
+
+	// Initialize GeoIP locator (optional, no-op when GEOIP_DATABASE_PATH is empty)
+	geoLocator, err := geoip.New(cfg.GeoIP.DatabasePath)
+	if err != nil {
+		log.Fatalf("Failed to open GeoIP database: %v", err)
+	}
+	defer geoLocator.Close()
+
+	// Initialize OTP manager and SMS gateway for phone verification and SMS login
+	otpManager := security.NewOTPManagerWithConfig(redisClient, security.OTPManagerConfig{
+		KeyPrefix:      "otp",
+		Length:         cfg.OTP.Length,
+		TTL:            cfg.OTP.TTL,
+		MaxAttempts:    cfg.OTP.MaxAttempts,
+		ResendInterval: cfg.OTP.ResendInterval,
+	})
+	smsSender, err := sms.New(cfg.SMS.Driver, cfg.SMS.FilePath)
+	if err != nil {
+		log.Fatalf("Failed to initialize SMS sender: %v", err)
+	}
+
+	// ==================== Initialize WebSocket ====================
+	wsHub := websocket.NewHub()
+	go wsHub.Run()
+
 	// ==================== Initialize Repositories ====================
 	authRepo := auth.NewAuthRepository(db, cacheHelper)
-	rbacRepo := rbac.NewRBACRepository(db, cacheHelper)
+	rbacRepo := rbac.NewRBACRepository(db, cacheHelper, permissionVersions)
+	orgRepo := organization.NewOrganizationRepository(db, cacheHelper, permissionVersions)
+	accessRequestRepo := accessrequest.NewAccessRequestRepository(db)
+	userRepo := users.NewUserRepository(db, cacheHelper, permissionVersions)
 
 	// ==================== Initialize Use Cases ====================
 	authUseCase := auth.NewAuthUseCase(authRepo, jwtManager, tokenManager)
+	authUseCase.SetGeoLocator(geoLocator)
+	authUseCase.SetPhoneVerification(otpManager, smsSender)
 	rbacUseCase := rbac.NewRBACUseCase(rbacRepo)
+	authUseCase.SetRoleAssigner(rbacUseCase)
+	if cfg.JWT.EmbedPermissions {
+		jwtManager.EmbedAuthorization(rbacUseCase, permissionVersions)
+	}
+	orgUseCase := organization.NewOrganizationUseCase(orgRepo, rbacUseCase)
+	authUseCase.SetMembershipChecker(orgUseCase)
+	accessRequestUseCase := accessrequest.NewAccessRequestUseCase(
+		accessRequestRepo, rbacUseCase, accessrequest.NewWebSocketNotifier(wsHub),
+	)
+	userUseCase := users.NewUserUseCase(userRepo, rbacUseCase, tokenManager)
+	if cfg.LDAP.Enabled {
+		// Local passwords first, then the directory; directory users are provisioned on first login
+		authUseCase.SetAuthenticators(
+			auth.NewPasswordAuthenticator(authRepo),
+			auth.NewLDAPAuthenticator(cfg.LDAP, authRepo, rbacUseCase),
+		)
+	}
+
+	// Delete time-bound role assignments once they expire
+	sweeperCtx, stopSweeper := context.WithCancel(context.Background())
+	defer stopSweeper()
+	go rbac.NewExpirySweeper(rbacUseCase, cfg.RBAC.ExpirySweepInterval).Run(sweeperCtx)
+
+	// ==================== Initialize Policies ====================
+	policyEngine := policy.NewEngine(rbacUseCase)
+	auth.RegisterPolicies(policyEngine, authRepo)
+
+	// ==================== Initialize Permission Registry ====================
+	// Modules declare their permissions; routes mounted through the guard record the ones they require
+	permissionRegistry := rbac.NewPermissionRegistry()
+	rbac.RegisterPermissions(permissionRegistry)
+	auth.RegisterPermissions(permissionRegistry)
+	organization.RegisterPermissions(permissionRegistry)
+	users.RegisterPermissions(permissionRegistry)
+	permissionGuard := middleware.NewPermissionGuard(rbacUseCase, permissionRegistry)
+
+	// Hide response fields tagged with permissions the caller does not hold
+	response.SetViewerResolver(middleware.ResponseViewer(rbacUseCase))
 
 	// ==================== Initialize Handlers ====================
 	authHandler := auth.NewAuthHandler(authUseCase)
+	if cfg.Challenge.Enabled {
+		authHandler.SetChallengeGuard(newChallengeGuard(cfg.Challenge, redisClient))
+	}
 	rbacHandler := rbac.NewRBACHandler(rbacUseCase)
-
-	// ==================== Initialize WebSocket ====================
-	wsHub := websocket.NewHub()
-	go wsHub.Run()
+	rbacHandler.SetPermissionRegistry(permissionRegistry)
+	orgHandler := organization.NewOrganizationHandler(orgUseCase)
+	accessRequestHandler := accessrequest.NewAccessRequestHandler(accessRequestUseCase)
+	userHandler := users.NewUserHandler(userUseCase)
 
 	// Initialize Fiber app with optimized config
 	app := fiber.New(fiber.Config{
@@ -133,7 +216,8 @@ func main() {
 	})
 
 	// ==================== WebSocket Routes ====================
-	websocket.RegisterRoutes(app, wsHub)
+	// Connections opened with ?token=<access token> receive messages addressed to their user
+	websocket.RegisterRoutes(app, wsHub, middleware.WebSocketAuth(jwtManager, redisClient))
 
 	// Routes
 	api := app.Group("/api/v1")
@@ -144,6 +228,10 @@ func main() {
 	authGroup.Post("/register", authHandler.Register)
 	authGroup.Post("/login", authHandler.Login)
 	authGroup.Post("/refresh", authHandler.RefreshToken)
+	authGroup.Get("/username-available", authHandler.UsernameAvailability)
+	authGroup.Get("/challenge", authHandler.Challenge)
+	authGroup.Post("/otp/request", authHandler.RequestLoginOTP)
+	authGroup.Post("/otp/login", authHandler.LoginWithOTP)
 
 	// ==================== Protected Routes (Authenticated Users) ====================
 	// Auth routes (protected)
@@ -151,8 +239,63 @@ func main() {
 	authProtected.Post("/logout", authHandler.Logout)
 	authProtected.Get("/profile", authHandler.Profile)
 	authProtected.Put("/profile", authHandler.UpdateProfile)
+	authProtected.Get("/login-history", authHandler.LoginHistory)
+	authProtected.Post("/phone", authHandler.RequestPhoneVerification)
+	authProtected.Post("/phone/verify", authHandler.VerifyPhone)
 	authProtected.Get("/my-roles", rbacHandler.GetMyRoles)
-	authProtected.Get("/my-permissions", rbacHandler.GetMyPermissions)
+	authProtected.Get("/my-permissions", middleware.Tenant(orgUseCase), rbacHandler.GetMyPermissions)
+	authProtected.Post("/permissions/check", middleware.Tenant(orgUseCase), rbacHandler.CheckPermissions)
+	authProtected.Post("/organization", authHandler.SwitchOrganization)
+
+	// User routes; administration is guarded by permission, login history per record by policy.
+	// Accounts are global, so no tenant is selected and roles held inside an organization do not count.
+	userRoutes := api.Group("/users", middleware.AuthMiddleware(jwtManager, redisClient))
+	permissionGuard.Get(userRoutes, "", userHandler.ListUsers, "users:read")
+	permissionGuard.Post(userRoutes, "", userHandler.CreateUser, "users:write")
+	permissionGuard.Post(userRoutes, "/bulk-delete", userHandler.BulkDeleteUsers, "users:delete")
+	permissionGuard.Post(userRoutes, "/bulk-roles", userHandler.BulkUserRoles, "users:write")
+	permissionGuard.Get(userRoutes, "/:id", userHandler.GetUser, "users:read")
+	permissionGuard.Put(userRoutes, "/:id", userHandler.UpdateUser, "users:write")
+	permissionGuard.Delete(userRoutes, "/:id", userHandler.DeleteUser, "users:delete")
+	userRoutes.Get("/:id/login-history",
+		middleware.RequirePolicy(policyEngine, auth.PolicyReadLoginHistory, auth.ResourceTypeUser, "id"),
+		authHandler.UserLoginHistory,
+	)
+
+	// ==================== Organization Routes ====================
+	// Organization routes; under /:orgId the organization is the active tenant and the
+	// caller must be a member, so permission checks include their roles inside it
+	orgs := api.Group("/organizations", middleware.AuthMiddleware(jwtManager, redisClient))
+	orgs.Get("", orgHandler.GetMyOrganizations)
+	orgs.Post("", orgHandler.CreateOrganization)
+
+	tenant := orgs.Group("/:orgId", middleware.RequireTenant(orgUseCase, "orgId"))
+	permissionGuard.Get(tenant, "", orgHandler.GetOrganization, "orgs:read")
+	permissionGuard.Put(tenant, "", orgHandler.UpdateOrganization, "orgs:update")
+	permissionGuard.Delete(tenant, "", orgHandler.DeleteOrganization, "orgs:delete")
+	permissionGuard.Get(tenant, "/members", orgHandler.GetMembers, "orgs:members:read")
+	permissionGuard.Post(tenant, "/members", orgHandler.AddMember, "orgs:members:write")
+	permissionGuard.Delete(tenant, "/members/:userId", orgHandler.RemoveMember, "orgs:members:write")
+	permissionGuard.Get(tenant, "/members/:userId/roles", orgHandler.GetMemberRoles, "orgs:members:read")
+	permissionGuard.Post(tenant, "/members/:userId/roles", orgHandler.AssignMemberRole, "orgs:roles:assign")
+	permissionGuard.Delete(tenant, "/members/:userId/roles/:roleId", orgHandler.RemoveMemberRole, "orgs:roles:assign")
+
+	// ==================== Access Request Routes ====================
+	// Users request roles; the role's designated approvers review them
+	accessRequests := api.Group("/access-requests", middleware.AuthMiddleware(jwtManager, redisClient))
+	accessRequests.Get("", accessRequestHandler.GetMyAccessRequests)
+	accessRequests.Post("", accessRequestHandler.CreateAccessRequest)
+	accessRequests.Get("/approvals", accessRequestHandler.GetPendingApprovals)
+	accessRequests.Post("/:id/cancel", accessRequestHandler.CancelAccessRequest)
+	accessRequests.Post("/:id/approve", accessRequestHandler.ApproveAccessRequest)
+	accessRequests.Post("/:id/deny", accessRequestHandler.DenyAccessRequest)
+
+	// ==================== Delegated Role Management Routes ====================
+	// Holders of a role's manager roles assign and remove it without being super admins
+	roleManagement := api.Group("/role-management", middleware.AuthMiddleware(jwtManager, redisClient))
+	roleManagement.Get("/roles", rbacHandler.GetManageableRoles)
+	roleManagement.Post("/users/:userId/roles", rbacHandler.AssignManagedRole)
+	roleManagement.Delete("/users/:userId/roles/:roleId", rbacHandler.RemoveManagedRole)
 
 	// ==================== Super Admin Routes ====================
 	// Super admin routes (requires super_admin role)
@@ -165,6 +308,21 @@ func main() {
 	superAdmin.Get("/users/:userId/roles", rbacHandler.GetUserRoles)
 	superAdmin.Post("/users/:userId/roles", rbacHandler.AssignRoleToUser)
 	superAdmin.Delete("/users/:userId/roles/:roleId", rbacHandler.RemoveRoleFromUser)
+	superAdmin.Get("/users/:userId/groups", rbacHandler.GetUserGroups)
+	superAdmin.Get("/users/:userId/denies", rbacHandler.GetUserDenies)
+	superAdmin.Post("/users/:userId/denies", rbacHandler.AddDenyToUser)
+	superAdmin.Delete("/users/:userId/denies/:permissionId", rbacHandler.RemoveDenyFromUser)
+
+	// Group management; members hold the group's roles
+	superAdmin.Get("/groups", rbacHandler.GetGroups)
+	superAdmin.Get("/groups/:id", rbacHandler.GetGroup)
+	superAdmin.Post("/groups", rbacHandler.CreateGroup)
+	superAdmin.Put("/groups/:id", rbacHandler.UpdateGroup)
+	superAdmin.Delete("/groups/:id", rbacHandler.DeleteGroup)
+	superAdmin.Post("/groups/:id/members", rbacHandler.AddGroupMember)
+	superAdmin.Delete("/groups/:id/members/:userId", rbacHandler.RemoveGroupMember)
+	superAdmin.Post("/groups/:id/roles", rbacHandler.AssignRoleToGroup)
+	superAdmin.Delete("/groups/:id/roles/:roleId", rbacHandler.RemoveRoleFromGroup)
 
 	// Role management
 	superAdmin.Get("/roles", rbacHandler.GetRoles)
@@ -172,13 +330,53 @@ func main() {
 	superAdmin.Post("/roles", rbacHandler.CreateRole)
 	superAdmin.Put("/roles/:id", rbacHandler.UpdateRole)
 	superAdmin.Delete("/roles/:id", rbacHandler.DeleteRole)
+	superAdmin.Put("/roles/:id/parent", rbacHandler.SetRoleParent)
+	superAdmin.Get("/roles/:id/approvers", accessRequestHandler.GetRoleApprovers)
+	superAdmin.Put("/roles/:id/approvers", accessRequestHandler.SetRoleApprovers)
+	superAdmin.Get("/roles/:id/managers", rbacHandler.GetRoleManagers)
+	superAdmin.Put("/roles/:id/managers", rbacHandler.SetRoleManagers)
 
 	// Permission management
 	superAdmin.Get("/permissions", rbacHandler.GetPermissions)
+	superAdmin.Post("/permissions", rbacHandler.CreatePermission)
+	superAdmin.Get("/permissions/matrix", rbacHandler.GetPermissionMatrix)
+	superAdmin.Put("/permissions/:id", rbacHandler.UpdatePermission)
+	superAdmin.Delete("/permissions/:id", rbacHandler.DeletePermission)
 	superAdmin.Get("/roles/:id/permissions", rbacHandler.GetRolePermissions)
 	superAdmin.Post("/roles/:id/permissions", rbacHandler.AssignPermissionToRole)
 	superAdmin.Delete("/roles/:id/permissions/:permissionId", rbacHandler.RemovePermissionFromRole)
-
+	superAdmin.Get("/roles/:id/denies", rbacHandler.GetRoleDenies)
+	superAdmin.Post("/roles/:id/denies", rbacHandler.AddDenyToRole)
+	superAdmin.Delete("/roles/:id/denies/:permissionId", rbacHandler.RemoveDenyFromRole)
+
+	// Separation of duty
+	superAdmin.Get("/role-conflicts", rbacHandler.GetRoleConflictSets)
+	superAdmin.Post("/role-conflicts", rbacHandler.CreateRoleConflictSet)
+	superAdmin.Get("/role-conflicts/violations", rbacHandler.GetRoleConflictViolations)
+	superAdmin.Get("/role-conflicts/:id", rbacHandler.GetRoleConflictSet)
+	superAdmin.Delete("/role-conflicts/:id", rbacHandler.DeleteRoleConflictSet)
+
+	// Policy as code
+	superAdmin.Get("/rbac/policy", rbacHandler.ExportPolicy)
+	superAdmin.Post("/rbac/policy", rbacHandler.ApplyPolicy)
+
+	// Change history; who could do what, and when
+	superAdmin.Get("/rbac/changes", rbacHandler.GetChanges)
+	superAdmin.Get("/users/:userId/permissions/history", rbacHandler.GetUserPermissionsAt)
+	superAdmin.Get("/users/:userId/permissions/diff", rbacHandler.DiffUserPermissions)
+
+	// Every permission a route requires must be declared by a module; declared permissions
+	// missing from the database are created
+	if err := permissionRegistry.Verify(); err != nil {
+		log.Fatalf("Permission registry check failed: %v", err)
+	}
+	seeded, err := rbacUseCase.SeedPermissions(permissionRegistry.Permissions())
+	if err != nil {
+		log.Fatalf("Failed to seed permissions: %v", err)
+	}
+	for _, permission := range seeded {
+		log.Printf("Seeded permission %s", permission.Name)
+	}
 
 	// Health check - HTML UI
 	api.Get("/health", func(c *fiber.Ctx) error {
@@ -261,3 +459,31 @@ func main() {
 
 	log.Println("Server exited")
 }
+
+// newChallengeGuard builds the register/login bot challenge from configuration
+func newChallengeGuard(cfg config.ChallengeConfig, redisClient *database.RedisClient) *challenge.Guard {
+	var verifier challenge.Verifier
+	switch cfg.Provider {
+	case challenge.ProviderProofOfWork:
+		verifier = challenge.NewProofOfWorkVerifier(redisClient, challenge.ProofOfWorkConfig{
+			KeyPrefix:  "challenge:pow",
+			Difficulty: cfg.Difficulty,
+			TTL:        cfg.TTL,
+		})
+	case challenge.ProviderHosted:
+		verifier = challenge.NewHostedCaptchaVerifier(challenge.HostedCaptchaConfig{
+			VerifyURL: cfg.CaptchaVerifyURL,
+			Secret:    cfg.CaptchaSecret,
+			SiteKey:   cfg.CaptchaSiteKey,
+			Timeout:   10 * time.Second,
+		})
+	default:
+		log.Fatalf("Unknown challenge provider: %q", cfg.Provider)
+	}
+
+	tracker := challenge.NewRiskTracker(redisClient, "challenge:risk", cfg.Window)
+	return challenge.NewGuard(verifier, tracker, map[string]int{
+		challenge.ScopeLogin:    cfg.LoginFailures,
+		challenge.ScopeRegister: cfg.RegisterAttempts,
+	})
+}
@@ -13,6 +13,28 @@ type SuccessResponse struct {
 	Timestamp time.Time   `json:"timestamp"`
 }
 
+// PaginatedResponse represents a successful paginated API response
+// @Description Standard paginated response wrapper
+type PaginatedResponse struct {
+	Status    bool         `json:"status" example:"true"`
+	Code      int          `json:"code" example:"200"`
+	Message   string       `json:"message" example:"Operation successful"`
+	Data      interface{}  `json:"data,omitempty"`
+	Meta      MetaResponse `json:"meta"`
+	Timestamp time.Time    `json:"timestamp"`
+}
+
+// MetaResponse represents pagination metadata
+// @Description Pagination metadata
+type MetaResponse struct {
+	Page      int64 `json:"page" example:"1"`
+	PageSize  int64 `json:"page_size" example:"20"`
+	Total     int64 `json:"total" example:"42"`
+	TotalPage int64 `json:"total_page" example:"3"`
+	IsNext    bool  `json:"is_next" example:"true"`
+	IsBack    bool  `json:"is_back" example:"false"`
+}
+
 // ErrorResponse represents an error API response
 // @Description Standard error response wrapper
 type ErrorResponse struct {
@@ -24,14 +46,16 @@ type ErrorResponse struct {
 }
 
 // UserResponse represents user data in responses
-// @Description User information
+// @Description User information; email and phone are only shown to the user and to holders of users:read
 type UserResponse struct {
-	ID        string    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	Name      string    `json:"name" example:"John Doe"`
-	Email     string    `json:"email" example:"john@example.com"`
-	Role      string    `json:"role" example:"user"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              string     `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name            string     `json:"name" example:"John Doe"`
+	Username        string     `json:"username,omitempty" example:"johndoe"`
+	Email           string     `json:"email,omitempty" example:"john@example.com"`
+	Phone           string     `json:"phone,omitempty" example:"+6281234567890"`
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 }
 
 // AuthResponse represents authentication response with user and tokens
@@ -53,13 +77,43 @@ type TokenResponse struct {
 	ExpiresIn    int64  `json:"expires_in" example:"86400"`
 }
 
+// LoginEventResponse represents a login history entry
+// @Description Login attempt information
+type LoginEventResponse struct {
+	ID            string    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Method        string    `json:"method" example:"password"`
+	Success       bool      `json:"success" example:"true"`
+	FailureReason string    `json:"failure_reason,omitempty" example:"PASSWORD_MISMATCH"`
+	IPAddress     string    `json:"ip_address" example:"203.0.113.10"`
+	UserAgent     string    `json:"user_agent" example:"Mozilla/5.0"`
+	CountryCode   string    `json:"country_code,omitempty" example:"ID"`
+	Country       string    `json:"country,omitempty" example:"Indonesia"`
+	City          string    `json:"city,omitempty" example:"Jakarta"`
+	Suspicious    bool      `json:"suspicious" example:"false"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
 // RoleResponse represents role data
 // @Description Role information
 type RoleResponse struct {
 	ID          string    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
 	Name        string    `json:"name" example:"admin"`
 	Description string    `json:"description,omitempty" example:"Administrator role"`
+	ParentID    string    `json:"parent_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440001"`
 	CreatedAt   time.Time `json:"created_at"`
+	// Set only when listing a user's roles
+	ValidFrom  *time.Time `json:"valid_from,omitempty"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
+	// Set only when listing a user's roles held through a group
+	Group string `json:"group,omitempty" example:"engineering"`
+}
+
+// RoleWithPermissionsResponse represents a role with its direct permissions and ancestors
+// @Description Role details; permissions are those assigned directly, ancestors are listed nearest first
+type RoleWithPermissionsResponse struct {
+	RoleResponse
+	Permissions []PermissionResponse `json:"permissions"`
+	Ancestors   []RoleResponse       `json:"ancestors"`
 }
 
 // PermissionResponse represents permission data
@@ -70,9 +124,34 @@ type PermissionResponse struct {
 	Description string    `json:"description,omitempty" example:"View users"`
 	Resource    string    `json:"resource" example:"users"`
 	Action      string    `json:"action" example:"read"`
+	IsSystem    bool      `json:"is_system" example:"true"`
 	CreatedAt   time.Time `json:"created_at"`
 }
 
+// PermissionMatrixResponse represents the route-permission matrix
+// @Description Declared permissions with the routes requiring them, and permission-guarded routes with their permissions
+type PermissionMatrixResponse struct {
+	Permissions []PermissionMatrixEntryResponse `json:"permissions"`
+	Routes      []RouteRequirementResponse      `json:"routes"`
+}
+
+// PermissionMatrixEntryResponse represents a declared permission and the routes requiring it
+// @Description Declared permission; routes is empty when the permission is only checked in code, e.g. by access policies
+type PermissionMatrixEntryResponse struct {
+	Name        string   `json:"name" example:"users:read"`
+	Description string   `json:"description" example:"View user list and details"`
+	Module      string   `json:"module" example:"users"`
+	Routes      []string `json:"routes" example:"GET /api/v1/users"`
+}
+
+// RouteRequirementResponse represents a route and the permissions it requires
+// @Description Permission-guarded route; holding any of the permissions is enough
+type RouteRequirementResponse struct {
+	Method      string   `json:"method" example:"GET"`
+	Path        string   `json:"path" example:"/api/v1/users/:id"`
+	Permissions []string `json:"permissions" example:"users:read"`
+}
+
 // UserRolesResponse represents user with their roles
 // @Description User roles response
 type UserRolesResponse struct {
@@ -80,18 +159,273 @@ type UserRolesResponse struct {
 	Roles  []RoleResponse `json:"roles"`
 }
 
+// UserDeniesResponse represents the per-user permission denies of a user
+// @Description Permissions denied to the user whatever roles they hold
+type UserDeniesResponse struct {
+	UserID string               `json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Denies []PermissionResponse `json:"denies"`
+}
+
+// UserDetailResponse represents a user with the global roles they hold
+// @Description User information with global roles, including those held through groups
+type UserDetailResponse struct {
+	UserResponse
+	Roles []RoleResponse `json:"roles"`
+}
+
+// BulkResultResponse represents the outcome of a bulk user operation
+// @Description Users the operation changed and the error met for each of the others
+type BulkResultResponse struct {
+	Succeeded []string              `json:"succeeded"`
+	Failed    []BulkFailureResponse `json:"failed"`
+}
+
+// BulkFailureResponse represents the error a bulk operation met for one user
+// @Description Per-user failure of a bulk operation
+type BulkFailureResponse struct {
+	UserID    string `json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	ErrorCode int    `json:"error_code" example:"-1311"`
+	Error     string `json:"error" example:"PRIVILEGE_ESCALATION"`
+}
+
+// RoleConflictSetResponse represents a separation-of-duty conflict set
+// @Description Mutually exclusive roles; nobody may hold more than one of them
+type RoleConflictSetResponse struct {
+	ID          string         `json:"id" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	Name        string         `json:"name" example:"payments"`
+	Description string         `json:"description,omitempty" example:"Payments are created and approved by different people"`
+	Roles       []RoleResponse `json:"roles"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// RoleConflictViolationResponse represents a user holding several roles of one conflict set
+// @Description Existing separation-of-duty violation; roles are those of the set the user holds
+type RoleConflictViolationResponse struct {
+	ConflictSet string   `json:"conflict_set" example:"payments"`
+	UserID      string   `json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name        string   `json:"name" example:"John Doe"`
+	Email       string   `json:"email" example:"john@example.com"`
+	Roles       []string `json:"roles" example:"payments_approver,payments_creator"`
+}
+
+// GroupResponse represents a user group
+// @Description Group information
+type GroupResponse struct {
+	ID          string    `json:"id" example:"9b1deb4d-3b7d-4bad-9bdd-2b0d7b3dcb6d"`
+	Name        string    `json:"name" example:"engineering"`
+	Description string    `json:"description,omitempty" example:"Engineering department"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// GroupMemberResponse represents a group member
+// @Description Group member information
+type GroupMemberResponse struct {
+	UserID   string    `json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name     string    `json:"name" example:"John Doe"`
+	Email    string    `json:"email" example:"user@example.com"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// GroupDetailResponse represents a group with its members and roles
+// @Description Group details; members hold the listed roles through the group
+type GroupDetailResponse struct {
+	GroupResponse
+	Members []GroupMemberResponse `json:"members"`
+	Roles   []RoleResponse        `json:"roles"`
+}
+
+// UserGroupsResponse represents the groups a user belongs to
+// @Description User groups response
+type UserGroupsResponse struct {
+	UserID string          `json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Groups []GroupResponse `json:"groups"`
+}
+
+// OrganizationResponse represents an organization (tenant)
+// @Description Organization information; created_by is only shown to those who may update the organization
+type OrganizationResponse struct {
+	ID        string    `json:"id" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	Name      string    `json:"name" example:"Acme Corp"`
+	Slug      string    `json:"slug" example:"acme-corp"`
+	CreatedBy string    `json:"created_by,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MemberResponse represents an organization member
+// @Description Organization member information; the email is only shown to the member and to holders of users:read
+type MemberResponse struct {
+	UserID   string    `json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name     string    `json:"name" example:"John Doe"`
+	Email    string    `json:"email,omitempty" example:"user@example.com"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// MemberRolesResponse represents the roles a member holds inside an organization
+// @Description Organization member roles response
+type MemberRolesResponse struct {
+	OrganizationID string         `json:"organization_id" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	UserID         string         `json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Roles          []RoleResponse `json:"roles"`
+}
+
+// AccessRequestResponse represents a request for a role
+// @Description Access request; status is pending, approved, denied or cancelled
+type AccessRequestResponse struct {
+	ID            string     `json:"id" example:"3f2b8c1e-9d4a-4e6b-8f1c-2a5d7e9b0c31"`
+	RequesterID   string     `json:"requester_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	RoleID        string     `json:"role_id" example:"550e8400-e29b-41d4-a716-446655440001"`
+	RoleName      string     `json:"role_name" example:"admin"`
+	Justification string     `json:"justification" example:"Covering the on-call rotation this week"`
+	ValidUntil    *time.Time `json:"valid_until,omitempty" example:"2025-12-27T09:00:00Z"`
+	Status        string     `json:"status" example:"pending"`
+	ReviewerID    string     `json:"reviewer_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440002"`
+	ReviewNote    string     `json:"review_note,omitempty" example:"Approved for the rotation"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// ApproverResponse represents a role approver
+// @Description Role approver information
+type ApproverResponse struct {
+	UserID string `json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name   string `json:"name" example:"John Doe"`
+	Email  string `json:"email" example:"user@example.com"`
+}
+
+// PermissionGrantResponse explains how a role grants a permission
+// @Description Role and inheritance path granting, or that would grant, a permission
+type PermissionGrantResponse struct {
+	Role           string   `json:"role" example:"admin"`
+	Path           []string `json:"path" example:"admin,user"`
+	Pattern        string   `json:"pattern" example:"profile:*"`
+	Assigned       bool     `json:"assigned" example:"true"`
+	OrganizationID string   `json:"organization_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Group          string   `json:"group,omitempty" example:"engineering"`
+}
+
+// DenyRuleResponse names the deny rule that refused a permission
+// @Description Deny rule; role is empty for a per-user override
+type DenyRuleResponse struct {
+	Pattern string `json:"pattern" example:"users:delete"`
+	Role    string `json:"role,omitempty" example:"admin"`
+}
+
+// PermissionDecisionResponse represents the decision for one permission
+// @Description Allow/deny decision; denied_by is set when a deny rule overrode the grants, which are only present in explain mode
+type PermissionDecisionResponse struct {
+	Permission string                    `json:"permission" example:"profile:read"`
+	Allowed    bool                      `json:"allowed" example:"true"`
+	MatchedBy  string                    `json:"matched_by,omitempty" example:"profile:*"`
+	DeniedBy   *DenyRuleResponse         `json:"denied_by,omitempty"`
+	Grants     []PermissionGrantResponse `json:"grants,omitempty"`
+}
+
+// PermissionCheckResponse represents the result of a batch permission check
+// @Description Decisions for each checked permission
+type PermissionCheckResponse struct {
+	UserID         string                       `json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	OrganizationID string                       `json:"organization_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Results        []PermissionDecisionResponse `json:"results"`
+}
+
+// PolicyDocument represents roles, permissions and role-permission mappings as code
+// @Description RBAC policy document; role parents and role permissions must be declared in it
+type PolicyDocument struct {
+	Permissions []PermissionSpec `json:"permissions"`
+	Roles       []RoleSpec       `json:"roles"`
+}
+
+// PermissionSpec represents a declared permission
+// @Description Permission declaration; an empty description leaves the stored one unchanged
+type PermissionSpec struct {
+	Name        string `json:"name" example:"reports:read"`
+	Description string `json:"description,omitempty" example:"View reports"`
+}
+
+// RoleSpec represents a declared role
+// @Description Role declaration with its parent, directly assigned permissions and denied permissions
+type RoleSpec struct {
+	Name        string   `json:"name" example:"analyst"`
+	Description string   `json:"description,omitempty" example:"Reads and exports reports"`
+	Parent      string   `json:"parent,omitempty" example:"user"`
+	Permissions []string `json:"permissions,omitempty" example:"reports:read,reports:export"`
+	Deny        []string `json:"deny,omitempty" example:"reports:delete"`
+}
+
+// PolicyChangeResponse represents one planned or applied change
+// @Description Policy change; detail is the new description, the new parent, or the mapped or denied permission
+type PolicyChangeResponse struct {
+	Action string `json:"action" example:"create"`
+	Kind   string `json:"kind" example:"role_permission"`
+	Name   string `json:"name" example:"analyst"`
+	Detail string `json:"detail,omitempty" example:"reports:export"`
+}
+
+// PolicyPlanResponse represents the result of applying a policy document
+// @Description Policy plan; on a dry run nothing was committed
+type PolicyPlanResponse struct {
+	DryRun  bool                   `json:"dry_run" example:"true"`
+	Changes []PolicyChangeResponse `json:"changes"`
+}
+
+// ChangeRecordResponse represents one recorded RBAC change
+// @Description Row changed in an RBAC table; old_row is null for inserts and new_row for deletes. actor_id and request_id are empty for changes made outside the RBAC endpoints, e.g. on registration or by the expiry sweeper
+type ChangeRecordResponse struct {
+	ID        int64                  `json:"id" example:"42"`
+	Table     string                 `json:"table" example:"user_roles"`
+	Action    string                 `json:"action" example:"INSERT"`
+	OldRow    map[string]interface{} `json:"old_row"`
+	NewRow    map[string]interface{} `json:"new_row"`
+	ActorID   string                 `json:"actor_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	RequestID string                 `json:"request_id,omitempty" example:"0f8fad5b-d9cb-469f-a165-70867728950e"`
+	ChangedAt time.Time              `json:"changed_at" example:"2025-12-20T14:00:00Z"`
+}
+
+// PermissionSnapshotResponse represents what a user could do at a point in time
+// @Description Roles held directly or through groups at the time, with the permissions and denies of those roles and their ancestors; denies win over permissions
+type PermissionSnapshotResponse struct {
+	UserID         string    `json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	OrganizationID string    `json:"organization_id,omitempty" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	At             time.Time `json:"at" example:"2025-12-16T09:00:00Z"`
+	Roles          []string  `json:"roles" example:"editor"`
+	Permissions    []string  `json:"permissions" example:"users:read"`
+	Denies         []string  `json:"denies" example:"users:delete"`
+}
+
+// NameDiffResponse represents names gained and lost between two points in time
+// @Description Names present only at the later time are added, names present only at the earlier time removed
+type NameDiffResponse struct {
+	Added   []string `json:"added" example:"users:write"`
+	Removed []string `json:"removed" example:"reports:export"`
+}
+
+// PermissionDiffResponse represents the difference between two permission snapshots
+// @Description What a user could do at two points in time and what changed in between
+type PermissionDiffResponse struct {
+	From        PermissionSnapshotResponse `json:"from"`
+	To          PermissionSnapshotResponse `json:"to"`
+	Roles       NameDiffResponse           `json:"roles"`
+	Permissions NameDiffResponse           `json:"permissions"`
+	Denies      NameDiffResponse           `json:"denies"`
+}
+
 // RegisterRequest represents registration payload
 // @Description User registration request
 type RegisterRequest struct {
 	Email    string `json:"email" example:"user@example.com" validate:"required,email"`
 	Password string `json:"password" example:"password123" validate:"required,min=6"`
 	Name     string `json:"name" example:"John Doe" validate:"required,min=2"`
+	Username string `json:"username,omitempty" example:"johndoe" validate:"omitempty,username"`
 }
 
 // LoginRequest represents login payload
-// @Description User login request
+// @Description User login request; provide either email or username
 type LoginRequest struct {
-	Email    string `json:"email" example:"user@example.com" validate:"required,email"`
+	Email    string `json:"email,omitempty" example:"user@example.com" validate:"required_without=Username,omitempty,email"`
+	Username string `json:"username,omitempty" example:"johndoe" validate:"required_without=Email,omitempty,username"`
 	Password string `json:"password" example:"password123" validate:"required"`
 }
 
@@ -101,10 +435,55 @@ type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" example:"eyJhbGciOiJIUzI1NiIs..." validate:"required"`
 }
 
+// SwitchOrganizationRequest represents active organization selection payload
+// @Description Switch active organization request; an empty organization_id clears the tenant
+type SwitchOrganizationRequest struct {
+	OrganizationID string `json:"organization_id" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7" validate:"omitempty,uuid"`
+}
+
 // UpdateProfileRequest represents profile update payload
 // @Description Profile update request
 type UpdateProfileRequest struct {
-	Name string `json:"name" example:"John Updated" validate:"omitempty,min=2"`
+	Name     string `json:"name" example:"John Updated" validate:"omitempty,min=2"`
+	Username string `json:"username,omitempty" example:"johnupdated" validate:"omitempty,username"`
+}
+
+// ChallengeResponse represents a bot challenge
+// @Description Challenge to solve; proof_of_work uses token and difficulty, captcha uses site_key
+type ChallengeResponse struct {
+	Type       string    `json:"type" example:"proof_of_work" enums:"proof_of_work,captcha"`
+	Token      string    `json:"token,omitempty" example:"3f2a9c1e5b7d4a6f8e0c2b4d6f8a0c2e"`
+	Difficulty int       `json:"difficulty,omitempty" example:"20"`
+	SiteKey    string    `json:"site_key,omitempty"`
+	ExpiresAt  time.Time `json:"expires_at,omitempty"`
+}
+
+// PhoneRequest represents a phone number payload
+// @Description Phone number in E.164 format
+type PhoneRequest struct {
+	Phone string `json:"phone" example:"+6281234567890" validate:"required,e164"`
+}
+
+// VerifyPhoneRequest represents phone verification payload
+// @Description Phone number and the code sent to it
+type VerifyPhoneRequest struct {
+	Phone string `json:"phone" example:"+6281234567890" validate:"required,e164"`
+	Code  string `json:"code" example:"123456" validate:"required,numeric"`
+}
+
+// OTPLoginRequest represents SMS code login payload
+// @Description Phone number and the login code sent to it
+type OTPLoginRequest struct {
+	Phone string `json:"phone" example:"+6281234567890" validate:"required,e164"`
+	Code  string `json:"code" example:"123456" validate:"required,numeric"`
+}
+
+// UsernameAvailabilityResponse represents username availability check result
+// @Description Username availability; reason is set when unavailable
+type UsernameAvailabilityResponse struct {
+	Username  string `json:"username" example:"johndoe"`
+	Available bool   `json:"available" example:"false"`
+	Reason    string `json:"reason,omitempty" example:"taken" enums:"invalid_format,reserved,taken"`
 }
 
 // CreateRoleRequest represents role creation payload
@@ -114,10 +493,36 @@ type CreateRoleRequest struct {
 	Description string `json:"description" example:"Content moderator" validate:"max=255"`
 }
 
-// AssignRoleRequest represents role assignment payload
-// @Description Role assignment request
-type AssignRoleRequest struct {
-	RoleID string `json:"role_id" example:"550e8400-e29b-41d4-a716-446655440000" validate:"required,uuid"`
+// AssignRoleToUserRequest represents role assignment payload
+// @Description Role assignment request; omit valid_from to start now and valid_until to never expire
+type AssignRoleToUserRequest struct {
+	RoleID     string     `json:"role_id" example:"550e8400-e29b-41d4-a716-446655440000" validate:"required,uuid"`
+	ValidFrom  *time.Time `json:"valid_from,omitempty" example:"2025-12-20T09:00:00Z"`
+	ValidUntil *time.Time `json:"valid_until,omitempty" example:"2025-12-27T09:00:00Z"`
+}
+
+// SetRoleParentRequest represents role parent payload
+// @Description Role parent request; an empty parent_id clears the parent
+type SetRoleParentRequest struct {
+	ParentID string `json:"parent_id" example:"550e8400-e29b-41d4-a716-446655440000" validate:"omitempty,uuid"`
+}
+
+// CreatePermissionRequest represents permission creation payload
+// @Description Permission creation request; name must equal resource:action
+type CreatePermissionRequest struct {
+	Name        string `json:"name" example:"reports:export" validate:"required,max=100"`
+	Resource    string `json:"resource" example:"reports" validate:"required,max=50"`
+	Action      string `json:"action" example:"export" validate:"required,max=20"`
+	Description string `json:"description" example:"Export reports as CSV" validate:"max=255"`
+}
+
+// UpdatePermissionRequest represents permission update payload
+// @Description Permission update request; empty fields are left unchanged
+type UpdatePermissionRequest struct {
+	Name        string `json:"name" example:"reports:export"`
+	Resource    string `json:"resource" example:"reports"`
+	Action      string `json:"action" example:"export"`
+	Description string `json:"description" example:"Export reports as CSV or PDF"`
 }
 
 // AssignPermissionRequest represents permission assignment payload
@@ -125,3 +530,129 @@ type AssignRoleRequest struct {
 type AssignPermissionRequest struct {
 	PermissionID string `json:"permission_id" example:"550e8400-e29b-41d4-a716-446655440000" validate:"required,uuid"`
 }
+
+// CreateOrganizationRequest represents organization creation payload
+// @Description Create organization request
+type CreateOrganizationRequest struct {
+	Name string `json:"name" example:"Acme Corp" validate:"required,min=2,max=255"`
+	Slug string `json:"slug" example:"acme-corp" validate:"required,min=2,max=100,slug"`
+}
+
+// UpdateOrganizationRequest represents organization update payload
+// @Description Update organization request
+type UpdateOrganizationRequest struct {
+	Name string `json:"name" example:"Acme Corporation" validate:"required,min=2,max=255"`
+}
+
+// AddMemberRequest represents organization member payload
+// @Description Add organization member request
+type AddMemberRequest struct {
+	UserID string `json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000" validate:"required,uuid"`
+}
+
+// AssignMemberRoleRequest represents tenant-scoped role assignment payload
+// @Description Assign role inside an organization request
+type AssignMemberRoleRequest struct {
+	RoleID string `json:"role_id" example:"550e8400-e29b-41d4-a716-446655440000" validate:"required,uuid"`
+}
+
+// CreateAccessRequestRequest represents role request payload
+// @Description Request a role; omit valid_until to ask for a permanent grant
+type CreateAccessRequestRequest struct {
+	RoleID        string     `json:"role_id" example:"550e8400-e29b-41d4-a716-446655440001" validate:"required,uuid"`
+	Justification string     `json:"justification" example:"Covering the on-call rotation this week" validate:"required,min=10,max=1000"`
+	ValidUntil    *time.Time `json:"valid_until,omitempty" example:"2025-12-27T09:00:00Z"`
+}
+
+// ReviewAccessRequestRequest represents access request review payload
+// @Description Approve or deny an access request with an optional note
+type ReviewAccessRequestRequest struct {
+	Note string `json:"note" example:"Approved for the rotation" validate:"max=1000"`
+}
+
+// SetApproversRequest represents role approvers payload
+// @Description Replace a role's approvers; an empty list makes the role unrequestable
+type SetApproversRequest struct {
+	UserIDs []string `json:"user_ids" example:"550e8400-e29b-41d4-a716-446655440000" validate:"dive,uuid"`
+}
+
+// CheckPermissionsRequest represents a batch permission check payload
+// @Description Permissions to check; explain and user_id are Super Admin only
+type CheckPermissionsRequest struct {
+	Permissions []string `json:"permissions" example:"profile:read,users:delete" validate:"required"`
+	Explain     bool     `json:"explain" example:"false"`
+	UserID      string   `json:"user_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+}
+
+// CreateGroupRequest represents group creation payload
+// @Description Group creation request
+type CreateGroupRequest struct {
+	Name        string `json:"name" example:"engineering" validate:"required,min=2,max=100"`
+	Description string `json:"description" example:"Engineering department" validate:"max=255"`
+}
+
+// UpdateGroupRequest represents group update payload
+// @Description Group update request; empty fields are left unchanged
+type UpdateGroupRequest struct {
+	Name        string `json:"name" example:"engineering" validate:"omitempty,min=2,max=100"`
+	Description string `json:"description" example:"Engineering department" validate:"max=255"`
+}
+
+// AddGroupMemberRequest represents group member payload
+// @Description Add group member request
+type AddGroupMemberRequest struct {
+	UserID string `json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000" validate:"required,uuid"`
+}
+
+// AssignRoleToGroupRequest represents group role assignment payload
+// @Description Assign role to group request
+type AssignRoleToGroupRequest struct {
+	RoleID string `json:"role_id" example:"550e8400-e29b-41d4-a716-446655440000" validate:"required,uuid"`
+}
+
+// CreateRoleConflictSetRequest represents conflict set creation payload
+// @Description Separation-of-duty conflict set creation request; at least two distinct roles
+type CreateRoleConflictSetRequest struct {
+	Name        string   `json:"name" example:"payments" validate:"required,min=2,max=100"`
+	Description string   `json:"description" example:"Payments are created and approved by different people" validate:"max=255"`
+	RoleIDs     []string `json:"role_ids" validate:"required,min=2,unique,dive,uuid"`
+}
+
+// SetRoleManagersRequest represents role managers payload
+// @Description Replace the roles whose holders manage a role; an empty list leaves it to super admins
+type SetRoleManagersRequest struct {
+	RoleIDs []string `json:"role_ids" validate:"dive,uuid"`
+}
+
+// CreateUserRequest represents admin user creation payload
+// @Description User creation request; the user is given the default role
+type CreateUserRequest struct {
+	Name     string `json:"name" example:"John Doe" validate:"required,min=2,max=100"`
+	Username string `json:"username,omitempty" example:"johndoe" validate:"omitempty,username"`
+	Email    string `json:"email" example:"user@example.com" validate:"required,email"`
+	Password string `json:"password" example:"password123" validate:"required,min=6,max=100"`
+}
+
+// UpdateUserRequest represents admin user update payload
+// @Description User update request; an empty username or password is left unchanged, a new password ends the user's sessions
+type UpdateUserRequest struct {
+	Name     string `json:"name" example:"John Doe" validate:"required,min=2,max=100"`
+	Username string `json:"username,omitempty" example:"johndoe" validate:"omitempty,username"`
+	Email    string `json:"email" example:"user@example.com" validate:"required,email"`
+	Password string `json:"password,omitempty" example:"password123" validate:"omitempty,min=6,max=100"`
+}
+
+// BulkDeleteRequest represents bulk user deletion payload
+// @Description Delete up to 100 users
+type BulkDeleteRequest struct {
+	UserIDs []string `json:"user_ids" validate:"required,min=1,max=100,unique,dive,uuid"`
+}
+
+// BulkRoleRequest represents bulk role assignment payload
+// @Description Assign a role to, or remove it from, up to 100 users; valid_until only applies to assign
+type BulkRoleRequest struct {
+	Action     string     `json:"action" example:"assign" validate:"required,oneof=assign remove"`
+	RoleID     string     `json:"role_id" example:"550e8400-e29b-41d4-a716-446655440000" validate:"required,uuid"`
+	UserIDs    []string   `json:"user_ids" validate:"required,min=1,max=100,unique,dive,uuid"`
+	ValidUntil *time.Time `json:"valid_until,omitempty" example:"2025-12-27T09:00:00Z"`
+}
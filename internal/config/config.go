@@ -15,6 +15,12 @@ type Config struct {
 	Security  SecurityConfig
 	CORS      CORSConfig
 	RateLimit RateLimitConfig
+	GeoIP     GeoIPConfig
+	SMS       SMSConfig
+	OTP       OTPConfig
+	LDAP      LDAPConfig
+	Challenge ChallengeConfig
+	RBAC      RBACConfig
 }
 
 type AppConfig struct {
@@ -50,6 +56,10 @@ type JWTConfig struct {
 	Secret        string
 	Expiry        time.Duration
 	RefreshExpiry time.Duration
+
+	// EmbedPermissions puts RBAC roles and permissions in access tokens so permission checks
+	// need no lookups; tokens are rejected once the user's assignments change
+	EmbedPermissions bool
 }
 
 type SecurityConfig struct {
@@ -67,6 +77,56 @@ type RateLimitConfig struct {
 	Window time.Duration
 }
 
+type GeoIPConfig struct {
+	DatabasePath string
+}
+
+type SMSConfig struct {
+	Driver   string
+	FilePath string
+}
+
+type LDAPConfig struct {
+	Enabled            bool
+	URL                string
+	StartTLS           bool
+	InsecureSkipVerify bool
+	BindDN             string
+	BindPassword       string
+	BaseDN             string
+	UserFilter         string
+	IDAttribute        string
+	EmailAttribute     string
+	NameAttribute      string
+	GroupAttribute     string
+	GroupRoleMap       map[string]string
+	Timeout            time.Duration
+}
+
+type ChallengeConfig struct {
+	Enabled          bool
+	Provider         string
+	Difficulty       int
+	TTL              time.Duration
+	Window           time.Duration
+	LoginFailures    int
+	RegisterAttempts int
+	CaptchaVerifyURL string
+	CaptchaSecret    string
+	CaptchaSiteKey   string
+}
+
+type RBACConfig struct {
+	ExpirySweepInterval time.Duration
+}
+
+type OTPConfig struct {
+	Length         int
+	TTL            time.Duration
+	MaxAttempts    int
+	ResendInterval time.Duration
+}
+
 func New() *Config {
 	return &Config{
 		App: AppConfig{
@@ -99,6 +159,8 @@ func New() *Config {
 			Secret:        getEnv("JWT_SECRET", "your-secret-key"),
 			Expiry:        parseDuration(getEnv("JWT_EXPIRY", "24h"), 24*time.Hour),
 			RefreshExpiry: parseDuration(getEnv("JWT_REFRESH_EXPIRY", "168h"), 168*time.Hour),
+
+			EmbedPermissions: getEnv("JWT_EMBED_PERMISSIONS", "false") == "true",
 		},
 		Security: SecurityConfig{
 			BCryptCost: 12,
@@ -112,6 +174,50 @@ func New() *Config {
 			Max:    parseInt(getEnv("RATE_LIMIT_MAX", "100"), 100),
 			Window: parseDuration(getEnv("RATE_LIMIT_WINDOW", "1m"), time.Minute),
 		},
+		GeoIP: GeoIPConfig{
+			DatabasePath: getEnv("GEOIP_DATABASE_PATH", ""),
+		},
+		SMS: SMSConfig{
+			Driver:   getEnv("SMS_DRIVER", "console"),
+			FilePath: getEnv("SMS_FILE_PATH", ""),
+		},
+		LDAP: LDAPConfig{
+			Enabled:            getEnv("LDAP_ENABLED", "false") == "true",
+			URL:                getEnv("LDAP_URL", "ldap://localhost:389"),
+			StartTLS:           getEnv("LDAP_START_TLS", "false") == "true",
+			InsecureSkipVerify: getEnv("LDAP_INSECURE_SKIP_VERIFY", "false") == "true",
+			BindDN:             getEnv("LDAP_BIND_DN", ""),
+			BindPassword:       getEnv("LDAP_BIND_PASSWORD", ""),
+			BaseDN:             getEnv("LDAP_BASE_DN", ""),
+			UserFilter:         getEnv("LDAP_USER_FILTER", "(|(uid=%s)(mail=%s)(sAMAccountName=%s))"),
+			IDAttribute:        getEnv("LDAP_ID_ATTRIBUTE", ""),
+			EmailAttribute:     getEnv("LDAP_EMAIL_ATTRIBUTE", "mail"),
+			NameAttribute:      getEnv("LDAP_NAME_ATTRIBUTE", "cn"),
+			GroupAttribute:     getEnv("LDAP_GROUP_ATTRIBUTE", "memberOf"),
+			GroupRoleMap:       parseMapping(getEnv("LDAP_GROUP_ROLE_MAP", "")),
+			Timeout:            parseDuration(getEnv("LDAP_TIMEOUT", "10s"), 10*time.Second),
+		},
+		Challenge: ChallengeConfig{
+			Enabled:          getEnv("CHALLENGE_ENABLED", "true") == "true",
+			Provider:         getEnv("CHALLENGE_PROVIDER", "pow"),
+			Difficulty:       parseInt(getEnv("CHALLENGE_POW_DIFFICULTY", "20"), 20),
+			TTL:              parseDuration(getEnv("CHALLENGE_TTL", "5m"), 5*time.Minute),
+			Window:           parseDuration(getEnv("CHALLENGE_WINDOW", "15m"), 15*time.Minute),
+			LoginFailures:    parseInt(getEnv("CHALLENGE_LOGIN_FAILURES", "5"), 5),
+			RegisterAttempts: parseInt(getEnv("CHALLENGE_REGISTER_ATTEMPTS", "3"), 3),
+			CaptchaVerifyURL: getEnv("CHALLENGE_CAPTCHA_VERIFY_URL", ""),
+			CaptchaSecret:    getEnv("CHALLENGE_CAPTCHA_SECRET", ""),
+			CaptchaSiteKey:   getEnv("CHALLENGE_CAPTCHA_SITE_KEY", ""),
+		},
+		OTP: OTPConfig{
+			Length:         parseInt(getEnv("OTP_LENGTH", "6"), 6),
+			TTL:            parseDuration(getEnv("OTP_TTL", "5m"), 5*time.Minute),
+			MaxAttempts:    parseInt(getEnv("OTP_MAX_ATTEMPTS", "5"), 5),
+			ResendInterval: parseDuration(getEnv("OTP_RESEND_INTERVAL", "1m"), time.Minute),
+		},
+		RBAC: RBACConfig{
+			ExpirySweepInterval: parseDuration(getEnv("RBAC_EXPIRY_SWEEP_INTERVAL", "1m"), time.Minute),
+		},
 	}
 }
 
@@ -143,3 +249,21 @@ func splitAndTrim(value string) []string {
 	}
 	return parts
 }
+
+// parseMapping parses "key:value;key:value" pairs. Keys may contain commas and '=' (e.g. LDAP DNs),
+// so the value is taken after the last ':'.
+func parseMapping(value string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(value, ";") {
+		idx := strings.LastIndex(pair, ":")
+		if idx <= 0 {
+			continue
+		}
+		key := strings.TrimSpace(pair[:idx])
+		val := strings.TrimSpace(pair[idx+1:])
+		if key != "" && val != "" {
+			result[key] = val
+		}
+	}
+	return result
+}
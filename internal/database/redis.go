@@ -47,6 +47,11 @@ func (c *RedisClient) SetWithTTL(ctx context.Context, key string, value interfac
 	return c.Client.Set(ctx, key, value, ttl).Err()
 }
 
+// SetIfAbsent sets the key only if it does not exist and reports whether it did
+func (c *RedisClient) SetIfAbsent(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	return c.Client.SetNX(ctx, key, value, ttl).Result()
+}
+
 func (c *RedisClient) GetValue(ctx context.Context, key string) (string, error) {
 	val, err := c.Client.Get(ctx, key).Result()
 	if err == redis.Nil {
@@ -55,6 +60,29 @@ func (c *RedisClient) GetValue(ctx context.Context, key string) (string, error)
 	return val, err
 }
 
+// GetAndDelete atomically returns the value and deletes the key; a missing key returns ""
+func (c *RedisClient) GetAndDelete(ctx context.Context, key string) (string, error) {
+	val, err := c.Client.GetDel(ctx, key).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return val, err
+}
+
+// deleteIfEqualScript deletes KEYS[1] only while it holds ARGV[1]
+var deleteIfEqualScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// DeleteIfEqual atomically deletes the key if it holds value and reports whether it did
+func (c *RedisClient) DeleteIfEqual(ctx context.Context, key, value string) (bool, error) {
+	deleted, err := deleteIfEqualScript.Run(ctx, c.Client, []string{key}, value).Int()
+	return deleted == 1, err
+}
+
 func (c *RedisClient) DeleteKey(ctx context.Context, key string) error {
 	return c.Client.Del(ctx, key).Err()
 }
@@ -99,6 +127,14 @@ func (rh *RedisHelper) SetWithTTL(ctx context.Context, key, value string, ttl ti
 	return rh.handleRedisError(err, errors.CacheStoreFailed)
 }
 
+func (rh *RedisHelper) SetIfAbsent(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	set, err := rh.client.SetIfAbsent(ctx, key, value, ttl)
+	if err != nil {
+		return false, rh.handleRedisError(err, errors.CacheStoreFailed)
+	}
+	return set, nil
+}
+
 func (rh *RedisHelper) Get(ctx context.Context, key string) (string, error) {
 	value, err := rh.client.GetValue(ctx, key)
 	if err != nil {
@@ -107,6 +143,16 @@ func (rh *RedisHelper) Get(ctx context.Context, key string) (string, error) {
 	return value, nil
 }
 
+// GetAndDelete returns the value and deletes the key in one step, so only one caller can
+// receive it
+func (rh *RedisHelper) GetAndDelete(ctx context.Context, key string) (string, error) {
+	value, err := rh.client.GetAndDelete(ctx, key)
+	if err != nil {
+		return "", rh.handleRedisError(err, errors.CacheRetrieveFailed)
+	}
+	return value, nil
+}
+
 func (rh *RedisHelper) Exists(ctx context.Context, key string) (bool, error) {
 	exists, err := rh.client.Exists(ctx, key)
 	if err != nil {
@@ -133,6 +179,15 @@ func (rh *RedisHelper) Delete(ctx context.Context, keys ...string) error {
 	return nil
 }
 
+// DeleteIfEqual deletes the key only while it holds value, so only one caller can consume it
+func (rh *RedisHelper) DeleteIfEqual(ctx context.Context, key, value string) (bool, error) {
+	deleted, err := rh.client.DeleteIfEqual(ctx, key, value)
+	if err != nil {
+		return false, rh.handleRedisError(err, errors.CacheDeleteFailed)
+	}
+	return deleted, nil
+}
+
 func (rh *RedisHelper) Keys(ctx context.Context, pattern string) ([]string, error) {
 	keys, err := rh.client.Keys(ctx, pattern)
 	if err != nil {
@@ -32,15 +32,19 @@ type Client struct {
 
 	// Client identifier (optional, for tracking)
 	ID string
+
+	// Authenticated user, empty for anonymous connections
+	UserID string
 }
 
 // NewClient creates a new Client instance.
-func NewClient(hub *Hub, conn *websocket.Conn, id string) *Client {
+func NewClient(hub *Hub, conn *websocket.Conn, id, userID string) *Client {
 	return &Client{
-		hub:  hub,
-		conn: conn,
-		send: make(chan []byte, 256),
-		ID:   id,
+		hub:    hub,
+		conn:   conn,
+		send:   make(chan []byte, 256),
+		ID:     id,
+		UserID: userID,
 	}
 }
 
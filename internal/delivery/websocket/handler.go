@@ -32,8 +32,11 @@ func (h *Handler) HandleWebSocket() fiber.Handler {
 		// Generate a unique client ID
 		clientID := uuid.New().String()
 
+		// Identify the user when an auth middleware authenticated the upgrade request
+		userID, _ := c.Locals("user_id").(string)
+
 		// Create a new client
-		client := NewClient(h.hub, c, clientID)
+		client := NewClient(h.hub, c, clientID, userID)
 
 		// Register the client
 		h.hub.register <- client
@@ -52,12 +55,16 @@ func (h *Handler) GetHub() *Hub {
 }
 
 // RegisterRoutes registers WebSocket routes to the Fiber app.
-func RegisterRoutes(app *fiber.App, hub *Hub) *Handler {
+// Middlewares run before the upgrade, e.g. to identify the user for Hub.SendToUsers.
+func RegisterRoutes(app *fiber.App, hub *Hub, middlewares ...fiber.Handler) *Handler {
 	handler := NewHandler(hub)
 
 	// WebSocket endpoint
 	ws := app.Group("/ws")
 	ws.Use(handler.Upgrade())
+	for _, middleware := range middlewares {
+		ws.Use(middleware)
+	}
 	ws.Get("/", handler.HandleWebSocket())
 
 	return handler
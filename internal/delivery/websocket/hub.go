@@ -92,6 +92,33 @@ func (h *Hub) BroadcastMessage(msgType string, payload interface{}) {
 	h.Broadcast(data)
 }
 
+// SendToUsers sends a Message struct to every connection of the given users.
+// Clients that cannot keep up are skipped rather than blocking the caller.
+func (h *Hub) SendToUsers(userIDs []string, msgType string, payload interface{}) {
+	msg := NewMessage(msgType, payload)
+	data, err := msg.ToJSON()
+	if err != nil {
+		return
+	}
+
+	recipients := make(map[string]bool, len(userIDs))
+	for _, userID := range userIDs {
+		recipients[userID] = true
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for client := range h.clients {
+		if client.UserID == "" || !recipients[client.UserID] {
+			continue
+		}
+		select {
+		case client.send <- data:
+		default:
+		}
+	}
+}
+
 // Shutdown gracefully closes all client connections.
 func (h *Hub) Shutdown() {
 	h.mu.Lock()
@@ -1,9 +1,16 @@
 package middleware
 
 import (
+	"io"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"boilerplate-be/internal/module/policy"
+	"boilerplate-be/internal/module/rbac"
+	"boilerplate-be/internal/shared/errors"
+	"boilerplate-be/internal/shared/security"
+
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -106,3 +113,355 @@ func TestCorsMiddleware(t *testing.T) {
 	// This test requires config, skipping for now
 	t.Skip("CORS middleware requires full config setup")
 }
+
+// staticSubjects serves fixed permissions and no roles or denies to the policy engine
+type staticSubjects map[string][]string
+
+func (s staticSubjects) GetUserRoles(userID string) ([]rbac.Role, error) {
+	return nil, nil
+}
+
+func (s staticSubjects) GetUserPermissions(userID string) ([]rbac.Permission, error) {
+	var permissions []rbac.Permission
+	for _, name := range s[userID] {
+		permissions = append(permissions, rbac.Permission{Name: name})
+	}
+	return permissions, nil
+}
+
+func (s staticSubjects) GetUserDenyRules(userID, organizationID string) ([]rbac.DenyRule, error) {
+	return nil, nil
+}
+
+func TestRequirePolicy(t *testing.T) {
+	engine := policy.NewEngine(staticSubjects{"auditor": {"notes:read"}})
+	engine.RegisterLoader("note", func(id string) (*policy.Resource, error) {
+		if id != "note-1" {
+			return nil, errors.New(errors.ResourceNotFound)
+		}
+		return &policy.Resource{ID: id, OwnerID: "owner"}, nil
+	})
+	if err := engine.RegisterExpression("note.read", `own(note) || has_permission("notes:read")`); err != nil {
+		t.Fatalf("failed to register policy: %v", err)
+	}
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if userID := c.Get("X-User"); userID != "" {
+			c.Locals("user_id", userID)
+		}
+		return c.Next()
+	})
+	app.Get("/notes/:id", RequirePolicy(engine, "note.read", "note", "id"), func(c *fiber.Ctx) error {
+		resource := c.Locals(PolicyResourceKey).(*policy.Resource)
+		return c.SendString(resource.Type + ":" + resource.ID)
+	})
+	app.Get("/broken/:id", RequirePolicy(engine, "missing.policy", "note", "id"), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		path       string
+		user       string
+		wantStatus int
+	}{
+		{"owner is allowed", "/notes/note-1", "owner", fiber.StatusOK},
+		{"permission holder is allowed", "/notes/note-1", "auditor", fiber.StatusOK},
+		{"other user is forbidden", "/notes/note-1", "stranger", fiber.StatusForbidden},
+		{"missing resource", "/notes/note-2", "owner", fiber.StatusNotFound},
+		{"unauthenticated", "/notes/note-1", "", fiber.StatusUnauthorized},
+		{"unregistered policy", "/broken/note-1", "owner", fiber.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.user != "" {
+				req.Header.Set("X-User", tt.user)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("failed to execute request: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+		})
+	}
+}
+
+// staticMembers implements TenantMembership from a set of "organization/user" pairs
+type staticMembers map[string]bool
+
+func (m staticMembers) IsMember(organizationID, userID string) (bool, error) {
+	return m[organizationID+"/"+userID], nil
+}
+
+// tenantPermissions grants global permissions per user and tenant permissions per
+// "organization/user"; embedded RBACUseCase methods are not called
+type tenantPermissions struct {
+	rbac.RBACUseCase
+	global map[string]string
+	tenant map[string]string
+}
+
+func (p tenantPermissions) CheckUserPermission(userID string, permissions ...string) (bool, error) {
+	return p.global[userID] == permissions[0], nil
+}
+
+func (p tenantPermissions) CheckUserPermissionInTenant(userID, organizationID string, permissions ...string) (bool, error) {
+	if p.global[userID] == permissions[0] {
+		return true, nil
+	}
+	return p.tenant[organizationID+"/"+userID] == permissions[0], nil
+}
+
+func TestTenant(t *testing.T) {
+	members := staticMembers{"org-a/alice": true, "org-b/bob": true}
+	permissions := tenantPermissions{
+		global: map[string]string{},
+		tenant: map[string]string{"org-a/alice": "orgs:read"},
+	}
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if userID := c.Get("X-User"); userID != "" {
+			c.Locals("user_id", userID)
+		}
+		if claim := c.Get("X-Claim"); claim != "" {
+			c.Locals(TokenTenantKey, claim)
+		}
+		return c.Next()
+	})
+	app.Get("/reports", Tenant(members), RequirePermission(permissions, "orgs:read"), func(c *fiber.Ctx) error {
+		return c.SendString(TenantID(c))
+	})
+	orgs := app.Group("/orgs/:orgId", RequireTenant(members, "orgId"))
+	orgs.Get("/members", RequirePermission(permissions, "orgs:read"), func(c *fiber.Ctx) error {
+		return c.SendString(TenantID(c))
+	})
+	// Global routes mount no tenant middleware, so the header is ignored
+	app.Get("/accounts", RequirePermission(permissions, "orgs:read"), func(c *fiber.Ctx) error {
+		return c.SendString(TenantID(c))
+	})
+
+	tests := []struct {
+		name       string
+		path       string
+		user       string
+		header     string
+		claim      string
+		wantStatus int
+	}{
+		{"no tenant uses global permissions", "/reports", "alice", "", "", fiber.StatusForbidden},
+		{"header selects member tenant", "/reports", "alice", "org-a", "", fiber.StatusOK},
+		{"claim selects member tenant", "/reports", "alice", "", "org-a", fiber.StatusOK},
+		{"header overrides claim", "/reports", "alice", "org-b", "org-a", fiber.StatusForbidden},
+		{"tenant roles do not cross tenants", "/reports", "bob", "org-b", "", fiber.StatusForbidden},
+		{"route tenant grants within it", "/orgs/org-a/members", "alice", "", "", fiber.StatusOK},
+		{"route tenant ignores header", "/orgs/org-b/members", "alice", "org-a", "", fiber.StatusForbidden},
+		{"non-member of route tenant", "/orgs/org-a/members", "bob", "", "", fiber.StatusForbidden},
+		{"unauthenticated", "/orgs/org-a/members", "", "", "", fiber.StatusUnauthorized},
+		{"global route ignores tenant grants from header", "/accounts", "alice", "org-a", "", fiber.StatusForbidden},
+		{"global route ignores tenant grants from claim", "/accounts", "alice", "", "org-a", fiber.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.user != "" {
+				req.Header.Set("X-User", tt.user)
+			}
+			if tt.header != "" {
+				req.Header.Set(TenantHeader, tt.header)
+			}
+			if tt.claim != "" {
+				req.Header.Set("X-Claim", tt.claim)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("failed to execute request: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestRequirePermission_EmbeddedAuthorization(t *testing.T) {
+	members := staticMembers{"org-a/alice": true, "org-b/alice": true}
+	// Lookups only grant orgs:read in org-b, so every other success comes from the token
+	lookups := tenantPermissions{
+		global: map[string]string{},
+		tenant: map[string]string{"org-b/alice": "orgs:read"},
+	}
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", "alice")
+		claims := &security.Claims{
+			UserID:              "alice",
+			OrganizationID:      c.Get("X-Claim"),
+			EmbedsAuthorization: true,
+			Roles:               []string{"user"},
+			Permissions:         []string{"orgs:*", "profile:*"},
+			Denies:              []string{"orgs:delete"},
+		}
+		if claims.OrganizationID != "" {
+			c.Locals(TokenTenantKey, claims.OrganizationID)
+		}
+		c.Locals(TokenAuthorizationKey, claims)
+		return c.Next()
+	})
+	app.Get("/reports", Tenant(members), RequirePermission(lookups, "orgs:read"), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Get("/purge", RequireAllPermissions(lookups, "orgs:read", "orgs:delete"), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Get("/either", RequirePermission(lookups, "orgs:delete", "profile:read"), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	// tenantPermissions does not implement CheckUserRole, so role checks must use the token
+	app.Get("/admin", RequireRole(lookups, "super_admin"), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Get("/home", RequireRole(lookups, "user"), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		path       string
+		header     string
+		claim      string
+		wantStatus int
+	}{
+		{"token permissions without tenant", "/reports", "", "", fiber.StatusOK},
+		{"token permissions for the token's tenant", "/reports", "", "org-a", fiber.StatusOK},
+		{"header selecting another tenant falls back to lookup", "/reports", "org-b", "", fiber.StatusOK},
+		{"token deny overrides a wildcard grant", "/purge", "", "", fiber.StatusForbidden},
+		{"any-of passes on a permission that is not denied", "/either", "", "", fiber.StatusOK},
+		{"token roles grant", "/home", "", "", fiber.StatusOK},
+		{"token roles deny", "/admin", "", "", fiber.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(TenantHeader, tt.header)
+			}
+			if tt.claim != "" {
+				req.Header.Set("X-Claim", tt.claim)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("failed to execute request: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestPermissionGuard(t *testing.T) {
+	lookups := tenantPermissions{global: map[string]string{"alice": "users:read"}}
+	registry := rbac.NewPermissionRegistry()
+	registry.Declare("users", rbac.PermissionSpec{Name: "users:read"}, rbac.PermissionSpec{Name: "users:delete"})
+	guard := NewPermissionGuard(lookups, registry)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", c.Get("X-User"))
+		return c.Next()
+	})
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) }
+	users := app.Group("/api").Group("/users")
+	guard.Get(users, "/:id", ok, "users:read")
+	guard.Delete(users, "/:id", ok, "users:delete")
+	guard.Post(app, "/reports", ok, "reports:write")
+
+	tests := []struct {
+		method     string
+		path       string
+		user       string
+		wantStatus int
+	}{
+		{"GET", "/api/users/1", "alice", fiber.StatusOK},
+		{"DELETE", "/api/users/1", "alice", fiber.StatusForbidden},
+		{"GET", "/api/users/1", "", fiber.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.Header.Set("X-User", tt.user)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("failed to execute request: %v", err)
+		}
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("%s %s as %q: expected status %d, got %d", tt.method, tt.path, tt.user, tt.wantStatus, resp.StatusCode)
+		}
+	}
+
+	// Routes are recorded with their group prefix, and the undeclared permission fails verification
+	matrix := registry.Matrix()
+	if len(matrix.Routes) != 3 || matrix.Routes[0].Path != "/api/users/:id" || matrix.Routes[2].Path != "/reports" {
+		t.Errorf("unexpected routes: %+v", matrix.Routes)
+	}
+	if err := registry.Verify(); err == nil || !strings.Contains(err.Error(), `"reports:write"`) {
+		t.Errorf("expected verification to report reports:write, got %v", err)
+	}
+}
+
+func TestResponseViewer(t *testing.T) {
+	lookups := tenantPermissions{
+		global: map[string]string{"alice": "users:read"},
+		tenant: map[string]string{"org-a/bob": "users:read"},
+	}
+	resolve := ResponseViewer(lookups)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", c.Get("X-User"))
+		if organizationID := c.Get("X-Org"); organizationID != "" {
+			c.Locals(TenantKey, organizationID)
+		}
+		return c.Next()
+	})
+	app.Get("/", func(c *fiber.Ctx) error {
+		viewer := resolve(c)
+		if viewer == nil {
+			return c.SendString("anonymous")
+		}
+		if viewer.HasPermission("users:read") && viewer.HasPermission("users:read") {
+			return c.SendString(viewer.UserID() + " allowed")
+		}
+		return c.SendString(viewer.UserID() + " denied")
+	})
+
+	tests := []struct {
+		user, org, want string
+	}{
+		{"alice", "", "alice allowed"},
+		{"bob", "", "bob denied"},
+		{"bob", "org-a", "bob allowed"},
+		{"", "", "anonymous"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-User", tt.user)
+		req.Header.Set("X-Org", tt.org)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("failed to execute request: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if string(body) != tt.want {
+			t.Errorf("user %q in %q: got %q, want %q", tt.user, tt.org, body, tt.want)
+		}
+	}
+}
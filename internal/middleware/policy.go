@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"boilerplate-be/internal/module/policy"
+	"boilerplate-be/internal/shared/errors"
+	"boilerplate-be/internal/shared/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PolicyResourceKey is the Locals key holding the resource loaded by RequirePolicy
+const PolicyResourceKey = "policy_resource"
+
+// RequirePolicy loads the resource named by the idParam route parameter through the loader
+// registered for resourceType, then evaluates the named policy against it. On success the
+// resource is stored in Locals under PolicyResourceKey so the handler need not load it again.
+func RequirePolicy(engine *policy.Engine, policyName, resourceType, idParam string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, ok := c.Locals("user_id").(string)
+		if !ok || userID == "" {
+			appErr := errors.New(errors.Unauthorized)
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+
+		resource, err := engine.LoadResource(resourceType, c.Params(idParam))
+		if err != nil {
+			return policyError(c, err)
+		}
+
+		if err := engine.Authorize(policyName, userID, resource, PolicyContext(c)); err != nil {
+			return policyError(c, err)
+		}
+
+		c.Locals(PolicyResourceKey, resource)
+		return c.Next()
+	}
+}
+
+// PolicyContext exposes request attributes to policies as context.*
+func PolicyContext(c *fiber.Ctx) map[string]interface{} {
+	return map[string]interface{}{
+		"ip":         c.IP(),
+		"method":     c.Method(),
+		"path":       c.Path(),
+		"request_id": c.Locals("request_id"),
+	}
+}
+
+func policyError(c *fiber.Ctx, err error) error {
+	if appErr, ok := errors.IsAppError(err); ok {
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+	appErr := errors.New(errors.InternalServerError)
+	return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+}
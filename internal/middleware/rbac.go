@@ -1,9 +1,12 @@
 package middleware
 
 import (
+	"slices"
+
 	"boilerplate-be/internal/module/rbac"
 	"boilerplate-be/internal/shared/errors"
 	"boilerplate-be/internal/shared/response"
+	"boilerplate-be/internal/shared/security"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -17,7 +20,7 @@ func RequireRole(rbacUseCase rbac.RBACUseCase, roles ...string) fiber.Handler {
 			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
 		}
 
-		hasRole, err := rbacUseCase.CheckUserRole(userID, roles...)
+		hasRole, err := checkRole(c, rbacUseCase, userID, roles...)
 		if err != nil {
 			appErr := errors.New(errors.InternalServerError)
 			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
@@ -32,7 +35,8 @@ func RequireRole(rbacUseCase rbac.RBACUseCase, roles ...string) fiber.Handler {
 	}
 }
 
-// RequirePermission creates a middleware that checks if the user has any of the required permissions
+// RequirePermission creates a middleware that checks if the user has any of the required permissions.
+// Inside an active tenant, roles assigned in that organization count alongside global roles.
 func RequirePermission(rbacUseCase rbac.RBACUseCase, permissions ...string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		userID, ok := c.Locals("user_id").(string)
@@ -41,7 +45,7 @@ func RequirePermission(rbacUseCase rbac.RBACUseCase, permissions ...string) fibe
 			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
 		}
 
-		hasPermission, err := rbacUseCase.CheckUserPermission(userID, permissions...)
+		hasPermission, err := checkPermission(c, rbacUseCase, userID, permissions...)
 		if err != nil {
 			appErr := errors.New(errors.InternalServerError)
 			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
@@ -72,7 +76,7 @@ func RequireAllRoles(rbacUseCase rbac.RBACUseCase, roles ...string) fiber.Handle
 		}
 
 		for _, role := range roles {
-			hasRole, err := rbacUseCase.CheckUserRole(userID, role)
+			hasRole, err := checkRole(c, rbacUseCase, userID, role)
 			if err != nil {
 				appErr := errors.New(errors.InternalServerError)
 				return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
@@ -97,7 +101,7 @@ func RequireAllPermissions(rbacUseCase rbac.RBACUseCase, permissions ...string)
 		}
 
 		for _, permission := range permissions {
-			hasPermission, err := rbacUseCase.CheckUserPermission(userID, permission)
+			hasPermission, err := checkPermission(c, rbacUseCase, userID, permission)
 			if err != nil {
 				appErr := errors.New(errors.InternalServerError)
 				return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
@@ -112,7 +116,94 @@ func RequireAllPermissions(rbacUseCase rbac.RBACUseCase, permissions ...string)
 	}
 }
 
+// TokenAuthorizationKey is the Locals key holding the claims of an access token that embeds
+// the user's roles and permissions
+const TokenAuthorizationKey = "token_authorization"
+
+// tokenAuthorization returns the claims of a token with embedded authorization
+func tokenAuthorization(c *fiber.Ctx) (*security.Claims, bool) {
+	claims, ok := c.Locals(TokenAuthorizationKey).(*security.Claims)
+	return claims, ok
+}
+
+// checkRole answers from the token's embedded global roles when present
+func checkRole(c *fiber.Ctx, rbacUseCase rbac.RBACUseCase, userID string, roles ...string) (bool, error) {
+	if claims, ok := tokenAuthorization(c); ok {
+		for _, role := range roles {
+			if slices.Contains(claims.Roles, role) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return rbacUseCase.CheckUserRole(userID, roles...)
+}
+
+// checkPermission evaluates within the active tenant when Tenant or RequireTenant selected one.
+// Embedded permissions and denies are used when they were issued for that same tenant; a deny
+// wins over any grant either way.
+func checkPermission(c *fiber.Ctx, rbacUseCase rbac.RBACUseCase, userID string, permissions ...string) (bool, error) {
+	if claims, ok := tokenAuthorization(c); ok && claims.OrganizationID == TenantID(c) {
+		for _, permission := range permissions {
+			if _, ok := rbac.Authorize(claims.Permissions, claims.Denies, permission); ok {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	if organizationID := TenantID(c); organizationID != "" {
+		return rbacUseCase.CheckUserPermissionInTenant(userID, organizationID, permissions...)
+	}
+	return rbacUseCase.CheckUserPermission(userID, permissions...)
+}
+
 // IsSuperAdmin is a convenience middleware for super admin only routes
 func IsSuperAdmin(rbacUseCase rbac.RBACUseCase) fiber.Handler {
 	return RequireRole(rbacUseCase, "super_admin")
 }
+
+// PermissionGuard mounts routes behind RequirePermission and records each route's permissions
+// in the registry, so they are verified at startup and listed in the permission matrix
+type PermissionGuard struct {
+	rbacUseCase rbac.RBACUseCase
+	registry    *rbac.PermissionRegistry
+}
+
+// NewPermissionGuard creates a guard recording into the registry
+func NewPermissionGuard(rbacUseCase rbac.RBACUseCase, registry *rbac.PermissionRegistry) *PermissionGuard {
+	return &PermissionGuard{
+		rbacUseCase: rbacUseCase,
+		registry:    registry,
+	}
+}
+
+// Get mounts a GET route requiring any of the permissions
+func (g *PermissionGuard) Get(router fiber.Router, path string, handler fiber.Handler, permissions ...string) {
+	g.Add(router, fiber.MethodGet, path, handler, permissions...)
+}
+
+// Post mounts a POST route requiring any of the permissions
+func (g *PermissionGuard) Post(router fiber.Router, path string, handler fiber.Handler, permissions ...string) {
+	g.Add(router, fiber.MethodPost, path, handler, permissions...)
+}
+
+// Put mounts a PUT route requiring any of the permissions
+func (g *PermissionGuard) Put(router fiber.Router, path string, handler fiber.Handler, permissions ...string) {
+	g.Add(router, fiber.MethodPut, path, handler, permissions...)
+}
+
+// Delete mounts a DELETE route requiring any of the permissions
+func (g *PermissionGuard) Delete(router fiber.Router, path string, handler fiber.Handler, permissions ...string) {
+	g.Add(router, fiber.MethodDelete, path, handler, permissions...)
+}
+
+// Add mounts a route requiring any of the permissions; the registry records the full path,
+// including the prefix of the group it is mounted on
+func (g *PermissionGuard) Add(router fiber.Router, method, path string, handler fiber.Handler, permissions ...string) {
+	prefix := ""
+	if group, ok := router.(*fiber.Group); ok {
+		prefix = group.Prefix
+	}
+	g.registry.Require(method, prefix+path, permissions...)
+	router.Add(method, path, RequirePermission(g.rbacUseCase, permissions...), handler)
+}
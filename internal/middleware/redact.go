@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"boilerplate-be/internal/module/rbac"
+	"boilerplate-be/internal/shared/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ResponseViewer resolves the authenticated caller responses are redacted for, with permissions
+// checked like RequirePermission, including the active tenant's roles. Requests without a user,
+// such as login and registration, which only return the caller's own account, are not redacted.
+func ResponseViewer(rbacUseCase rbac.RBACUseCase) func(c *fiber.Ctx) response.Viewer {
+	return func(c *fiber.Ctx) response.Viewer {
+		userID, ok := c.Locals("user_id").(string)
+		if !ok || userID == "" {
+			return nil
+		}
+		return &requestViewer{
+			c:           c,
+			rbacUseCase: rbacUseCase,
+			userID:      userID,
+			checked:     make(map[string]bool),
+		}
+	}
+}
+
+// requestViewer remembers each permission decision for the rest of the response; a failed
+// check hides the field
+type requestViewer struct {
+	c           *fiber.Ctx
+	rbacUseCase rbac.RBACUseCase
+	userID      string
+	checked     map[string]bool
+}
+
+func (v *requestViewer) UserID() string {
+	return v.userID
+}
+
+func (v *requestViewer) HasPermission(permission string) bool {
+	if allowed, ok := v.checked[permission]; ok {
+		return allowed
+	}
+	allowed, err := checkPermission(v.c, v.rbacUseCase, v.userID, permission)
+	allowed = allowed && err == nil
+	v.checked[permission] = allowed
+	return allowed
+}
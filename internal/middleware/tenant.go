@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"boilerplate-be/internal/shared/errors"
+	"boilerplate-be/internal/shared/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// TenantHeader selects the active organization, overriding the token's org_id claim
+	TenantHeader = "X-Organization-ID"
+
+	// TenantKey is the Locals key holding the verified active organization ID
+	TenantKey = "organization_id"
+
+	// TokenTenantKey is the Locals key holding the org_id claim before membership is verified
+	TokenTenantKey = "token_organization_id"
+)
+
+// TenantMembership reports whether a user belongs to an organization
+type TenantMembership interface {
+	IsMember(organizationID, userID string) (bool, error)
+}
+
+// Tenant resolves the active organization from the X-Organization-ID header, falling back
+// to the token's org_id claim. Requests without either continue with no tenant; otherwise
+// the user must be a member. Must run after AuthMiddleware.
+func Tenant(members TenantMembership) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		organizationID := c.Get(TenantHeader)
+		if organizationID == "" {
+			organizationID, _ = c.Locals(TokenTenantKey).(string)
+		}
+		if organizationID == "" {
+			return c.Next()
+		}
+		return enterTenant(c, members, organizationID)
+	}
+}
+
+// RequireTenant makes the organization named by the param route parameter the active tenant,
+// ignoring the header and token claim. The user must be a member.
+func RequireTenant(members TenantMembership, param string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return enterTenant(c, members, c.Params(param))
+	}
+}
+
+func enterTenant(c *fiber.Ctx, members TenantMembership, organizationID string) error {
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		appErr := errors.New(errors.Unauthorized)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	isMember, err := members.IsMember(organizationID, userID)
+	if err != nil {
+		appErr := errors.New(errors.InternalServerError)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+	if !isMember {
+		appErr := errors.New(errors.NotOrganizationMember)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	c.Locals(TenantKey, organizationID)
+	return c.Next()
+}
+
+// TenantID returns the verified active organization, or empty when none is selected
+func TenantID(c *fiber.Ctx) string {
+	organizationID, _ := c.Locals(TenantKey).(string)
+	return organizationID
+}
@@ -0,0 +1,58 @@
+package accessrequest
+
+import (
+	"time"
+
+	"boilerplate-be/internal/module/rbac"
+)
+
+// AccessRequestRepository defines the data access layer for access requests and role approvers
+type AccessRequestRepository interface {
+	// Create stores a pending request; a second pending request for the same role is a Conflict
+	Create(request *AccessRequest) error
+	GetByID(id string) (*AccessRequest, error)
+	GetByRequester(requesterID string) ([]AccessRequest, error)
+	GetPendingForApprover(approverID string) ([]AccessRequest, error)
+	// Transition persists the request's new status and review fields, but only while the stored
+	// status is still from, so concurrent reviewers cannot both resolve it
+	Transition(request *AccessRequest, from Status) error
+
+	// Approver operations
+	GetApprovers(roleID string) ([]Approver, error)
+	SetApprovers(roleID string, userIDs []string) error
+	IsApprover(roleID, userID string) (bool, error)
+}
+
+// AccessRequestUseCase defines the business logic for requesting and reviewing role grants
+type AccessRequestUseCase interface {
+	// Requester operations
+	RequestRole(requesterID, roleID, justification string, validUntil *time.Time) (*AccessRequest, error)
+	GetMyRequests(requesterID string) ([]AccessRequest, error)
+	CancelRequest(requesterID, requestID string) (*AccessRequest, error)
+
+	// Approver operations; only the role's approvers, never the requester, may review
+	GetPendingApprovals(approverID string) ([]AccessRequest, error)
+	// ApproveRequest resolves the request and grants the role to the requester
+	ApproveRequest(approverID, requestID, note string) (*AccessRequest, error)
+	DenyRequest(approverID, requestID, note string) (*AccessRequest, error)
+
+	// Approver configuration
+	GetApprovers(roleID string) ([]Approver, error)
+	SetApprovers(roleID string, userIDs []string) ([]Approver, error)
+
+	// WithActor returns a use case whose role grants are recorded as made by the actor
+	WithActor(actor rbac.ChangeActor) AccessRequestUseCase
+}
+
+// RoleGranter looks up and grants roles, e.g. rbac.RBACUseCase
+type RoleGranter interface {
+	GetRoleByID(id string) (*rbac.Role, error)
+	AssignRoleToUser(userID, roleID string, validity rbac.RoleValidity) error
+	WithActor(actor rbac.ChangeActor) rbac.RBACUseCase
+}
+
+// Notifier tells approvers about new requests and requesters about the outcome
+type Notifier interface {
+	NotifyRequested(approverIDs []string, request *AccessRequest)
+	NotifyReviewed(request *AccessRequest)
+}
@@ -0,0 +1,51 @@
+package accessrequest
+
+import "time"
+
+// Status is the state of an access request
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusApproved  Status = "approved"
+	StatusDenied    Status = "denied"
+	StatusCancelled Status = "cancelled"
+)
+
+// transitions lists the states each state may move to; resolved requests are final
+var transitions = map[Status][]Status{
+	StatusPending: {StatusApproved, StatusDenied, StatusCancelled},
+}
+
+// CanTransitionTo reports whether a request in this state may move to next
+func (s Status) CanTransitionTo(next Status) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// AccessRequest is a user's request to be granted a role, resolved by one of the role's approvers
+type AccessRequest struct {
+	ID            string     `json:"id"`
+	RequesterID   string     `json:"requester_id"`
+	RoleID        string     `json:"role_id"`
+	RoleName      string     `json:"role_name"`
+	Justification string     `json:"justification"`
+	ValidUntil    *time.Time `json:"valid_until,omitempty"`
+	Status        Status     `json:"status"`
+	ReviewerID    string     `json:"reviewer_id,omitempty"`
+	ReviewNote    string     `json:"review_note,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// Approver is a user designated to approve requests for a role
+type Approver struct {
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+}
@@ -0,0 +1,297 @@
+package accessrequest
+
+import (
+	"boilerplate-be/internal/module/rbac"
+	"boilerplate-be/internal/shared/errors"
+	"boilerplate-be/internal/shared/response"
+	"boilerplate-be/internal/shared/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AccessRequestHandler struct {
+	requestUseCase AccessRequestUseCase
+}
+
+// NewAccessRequestHandler creates a new access request handler
+func NewAccessRequestHandler(requestUseCase AccessRequestUseCase) *AccessRequestHandler {
+	return &AccessRequestHandler{
+		requestUseCase: requestUseCase,
+	}
+}
+
+// useCase records the role grants made through it as made by the caller, in the current request
+func (h *AccessRequestHandler) useCase(c *fiber.Ctx) AccessRequestUseCase {
+	return h.requestUseCase.WithActor(rbac.RequestActor(c))
+}
+
+// ==================== Requester Endpoints ====================
+
+// CreateAccessRequest godoc
+// @Summary      Request a role
+// @Description  Opens a request for a role with a justification; the role's approvers are notified over websocket. Roles without approvers cannot be requested.
+// @Tags         Access Requests
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      docs.CreateAccessRequestRequest  true  "Access request"
+// @Success      201   {object}  docs.SuccessResponse{data=docs.AccessRequestResponse}
+// @Failure      400   {object}  docs.ErrorResponse
+// @Failure      401   {object}  docs.ErrorResponse
+// @Failure      404   {object}  docs.ErrorResponse
+// @Failure      409   {object}  docs.ErrorResponse
+// @Failure      422   {object}  docs.ErrorResponse
+// @Router       /access-requests [post]
+func (h *AccessRequestHandler) CreateAccessRequest(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var req CreateAccessRequestRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.CreateErrorResponse(c, errors.New(errors.InvalidRequestBody)))
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		validationErrors := validator.FormatValidationErrorForResponseBilingual(err)
+		appErr := errors.NewWithDetails(errors.ValidationFailed, validationErrors)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	request, err := h.requestUseCase.RequestRole(userID, req.RoleID, req.Justification, req.ValidUntil)
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(response.CreateErrorResponse(c, errors.New(errors.InternalServerError)))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.CreateSuccessResponse(
+		c, "Permintaan akses berhasil dibuat", "Access request created successfully", ToAccessRequestResponse(request), fiber.StatusCreated,
+	))
+}
+
+// GetMyAccessRequests godoc
+// @Summary      List my access requests
+// @Description  Returns the current user's access requests, newest first
+// @Tags         Access Requests
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  docs.SuccessResponse{data=[]docs.AccessRequestResponse}
+// @Failure      401  {object}  docs.ErrorResponse
+// @Router       /access-requests [get]
+func (h *AccessRequestHandler) GetMyAccessRequests(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	requests, err := h.requestUseCase.GetMyRequests(userID)
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(response.CreateErrorResponse(c, errors.New(errors.InternalServerError)))
+	}
+
+	return c.JSON(response.CreateSuccessResponse(
+		c, "Daftar permintaan akses berhasil diambil", "Access requests retrieved successfully", ToAccessRequestResponses(requests),
+	))
+}
+
+// CancelAccessRequest godoc
+// @Summary      Cancel my access request
+// @Description  Withdraws one of the current user's pending access requests
+// @Tags         Access Requests
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Access request ID"
+// @Success      200  {object}  docs.SuccessResponse{data=docs.AccessRequestResponse}
+// @Failure      401  {object}  docs.ErrorResponse
+// @Failure      404  {object}  docs.ErrorResponse
+// @Failure      409  {object}  docs.ErrorResponse
+// @Router       /access-requests/{id}/cancel [post]
+func (h *AccessRequestHandler) CancelAccessRequest(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	request, err := h.requestUseCase.CancelRequest(userID, c.Params("id"))
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(response.CreateErrorResponse(c, errors.New(errors.InternalServerError)))
+	}
+
+	return c.JSON(response.CreateSuccessResponse(
+		c, "Permintaan akses berhasil dibatalkan", "Access request cancelled successfully", ToAccessRequestResponse(request),
+	))
+}
+
+// ==================== Approver Endpoints ====================
+
+// GetPendingApprovals godoc
+// @Summary      List requests awaiting my approval
+// @Description  Returns pending requests for the roles the current user approves, oldest first
+// @Tags         Access Requests
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  docs.SuccessResponse{data=[]docs.AccessRequestResponse}
+// @Failure      401  {object}  docs.ErrorResponse
+// @Router       /access-requests/approvals [get]
+func (h *AccessRequestHandler) GetPendingApprovals(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	requests, err := h.requestUseCase.GetPendingApprovals(userID)
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(response.CreateErrorResponse(c, errors.New(errors.InternalServerError)))
+	}
+
+	return c.JSON(response.CreateSuccessResponse(
+		c, "Daftar permintaan akses berhasil diambil", "Access requests retrieved successfully", ToAccessRequestResponses(requests),
+	))
+}
+
+// ApproveAccessRequest godoc
+// @Summary      Approve an access request
+// @Description  Approves a pending request for a role the current user approves and grants the role to the requester, until valid_until when set. Approvers cannot approve their own requests.
+// @Tags         Access Requests
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      string                           true   "Access request ID"
+// @Param        body  body      docs.ReviewAccessRequestRequest  false  "Review note"
+// @Success      200   {object}  docs.SuccessResponse{data=docs.AccessRequestResponse}
+// @Failure      400   {object}  docs.ErrorResponse
+// @Failure      401   {object}  docs.ErrorResponse
+// @Failure      403   {object}  docs.ErrorResponse
+// @Failure      404   {object}  docs.ErrorResponse
+// @Failure      409   {object}  docs.ErrorResponse
+// @Failure      422   {object}  docs.ErrorResponse
+// @Router       /access-requests/{id}/approve [post]
+func (h *AccessRequestHandler) ApproveAccessRequest(c *fiber.Ctx) error {
+	return h.review(c, h.useCase(c).ApproveRequest,
+		"Permintaan akses berhasil disetujui", "Access request approved successfully")
+}
+
+// DenyAccessRequest godoc
+// @Summary      Deny an access request
+// @Description  Denies a pending request for a role the current user approves
+// @Tags         Access Requests
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      string                           true   "Access request ID"
+// @Param        body  body      docs.ReviewAccessRequestRequest  false  "Review note"
+// @Success      200   {object}  docs.SuccessResponse{data=docs.AccessRequestResponse}
+// @Failure      400   {object}  docs.ErrorResponse
+// @Failure      401   {object}  docs.ErrorResponse
+// @Failure      403   {object}  docs.ErrorResponse
+// @Failure      404   {object}  docs.ErrorResponse
+// @Failure      409   {object}  docs.ErrorResponse
+// @Router       /access-requests/{id}/deny [post]
+func (h *AccessRequestHandler) DenyAccessRequest(c *fiber.Ctx) error {
+	return h.review(c, h.useCase(c).DenyRequest,
+		"Permintaan akses berhasil ditolak", "Access request denied successfully")
+}
+
+// review resolves the request in the path with an optional note in the body
+func (h *AccessRequestHandler) review(
+	c *fiber.Ctx,
+	resolve func(approverID, requestID, note string) (*AccessRequest, error),
+	messageID, messageEN string,
+) error {
+	userID := c.Locals("user_id").(string)
+
+	var req ReviewAccessRequestRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(response.CreateErrorResponse(c, errors.New(errors.InvalidRequestBody)))
+		}
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		validationErrors := validator.FormatValidationErrorForResponseBilingual(err)
+		appErr := errors.NewWithDetails(errors.ValidationFailed, validationErrors)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	request, err := resolve(userID, c.Params("id"), req.Note)
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(response.CreateErrorResponse(c, errors.New(errors.InternalServerError)))
+	}
+
+	return c.JSON(response.CreateSuccessResponse(c, messageID, messageEN, ToAccessRequestResponse(request)))
+}
+
+// ==================== Approver Configuration Endpoints ====================
+
+// GetRoleApprovers godoc
+// @Summary      List role approvers
+// @Description  Returns the users who approve requests for a role (Super Admin only)
+// @Tags         Super Admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Role ID"
+// @Success      200  {object}  docs.SuccessResponse{data=[]docs.ApproverResponse}
+// @Failure      401  {object}  docs.ErrorResponse
+// @Failure      403  {object}  docs.ErrorResponse
+// @Failure      404  {object}  docs.ErrorResponse
+// @Router       /super-admin/roles/{id}/approvers [get]
+func (h *AccessRequestHandler) GetRoleApprovers(c *fiber.Ctx) error {
+	approvers, err := h.requestUseCase.GetApprovers(c.Params("id"))
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(response.CreateErrorResponse(c, errors.New(errors.InternalServerError)))
+	}
+
+	return c.JSON(response.CreateSuccessResponse(
+		c, "Daftar approver berhasil diambil", "Approvers retrieved successfully", ToApproverResponses(approvers),
+	))
+}
+
+// SetRoleApprovers godoc
+// @Summary      Set role approvers
+// @Description  Replaces the users who approve requests for a role; an empty list makes the role unrequestable (Super Admin only)
+// @Tags         Super Admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      string                    true  "Role ID"
+// @Param        body  body      docs.SetApproversRequest  true  "Approver user IDs"
+// @Success      200   {object}  docs.SuccessResponse{data=[]docs.ApproverResponse}
+// @Failure      400   {object}  docs.ErrorResponse
+// @Failure      401   {object}  docs.ErrorResponse
+// @Failure      403   {object}  docs.ErrorResponse
+// @Failure      404   {object}  docs.ErrorResponse
+// @Router       /super-admin/roles/{id}/approvers [put]
+func (h *AccessRequestHandler) SetRoleApprovers(c *fiber.Ctx) error {
+	var req SetApproversRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.CreateErrorResponse(c, errors.New(errors.InvalidRequestBody)))
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		validationErrors := validator.FormatValidationErrorForResponseBilingual(err)
+		appErr := errors.NewWithDetails(errors.ValidationFailed, validationErrors)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	approvers, err := h.requestUseCase.SetApprovers(c.Params("id"), req.UserIDs)
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(response.CreateErrorResponse(c, errors.New(errors.InternalServerError)))
+	}
+
+	return c.JSON(response.CreateSuccessResponse(
+		c, "Approver berhasil diperbarui", "Approvers updated successfully", ToApproverResponses(approvers),
+	))
+}
@@ -0,0 +1,31 @@
+package accessrequest
+
+// Websocket message types sent by WebSocketNotifier
+const (
+	MessageTypeAccessRequested = "access_request.requested"
+	MessageTypeAccessReviewed  = "access_request.reviewed"
+)
+
+// UserMessenger delivers a message to every connection of the given users, e.g. websocket.Hub
+type UserMessenger interface {
+	SendToUsers(userIDs []string, msgType string, payload interface{})
+}
+
+// WebSocketNotifier pushes new requests to the role's approvers and the outcome to the requester.
+// Users only receive messages on connections opened with their access token.
+type WebSocketNotifier struct {
+	messenger UserMessenger
+}
+
+// NewWebSocketNotifier creates a notifier that sends access request events over websockets
+func NewWebSocketNotifier(messenger UserMessenger) *WebSocketNotifier {
+	return &WebSocketNotifier{messenger: messenger}
+}
+
+func (n *WebSocketNotifier) NotifyRequested(approverIDs []string, request *AccessRequest) {
+	n.messenger.SendToUsers(approverIDs, MessageTypeAccessRequested, ToAccessRequestResponse(request))
+}
+
+func (n *WebSocketNotifier) NotifyReviewed(request *AccessRequest) {
+	n.messenger.SendToUsers([]string{request.RequesterID}, MessageTypeAccessReviewed, ToAccessRequestResponse(request))
+}
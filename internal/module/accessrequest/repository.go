@@ -0,0 +1,219 @@
+package accessrequest
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"boilerplate-be/internal/database"
+	"boilerplate-be/internal/shared/errors"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type accessRequestRepository struct {
+	db        *sql.DB
+	txManager *database.TxManager
+}
+
+// NewAccessRequestRepository creates a new access request repository
+func NewAccessRequestRepository(db *sql.DB) AccessRequestRepository {
+	return &accessRequestRepository{
+		db:        db,
+		txManager: database.NewTxManager(db),
+	}
+}
+
+// accessRequestColumns is the column list read by scanAccessRequest; queries alias
+// access_requests as ar and join roles as r
+const accessRequestColumns = `ar.id, ar.requester_id, ar.role_id, r.name, ar.justification, ar.valid_until,
+	ar.status, ar.reviewer_id, ar.review_note, ar.reviewed_at, ar.created_at, ar.updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanAccessRequest reads a row selected with accessRequestColumns
+func scanAccessRequest(row rowScanner) (AccessRequest, error) {
+	var request AccessRequest
+	var validUntil, reviewedAt sql.NullTime
+	var reviewerID, reviewNote sql.NullString
+	err := row.Scan(
+		&request.ID, &request.RequesterID, &request.RoleID, &request.RoleName, &request.Justification, &validUntil,
+		&request.Status, &reviewerID, &reviewNote, &reviewedAt, &request.CreatedAt, &request.UpdatedAt,
+	)
+	if validUntil.Valid {
+		request.ValidUntil = &validUntil.Time
+	}
+	if reviewedAt.Valid {
+		request.ReviewedAt = &reviewedAt.Time
+	}
+	request.ReviewerID = reviewerID.String
+	request.ReviewNote = reviewNote.String
+	return request, err
+}
+
+// ==================== Access Request Operations ====================
+
+func (r *accessRequestRepository) Create(request *AccessRequest) error {
+	request.ID = uuid.New().String()
+	request.Status = StatusPending
+	request.CreatedAt = time.Now()
+	request.UpdatedAt = request.CreatedAt
+
+	query := `
+		INSERT INTO access_requests (id, requester_id, role_id, justification, valid_until, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := r.db.Exec(query, request.ID, request.RequesterID, request.RoleID, request.Justification,
+		request.ValidUntil, request.Status, request.CreatedAt, request.UpdatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			return errors.New(errors.Conflict)
+		}
+		return errors.Wrap(err, errors.DatabaseInsertFailed)
+	}
+	return nil
+}
+
+func (r *accessRequestRepository) GetByID(id string) (*AccessRequest, error) {
+	query := `
+		SELECT ` + accessRequestColumns + `
+		FROM access_requests ar
+		INNER JOIN roles r ON r.id = ar.role_id
+		WHERE ar.id = $1
+	`
+	request, err := scanAccessRequest(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New(errors.ResourceNotFound)
+		}
+		return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
+	}
+	return &request, nil
+}
+
+func (r *accessRequestRepository) GetByRequester(requesterID string) ([]AccessRequest, error) {
+	query := `
+		SELECT ` + accessRequestColumns + `
+		FROM access_requests ar
+		INNER JOIN roles r ON r.id = ar.role_id
+		WHERE ar.requester_id = $1
+		ORDER BY ar.created_at DESC
+	`
+	return r.queryAccessRequests(query, requesterID)
+}
+
+func (r *accessRequestRepository) GetPendingForApprover(approverID string) ([]AccessRequest, error) {
+	query := `
+		SELECT ` + accessRequestColumns + `
+		FROM access_requests ar
+		INNER JOIN roles r ON r.id = ar.role_id
+		INNER JOIN role_approvers ra ON ra.role_id = ar.role_id
+		WHERE ra.user_id = $1 AND ar.status = $2 AND ar.requester_id <> $1
+		ORDER BY ar.created_at
+	`
+	return r.queryAccessRequests(query, approverID, StatusPending)
+}
+
+func (r *accessRequestRepository) Transition(request *AccessRequest, from Status) error {
+	var reviewerID interface{}
+	if request.ReviewerID != "" {
+		reviewerID = request.ReviewerID
+	}
+
+	query := `
+		UPDATE access_requests
+		SET status = $2, reviewer_id = $3, review_note = $4, reviewed_at = $5
+		WHERE id = $1 AND status = $6
+		RETURNING updated_at
+	`
+	err := r.db.QueryRow(query, request.ID, request.Status, reviewerID, request.ReviewNote, request.ReviewedAt, from).
+		Scan(&request.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.New(errors.AccessRequestNotPending)
+		}
+		return errors.Wrap(err, errors.DatabaseUpdateFailed)
+	}
+	return nil
+}
+
+func (r *accessRequestRepository) queryAccessRequests(query string, args ...interface{}) ([]AccessRequest, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
+	}
+	defer rows.Close()
+
+	var requests []AccessRequest
+	for rows.Next() {
+		request, err := scanAccessRequest(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.DatabaseScanFailed)
+		}
+		requests = append(requests, request)
+	}
+
+	return requests, nil
+}
+
+// ==================== Approver Operations ====================
+
+func (r *accessRequestRepository) GetApprovers(roleID string) ([]Approver, error) {
+	query := `
+		SELECT u.id, u.name, u.email
+		FROM role_approvers ra
+		INNER JOIN users u ON u.id = ra.user_id
+		WHERE ra.role_id = $1
+		ORDER BY u.name
+	`
+	rows, err := r.db.Query(query, roleID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
+	}
+	defer rows.Close()
+
+	var approvers []Approver
+	for rows.Next() {
+		var approver Approver
+		if err := rows.Scan(&approver.UserID, &approver.Name, &approver.Email); err != nil {
+			return nil, errors.Wrap(err, errors.DatabaseScanFailed)
+		}
+		approvers = append(approvers, approver)
+	}
+
+	return approvers, nil
+}
+
+func (r *accessRequestRepository) SetApprovers(roleID string, userIDs []string) error {
+	return r.txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		exec := database.GetExecutor(ctx, r.db)
+
+		if _, err := exec.ExecContext(ctx, `DELETE FROM role_approvers WHERE role_id = $1`, roleID); err != nil {
+			return errors.Wrap(err, errors.DatabaseDeleteFailed)
+		}
+
+		query := `INSERT INTO role_approvers (role_id, user_id, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`
+		now := time.Now()
+		for _, userID := range userIDs {
+			if _, err := exec.ExecContext(ctx, query, roleID, userID, now); err != nil {
+				if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "foreign_key_violation" {
+					return errors.New(errors.AccountNotFound)
+				}
+				return errors.Wrap(err, errors.DatabaseInsertFailed)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *accessRequestRepository) IsApprover(roleID, userID string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM role_approvers WHERE role_id = $1 AND user_id = $2)`
+	var exists bool
+	if err := r.db.QueryRow(query, roleID, userID).Scan(&exists); err != nil {
+		return false, errors.Wrap(err, errors.DatabaseQueryFailed)
+	}
+	return exists, nil
+}
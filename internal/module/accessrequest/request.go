@@ -0,0 +1,20 @@
+package accessrequest
+
+import "time"
+
+// CreateAccessRequestRequest is the request body for requesting a role
+type CreateAccessRequestRequest struct {
+	RoleID        string     `json:"role_id" validate:"required,uuid"`
+	Justification string     `json:"justification" validate:"required,min=10,max=1000"`
+	ValidUntil    *time.Time `json:"valid_until,omitempty"`
+}
+
+// ReviewAccessRequestRequest is the request body for approving or denying an access request
+type ReviewAccessRequestRequest struct {
+	Note string `json:"note" validate:"max=1000"`
+}
+
+// SetApproversRequest is the request body for replacing a role's approvers
+type SetApproversRequest struct {
+	UserIDs []string `json:"user_ids" validate:"dive,uuid"`
+}
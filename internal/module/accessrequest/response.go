@@ -0,0 +1,66 @@
+package accessrequest
+
+import "time"
+
+// AccessRequestResponse is the response for a single access request
+type AccessRequestResponse struct {
+	ID            string     `json:"id"`
+	RequesterID   string     `json:"requester_id"`
+	RoleID        string     `json:"role_id"`
+	RoleName      string     `json:"role_name"`
+	Justification string     `json:"justification"`
+	ValidUntil    *time.Time `json:"valid_until,omitempty"`
+	Status        string     `json:"status"`
+	ReviewerID    string     `json:"reviewer_id,omitempty"`
+	ReviewNote    string     `json:"review_note,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// ApproverResponse is the response for a single role approver
+type ApproverResponse struct {
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+}
+
+// ToAccessRequestResponse converts AccessRequest entity to AccessRequestResponse
+func ToAccessRequestResponse(request *AccessRequest) AccessRequestResponse {
+	return AccessRequestResponse{
+		ID:            request.ID,
+		RequesterID:   request.RequesterID,
+		RoleID:        request.RoleID,
+		RoleName:      request.RoleName,
+		Justification: request.Justification,
+		ValidUntil:    request.ValidUntil,
+		Status:        string(request.Status),
+		ReviewerID:    request.ReviewerID,
+		ReviewNote:    request.ReviewNote,
+		ReviewedAt:    request.ReviewedAt,
+		CreatedAt:     request.CreatedAt,
+		UpdatedAt:     request.UpdatedAt,
+	}
+}
+
+// ToAccessRequestResponses converts slice of AccessRequest to slice of AccessRequestResponse
+func ToAccessRequestResponses(requests []AccessRequest) []AccessRequestResponse {
+	responses := make([]AccessRequestResponse, len(requests))
+	for i, request := range requests {
+		responses[i] = ToAccessRequestResponse(&request)
+	}
+	return responses
+}
+
+// ToApproverResponses converts slice of Approver to slice of ApproverResponse
+func ToApproverResponses(approvers []Approver) []ApproverResponse {
+	responses := make([]ApproverResponse, len(approvers))
+	for i, approver := range approvers {
+		responses[i] = ApproverResponse{
+			UserID: approver.UserID,
+			Name:   approver.Name,
+			Email:  approver.Email,
+		}
+	}
+	return responses
+}
@@ -0,0 +1,203 @@
+package accessrequest
+
+import (
+	"time"
+
+	"boilerplate-be/internal/module/rbac"
+	"boilerplate-be/internal/shared/errors"
+)
+
+type accessRequestUseCase struct {
+	requestRepo AccessRequestRepository
+	roles       RoleGranter
+	notifier    Notifier
+}
+
+// NewAccessRequestUseCase creates a new access request use case
+func NewAccessRequestUseCase(requestRepo AccessRequestRepository, roles RoleGranter, notifier Notifier) AccessRequestUseCase {
+	return &accessRequestUseCase{
+		requestRepo: requestRepo,
+		roles:       roles,
+		notifier:    notifier,
+	}
+}
+
+func (u *accessRequestUseCase) WithActor(actor rbac.ChangeActor) AccessRequestUseCase {
+	return &accessRequestUseCase{
+		requestRepo: u.requestRepo,
+		roles:       u.roles.WithActor(actor),
+		notifier:    u.notifier,
+	}
+}
+
+// ==================== Requester Operations ====================
+
+// RequestRole opens a request for a role that has approvers and notifies them
+func (u *accessRequestUseCase) RequestRole(requesterID, roleID, justification string, validUntil *time.Time) (*AccessRequest, error) {
+	role, err := u.roles.GetRoleByID(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if validUntil != nil && !validUntil.After(time.Now()) {
+		return nil, errors.New(errors.InvalidRoleValidity)
+	}
+
+	approvers, err := u.requestRepo.GetApprovers(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Requesters never review their own request
+	var approverIDs []string
+	for _, approver := range approvers {
+		if approver.UserID != requesterID {
+			approverIDs = append(approverIDs, approver.UserID)
+		}
+	}
+	if len(approverIDs) == 0 {
+		return nil, errors.New(errors.RoleNotRequestable)
+	}
+
+	request := &AccessRequest{
+		RequesterID:   requesterID,
+		RoleID:        role.ID,
+		RoleName:      role.Name,
+		Justification: justification,
+		ValidUntil:    validUntil,
+	}
+	if err := u.requestRepo.Create(request); err != nil {
+		return nil, err
+	}
+
+	u.notifier.NotifyRequested(approverIDs, request)
+	return request, nil
+}
+
+func (u *accessRequestUseCase) GetMyRequests(requesterID string) ([]AccessRequest, error) {
+	return u.requestRepo.GetByRequester(requesterID)
+}
+
+// CancelRequest withdraws a pending request; only the requester may cancel it
+func (u *accessRequestUseCase) CancelRequest(requesterID, requestID string) (*AccessRequest, error) {
+	request, err := u.requestRepo.GetByID(requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request.RequesterID != requesterID {
+		// Other users' requests are not disclosed
+		return nil, errors.New(errors.ResourceNotFound)
+	}
+
+	if err := u.transition(request, StatusCancelled, "", ""); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// ==================== Approver Operations ====================
+
+func (u *accessRequestUseCase) GetPendingApprovals(approverID string) ([]AccessRequest, error) {
+	return u.requestRepo.GetPendingForApprover(approverID)
+}
+
+// ApproveRequest resolves the request first, so a concurrent reviewer cannot also resolve it, and
+// then grants the role; if the grant fails the request is reopened
+func (u *accessRequestUseCase) ApproveRequest(approverID, requestID, note string) (*AccessRequest, error) {
+	request, err := u.reviewable(approverID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.transition(request, StatusApproved, approverID, note); err != nil {
+		return nil, err
+	}
+
+	validity := rbac.RoleValidity{Until: request.ValidUntil}
+	if err := u.roles.AssignRoleToUser(request.RequesterID, request.RoleID, validity); err != nil {
+		u.reopen(request)
+		return nil, err
+	}
+
+	u.notifier.NotifyReviewed(request)
+	return request, nil
+}
+
+func (u *accessRequestUseCase) DenyRequest(approverID, requestID, note string) (*AccessRequest, error) {
+	request, err := u.reviewable(approverID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.transition(request, StatusDenied, approverID, note); err != nil {
+		return nil, err
+	}
+
+	u.notifier.NotifyReviewed(request)
+	return request, nil
+}
+
+// reviewable loads a request the approver may review: one of their roles, not their own request
+func (u *accessRequestUseCase) reviewable(approverID, requestID string) (*AccessRequest, error) {
+	request, err := u.requestRepo.GetByID(requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request.RequesterID == approverID {
+		return nil, errors.New(errors.Forbidden)
+	}
+
+	isApprover, err := u.requestRepo.IsApprover(request.RoleID, approverID)
+	if err != nil {
+		return nil, err
+	}
+	if !isApprover {
+		return nil, errors.New(errors.Forbidden)
+	}
+	return request, nil
+}
+
+// transition moves a pending request to its resolved state and records who resolved it
+func (u *accessRequestUseCase) transition(request *AccessRequest, next Status, reviewerID, note string) error {
+	from := request.Status
+	if !from.CanTransitionTo(next) {
+		return errors.New(errors.AccessRequestNotPending)
+	}
+
+	now := time.Now()
+	request.Status = next
+	request.ReviewerID = reviewerID
+	request.ReviewNote = note
+	request.ReviewedAt = &now
+
+	return u.requestRepo.Transition(request, from)
+}
+
+// reopen undoes an approval whose role grant failed; it bypasses the state machine on purpose
+func (u *accessRequestUseCase) reopen(request *AccessRequest) {
+	request.Status = StatusPending
+	request.ReviewerID = ""
+	request.ReviewNote = ""
+	request.ReviewedAt = nil
+	_ = u.requestRepo.Transition(request, StatusApproved)
+}
+
+// ==================== Approver Configuration ====================
+
+func (u *accessRequestUseCase) GetApprovers(roleID string) ([]Approver, error) {
+	if _, err := u.roles.GetRoleByID(roleID); err != nil {
+		return nil, err
+	}
+	return u.requestRepo.GetApprovers(roleID)
+}
+
+// SetApprovers replaces the role's approvers; an empty list makes the role unrequestable
+func (u *accessRequestUseCase) SetApprovers(roleID string, userIDs []string) ([]Approver, error) {
+	if _, err := u.roles.GetRoleByID(roleID); err != nil {
+		return nil, err
+	}
+	if err := u.requestRepo.SetApprovers(roleID, userIDs); err != nil {
+		return nil, err
+	}
+	return u.requestRepo.GetApprovers(roleID)
+}
@@ -0,0 +1,332 @@
+package accessrequest
+
+import (
+	"testing"
+	"time"
+
+	"boilerplate-be/internal/module/rbac"
+	"boilerplate-be/internal/shared/enum"
+	apperrors "boilerplate-be/internal/shared/errors"
+
+	"github.com/google/uuid"
+)
+
+// MockAccessRequestRepository implements AccessRequestRepository in memory for testing
+type MockAccessRequestRepository struct {
+	requests  map[string]*AccessRequest
+	approvers map[string][]string // role ID -> approver user IDs
+}
+
+func NewMockAccessRequestRepository() *MockAccessRequestRepository {
+	return &MockAccessRequestRepository{
+		requests:  make(map[string]*AccessRequest),
+		approvers: make(map[string][]string),
+	}
+}
+
+func (m *MockAccessRequestRepository) Create(request *AccessRequest) error {
+	for _, existing := range m.requests {
+		if existing.RequesterID == request.RequesterID && existing.RoleID == request.RoleID && existing.Status == StatusPending {
+			return apperrors.New(apperrors.Conflict)
+		}
+	}
+	request.ID = uuid.New().String()
+	request.Status = StatusPending
+	request.CreatedAt = time.Now()
+	copied := *request
+	m.requests[request.ID] = &copied
+	return nil
+}
+
+func (m *MockAccessRequestRepository) GetByID(id string) (*AccessRequest, error) {
+	if request, ok := m.requests[id]; ok {
+		copied := *request
+		return &copied, nil
+	}
+	return nil, apperrors.New(apperrors.ResourceNotFound)
+}
+
+func (m *MockAccessRequestRepository) GetByRequester(requesterID string) ([]AccessRequest, error) {
+	var requests []AccessRequest
+	for _, request := range m.requests {
+		if request.RequesterID == requesterID {
+			requests = append(requests, *request)
+		}
+	}
+	return requests, nil
+}
+
+func (m *MockAccessRequestRepository) GetPendingForApprover(approverID string) ([]AccessRequest, error) {
+	var requests []AccessRequest
+	for _, request := range m.requests {
+		isApprover, _ := m.IsApprover(request.RoleID, approverID)
+		if isApprover && request.Status == StatusPending && request.RequesterID != approverID {
+			requests = append(requests, *request)
+		}
+	}
+	return requests, nil
+}
+
+func (m *MockAccessRequestRepository) Transition(request *AccessRequest, from Status) error {
+	stored, ok := m.requests[request.ID]
+	if !ok || stored.Status != from {
+		return apperrors.New(apperrors.AccessRequestNotPending)
+	}
+	copied := *request
+	m.requests[request.ID] = &copied
+	return nil
+}
+
+func (m *MockAccessRequestRepository) GetApprovers(roleID string) ([]Approver, error) {
+	var approvers []Approver
+	for _, userID := range m.approvers[roleID] {
+		approvers = append(approvers, Approver{UserID: userID})
+	}
+	return approvers, nil
+}
+
+func (m *MockAccessRequestRepository) SetApprovers(roleID string, userIDs []string) error {
+	m.approvers[roleID] = userIDs
+	return nil
+}
+
+func (m *MockAccessRequestRepository) IsApprover(roleID, userID string) (bool, error) {
+	for _, id := range m.approvers[roleID] {
+		if id == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// mockRoleGranter implements RoleGranter and records granted roles; embedded RBACUseCase
+// methods are not called
+type mockRoleGranter struct {
+	rbac.RBACUseCase
+	roles     map[string]*rbac.Role
+	granted   map[string]rbac.RoleValidity // "user/role" -> validity
+	grantedBy map[string]rbac.ChangeActor  // "user/role" -> actor of the grant
+	actor     rbac.ChangeActor
+	fail      error
+}
+
+func (m *mockRoleGranter) WithActor(actor rbac.ChangeActor) rbac.RBACUseCase {
+	copied := *m
+	copied.actor = actor
+	return &copied
+}
+
+func (m *mockRoleGranter) GetRoleByID(id string) (*rbac.Role, error) {
+	if role, ok := m.roles[id]; ok {
+		return role, nil
+	}
+	return nil, apperrors.New(apperrors.ResourceNotFound)
+}
+
+func (m *mockRoleGranter) AssignRoleToUser(userID, roleID string, validity rbac.RoleValidity) error {
+	if m.fail != nil {
+		return m.fail
+	}
+	m.granted[userID+"/"+roleID] = validity
+	m.grantedBy[userID+"/"+roleID] = m.actor
+	return nil
+}
+
+// mockNotifier records who was notified
+type mockNotifier struct {
+	requested [][]string
+	reviewed  []Status
+}
+
+func (m *mockNotifier) NotifyRequested(approverIDs []string, request *AccessRequest) {
+	m.requested = append(m.requested, approverIDs)
+}
+
+func (m *mockNotifier) NotifyReviewed(request *AccessRequest) {
+	m.reviewed = append(m.reviewed, request.Status)
+}
+
+func assertErrorCode(t *testing.T, err error, want enum.ErrorCode) {
+	t.Helper()
+	appErr, ok := apperrors.IsAppError(err)
+	if !ok || appErr.Code != want {
+		t.Fatalf("expected error code %v, got %v", want, err)
+	}
+}
+
+func newTestUseCase() (AccessRequestUseCase, *MockAccessRequestRepository, *mockRoleGranter, *mockNotifier) {
+	repo := NewMockAccessRequestRepository()
+	granter := &mockRoleGranter{
+		roles: map[string]*rbac.Role{
+			"admin":  {ID: "admin", Name: "admin"},
+			"closed": {ID: "closed", Name: "closed"},
+		},
+		granted:   make(map[string]rbac.RoleValidity),
+		grantedBy: make(map[string]rbac.ChangeActor),
+	}
+	notifier := &mockNotifier{}
+	_ = repo.SetApprovers("admin", []string{"alice", "bob"})
+	return NewAccessRequestUseCase(repo, granter, notifier), repo, granter, notifier
+}
+
+func TestStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from Status
+		to   Status
+		want bool
+	}{
+		{StatusPending, StatusApproved, true},
+		{StatusPending, StatusDenied, true},
+		{StatusPending, StatusCancelled, true},
+		{StatusApproved, StatusDenied, false},
+		{StatusDenied, StatusApproved, false},
+		{StatusCancelled, StatusPending, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+				t.Errorf("CanTransitionTo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccessRequestService_RequestRole(t *testing.T) {
+	useCase, _, _, notifier := newTestUseCase()
+
+	// Roles without approvers cannot be requested
+	_, err := useCase.RequestRole("carol", "closed", "Need to close the books", nil)
+	assertErrorCode(t, err, apperrors.RoleNotRequestable)
+
+	past := time.Now().Add(-time.Hour)
+	_, err = useCase.RequestRole("carol", "admin", "Need to manage users", &past)
+	assertErrorCode(t, err, apperrors.InvalidRoleValidity)
+
+	request, err := useCase.RequestRole("carol", "admin", "Need to manage users", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if request.Status != StatusPending || request.RoleName != "admin" {
+		t.Errorf("unexpected request: %+v", request)
+	}
+	if len(notifier.requested) != 1 || len(notifier.requested[0]) != 2 {
+		t.Errorf("expected both approvers to be notified, got %v", notifier.requested)
+	}
+
+	// Only one open request per role
+	_, err = useCase.RequestRole("carol", "admin", "Need to manage users", nil)
+	assertErrorCode(t, err, apperrors.Conflict)
+
+	// An approver's own request goes only to the other approvers
+	if _, err := useCase.RequestRole("alice", "admin", "Need to manage users", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := notifier.requested[1]; len(got) != 1 || got[0] != "bob" {
+		t.Errorf("expected only bob to be notified, got %v", got)
+	}
+}
+
+func TestAccessRequestService_Review(t *testing.T) {
+	until := time.Now().Add(24 * time.Hour)
+
+	t.Run("approval grants the role", func(t *testing.T) {
+		useCase, _, granter, notifier := newTestUseCase()
+		request, _ := useCase.RequestRole("carol", "admin", "Need to manage users", &until)
+
+		_, err := useCase.ApproveRequest("dave", request.ID, "")
+		assertErrorCode(t, err, apperrors.Forbidden)
+
+		approved, err := useCase.ApproveRequest("alice", request.ID, "ok for this week")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if approved.Status != StatusApproved || approved.ReviewerID != "alice" {
+			t.Errorf("unexpected request: %+v", approved)
+		}
+		validity, ok := granter.granted["carol/admin"]
+		if !ok || validity.Until == nil || !validity.Until.Equal(until) {
+			t.Errorf("expected admin granted to carol until %v, got %v", until, granter.granted)
+		}
+		if len(notifier.reviewed) != 1 || notifier.reviewed[0] != StatusApproved {
+			t.Errorf("expected requester to be notified, got %v", notifier.reviewed)
+		}
+
+		// Resolved requests are final
+		_, err = useCase.DenyRequest("bob", request.ID, "")
+		assertErrorCode(t, err, apperrors.AccessRequestNotPending)
+		_, err = useCase.CancelRequest("carol", request.ID)
+		assertErrorCode(t, err, apperrors.AccessRequestNotPending)
+	})
+
+	t.Run("approval records the approver as the actor of the grant", func(t *testing.T) {
+		useCase, _, granter, _ := newTestUseCase()
+		request, _ := useCase.RequestRole("carol", "admin", "Need to manage users", nil)
+
+		actor := rbac.ChangeActor{UserID: "alice", RequestID: "req-1"}
+		if _, err := useCase.WithActor(actor).ApproveRequest("alice", request.ID, ""); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := granter.grantedBy["carol/admin"]; got != actor {
+			t.Errorf("expected the grant to be recorded as made by %+v, got %+v", actor, got)
+		}
+	})
+
+	t.Run("approvers cannot approve their own request", func(t *testing.T) {
+		useCase, _, _, _ := newTestUseCase()
+		request, _ := useCase.RequestRole("alice", "admin", "Need to manage users", nil)
+
+		_, err := useCase.ApproveRequest("alice", request.ID, "")
+		assertErrorCode(t, err, apperrors.Forbidden)
+
+		pending, _ := useCase.GetPendingApprovals("alice")
+		if len(pending) != 0 {
+			t.Errorf("expected no pending approvals for alice, got %v", pending)
+		}
+		if pending, _ := useCase.GetPendingApprovals("bob"); len(pending) != 1 {
+			t.Errorf("expected one pending approval for bob, got %v", pending)
+		}
+	})
+
+	t.Run("failed grant reopens the request", func(t *testing.T) {
+		useCase, repo, granter, _ := newTestUseCase()
+		request, _ := useCase.RequestRole("carol", "admin", "Need to manage users", nil)
+		granter.fail = apperrors.New(apperrors.DatabaseInsertFailed)
+
+		_, err := useCase.ApproveRequest("alice", request.ID, "")
+		assertErrorCode(t, err, apperrors.DatabaseInsertFailed)
+		if status := repo.requests[request.ID].Status; status != StatusPending {
+			t.Errorf("expected request to be pending again, got %s", status)
+		}
+	})
+
+	t.Run("deny and cancel", func(t *testing.T) {
+		useCase, _, granter, _ := newTestUseCase()
+		request, _ := useCase.RequestRole("carol", "admin", "Need to manage users", nil)
+
+		// Other users cannot see or cancel the request
+		_, err := useCase.CancelRequest("dave", request.ID)
+		assertErrorCode(t, err, apperrors.ResourceNotFound)
+
+		denied, err := useCase.DenyRequest("bob", request.ID, "use the reports role")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if denied.Status != StatusDenied || denied.ReviewNote != "use the reports role" {
+			t.Errorf("unexpected request: %+v", denied)
+		}
+		if len(granter.granted) != 0 {
+			t.Errorf("expected no role to be granted, got %v", granter.granted)
+		}
+
+		// A new request can be opened once the previous one is resolved
+		request, err = useCase.RequestRole("carol", "admin", "Need to manage users after all", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		cancelled, err := useCase.CancelRequest("carol", request.ID)
+		if err != nil || cancelled.Status != StatusCancelled {
+			t.Errorf("expected request to be cancelled, got %+v, %v", cancelled, err)
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"strings"
+
+	"boilerplate-be/internal/shared/errors"
+	"boilerplate-be/internal/shared/security"
+)
+
+// passwordAuthenticator checks the bcrypt password stored in the users table
+type passwordAuthenticator struct {
+	authRepo AuthRepository
+}
+
+// NewPasswordAuthenticator returns the default authenticator backed by local passwords
+func NewPasswordAuthenticator(authRepo AuthRepository) Authenticator {
+	return &passwordAuthenticator{authRepo: authRepo}
+}
+
+func (a *passwordAuthenticator) Name() string {
+	return LoginMethodPassword
+}
+
+func (a *passwordAuthenticator) Authenticate(identifier, password string) (*User, error) {
+	user, err := findUserByIdentifier(a.authRepo, identifier)
+	if err != nil {
+		return nil, errors.New(errors.AccountNotFound)
+	}
+
+	if err := security.CheckPassword(user.Password, password); err != nil {
+		return user, errors.New(errors.PasswordMismatch)
+	}
+
+	return user, nil
+}
+
+// findUserByIdentifier looks a user up by email when identifier contains "@", otherwise by username
+func findUserByIdentifier(authRepo AuthRepository, identifier string) (*User, error) {
+	if strings.Contains(identifier, "@") {
+		return authRepo.GetUserByEmail(identifier)
+	}
+	return authRepo.GetUserByUsername(normalizeUsername(identifier))
+}
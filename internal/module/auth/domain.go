@@ -1,17 +1,74 @@
 package auth
 
+import "time"
+
 type AuthRepository interface {
 	CreateUser(user *User) error
 	GetUserByEmail(email string) (*User, error)
+	GetUserByUsername(username string) (*User, error)
 	GetUserByID(id string) (*User, error)
+	GetUserByExternalID(source, externalID string) (*User, error)
 	UpdateUser(user *User) error
+	DeleteUser(id string) error
+	GetUserByPhone(phone string) (*User, error)
+	UpdateUserPhone(userID, phone string, verifiedAt time.Time) error
+
+	// Login history
+	CreateLoginEvent(event *LoginEvent) error
+	GetLoginEvents(userID string, limit, offset int) ([]LoginEvent, int64, error)
+	CountSuccessfulLogins(userID string) (int64, error)
+	HasSuccessfulLoginFrom(userID, ipAddress, userAgent string) (bool, error)
 }
 
 type AuthUseCase interface {
-	Register(email, password, name string) (*User, string, string, error)
-	Login(email, password string) (string, string, error)
+	Register(email, password, name, username string) (*User, string, string, error)
+	Login(identifier, password string, meta LoginMetadata) (string, string, error)
 	RefreshToken(refreshToken string) (string, string, error)
+	SwitchOrganization(userID, organizationID string) (string, string, error)
 	Logout(userID, tokenID string) error
 	GetProfile(userID string) (*User, error)
-	UpdateProfile(userID, name string) (*User, error)
+	UpdateProfile(userID, name, username string) (*User, error)
+	CheckUsernameAvailability(username string) (bool, string, error)
+	GetLoginHistory(userID string, page, pageSize int) ([]LoginEvent, int64, error)
+
+	// Phone verification and SMS one-time code login
+	RequestPhoneVerification(userID, phone string) error
+	VerifyPhone(userID, phone, code string) (*User, error)
+	RequestLoginOTP(phone string) error
+	LoginWithOTP(phone, code string, meta LoginMetadata) (string, string, error)
+}
+
+// Authenticator verifies login credentials against one identity source, e.g. the
+// users table or a directory. It returns AccountNotFound when the source does not
+// know the identifier and PasswordMismatch when the credentials are wrong; in the
+// latter case the matched local user may be returned alongside the error.
+type Authenticator interface {
+	Name() string
+	Authenticate(identifier, password string) (*User, error)
+}
+
+// MembershipChecker reports organization membership, e.g. organization.OrganizationUseCase
+type MembershipChecker interface {
+	IsMember(organizationID, userID string) (bool, error)
+}
+
+// DefaultRole is the global RBAC role granted to every account created by sign-up or
+// directory provisioning
+const DefaultRole = "user"
+
+// RoleAssigner grants and removes global RBAC roles by name, e.g. rbac.RBACUseCase
+type RoleAssigner interface {
+	AssignRolesByName(userID string, roleNames []string) error
+	RemoveRolesByName(userID string, roleNames []string) error
+}
+
+// OTPStore issues and verifies one-time codes, e.g. security.OTPManager
+type OTPStore interface {
+	Generate(purpose, subject string) (string, error)
+	Verify(purpose, subject, code string) error
+}
+
+// LoginNotifier is told about logins that look suspicious, e.g. from a never-seen IP/user-agent pair
+type LoginNotifier interface {
+	NotifySuspiciousLogin(user *User, event *LoginEvent) error
 }
@@ -2,16 +2,55 @@ package auth
 
 import (
 	"time"
-
-	"boilerplate-be/internal/shared/enum"
 )
 
+// User is an account; its roles are RBAC assignments, see rbac.RBACUseCase
 type User struct {
-	ID        string          `json:"id" db:"id"`
-	Name      string          `json:"name" db:"name"`
-	Email     string          `json:"email" db:"email"`
-	Password  string          `json:"-" db:"password"`
-	Role      enum.UserRole   `json:"role" db:"role"`
-	CreatedAt time.Time       `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time       `json:"updated_at" db:"updated_at"`
+	ID              string     `json:"id" db:"id"`
+	Name            string     `json:"name" db:"name"`
+	Username        string     `json:"username,omitempty" db:"username"`
+	Email           string     `json:"email" db:"email"`
+	Phone           string     `json:"phone,omitempty" db:"phone"`
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at,omitempty" db:"phone_verified_at"`
+	Password        string     `json:"-" db:"password"`
+	AuthSource      string     `json:"-" db:"auth_source"`
+	ExternalID      string     `json:"-" db:"external_id"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Auth sources; directory accounts carry the entry's identifier in ExternalID
+const (
+	AuthSourceLocal = "local"
+	AuthSourceLDAP  = "ldap"
+)
+
+// Login methods recorded in login history
+const (
+	LoginMethodPassword = "password"
+	LoginMethodSMSOTP   = "sms_otp"
+	LoginMethodLDAP     = "ldap"
+)
+
+// LoginEvent records a single login attempt, successful or not
+type LoginEvent struct {
+	ID            string    `json:"id" db:"id"`
+	UserID        string    `json:"user_id,omitempty" db:"user_id"`
+	Identifier    string    `json:"identifier" db:"identifier"`
+	Method        string    `json:"method" db:"method"`
+	Success       bool      `json:"success" db:"success"`
+	FailureReason string    `json:"failure_reason,omitempty" db:"failure_reason"`
+	IPAddress     string    `json:"ip_address" db:"ip_address"`
+	UserAgent     string    `json:"user_agent" db:"user_agent"`
+	CountryCode   string    `json:"country_code,omitempty" db:"country_code"`
+	Country       string    `json:"country,omitempty" db:"country"`
+	City          string    `json:"city,omitempty" db:"city"`
+	Suspicious    bool      `json:"suspicious" db:"is_suspicious"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// LoginMetadata carries information about the client attempting to log in
+type LoginMetadata struct {
+	IPAddress string
+	UserAgent string
 }
@@ -1,8 +1,10 @@
 package auth
 
 import (
+	"log"
 	"time"
 
+	"boilerplate-be/internal/shared/challenge"
 	"boilerplate-be/internal/shared/errors"
 	"boilerplate-be/internal/shared/response"
 	"boilerplate-be/internal/shared/validator"
@@ -11,7 +13,8 @@ import (
 )
 
 type AuthHandler struct {
-	authUseCase AuthUseCase
+	authUseCase    AuthUseCase
+	challengeGuard *challenge.Guard
 }
 
 func NewAuthHandler(authUseCase AuthUseCase) *AuthHandler {
@@ -20,16 +23,43 @@ func NewAuthHandler(authUseCase AuthUseCase) *AuthHandler {
 	}
 }
 
+// SetChallengeGuard makes register and login require a solved challenge once a client crosses its risk threshold
+func (h *AuthHandler) SetChallengeGuard(guard *challenge.Guard) {
+	h.challengeGuard = guard
+}
+
+// checkChallenge verifies the challenge headers when the guard requires one for this client
+func (h *AuthHandler) checkChallenge(c *fiber.Ctx, scope string) error {
+	if h.challengeGuard == nil {
+		return nil
+	}
+	return h.challengeGuard.Check(c.UserContext(), scope, c.IP(), c.Get(challenge.HeaderToken), c.Get(challenge.HeaderSolution))
+}
+
+// recordRisk counts a risky event for the client; failures are logged and never block the request
+func (h *AuthHandler) recordRisk(c *fiber.Ctx, scope string) {
+	if h.challengeGuard == nil {
+		return
+	}
+	if err := h.challengeGuard.Record(c.UserContext(), scope, c.IP()); err != nil {
+		log.Printf("challenge: failed to record %s risk for %s: %v", scope, c.IP(), err)
+	}
+}
+
 // Register godoc
 // @Summary      Register new user
 // @Description  Creates a new user account and returns tokens
 // @Tags         Auth
 // @Accept       json
 // @Produce      json
-// @Param        body  body      docs.RegisterRequest  true  "Registration data"
+// @Param        body                  body      docs.RegisterRequest  true   "Registration data"
+// @Param        X-Challenge-Token     header    string                false  "Challenge token, required once the client is challenged"
+// @Param        X-Challenge-Solution  header    string                false  "Proof-of-work solution"
 // @Success      201   {object}  docs.SuccessResponse{data=docs.AuthResponse}
 // @Failure      400   {object}  docs.ErrorResponse
+// @Failure      403   {object}  docs.ErrorResponse
 // @Failure      409   {object}  docs.ErrorResponse
+// @Failure      428   {object}  docs.ErrorResponse
 // @Router       /auth/register [post]
 func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	var req RegisterRequest
@@ -44,7 +74,16 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
 	}
 
-	user, accessToken, refreshToken, err := h.authUseCase.Register(req.Email, req.Password, req.Name)
+	if err := h.checkChallenge(c, challenge.ScopeRegister); err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		appErr := errors.New(errors.InternalServerError)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+	h.recordRisk(c, challenge.ScopeRegister)
+
+	user, accessToken, refreshToken, err := h.authUseCase.Register(req.Email, req.Password, req.Name, req.Username)
 	if err != nil {
 		if appErr, ok := errors.IsAppError(err); ok {
 			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
@@ -54,14 +93,7 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	}
 
 	authResponse := AuthResponse{
-		User: UserResponse{
-			ID:        user.ID,
-			Name:      user.Name,
-			Email:     user.Email,
-			Role:      user.Role,
-			CreatedAt: user.CreatedAt,
-			UpdatedAt: user.UpdatedAt,
-		},
+		User:         ToUserResponse(user),
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 		TokenType:    "Bearer",
@@ -75,15 +107,19 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 
 // Login godoc
 // @Summary      User login
-// @Description  Authenticates user and returns access/refresh tokens
+// @Description  Authenticates user by email or username and returns access/refresh tokens
 // @Tags         Auth
 // @Accept       json
 // @Produce      json
-// @Param        body  body      docs.LoginRequest  true  "Login credentials"
+// @Param        body                  body      docs.LoginRequest  true   "Login credentials"
+// @Param        X-Challenge-Token     header    string             false  "Challenge token, required once the client is challenged"
+// @Param        X-Challenge-Solution  header    string             false  "Proof-of-work solution"
 // @Success      200   {object}  docs.SuccessResponse{data=docs.TokenResponse}
 // @Failure      400   {object}  docs.ErrorResponse
+// @Failure      403   {object}  docs.ErrorResponse
 // @Failure      404   {object}  docs.ErrorResponse
 // @Failure      422   {object}  docs.ErrorResponse
+// @Failure      428   {object}  docs.ErrorResponse
 // @Router       /auth/login [post]
 func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	var req LoginRequest
@@ -98,8 +134,27 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
 	}
 
-	accessToken, refreshToken, err := h.authUseCase.Login(req.Email, req.Password)
+	meta := LoginMetadata{
+		IPAddress: c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}
+
+	identifier := req.Email
+	if identifier == "" {
+		identifier = req.Username
+	}
+
+	if err := h.checkChallenge(c, challenge.ScopeLogin); err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		appErr := errors.New(errors.InternalServerError)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	accessToken, refreshToken, err := h.authUseCase.Login(identifier, req.Password, meta)
 	if err != nil {
+		h.recordRisk(c, challenge.ScopeLogin)
 		if appErr, ok := errors.IsAppError(err); ok {
 			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
 		}
@@ -119,6 +174,35 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	))
 }
 
+// Challenge godoc
+// @Summary      Get a bot challenge
+// @Description  Issues a challenge to solve when register or login responds with 428. For proof_of_work, find a solution such that sha256(token + ":" + solution) starts with difficulty zero bits, then send X-Challenge-Token and X-Challenge-Solution. For captcha, render the widget with site_key and send its response as X-Challenge-Token.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  docs.SuccessResponse{data=docs.ChallengeResponse}
+// @Failure      503  {object}  docs.ErrorResponse
+// @Router       /auth/challenge [get]
+func (h *AuthHandler) Challenge(c *fiber.Ctx) error {
+	if h.challengeGuard == nil {
+		appErr := errors.New(errors.ServiceUnavailable)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	issued, err := h.challengeGuard.Issue(c.UserContext(), c.IP())
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		appErr := errors.New(errors.InternalServerError)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	return c.JSON(response.CreateSuccessResponse(
+		c, response.MsgChallengeIssued.ID, response.MsgChallengeIssued.EN, issued,
+	))
+}
+
 // RefreshToken godoc
 // @Summary      Refresh access token
 // @Description  Exchanges refresh token for new access/refresh token pair
@@ -165,6 +249,56 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 	))
 }
 
+// SwitchOrganization godoc
+// @Summary      Switch active organization
+// @Description  Issues a new token pair whose org_id claim makes the organization the active tenant. The user must be a member. An empty organization_id issues tokens without a tenant
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      docs.SwitchOrganizationRequest  true  "Organization to activate"
+// @Success      200   {object}  docs.SuccessResponse{data=docs.TokenResponse}
+// @Failure      400   {object}  docs.ErrorResponse
+// @Failure      401   {object}  docs.ErrorResponse
+// @Failure      403   {object}  docs.ErrorResponse
+// @Router       /auth/organization [post]
+func (h *AuthHandler) SwitchOrganization(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var req SwitchOrganizationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.CreateErrorResponse(
+			c, errors.New(errors.InvalidRequestBody),
+		))
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		validationErrors := validator.FormatValidationErrorForResponseBilingual(err)
+		appErr := errors.NewWithDetails(errors.ValidationFailed, validationErrors)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	accessToken, refreshToken, err := h.authUseCase.SwitchOrganization(userID, req.OrganizationID)
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		appErr := errors.New(errors.InternalServerError)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	tokenResponse := RefreshTokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(24 * time.Hour / time.Second),
+	}
+
+	return c.JSON(response.CreateSuccessResponse(
+		c, response.MsgOrganizationSwitched.ID, response.MsgOrganizationSwitched.EN, tokenResponse,
+	))
+}
+
 // Logout godoc
 // @Summary      User logout
 // @Description  Invalidates current access token and revokes refresh tokens
@@ -213,14 +347,7 @@ func (h *AuthHandler) Profile(c *fiber.Ctx) error {
 		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
 	}
 
-	userResponse := UserResponse{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		Role:      user.Role,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
-	}
+	userResponse := ToUserResponse(user)
 
 	return c.JSON(response.CreateSuccessResponse(
 		c, response.MsgProfileRetrieve.ID, response.MsgProfileRetrieve.EN, userResponse,
@@ -256,7 +383,7 @@ func (h *AuthHandler) UpdateProfile(c *fiber.Ctx) error {
 		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
 	}
 
-	user, err := h.authUseCase.UpdateProfile(userID, req.Name)
+	user, err := h.authUseCase.UpdateProfile(userID, req.Name, req.Username)
 	if err != nil {
 		if appErr, ok := errors.IsAppError(err); ok {
 			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
@@ -265,16 +392,299 @@ func (h *AuthHandler) UpdateProfile(c *fiber.Ctx) error {
 		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
 	}
 
-	userResponse := UserResponse{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		Role:      user.Role,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
-	}
+	userResponse := ToUserResponse(user)
 
 	return c.JSON(response.CreateSuccessResponse(
 		c, response.MsgProfileUpdate.ID, response.MsgProfileUpdate.EN, userResponse,
 	))
 }
+
+// RequestPhoneVerification godoc
+// @Summary      Request phone verification
+// @Description  Sends a one-time code by SMS to the given E.164 number; the number is saved once verified
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      docs.PhoneRequest  true  "Phone number"
+// @Success      200   {object}  docs.SuccessResponse
+// @Failure      400   {object}  docs.ErrorResponse
+// @Failure      401   {object}  docs.ErrorResponse
+// @Failure      409   {object}  docs.ErrorResponse
+// @Failure      429   {object}  docs.ErrorResponse
+// @Router       /auth/phone [post]
+func (h *AuthHandler) RequestPhoneVerification(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var req PhoneRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := errors.New(errors.InvalidRequestBody)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		validationErrors := validator.FormatValidationErrorForResponseBilingual(err)
+		appErr := errors.NewWithDetails(errors.ValidationFailed, validationErrors)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	if err := h.authUseCase.RequestPhoneVerification(userID, req.Phone); err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		appErr := errors.New(errors.InternalServerError)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	return c.JSON(response.CreateSuccessResponse(
+		c, response.MsgOTPSent.ID, response.MsgOTPSent.EN, nil,
+	))
+}
+
+// VerifyPhone godoc
+// @Summary      Verify phone number
+// @Description  Confirms the code sent by POST /auth/phone and saves the phone number
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      docs.VerifyPhoneRequest  true  "Phone number and code"
+// @Success      200   {object}  docs.SuccessResponse{data=docs.UserResponse}
+// @Failure      400   {object}  docs.ErrorResponse
+// @Failure      401   {object}  docs.ErrorResponse
+// @Failure      409   {object}  docs.ErrorResponse
+// @Failure      422   {object}  docs.ErrorResponse
+// @Failure      429   {object}  docs.ErrorResponse
+// @Router       /auth/phone/verify [post]
+func (h *AuthHandler) VerifyPhone(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var req VerifyPhoneRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := errors.New(errors.InvalidRequestBody)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		validationErrors := validator.FormatValidationErrorForResponseBilingual(err)
+		appErr := errors.NewWithDetails(errors.ValidationFailed, validationErrors)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	user, err := h.authUseCase.VerifyPhone(userID, req.Phone, req.Code)
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		appErr := errors.New(errors.InternalServerError)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	return c.JSON(response.CreateSuccessResponse(
+		c, response.MsgPhoneVerified.ID, response.MsgPhoneVerified.EN, ToUserResponse(user),
+	))
+}
+
+// RequestLoginOTP godoc
+// @Summary      Request SMS login code
+// @Description  Sends a one-time login code to a verified phone number. Always succeeds for unknown numbers, and for repeated requests within the resend interval, when no new code is sent.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body      docs.PhoneRequest  true  "Phone number"
+// @Success      200   {object}  docs.SuccessResponse
+// @Failure      400   {object}  docs.ErrorResponse
+// @Failure      429   {object}  docs.ErrorResponse
+// @Router       /auth/otp/request [post]
+func (h *AuthHandler) RequestLoginOTP(c *fiber.Ctx) error {
+	var req PhoneRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := errors.New(errors.InvalidRequestBody)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		validationErrors := validator.FormatValidationErrorForResponseBilingual(err)
+		appErr := errors.NewWithDetails(errors.ValidationFailed, validationErrors)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	if err := h.authUseCase.RequestLoginOTP(req.Phone); err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		appErr := errors.New(errors.InternalServerError)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	return c.JSON(response.CreateSuccessResponse(
+		c, response.MsgOTPSent.ID, response.MsgOTPSent.EN, nil,
+	))
+}
+
+// LoginWithOTP godoc
+// @Summary      Login with SMS code
+// @Description  Exchanges the code sent by POST /auth/otp/request for access/refresh tokens
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body      docs.OTPLoginRequest  true  "Phone number and code"
+// @Success      200   {object}  docs.SuccessResponse{data=docs.TokenResponse}
+// @Failure      400   {object}  docs.ErrorResponse
+// @Failure      422   {object}  docs.ErrorResponse
+// @Failure      429   {object}  docs.ErrorResponse
+// @Router       /auth/otp/login [post]
+func (h *AuthHandler) LoginWithOTP(c *fiber.Ctx) error {
+	var req OTPLoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := errors.New(errors.InvalidRequestBody)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		validationErrors := validator.FormatValidationErrorForResponseBilingual(err)
+		appErr := errors.NewWithDetails(errors.ValidationFailed, validationErrors)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	meta := LoginMetadata{
+		IPAddress: c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}
+
+	accessToken, refreshToken, err := h.authUseCase.LoginWithOTP(req.Phone, req.Code, meta)
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		appErr := errors.New(errors.InternalServerError)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	tokenResponse := RefreshTokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(24 * time.Hour / time.Second),
+	}
+
+	return c.JSON(response.CreateSuccessResponse(
+		c, response.MsgLoginSuccess.ID, response.MsgLoginSuccess.EN, tokenResponse,
+	))
+}
+
+// UsernameAvailability godoc
+// @Summary      Check username availability
+// @Description  Reports whether a username can be registered; reason is one of invalid_format, reserved or taken
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        username  query     string  true  "Username to check"
+// @Success      200       {object}  docs.SuccessResponse{data=docs.UsernameAvailabilityResponse}
+// @Failure      400       {object}  docs.ErrorResponse
+// @Router       /auth/username-available [get]
+func (h *AuthHandler) UsernameAvailability(c *fiber.Ctx) error {
+	var req UsernameAvailabilityRequest
+	if err := c.QueryParser(&req); err != nil {
+		appErr := errors.New(errors.InvalidRequest)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		validationErrors := validator.FormatValidationErrorForResponseBilingual(err)
+		appErr := errors.NewWithDetails(errors.ValidationFailed, validationErrors)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	available, reason, err := h.authUseCase.CheckUsernameAvailability(req.Username)
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		appErr := errors.New(errors.InternalServerError)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	availabilityResponse := UsernameAvailabilityResponse{
+		Username:  req.Username,
+		Available: available,
+		Reason:    reason,
+	}
+
+	return c.JSON(response.CreateSuccessResponse(
+		c, response.MsgUsernameAvailability.ID, response.MsgUsernameAvailability.EN, availabilityResponse,
+	))
+}
+
+// LoginHistory godoc
+// @Summary      Get login history
+// @Description  Returns the current user's login attempts (newest first), including IP, user agent, location and suspicious flag
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page       query     int  false  "Page number"  default(1)
+// @Param        page_size  query     int  false  "Page size"    default(20)
+// @Success      200        {object}  docs.PaginatedResponse{data=[]docs.LoginEventResponse}
+// @Failure      400        {object}  docs.ErrorResponse
+// @Failure      401        {object}  docs.ErrorResponse
+// @Router       /auth/login-history [get]
+func (h *AuthHandler) LoginHistory(c *fiber.Ctx) error {
+	return h.loginHistory(c, c.Locals("user_id").(string))
+}
+
+// UserLoginHistory godoc
+// @Summary      Get a user's login history
+// @Description  Returns login attempts for the given user; allowed for the user themself or holders of users:read
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id         path      string  true   "User ID"
+// @Param        page       query     int     false  "Page number"  default(1)
+// @Param        page_size  query     int     false  "Page size"    default(20)
+// @Success      200        {object}  docs.PaginatedResponse{data=[]docs.LoginEventResponse}
+// @Failure      400        {object}  docs.ErrorResponse
+// @Failure      401        {object}  docs.ErrorResponse
+// @Failure      403        {object}  docs.ErrorResponse
+// @Failure      404        {object}  docs.ErrorResponse
+// @Router       /users/{id}/login-history [get]
+func (h *AuthHandler) UserLoginHistory(c *fiber.Ctx) error {
+	return h.loginHistory(c, c.Params("id"))
+}
+
+func (h *AuthHandler) loginHistory(c *fiber.Ctx, userID string) error {
+	var req LoginHistoryRequest
+	if err := c.QueryParser(&req); err != nil {
+		appErr := errors.New(errors.InvalidRequest)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		validationErrors := validator.FormatValidationErrorForResponseBilingual(err)
+		appErr := errors.NewWithDetails(errors.ValidationFailed, validationErrors)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = 20
+	}
+
+	events, total, err := h.authUseCase.GetLoginHistory(userID, req.Page, req.PageSize)
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		appErr := errors.New(errors.InternalServerError)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	meta := response.NewMetaResponse(int64(req.Page), int64(req.PageSize), total)
+
+	return c.JSON(response.CreatePaginatedResponse(
+		c, response.MsgLoginHistoryRetrieve.ID, response.MsgLoginHistoryRetrieve.EN, ToLoginEventResponses(events), meta,
+	))
+}
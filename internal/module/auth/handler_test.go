@@ -2,16 +2,21 @@ package auth
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"boilerplate-be/internal/database"
 	"boilerplate-be/internal/middleware"
+	"boilerplate-be/internal/shared/challenge"
 	apperrors "boilerplate-be/internal/shared/errors"
 	"boilerplate-be/internal/shared/security"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
 )
 
 // setupTestApp creates a test Fiber app with auth routes
@@ -132,7 +137,6 @@ func TestAuthHandler_Login(t *testing.T) {
 					Email:    "test@example.com",
 					Name:     "Test User",
 					Password: hashedPassword,
-					Role:     "user",
 				}
 			},
 			requestBody: map[string]interface{}{
@@ -166,6 +170,31 @@ func TestAuthHandler_Login(t *testing.T) {
 			},
 			expectedStatus: fiber.StatusUnprocessableEntity, // PasswordMismatch returns 422
 		},
+		{
+			name: "valid login by username",
+			setupMock: func(m *MockAuthRepository) {
+				hashedPassword, _ := security.HashPassword("password123")
+				m.users["user-id"] = &User{
+					ID:       "user-id",
+					Email:    "test@example.com",
+					Username: "testuser",
+					Password: hashedPassword,
+				}
+			},
+			requestBody: map[string]interface{}{
+				"username": "testuser",
+				"password": "password123",
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:      "missing email and username",
+			setupMock: func(m *MockAuthRepository) {},
+			requestBody: map[string]interface{}{
+				"password": "password123",
+			},
+			expectedStatus: fiber.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -198,13 +227,69 @@ func TestAuthHandler_Login(t *testing.T) {
 	}
 }
 
+func TestAuthHandler_LoginChallenge(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := &database.RedisClient{Client: redis.NewClient(&redis.Options{Addr: mr.Addr()})}
+	defer redisClient.Close()
+
+	verifier := challenge.NewProofOfWorkVerifier(redisClient, challenge.ProofOfWorkConfig{
+		KeyPrefix: "challenge:pow", Difficulty: 4, TTL: time.Minute,
+	})
+	tracker := challenge.NewRiskTracker(redisClient, "challenge:risk", time.Minute)
+	guard := challenge.NewGuard(verifier, tracker, map[string]int{challenge.ScopeLogin: 1})
+
+	mockRepo := NewMockAuthRepository()
+	hashedPassword, _ := security.HashPassword("password123")
+	mockRepo.users["user-id"] = &User{ID: "user-id", Email: "test@example.com", Password: hashedPassword}
+
+	handler := &AuthHandler{authUseCase: &mockAuthUseCase{
+		repo:       mockRepo,
+		jwtManager: security.NewJWTManager("test-secret", 24*time.Hour),
+	}}
+	handler.SetChallengeGuard(guard)
+	app := setupTestApp(handler)
+
+	login := func(password string, headers map[string]string) int {
+		body, _ := json.Marshal(map[string]interface{}{"email": "test@example.com", "password": password})
+		req := httptest.NewRequest("POST", "/login", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("failed to execute request: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	if status := login("wrongpassword", nil); status != fiber.StatusUnprocessableEntity {
+		t.Fatalf("expected first failure to be unchallenged, got %d", status)
+	}
+	if status := login("password123", nil); status != fiber.StatusPreconditionRequired {
+		t.Fatalf("expected challenge after failure, got %d", status)
+	}
+
+	issued, err := guard.Issue(context.Background(), "0.0.0.0")
+	if err != nil {
+		t.Fatalf("failed to issue challenge: %v", err)
+	}
+	status := login("password123", map[string]string{
+		challenge.HeaderToken:    issued.Token,
+		challenge.HeaderSolution: challenge.Solve(issued.Token, issued.Difficulty),
+	})
+	if status != fiber.StatusOK {
+		t.Errorf("expected solved challenge to allow login, got %d", status)
+	}
+}
+
 // mockAuthUseCase implements AuthUseCase for testing
 type mockAuthUseCase struct {
 	repo       *MockAuthRepository
 	jwtManager *security.JWTManager
 }
 
-func (m *mockAuthUseCase) Register(email, password, name string) (*User, string, string, error) {
+func (m *mockAuthUseCase) Register(email, password, name, username string) (*User, string, string, error) {
 	// Check if user exists
 	if _, err := m.repo.GetUserByEmail(email); err == nil {
 		return nil, "", "", err
@@ -220,7 +305,7 @@ func (m *mockAuthUseCase) Register(email, password, name string) (*User, string,
 		Email:     email,
 		Password:  hashedPassword,
 		Name:      name,
-		Role:      "user",
+		Username:  username,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -229,7 +314,7 @@ func (m *mockAuthUseCase) Register(email, password, name string) (*User, string,
 		return nil, "", "", err
 	}
 
-	accessToken, refreshToken, err := m.jwtManager.GenerateTokenPair(user.ID, user.Email, user.Role)
+	accessToken, refreshToken, err := m.jwtManager.GenerateTokenPair(user.ID, user.Email)
 	if err != nil {
 		return nil, "", "", err
 	}
@@ -237,8 +322,11 @@ func (m *mockAuthUseCase) Register(email, password, name string) (*User, string,
 	return user, accessToken, refreshToken, nil
 }
 
-func (m *mockAuthUseCase) Login(email, password string) (string, string, error) {
-	user, err := m.repo.GetUserByEmail(email)
+func (m *mockAuthUseCase) Login(identifier, password string, meta LoginMetadata) (string, string, error) {
+	user, err := m.repo.GetUserByEmail(identifier)
+	if err != nil {
+		user, err = m.repo.GetUserByUsername(identifier)
+	}
 	if err != nil {
 		return "", "", err
 	}
@@ -248,13 +336,21 @@ func (m *mockAuthUseCase) Login(email, password string) (string, string, error)
 		return "", "", apperrors.New(apperrors.PasswordMismatch)
 	}
 
-	return m.jwtManager.GenerateTokenPair(user.ID, user.Email, user.Role)
+	return m.jwtManager.GenerateTokenPair(user.ID, user.Email)
 }
 
 func (m *mockAuthUseCase) RefreshToken(refreshToken string) (string, string, error) {
 	return "", "", nil
 }
 
+func (m *mockAuthUseCase) SwitchOrganization(userID, organizationID string) (string, string, error) {
+	user, err := m.repo.GetUserByID(userID)
+	if err != nil {
+		return "", "", err
+	}
+	return m.jwtManager.GenerateTokenPair(user.ID, user.Email, security.WithOrganization(organizationID))
+}
+
 func (m *mockAuthUseCase) Logout(userID, tokenID string) error {
 	return nil
 }
@@ -263,11 +359,45 @@ func (m *mockAuthUseCase) GetProfile(userID string) (*User, error) {
 	return m.repo.GetUserByID(userID)
 }
 
-func (m *mockAuthUseCase) UpdateProfile(userID, name string) (*User, error) {
+func (m *mockAuthUseCase) UpdateProfile(userID, name, username string) (*User, error) {
 	user, err := m.repo.GetUserByID(userID)
 	if err != nil {
 		return nil, err
 	}
 	user.Name = name
+	if username != "" {
+		user.Username = username
+	}
 	return user, m.repo.UpdateUser(user)
 }
+
+func (m *mockAuthUseCase) CheckUsernameAvailability(username string) (bool, string, error) {
+	if _, err := m.repo.GetUserByUsername(username); err == nil {
+		return false, UsernameReasonTaken, nil
+	}
+	return true, "", nil
+}
+
+func (m *mockAuthUseCase) GetLoginHistory(userID string, page, pageSize int) ([]LoginEvent, int64, error) {
+	return m.repo.GetLoginEvents(userID, pageSize, (page-1)*pageSize)
+}
+
+func (m *mockAuthUseCase) RequestPhoneVerification(userID, phone string) error {
+	return nil
+}
+
+func (m *mockAuthUseCase) VerifyPhone(userID, phone, code string) (*User, error) {
+	return m.repo.GetUserByID(userID)
+}
+
+func (m *mockAuthUseCase) RequestLoginOTP(phone string) error {
+	return nil
+}
+
+func (m *mockAuthUseCase) LoginWithOTP(phone, code string, meta LoginMetadata) (string, string, error) {
+	user, err := m.repo.GetUserByPhone(phone)
+	if err != nil {
+		return "", "", apperrors.New(apperrors.InvalidOTP)
+	}
+	return m.jwtManager.GenerateTokenPair(user.ID, user.Email)
+}
@@ -0,0 +1,276 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"log"
+	"net"
+	"slices"
+	"strings"
+
+	"boilerplate-be/internal/config"
+	"boilerplate-be/internal/shared/errors"
+	"boilerplate-be/internal/shared/security"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// LDAPAuthenticator verifies credentials with an LDAP bind (OpenLDAP, Active Directory, ...).
+// Users are provisioned into the users table on their first successful login and linked to
+// their entry by its identifier, never by email. Their directory groups are mapped to RBAC
+// roles through config.LDAPConfig.GroupRoleMap and reconciled on every login.
+type LDAPAuthenticator struct {
+	config       config.LDAPConfig
+	authRepo     AuthRepository
+	roleAssigner RoleAssigner
+	groupRoles   map[string]string
+}
+
+func NewLDAPAuthenticator(cfg config.LDAPConfig, authRepo AuthRepository, roleAssigner RoleAssigner) *LDAPAuthenticator {
+	groupRoles := make(map[string]string, len(cfg.GroupRoleMap))
+	for group, role := range cfg.GroupRoleMap {
+		groupRoles[strings.ToLower(group)] = role
+	}
+
+	return &LDAPAuthenticator{
+		config:       cfg,
+		authRepo:     authRepo,
+		roleAssigner: roleAssigner,
+		groupRoles:   groupRoles,
+	}
+}
+
+func (a *LDAPAuthenticator) Name() string {
+	return LoginMethodLDAP
+}
+
+func (a *LDAPAuthenticator) Authenticate(identifier, password string) (*User, error) {
+	// An empty password would be an unauthenticated bind, which most servers accept
+	if password == "" {
+		return nil, errors.New(errors.PasswordMismatch)
+	}
+
+	conn, err := a.connect()
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ExternalServiceError)
+	}
+	defer conn.Close()
+
+	if a.config.BindDN != "" {
+		if err := conn.Bind(a.config.BindDN, a.config.BindPassword); err != nil {
+			return nil, errors.Wrap(err, errors.ExternalServiceError)
+		}
+	}
+
+	entry, err := a.findEntry(conn, identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, errors.New(errors.PasswordMismatch)
+		}
+		return nil, errors.Wrap(err, errors.ExternalServiceError)
+	}
+
+	user, created, err := a.provisionUser(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	a.syncRoles(user, entry.GetAttributeValues(a.config.GroupAttribute), created)
+
+	return user, nil
+}
+
+func (a *LDAPAuthenticator) connect() (*ldap.Conn, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: a.config.InsecureSkipVerify}
+
+	conn, err := ldap.DialURL(a.config.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: a.config.Timeout}),
+		ldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(a.config.Timeout)
+
+	if a.config.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+// findEntry searches for exactly one directory entry matching identifier
+func (a *LDAPAuthenticator) findEntry(conn *ldap.Conn, identifier string) (*ldap.Entry, error) {
+	filter := strings.ReplaceAll(a.config.UserFilter, "%s", ldap.EscapeFilter(identifier))
+
+	request := ldap.NewSearchRequest(
+		a.config.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, 0, false,
+		filter,
+		a.searchAttributes(),
+		nil,
+	)
+
+	result, err := conn.Search(request)
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+			return nil, errors.New(errors.AccountNotFound)
+		}
+		return nil, errors.Wrap(err, errors.ExternalServiceError)
+	}
+
+	// Unknown or ambiguous identifiers are both treated as unknown accounts
+	if len(result.Entries) != 1 {
+		return nil, errors.New(errors.AccountNotFound)
+	}
+
+	return result.Entries[0], nil
+}
+
+func (a *LDAPAuthenticator) searchAttributes() []string {
+	attributes := []string{a.config.EmailAttribute, a.config.NameAttribute, a.config.GroupAttribute}
+	if a.config.IDAttribute != "" {
+		attributes = append(attributes, a.config.IDAttribute)
+	}
+	return attributes
+}
+
+// externalID identifies the entry by config.LDAPConfig.IDAttribute, hex-encoded since
+// objectGUID is binary, or by its DN when no attribute is configured
+func (a *LDAPAuthenticator) externalID(entry *ldap.Entry) string {
+	if a.config.IDAttribute == "" {
+		return strings.ToLower(entry.DN)
+	}
+	return hex.EncodeToString(entry.GetRawAttributeValue(a.config.IDAttribute))
+}
+
+// provisionUser returns the local user linked to a directory entry, creating it on first login.
+// A local account that already owns the entry's email is not linked automatically: whoever
+// controls the entry would otherwise log in as it. Provisioned users get a random password so
+// they cannot log in with a local password. created reports whether the user was provisioned
+// by this call.
+func (a *LDAPAuthenticator) provisionUser(entry *ldap.Entry) (user *User, created bool, err error) {
+	externalID := a.externalID(entry)
+	if externalID == "" {
+		return nil, false, errors.New(errors.ConfigurationError)
+	}
+
+	user, err = a.authRepo.GetUserByExternalID(AuthSourceLDAP, externalID)
+	if err == nil {
+		return user, false, nil
+	}
+	if appErr, ok := errors.IsAppError(err); !ok || appErr.Code != errors.AccountNotFound {
+		return nil, false, err
+	}
+
+	email := entry.GetAttributeValue(a.config.EmailAttribute)
+	if email == "" {
+		return nil, false, errors.New(errors.InvalidEmail)
+	}
+
+	if _, err := a.authRepo.GetUserByEmail(email); err == nil {
+		return nil, false, errors.New(errors.AccountNotLinked)
+	} else if appErr, ok := errors.IsAppError(err); !ok || appErr.Code != errors.AccountNotFound {
+		return nil, false, err
+	}
+
+	randomPassword := make([]byte, 32)
+	if _, err := rand.Read(randomPassword); err != nil {
+		return nil, false, errors.Wrap(err, errors.PasswordHashFailed)
+	}
+	hashedPassword, err := security.HashPassword(hex.EncodeToString(randomPassword))
+	if err != nil {
+		return nil, false, errors.Wrap(err, errors.PasswordHashFailed)
+	}
+
+	name := entry.GetAttributeValue(a.config.NameAttribute)
+	if name == "" {
+		name = email
+	}
+
+	user = &User{
+		Name:       name,
+		Email:      email,
+		Password:   hashedPassword,
+		AuthSource: AuthSourceLDAP,
+		ExternalID: externalID,
+	}
+	if err := a.authRepo.CreateUser(user); err != nil {
+		return nil, false, err
+	}
+
+	return user, true, nil
+}
+
+// syncRoles makes the directory the source of truth for mapped roles: roles mapped from the
+// user's current groups are granted and other mapped roles removed, except DefaultRole, which
+// every account holds and a newly provisioned user is granted. Roles outside the mapping are
+// left alone. A failure is logged rather than failing the login.
+func (a *LDAPAuthenticator) syncRoles(user *User, groups []string, created bool) {
+	if a.roleAssigner == nil {
+		return
+	}
+
+	roles := a.mapGroupsToRoles(groups)
+
+	var revoked []string
+	for _, role := range a.groupRoles {
+		if role != DefaultRole && !slices.Contains(roles, role) && !slices.Contains(revoked, role) {
+			revoked = append(revoked, role)
+		}
+	}
+	slices.Sort(revoked)
+
+	// Revoke first so a role moved between conflicting groups does not trip separation of duty
+	if len(revoked) > 0 {
+		if err := a.roleAssigner.RemoveRolesByName(user.ID, revoked); err != nil {
+			log.Printf("ldap: failed to remove roles %v from user %s: %v", revoked, user.ID, err)
+		}
+	}
+
+	if created && !slices.Contains(roles, DefaultRole) {
+		roles = append([]string{DefaultRole}, roles...)
+	}
+	if len(roles) == 0 {
+		return
+	}
+
+	if err := a.roleAssigner.AssignRolesByName(user.ID, roles); err != nil {
+		log.Printf("ldap: failed to assign roles %v to user %s: %v", roles, user.ID, err)
+	}
+}
+
+// mapGroupsToRoles matches each group by full DN or by its CN, case-insensitively
+func (a *LDAPAuthenticator) mapGroupsToRoles(groups []string) []string {
+	seen := make(map[string]bool)
+	var roles []string
+
+	for _, group := range groups {
+		role, ok := a.groupRoles[strings.ToLower(group)]
+		if !ok {
+			role, ok = a.groupRoles[strings.ToLower(groupCN(group))]
+		}
+		if ok && !seen[role] {
+			seen[role] = true
+			roles = append(roles, role)
+		}
+	}
+
+	return roles
+}
+
+// groupCN returns the value of the first RDN of a group DN, e.g. "admins" for "cn=admins,ou=groups"
+func groupCN(dn string) string {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil || len(parsed.RDNs) == 0 || len(parsed.RDNs[0].Attributes) == 0 {
+		return ""
+	}
+	return parsed.RDNs[0].Attributes[0].Value
+}
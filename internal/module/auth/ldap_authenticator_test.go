@@ -0,0 +1,399 @@
+package auth
+
+import (
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"boilerplate-be/internal/config"
+	"boilerplate-be/internal/shared/enum"
+	apperrors "boilerplate-be/internal/shared/errors"
+	"boilerplate-be/internal/shared/security"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// ldapStubEntry is a directory entry served by ldapStub
+type ldapStubEntry struct {
+	dn       string
+	password string
+	attrs    map[string][]string
+}
+
+// ldapStub is a minimal in-process LDAP server supporting simple bind, search and unbind.
+// A search returns entries having any attribute value that appears as "(attr=value)" in the filter.
+type ldapStub struct {
+	listener        net.Listener
+	serviceDN       string
+	servicePassword string
+	entries         []ldapStubEntry
+}
+
+func startLDAPStub(t *testing.T, serviceDN, servicePassword string, entries ...ldapStubEntry) *ldapStub {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start ldap stub: %v", err)
+	}
+	stub := &ldapStub{
+		listener:        listener,
+		serviceDN:       serviceDN,
+		servicePassword: servicePassword,
+		entries:         entries,
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go stub.serve(conn)
+		}
+	}()
+
+	return stub
+}
+
+func (s *ldapStub) URL() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+func (s *ldapStub) serve(conn net.Conn) {
+	defer conn.Close()
+
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		messageID := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			name := op.Children[1].Value.(string)
+			password := op.Children[2].Data.String()
+			code := ldap.LDAPResultInvalidCredentials
+			if s.checkCredentials(name, password) {
+				code = ldap.LDAPResultSuccess
+			}
+			conn.Write(ldapResult(messageID, ldap.ApplicationBindResponse, code).Bytes())
+
+		case ldap.ApplicationSearchRequest:
+			filter, _ := ldap.DecompileFilter(op.Children[6])
+			for _, entry := range s.entries {
+				if entry.matches(filter) {
+					conn.Write(ldapSearchEntry(messageID, entry).Bytes())
+				}
+			}
+			conn.Write(ldapResult(messageID, ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess).Bytes())
+
+		case ldap.ApplicationUnbindRequest:
+			return
+		}
+	}
+}
+
+func (s *ldapStub) checkCredentials(dn, password string) bool {
+	if dn == s.serviceDN {
+		return password == s.servicePassword
+	}
+	for _, entry := range s.entries {
+		if entry.dn == dn {
+			return password == entry.password
+		}
+	}
+	return false
+}
+
+func (e ldapStubEntry) matches(filter string) bool {
+	for name, values := range e.attrs {
+		for _, value := range values {
+			if strings.Contains(filter, "("+name+"="+value+")") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func ldapEnvelope(messageID int64, op *ber.Packet) *ber.Packet {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "MessageID"))
+	packet.AppendChild(op)
+	return packet
+}
+
+func ldapResult(messageID int64, tag ber.Tag, code int) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "resultCode"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "matchedDN"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "diagnosticMessage"))
+	return ldapEnvelope(messageID, op)
+}
+
+func ldapSearchEntry(messageID int64, entry ldapStubEntry) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Entry")
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.dn, "objectName"))
+
+	attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attributes")
+	for name, values := range entry.attrs {
+		attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attribute")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "values")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "value"))
+		}
+		attribute.AppendChild(set)
+		attributes.AppendChild(attribute)
+	}
+	op.AppendChild(attributes)
+
+	return ldapEnvelope(messageID, op)
+}
+
+// mockRoleAssigner records the roles each user holds by name
+type mockRoleAssigner struct {
+	assigned map[string][]string
+	err      error
+}
+
+func (m *mockRoleAssigner) AssignRolesByName(userID string, roleNames []string) error {
+	if m.err != nil {
+		return m.err
+	}
+	for _, name := range roleNames {
+		if !slices.Contains(m.assigned[userID], name) {
+			m.assigned[userID] = append(m.assigned[userID], name)
+		}
+	}
+	return nil
+}
+
+func (m *mockRoleAssigner) RemoveRolesByName(userID string, roleNames []string) error {
+	m.assigned[userID] = slices.DeleteFunc(m.assigned[userID], func(name string) bool {
+		return slices.Contains(roleNames, name)
+	})
+	return nil
+}
+
+func newTestLDAPConfig(url string) config.LDAPConfig {
+	return config.LDAPConfig{
+		Enabled:        true,
+		URL:            url,
+		BindDN:         "cn=readonly,dc=example,dc=com",
+		BindPassword:   "readonly",
+		BaseDN:         "ou=people,dc=example,dc=com",
+		UserFilter:     "(|(uid=%s)(mail=%s))",
+		EmailAttribute: "mail",
+		NameAttribute:  "cn",
+		GroupAttribute: "memberOf",
+		GroupRoleMap: map[string]string{
+			"cn=Admins,ou=groups,dc=example,dc=com": "admin",
+			"developers":                            "user",
+		},
+		Timeout: 5 * time.Second,
+	}
+}
+
+func TestLDAPAuthenticator(t *testing.T) {
+	stub := startLDAPStub(t, "cn=readonly,dc=example,dc=com", "readonly", ldapStubEntry{
+		dn:       "uid=alice,ou=people,dc=example,dc=com",
+		password: "alice-secret",
+		attrs: map[string][]string{
+			"uid":  {"alice"},
+			"mail": {"alice@example.com"},
+			"cn":   {"Alice Example"},
+			"memberOf": {
+				"cn=admins,ou=groups,dc=example,dc=com",
+				"cn=developers,ou=groups,dc=example,dc=com",
+				"cn=unmapped,ou=groups,dc=example,dc=com",
+			},
+		},
+	})
+
+	mockRepo := NewMockAuthRepository()
+	roles := &mockRoleAssigner{assigned: map[string][]string{}}
+	authenticator := NewLDAPAuthenticator(newTestLDAPConfig(stub.URL()), mockRepo, roles)
+
+	t.Run("first login provisions the user and maps groups to roles", func(t *testing.T) {
+		user, err := authenticator.Authenticate("alice", "alice-secret")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if user.Email != "alice@example.com" || user.Name != "Alice Example" {
+			t.Errorf("unexpected provisioned user: %+v", user)
+		}
+		if len(mockRepo.users) != 1 {
+			t.Errorf("expected 1 provisioned user, got %d", len(mockRepo.users))
+		}
+		if got := strings.Join(roles.assigned[user.ID], ","); got != "admin,user" {
+			t.Errorf("expected roles admin,user, got %s", got)
+		}
+	})
+
+	t.Run("later logins reuse the provisioned user", func(t *testing.T) {
+		if _, err := authenticator.Authenticate("alice", "alice-secret"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(mockRepo.users) != 1 {
+			t.Errorf("expected 1 user, got %d", len(mockRepo.users))
+		}
+	})
+
+	tests := []struct {
+		name       string
+		identifier string
+		password   string
+		wantCode   enum.ErrorCode
+	}{
+		{"wrong password", "alice", "wrong", apperrors.PasswordMismatch},
+		{"empty password", "alice", "", apperrors.PasswordMismatch},
+		{"unknown user", "bob", "whatever", apperrors.AccountNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := authenticator.Authenticate(tt.identifier, tt.password)
+			if appErr, ok := apperrors.IsAppError(err); !ok || appErr.Code != tt.wantCode {
+				t.Errorf("expected %s, got %v", tt.wantCode, err)
+			}
+		})
+	}
+
+	t.Run("directory unavailable", func(t *testing.T) {
+		cfg := newTestLDAPConfig("ldap://127.0.0.1:1")
+		_, err := NewLDAPAuthenticator(cfg, mockRepo, roles).Authenticate("alice", "alice-secret")
+		if appErr, ok := apperrors.IsAppError(err); !ok || appErr.Code != apperrors.ExternalServiceError {
+			t.Errorf("expected ExternalServiceError, got %v", err)
+		}
+	})
+}
+
+func TestLDAPAuthenticator_LocalAccountNotLinked(t *testing.T) {
+	stub := startLDAPStub(t, "cn=readonly,dc=example,dc=com", "readonly", ldapStubEntry{
+		dn:       "uid=mallory,ou=people,dc=example,dc=com",
+		password: "mallory-secret",
+		attrs: map[string][]string{
+			"uid":  {"mallory"},
+			"mail": {"admin@example.com"},
+		},
+	})
+
+	mockRepo := NewMockAuthRepository()
+	mockRepo.users["admin-1"] = &User{ID: "admin-1", Email: "admin@example.com", AuthSource: AuthSourceLocal}
+	authenticator := NewLDAPAuthenticator(newTestLDAPConfig(stub.URL()), mockRepo, nil)
+
+	user, err := authenticator.Authenticate("mallory", "mallory-secret")
+	if appErr, ok := apperrors.IsAppError(err); !ok || appErr.Code != apperrors.AccountNotLinked {
+		t.Fatalf("expected AccountNotLinked, got %v", err)
+	}
+	if user != nil {
+		t.Errorf("expected no user, got %+v", user)
+	}
+	if len(mockRepo.users) != 1 {
+		t.Errorf("expected no user to be provisioned, got %d users", len(mockRepo.users))
+	}
+}
+
+func TestLDAPAuthenticator_ReconcilesRoles(t *testing.T) {
+	entry := func(groups ...string) ldapStubEntry {
+		return ldapStubEntry{
+			dn:       "uid=alice,ou=people,dc=example,dc=com",
+			password: "alice-secret",
+			attrs: map[string][]string{
+				"uid":      {"alice"},
+				"mail":     {"alice@example.com"},
+				"memberOf": groups,
+			},
+		}
+	}
+
+	mockRepo := NewMockAuthRepository()
+	roles := &mockRoleAssigner{assigned: map[string][]string{}}
+	login := func(groups ...string) *User {
+		t.Helper()
+		stub := startLDAPStub(t, "cn=readonly,dc=example,dc=com", "readonly", entry(groups...))
+		user, err := NewLDAPAuthenticator(newTestLDAPConfig(stub.URL()), mockRepo, roles).Authenticate("alice", "alice-secret")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return user
+	}
+
+	user := login("cn=admins,ou=groups,dc=example,dc=com")
+	if got := strings.Join(roles.assigned[user.ID], ","); got != "user,admin" {
+		t.Fatalf("expected roles user,admin, got %s", got)
+	}
+
+	// A role granted outside the mapping survives reconciliation
+	roles.assigned[user.ID] = append(roles.assigned[user.ID], "auditor")
+
+	user = login("cn=unmapped,ou=groups,dc=example,dc=com")
+	if got := strings.Join(roles.assigned[user.ID], ","); got != "user,auditor" {
+		t.Errorf("expected admin to be removed leaving user,auditor, got %s", got)
+	}
+	if len(mockRepo.users) != 1 {
+		t.Errorf("expected the linked user to be reused, got %d users", len(mockRepo.users))
+	}
+}
+
+func TestAuthService_AuthenticatorChain(t *testing.T) {
+	stub := startLDAPStub(t, "cn=readonly,dc=example,dc=com", "readonly", ldapStubEntry{
+		dn:       "uid=alice,ou=people,dc=example,dc=com",
+		password: "alice-secret",
+		attrs: map[string][]string{
+			"uid":  {"alice"},
+			"mail": {"alice@example.com"},
+		},
+	})
+
+	mockRepo := NewMockAuthRepository()
+	hashedPassword, _ := security.HashPassword("local-secret")
+	mockRepo.users["local-1"] = &User{ID: "local-1", Email: "local@example.com", Password: hashedPassword}
+
+	useCase := NewAuthUseCase(mockRepo, nil, nil)
+	useCase.SetAuthenticators(
+		NewPasswordAuthenticator(mockRepo),
+		NewLDAPAuthenticator(newTestLDAPConfig(stub.URL()), mockRepo, nil),
+	)
+
+	tests := []struct {
+		name       string
+		identifier string
+		password   string
+		wantMethod string
+		wantCode   enum.ErrorCode
+	}{
+		{"local password", "local@example.com", "local-secret", LoginMethodPassword, apperrors.Success},
+		{"directory password", "alice", "alice-secret", LoginMethodLDAP, apperrors.Success},
+		{"provisioned user still uses the directory", "alice@example.com", "alice-secret", LoginMethodLDAP, apperrors.Success},
+		{"wrong local password", "local@example.com", "wrong", LoginMethodPassword, apperrors.PasswordMismatch},
+		{"wrong directory password", "alice", "wrong", LoginMethodLDAP, apperrors.PasswordMismatch},
+		{"unknown everywhere", "nobody", "whatever", LoginMethodPassword, apperrors.AccountNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, method, err := useCase.authenticate(tt.identifier, tt.password)
+			if method != tt.wantMethod {
+				t.Errorf("expected method %s, got %s", tt.wantMethod, method)
+			}
+			if tt.wantCode == apperrors.Success {
+				if err != nil || user == nil {
+					t.Errorf("expected success, got %v", err)
+				}
+				return
+			}
+			if appErr, ok := apperrors.IsAppError(err); !ok || appErr.Code != tt.wantCode {
+				t.Errorf("expected %s, got %v", tt.wantCode, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package auth
+
+import "log"
+
+// LogLoginNotifier writes suspicious login notifications to the application log.
+// Swap it for an email, SMS or websocket notifier through AuthUseCase.SetLoginNotifier.
+type LogLoginNotifier struct{}
+
+// NewLogLoginNotifier creates a notifier that logs suspicious logins
+func NewLogLoginNotifier() *LogLoginNotifier {
+	return &LogLoginNotifier{}
+}
+
+func (n *LogLoginNotifier) NotifySuspiciousLogin(user *User, event *LoginEvent) error {
+	log.Printf("Suspicious login for user %s (%s) from ip=%s country=%s user_agent=%q",
+		user.ID, user.Email, event.IPAddress, event.CountryCode, event.UserAgent)
+	return nil
+}
@@ -0,0 +1,11 @@
+package auth
+
+import "boilerplate-be/internal/module/rbac"
+
+// RegisterPermissions declares the permissions over a user's own profile
+func RegisterPermissions(registry *rbac.PermissionRegistry) {
+	registry.Declare("auth",
+		rbac.PermissionSpec{Name: "profile:read", Description: "View own profile"},
+		rbac.PermissionSpec{Name: "profile:write", Description: "Update own profile"},
+	)
+}
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"boilerplate-be/internal/module/policy"
+)
+
+// ResourceTypeUser is the policy resource type for user accounts
+const ResourceTypeUser = "user"
+
+// PolicyReadLoginHistory allows reading a user's login history
+const PolicyReadLoginHistory = "user.login_history.read"
+
+// RegisterPolicies registers the user loader and the auth module's policies
+func RegisterPolicies(engine *policy.Engine, repo AuthRepository) {
+	engine.RegisterLoader(ResourceTypeUser, func(id string) (*policy.Resource, error) {
+		user, err := repo.GetUserByID(id)
+		if err != nil {
+			return nil, err
+		}
+		return &policy.Resource{
+			Type:    ResourceTypeUser,
+			ID:      user.ID,
+			OwnerID: user.ID,
+			Attributes: map[string]interface{}{
+				"email":    user.Email,
+				"username": user.Username,
+			},
+		}, nil
+	})
+
+	engine.Register(PolicyReadLoginHistory, policy.MustParseExpression(
+		`own(user) || has_permission("users:read")`,
+	))
+}
@@ -12,6 +12,12 @@ import (
 	"github.com/lib/pq"
 )
 
+// UsernameUniqueIndex enforces case-insensitive username uniqueness
+const UsernameUniqueIndex = "idx_users_username_lower"
+
+// externalIDUniqueIndex enforces one account per directory entry
+const externalIDUniqueIndex = "idx_users_external_id"
+
 type authRepository struct {
 	db          *sql.DB
 	cacheHelper *utils.CacheHelper
@@ -29,18 +35,25 @@ func (r *authRepository) CreateUser(user *User) error {
 	user.ID = id.String()
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
-	if user.Role == "" {
-		user.Role = "user"
+	if user.AuthSource == "" {
+		user.AuthSource = AuthSourceLocal
 	}
 
 	query := `
-		INSERT INTO users (id, name, email, password, role, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO users (id, name, username, email, password, auth_source, external_id, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, NULLIF($7, ''), $8, $9)
 	`
 
-	_, err := r.db.Exec(query, user.ID, user.Name, user.Email, user.Password, user.Role, user.CreatedAt, user.UpdatedAt)
+	_, err := r.db.Exec(query, user.ID, user.Name, user.Username, user.Email, user.Password,
+		user.AuthSource, user.ExternalID, user.CreatedAt, user.UpdatedAt)
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			switch pqErr.Constraint {
+			case UsernameUniqueIndex:
+				return errors.New(errors.UsernameExists)
+			case externalIDUniqueIndex:
+				return errors.New(errors.Conflict)
+			}
 			return errors.New(errors.EmailExists)
 		}
 		return errors.Wrap(err, errors.DatabaseInsertFailed)
@@ -49,48 +62,73 @@ func (r *authRepository) CreateUser(user *User) error {
 	return nil
 }
 
-func (r *authRepository) GetUserByEmail(email string) (*User, error) {
-	user := &User{}
-	query := `
-		SELECT id, name, email, password, role, created_at, updated_at
-		FROM users
-		WHERE email = $1
-	`
+// UserColumns is the column list read by ScanUser, for every repository reading users
+const UserColumns = `id, name, username, email, phone, phone_verified_at, password, auth_source, external_id, created_at, updated_at`
 
-	err := r.db.QueryRow(query, email).Scan(
-		&user.ID, &user.Name, &user.Email, &user.Password,
-		&user.Role, &user.CreatedAt, &user.UpdatedAt,
+// RowScanner is satisfied by both *sql.Row and *sql.Rows
+type RowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// ScanUser reads a row selected with UserColumns
+func ScanUser(row RowScanner) (User, error) {
+	var user User
+	var username, phone, externalID sql.NullString
+	var phoneVerifiedAt sql.NullTime
+
+	err := row.Scan(
+		&user.ID, &user.Name, &username, &user.Email, &phone, &phoneVerifiedAt,
+		&user.Password, &user.AuthSource, &externalID, &user.CreatedAt, &user.UpdatedAt,
 	)
+	user.Username = username.String
+	user.Phone = phone.String
+	user.ExternalID = externalID.String
+	if phoneVerifiedAt.Valid {
+		user.PhoneVerifiedAt = &phoneVerifiedAt.Time
+	}
+	return user, err
+}
 
+// scanUser reads a single user; a missing row is AccountNotFound
+func scanUser(row *sql.Row) (*User, error) {
+	user, err := ScanUser(row)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.New(errors.AccountNotFound)
 		}
 		return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
 	}
+	return &user, nil
+}
 
-	return user, nil
+func (r *authRepository) GetUserByEmail(email string) (*User, error) {
+	query := `SELECT ` + UserColumns + ` FROM users WHERE email = $1`
+	return scanUser(r.db.QueryRow(query, email))
+}
+
+func (r *authRepository) GetUserByUsername(username string) (*User, error) {
+	query := `SELECT ` + UserColumns + ` FROM users WHERE LOWER(username) = LOWER($1)`
+	return scanUser(r.db.QueryRow(query, username))
+}
+
+func (r *authRepository) GetUserByExternalID(source, externalID string) (*User, error) {
+	query := `SELECT ` + UserColumns + ` FROM users WHERE auth_source = $1 AND external_id = $2`
+	return scanUser(r.db.QueryRow(query, source, externalID))
+}
+
+func (r *authRepository) GetUserByPhone(phone string) (*User, error) {
+	query := `SELECT ` + UserColumns + ` FROM users WHERE phone = $1`
+	return scanUser(r.db.QueryRow(query, phone))
 }
 
 func (r *authRepository) GetUserByID(id string) (*User, error) {
 	cacheKey := r.cacheHelper.BuildUserCacheKey(id, "profile")
 
 	cachedData, err := r.cacheHelper.GetOrSet(context.Background(), cacheKey, func() (interface{}, error) {
-		dbUser := &User{}
-		query := `
-			SELECT id, name, email, password, role, created_at, updated_at
-			FROM users
-			WHERE id = $1
-		`
-		err := r.db.QueryRow(query, id).Scan(
-			&dbUser.ID, &dbUser.Name, &dbUser.Email, &dbUser.Password,
-			&dbUser.Role, &dbUser.CreatedAt, &dbUser.UpdatedAt,
-		)
+		query := `SELECT ` + UserColumns + ` FROM users WHERE id = $1`
+		dbUser, err := scanUser(r.db.QueryRow(query, id))
 		if err != nil {
-			if err == sql.ErrNoRows {
-				return nil, errors.New(errors.AccountNotFound)
-			}
-			return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
+			return nil, err
 		}
 		return dbUser, nil
 	}, 5*time.Minute)
@@ -113,12 +151,15 @@ func (r *authRepository) UpdateUser(user *User) error {
 
 	query := `
 		UPDATE users
-		SET name = $2, updated_at = $3
+		SET name = $2, username = NULLIF($3, ''), updated_at = $4
 		WHERE id = $1
 	`
 
-	result, err := r.db.Exec(query, user.ID, user.Name, user.UpdatedAt)
+	result, err := r.db.Exec(query, user.ID, user.Name, user.Username, user.UpdatedAt)
 	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" && pqErr.Constraint == UsernameUniqueIndex {
+			return errors.New(errors.UsernameExists)
+		}
 		return errors.Wrap(err, errors.DatabaseUpdateFailed)
 	}
 
@@ -137,3 +178,151 @@ func (r *authRepository) UpdateUser(user *User) error {
 
 	return nil
 }
+
+func (r *authRepository) DeleteUser(id string) error {
+	result, err := r.db.Exec(`DELETE FROM users WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, errors.DatabaseDeleteFailed)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, errors.DatabaseError)
+	}
+
+	if rowsAffected == 0 {
+		return errors.New(errors.AccountNotFound)
+	}
+
+	if err := r.cacheHelper.InvalidateUserCache(context.Background(), id); err != nil {
+		return errors.Wrap(err, errors.CacheError)
+	}
+
+	return nil
+}
+
+// UpdateUserPhone stores a phone number that has just been verified
+func (r *authRepository) UpdateUserPhone(userID, phone string, verifiedAt time.Time) error {
+	query := `
+		UPDATE users
+		SET phone = $2, phone_verified_at = $3, updated_at = $4
+		WHERE id = $1
+	`
+
+	result, err := r.db.Exec(query, userID, phone, verifiedAt, time.Now())
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			return errors.New(errors.PhoneExists)
+		}
+		return errors.Wrap(err, errors.DatabaseUpdateFailed)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, errors.DatabaseError)
+	}
+
+	if rowsAffected == 0 {
+		return errors.New(errors.AccountNotFound)
+	}
+
+	if err := r.cacheHelper.InvalidateUserCache(context.Background(), userID); err != nil {
+		return errors.Wrap(err, errors.CacheError)
+	}
+
+	return nil
+}
+
+// ==================== Login History ====================
+
+func (r *authRepository) CreateLoginEvent(event *LoginEvent) error {
+	id, _ := uuid.NewV7()
+	event.ID = id.String()
+	event.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO login_events (
+			id, user_id, identifier, method, success, failure_reason,
+			ip_address, user_agent, country_code, country, city, is_suspicious, created_at
+		)
+		VALUES ($1, NULLIF($2, '')::uuid, $3, $4, $5, NULLIF($6, ''),
+			NULLIF($7, '')::inet, $8, NULLIF($9, ''), NULLIF($10, ''), NULLIF($11, ''), $12, $13)
+	`
+
+	_, err := r.db.Exec(query,
+		event.ID, event.UserID, event.Identifier, event.Method, event.Success, event.FailureReason,
+		event.IPAddress, event.UserAgent, event.CountryCode, event.Country, event.City, event.Suspicious, event.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.DatabaseInsertFailed)
+	}
+
+	return nil
+}
+
+func (r *authRepository) GetLoginEvents(userID string, limit, offset int) ([]LoginEvent, int64, error) {
+	var total int64
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM login_events WHERE user_id = $1`, userID).Scan(&total); err != nil {
+		return nil, 0, errors.Wrap(err, errors.DatabaseQueryFailed)
+	}
+
+	query := `
+		SELECT id, user_id, identifier, method, success, failure_reason,
+			host(ip_address), user_agent, country_code, country, city, is_suspicious, created_at
+		FROM login_events
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Query(query, userID, limit, offset)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, errors.DatabaseQueryFailed)
+	}
+	defer rows.Close()
+
+	events := []LoginEvent{}
+	for rows.Next() {
+		var event LoginEvent
+		var failureReason, ipAddress, userAgent, countryCode, country, city sql.NullString
+		if err := rows.Scan(
+			&event.ID, &event.UserID, &event.Identifier, &event.Method, &event.Success, &failureReason,
+			&ipAddress, &userAgent, &countryCode, &country, &city, &event.Suspicious, &event.CreatedAt,
+		); err != nil {
+			return nil, 0, errors.Wrap(err, errors.DatabaseScanFailed)
+		}
+		event.FailureReason = failureReason.String
+		event.IPAddress = ipAddress.String
+		event.UserAgent = userAgent.String
+		event.CountryCode = countryCode.String
+		event.Country = country.String
+		event.City = city.String
+		events = append(events, event)
+	}
+
+	return events, total, nil
+}
+
+func (r *authRepository) CountSuccessfulLogins(userID string) (int64, error) {
+	var count int64
+	query := `SELECT COUNT(*) FROM login_events WHERE user_id = $1 AND success`
+	if err := r.db.QueryRow(query, userID).Scan(&count); err != nil {
+		return 0, errors.Wrap(err, errors.DatabaseQueryFailed)
+	}
+	return count, nil
+}
+
+func (r *authRepository) HasSuccessfulLoginFrom(userID, ipAddress, userAgent string) (bool, error) {
+	var exists bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM login_events
+			WHERE user_id = $1 AND success
+				AND ip_address IS NOT DISTINCT FROM NULLIF($2, '')::inet
+				AND user_agent IS NOT DISTINCT FROM $3
+		)
+	`
+	if err := r.db.QueryRow(query, userID, ipAddress, userAgent).Scan(&exists); err != nil {
+		return false, errors.Wrap(err, errors.DatabaseQueryFailed)
+	}
+	return exists, nil
+}
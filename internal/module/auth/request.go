@@ -2,12 +2,15 @@ package auth
 
 type RegisterRequest struct {
 	Name     string `json:"name" validate:"required,min=2,max=100"`
+	Username string `json:"username" validate:"omitempty,username"`
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6,max=100"`
 }
 
+// LoginRequest accepts either an email or a username
 type LoginRequest struct {
-	Email    string `json:"email" validate:"required,email"`
+	Email    string `json:"email" validate:"required_without=Username,omitempty,email"`
+	Username string `json:"username" validate:"required_without=Email,omitempty,username"`
 	Password string `json:"password" validate:"required,min=6,max=100"`
 }
 
@@ -15,6 +18,35 @@ type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
+// SwitchOrganizationRequest selects the active organization; an empty organization_id clears it
+type SwitchOrganizationRequest struct {
+	OrganizationID string `json:"organization_id" validate:"omitempty,uuid"`
+}
+
 type UpdateProfileRequest struct {
-	Name string `json:"name" validate:"required,min=2,max=100"`
+	Name     string `json:"name" validate:"required,min=2,max=100"`
+	Username string `json:"username" validate:"omitempty,username"`
+}
+
+type UsernameAvailabilityRequest struct {
+	Username string `query:"username" validate:"required"`
+}
+
+type LoginHistoryRequest struct {
+	Page     int `query:"page" validate:"omitempty,min=1"`
+	PageSize int `query:"page_size" validate:"omitempty,min=1,max=100"`
+}
+
+type PhoneRequest struct {
+	Phone string `json:"phone" validate:"required,e164"`
+}
+
+type VerifyPhoneRequest struct {
+	Phone string `json:"phone" validate:"required,e164"`
+	Code  string `json:"code" validate:"required,numeric,min=4,max=10"`
+}
+
+type OTPLoginRequest struct {
+	Phone string `json:"phone" validate:"required,e164"`
+	Code  string `json:"code" validate:"required,numeric,min=4,max=10"`
 }
@@ -2,8 +2,6 @@ package auth
 
 import (
 	"time"
-
-	"boilerplate-be/internal/shared/enum"
 )
 
 type AuthResponse struct {
@@ -21,11 +19,68 @@ type RefreshTokenResponse struct {
 	ExpiresIn    int64  `json:"expires_in"`
 }
 
+// UserResponse is the response for a user account; contact details are only shown to the user
+// themselves and to holders of users:read
 type UserResponse struct {
-	ID        string        `json:"id"`
-	Name      string        `json:"name"`
-	Email     string        `json:"email"`
-	Role      enum.UserRole `json:"role"`
-	CreatedAt time.Time     `json:"created_at"`
-	UpdatedAt time.Time     `json:"updated_at"`
+	ID              string     `json:"id" authz:"owner"`
+	Name            string     `json:"name"`
+	Username        string     `json:"username,omitempty"`
+	Email           string     `json:"email,omitempty" authz:"self,users:read"`
+	Phone           string     `json:"phone,omitempty" authz:"self,users:read"`
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at,omitempty" authz:"self,users:read"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+func ToUserResponse(user *User) UserResponse {
+	return UserResponse{
+		ID:              user.ID,
+		Name:            user.Name,
+		Username:        user.Username,
+		Email:           user.Email,
+		Phone:           user.Phone,
+		PhoneVerifiedAt: user.PhoneVerifiedAt,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+	}
+}
+
+type UsernameAvailabilityResponse struct {
+	Username  string `json:"username"`
+	Available bool   `json:"available"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+type LoginEventResponse struct {
+	ID            string    `json:"id"`
+	Method        string    `json:"method"`
+	Success       bool      `json:"success"`
+	FailureReason string    `json:"failure_reason,omitempty"`
+	IPAddress     string    `json:"ip_address"`
+	UserAgent     string    `json:"user_agent"`
+	CountryCode   string    `json:"country_code,omitempty"`
+	Country       string    `json:"country,omitempty"`
+	City          string    `json:"city,omitempty"`
+	Suspicious    bool      `json:"suspicious"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func ToLoginEventResponses(events []LoginEvent) []LoginEventResponse {
+	result := make([]LoginEventResponse, len(events))
+	for i, event := range events {
+		result[i] = LoginEventResponse{
+			ID:            event.ID,
+			Method:        event.Method,
+			Success:       event.Success,
+			FailureReason: event.FailureReason,
+			IPAddress:     event.IPAddress,
+			UserAgent:     event.UserAgent,
+			CountryCode:   event.CountryCode,
+			Country:       event.Country,
+			City:          event.City,
+			Suspicious:    event.Suspicious,
+			CreatedAt:     event.CreatedAt,
+		}
+	}
+	return result
 }
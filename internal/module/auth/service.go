@@ -1,14 +1,28 @@
 package auth
 
 import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
 	"boilerplate-be/internal/shared/errors"
+	"boilerplate-be/internal/shared/geoip"
 	"boilerplate-be/internal/shared/security"
+	"boilerplate-be/internal/shared/sms"
 )
 
 type authUseCase struct {
-	authRepo     AuthRepository
-	jwtManager   *security.JWTManager
-	tokenManager *security.TokenManager
+	authRepo       AuthRepository
+	jwtManager     *security.JWTManager
+	tokenManager   *security.TokenManager
+	loginNotifier  LoginNotifier
+	geoLocator     geoip.Locator
+	otpStore       OTPStore
+	smsSender      sms.SMSSender
+	authenticators []Authenticator
+	memberships    MembershipChecker
+	roleAssigner   RoleAssigner
 }
 
 func NewAuthUseCase(
@@ -17,18 +31,60 @@ func NewAuthUseCase(
 	tokenManager *security.TokenManager,
 ) *authUseCase {
 	return &authUseCase{
-		authRepo:     authRepo,
-		jwtManager:   jwtManager,
-		tokenManager: tokenManager,
+		authRepo:       authRepo,
+		jwtManager:     jwtManager,
+		tokenManager:   tokenManager,
+		loginNotifier:  NewLogLoginNotifier(),
+		authenticators: []Authenticator{NewPasswordAuthenticator(authRepo)},
 	}
 }
 
-func (u *authUseCase) Register(email, password, name string) (*User, string, string, error) {
+// SetAuthenticators replaces the chain of credential checks used by Login. They are tried
+// in order until one accepts the credentials or fails with something other than an unknown
+// account or wrong password.
+func (u *authUseCase) SetAuthenticators(authenticators ...Authenticator) {
+	u.authenticators = authenticators
+}
+
+// SetLoginNotifier replaces the notifier used for suspicious logins
+func (u *authUseCase) SetLoginNotifier(notifier LoginNotifier) {
+	u.loginNotifier = notifier
+}
+
+// SetGeoLocator enables geo lookup of login IP addresses
+func (u *authUseCase) SetGeoLocator(locator geoip.Locator) {
+	u.geoLocator = locator
+}
+
+// SetMembershipChecker enables issuing tokens scoped to an organization
+func (u *authUseCase) SetMembershipChecker(memberships MembershipChecker) {
+	u.memberships = memberships
+}
+
+// SetRoleAssigner grants DefaultRole to registered users; without it new accounts hold no role
+func (u *authUseCase) SetRoleAssigner(roleAssigner RoleAssigner) {
+	u.roleAssigner = roleAssigner
+}
+
+// SetPhoneVerification enables phone verification and SMS one-time code login
+func (u *authUseCase) SetPhoneVerification(otpStore OTPStore, smsSender sms.SMSSender) {
+	u.otpStore = otpStore
+	u.smsSender = smsSender
+}
+
+func (u *authUseCase) Register(email, password, name, username string) (*User, string, string, error) {
 	_, err := u.authRepo.GetUserByEmail(email)
 	if err == nil {
 		return nil, "", "", errors.New(errors.EmailExists)
 	}
 
+	username = normalizeUsername(username)
+	if username != "" {
+		if err := u.ensureUsernameAvailable(username, ""); err != nil {
+			return nil, "", "", err
+		}
+	}
+
 	hashedPassword, err := security.HashPassword(password)
 	if err != nil {
 		return nil, "", "", errors.Wrap(err, errors.PasswordHashFailed)
@@ -36,6 +92,7 @@ func (u *authUseCase) Register(email, password, name string) (*User, string, str
 
 	user := &User{
 		Name:     name,
+		Username: username,
 		Email:    email,
 		Password: hashedPassword,
 	}
@@ -44,34 +101,80 @@ func (u *authUseCase) Register(email, password, name string) (*User, string, str
 		return nil, "", "", err
 	}
 
-	accessToken, refreshToken, err := u.jwtManager.GenerateTokenPair(user.ID, user.Email, user.Role)
-	if err != nil {
-		return nil, "", "", errors.Wrap(err, errors.TokenGenerationFailed)
+	// Granted before the tokens are issued so embedded authorization already includes it. If the
+	// grant fails the account is removed, so signing up again is not blocked by its email.
+	if u.roleAssigner != nil {
+		if err := u.roleAssigner.AssignRolesByName(user.ID, []string{DefaultRole}); err != nil {
+			if deleteErr := u.authRepo.DeleteUser(user.ID); deleteErr != nil {
+				log.Printf("Failed to remove user %s after the default role grant failed: %v", user.ID, deleteErr)
+			}
+			return nil, "", "", err
+		}
 	}
 
-	refreshClaims, err := u.jwtManager.ValidateToken(refreshToken)
+	accessToken, refreshToken, err := u.issueTokens(user)
 	if err != nil {
-		return nil, "", "", errors.Wrap(err, errors.TokenGenerationFailed)
-	}
-
-	if err := u.tokenManager.StoreToken(user.ID, refreshClaims.ID); err != nil {
-		return nil, "", "", errors.Wrap(err, errors.CacheStoreFailed)
+		return nil, "", "", err
 	}
 
 	return user, accessToken, refreshToken, nil
 }
 
-func (u *authUseCase) Login(email, password string) (string, string, error) {
-	user, err := u.authRepo.GetUserByEmail(email)
+// Login authenticates by email or username through the configured authenticators
+func (u *authUseCase) Login(identifier, password string, meta LoginMetadata) (string, string, error) {
+	user, method, err := u.authenticate(identifier, password)
+	if err != nil {
+		reason := errors.InternalServerError.String()
+		if appErr, ok := errors.IsAppError(err); ok {
+			reason = appErr.Code.String()
+		}
+		u.recordLoginAttempt(user, identifier, method, meta, reason)
+		return "", "", err
+	}
+
+	accessToken, refreshToken, err := u.issueTokens(user)
 	if err != nil {
-		return "", "", errors.New(errors.AccountNotFound)
+		return "", "", err
 	}
 
-	if err := security.CheckPassword(user.Password, password); err != nil {
-		return "", "", errors.New(errors.PasswordMismatch)
+	u.recordLoginAttempt(user, identifier, method, meta, "")
+
+	return accessToken, refreshToken, nil
+}
+
+// authenticate runs the authenticator chain and returns the user and the name of the
+// authenticator that accepted (or last rejected) the credentials
+func (u *authUseCase) authenticate(identifier, password string) (*User, string, error) {
+	var matched *User
+	method := LoginMethodPassword
+	var failure error = errors.New(errors.AccountNotFound)
+
+	for _, authenticator := range u.authenticators {
+		user, err := authenticator.Authenticate(identifier, password)
+		if err == nil {
+			return user, authenticator.Name(), nil
+		}
+
+		if matched == nil {
+			matched = user
+		}
+
+		appErr, ok := errors.IsAppError(err)
+		if !ok || (appErr.Code != errors.AccountNotFound && appErr.Code != errors.PasswordMismatch) {
+			return matched, authenticator.Name(), err
+		}
+		if appErr.Code == errors.PasswordMismatch {
+			method = authenticator.Name()
+			failure = err
+		}
 	}
 
-	accessToken, refreshToken, err := u.jwtManager.GenerateTokenPair(user.ID, user.Email, user.Role)
+	return matched, method, failure
+}
+
+// issueTokens generates an access/refresh token pair and stores the refresh token
+func (u *authUseCase) issueTokens(user *User, opts ...security.TokenOption) (string, string, error) {
+	accessToken, refreshToken, err := u.jwtManager.GenerateTokenPair(user.ID, user.Email, opts...)
 	if err != nil {
 		return "", "", errors.Wrap(err, errors.TokenGenerationFailed)
 	}
@@ -111,7 +214,10 @@ func (u *authUseCase) RefreshToken(refreshTokenString string) (string, string, e
 		return "", "", errors.Wrap(err, errors.AccountNotFound)
 	}
 
-	newAccessToken, newRefreshToken, err := u.jwtManager.GenerateTokenPair(user.ID, user.Email, user.Role)
+	// The active organization carries over; membership is re-checked when the token is used
+	newAccessToken, newRefreshToken, err := u.jwtManager.GenerateTokenPair(
+		user.ID, user.Email, security.WithOrganization(claims.OrganizationID),
+	)
 	if err != nil {
 		return "", "", errors.Wrap(err, errors.TokenGenerationFailed)
 	}
@@ -132,6 +238,30 @@ func (u *authUseCase) RefreshToken(refreshTokenString string) (string, string, e
 	return newAccessToken, newRefreshToken, nil
 }
 
+// SwitchOrganization issues a token pair whose org_id claim selects the organization as the
+// active tenant. An empty organizationID issues tokens without a tenant.
+func (u *authUseCase) SwitchOrganization(userID, organizationID string) (string, string, error) {
+	if organizationID != "" {
+		if u.memberships == nil {
+			return "", "", errors.New(errors.ConfigurationError)
+		}
+		isMember, err := u.memberships.IsMember(organizationID, userID)
+		if err != nil {
+			return "", "", err
+		}
+		if !isMember {
+			return "", "", errors.New(errors.NotOrganizationMember)
+		}
+	}
+
+	user, err := u.authRepo.GetUserByID(userID)
+	if err != nil {
+		return "", "", err
+	}
+
+	return u.issueTokens(user, security.WithOrganization(organizationID))
+}
+
 func (u *authUseCase) Logout(userID, tokenID string) error {
 	if err := u.tokenManager.BlacklistToken(userID, tokenID); err != nil {
 		return errors.Wrap(err, errors.CacheError)
@@ -148,13 +278,24 @@ func (u *authUseCase) GetProfile(userID string) (*User, error) {
 	return u.authRepo.GetUserByID(userID)
 }
 
-func (u *authUseCase) UpdateProfile(userID, name string) (*User, error) {
+// UpdateProfile changes the user's name and, when given, their username
+func (u *authUseCase) UpdateProfile(userID, name, username string) (*User, error) {
 	user, err := u.authRepo.GetUserByID(userID)
 	if err != nil {
 		return nil, err
 	}
 
+	username = normalizeUsername(username)
+	if username != "" && !strings.EqualFold(username, user.Username) {
+		if err := u.ensureUsernameAvailable(username, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
 	user.Name = name
+	if username != "" {
+		user.Username = username
+	}
 
 	if err := u.authRepo.UpdateUser(user); err != nil {
 		return nil, err
@@ -162,3 +303,211 @@ func (u *authUseCase) UpdateProfile(userID, name string) (*User, error) {
 
 	return user, nil
 }
+
+// CheckUsernameAvailability reports whether username can be registered and, if not, why
+func (u *authUseCase) CheckUsernameAvailability(username string) (bool, string, error) {
+	username = normalizeUsername(username)
+	if reason := usernameUnavailableReason(username); reason != "" {
+		return false, reason, nil
+	}
+
+	_, err := u.authRepo.GetUserByUsername(username)
+	if err == nil {
+		return false, UsernameReasonTaken, nil
+	}
+	if appErr, ok := errors.IsAppError(err); ok && appErr.Code == errors.AccountNotFound {
+		return true, "", nil
+	}
+	return false, "", err
+}
+
+// ensureUsernameAvailable rejects malformed, reserved or taken usernames; ownerID may already hold it
+func (u *authUseCase) ensureUsernameAvailable(username, ownerID string) error {
+	if usernameUnavailableReason(username) != "" {
+		return errors.New(errors.InvalidUsername)
+	}
+
+	existing, err := u.authRepo.GetUserByUsername(username)
+	if err == nil {
+		if existing.ID != ownerID {
+			return errors.New(errors.UsernameExists)
+		}
+		return nil
+	}
+	if appErr, ok := errors.IsAppError(err); ok && appErr.Code == errors.AccountNotFound {
+		return nil
+	}
+	return err
+}
+
+// RequestPhoneVerification sends a one-time code to phone; the number is only saved once verified
+func (u *authUseCase) RequestPhoneVerification(userID, phone string) error {
+	if u.otpStore == nil || u.smsSender == nil {
+		return errors.New(errors.ServiceUnavailable)
+	}
+
+	existing, err := u.authRepo.GetUserByPhone(phone)
+	if err == nil && existing.ID != userID {
+		return errors.New(errors.PhoneExists)
+	}
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); !ok || appErr.Code != errors.AccountNotFound {
+			return err
+		}
+	}
+
+	return u.sendOTP(security.OTPPurposePhoneVerification, phoneVerificationSubject(userID, phone), phone)
+}
+
+// VerifyPhone checks the code sent by RequestPhoneVerification and saves the verified number
+func (u *authUseCase) VerifyPhone(userID, phone, code string) (*User, error) {
+	if u.otpStore == nil {
+		return nil, errors.New(errors.ServiceUnavailable)
+	}
+
+	if err := u.otpStore.Verify(security.OTPPurposePhoneVerification, phoneVerificationSubject(userID, phone), code); err != nil {
+		return nil, err
+	}
+
+	if err := u.authRepo.UpdateUserPhone(userID, phone, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return u.authRepo.GetUserByID(userID)
+}
+
+// RequestLoginOTP sends a login code to a verified phone number. Unknown numbers are
+// silently ignored, and so are repeated requests within the resend interval, so the endpoint
+// cannot be used to discover registered phones.
+func (u *authUseCase) RequestLoginOTP(phone string) error {
+	if u.otpStore == nil || u.smsSender == nil {
+		return errors.New(errors.ServiceUnavailable)
+	}
+
+	user, err := u.authRepo.GetUserByPhone(phone)
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok && appErr.Code == errors.AccountNotFound {
+			return nil
+		}
+		return err
+	}
+	if user.PhoneVerifiedAt == nil {
+		return nil
+	}
+
+	// A cooldown only exists for registered phones, so it must not show in the response
+	err = u.sendOTP(security.OTPPurposeLogin, phone, phone)
+	if appErr, ok := errors.IsAppError(err); ok && appErr.Code == errors.RateLimitExceeded {
+		return nil
+	}
+	return err
+}
+
+// LoginWithOTP exchanges a login code sent by RequestLoginOTP for a token pair
+func (u *authUseCase) LoginWithOTP(phone, code string, meta LoginMetadata) (string, string, error) {
+	if u.otpStore == nil {
+		return "", "", errors.New(errors.ServiceUnavailable)
+	}
+
+	user, err := u.authRepo.GetUserByPhone(phone)
+	if err != nil || user.PhoneVerifiedAt == nil {
+		u.recordLoginAttempt(nil, phone, LoginMethodSMSOTP, meta, errors.InvalidOTP.String())
+		return "", "", errors.New(errors.InvalidOTP)
+	}
+
+	if err := u.otpStore.Verify(security.OTPPurposeLogin, phone, code); err != nil {
+		reason := errors.InvalidOTP.String()
+		if appErr, ok := errors.IsAppError(err); ok {
+			reason = appErr.Code.String()
+		}
+		u.recordLoginAttempt(user, phone, LoginMethodSMSOTP, meta, reason)
+		return "", "", err
+	}
+
+	accessToken, refreshToken, err := u.issueTokens(user)
+	if err != nil {
+		return "", "", err
+	}
+
+	u.recordLoginAttempt(user, phone, LoginMethodSMSOTP, meta, "")
+
+	return accessToken, refreshToken, nil
+}
+
+func (u *authUseCase) sendOTP(purpose, subject, phone string) error {
+	code, err := u.otpStore.Generate(purpose, subject)
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("Your verification code is %s. Do not share this code with anyone.", code)
+	if err := u.smsSender.Send(phone, message); err != nil {
+		return errors.Wrap(err, errors.ExternalServiceError)
+	}
+
+	return nil
+}
+
+// phoneVerificationSubject binds a verification code to both the user and the number being verified
+func phoneVerificationSubject(userID, phone string) string {
+	return userID + ":" + phone
+}
+
+func (u *authUseCase) GetLoginHistory(userID string, page, pageSize int) ([]LoginEvent, int64, error) {
+	return u.authRepo.GetLoginEvents(userID, pageSize, (page-1)*pageSize)
+}
+
+// recordLoginAttempt stores a login event and notifies about successful logins from a
+// never-seen IP/user-agent pair. An empty failureReason marks the attempt as successful.
+// Recording is best effort: a failure here must never block the login itself.
+func (u *authUseCase) recordLoginAttempt(user *User, identifier, method string, meta LoginMetadata, failureReason string) {
+	event := &LoginEvent{
+		Identifier:    identifier,
+		Method:        method,
+		Success:       failureReason == "",
+		FailureReason: failureReason,
+		IPAddress:     meta.IPAddress,
+		UserAgent:     meta.UserAgent,
+	}
+	if user != nil {
+		event.UserID = user.ID
+	}
+
+	if u.geoLocator != nil && meta.IPAddress != "" {
+		if location, err := u.geoLocator.Lookup(meta.IPAddress); err == nil {
+			event.CountryCode = location.CountryCode
+			event.Country = location.Country
+			event.City = location.City
+		}
+	}
+
+	if event.Success {
+		event.Suspicious = u.isNewLoginSource(user.ID, meta)
+	}
+
+	if err := u.authRepo.CreateLoginEvent(event); err != nil {
+		log.Printf("Failed to record login event for %s: %v", identifier, err)
+	}
+
+	if event.Suspicious && u.loginNotifier != nil {
+		if err := u.loginNotifier.NotifySuspiciousLogin(user, event); err != nil {
+			log.Printf("Failed to send suspicious login notification for user %s: %v", user.ID, err)
+		}
+	}
+}
+
+// isNewLoginSource reports whether a user with previous successful logins has never
+// logged in from this IP/user-agent pair. A user's very first login is not flagged.
+func (u *authUseCase) isNewLoginSource(userID string, meta LoginMetadata) bool {
+	count, err := u.authRepo.CountSuccessfulLogins(userID)
+	if err != nil || count == 0 {
+		return false
+	}
+
+	seen, err := u.authRepo.HasSuccessfulLoginFrom(userID, meta.IPAddress, meta.UserAgent)
+	if err != nil {
+		return false
+	}
+
+	return !seen
+}
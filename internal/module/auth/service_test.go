@@ -1,16 +1,22 @@
 package auth
 
 import (
+	"strings"
 	"testing"
 	"time"
 
+	"boilerplate-be/internal/database"
 	apperrors "boilerplate-be/internal/shared/errors"
 	"boilerplate-be/internal/shared/security"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
 )
 
 // MockAuthRepository implements AuthRepository interface for testing
 type MockAuthRepository struct {
 	users         map[string]*User
+	loginEvents   []LoginEvent
 	createUserErr error
 	getUserErr    error
 	updateUserErr error
@@ -31,6 +37,9 @@ func (m *MockAuthRepository) CreateUser(user *User) error {
 		if u.Email == user.Email {
 			return apperrors.New(apperrors.EmailExists)
 		}
+		if user.Username != "" && strings.EqualFold(u.Username, user.Username) {
+			return apperrors.New(apperrors.UsernameExists)
+		}
 	}
 	m.users[user.ID] = user
 	return nil
@@ -48,6 +57,18 @@ func (m *MockAuthRepository) GetUserByEmail(email string) (*User, error) {
 	return nil, apperrors.New(apperrors.AccountNotFound)
 }
 
+func (m *MockAuthRepository) GetUserByUsername(username string) (*User, error) {
+	if m.getUserErr != nil {
+		return nil, m.getUserErr
+	}
+	for _, u := range m.users {
+		if u.Username != "" && strings.EqualFold(u.Username, username) {
+			return u, nil
+		}
+	}
+	return nil, apperrors.New(apperrors.AccountNotFound)
+}
+
 func (m *MockAuthRepository) GetUserByID(id string) (*User, error) {
 	if m.getUserErr != nil {
 		return nil, m.getUserErr
@@ -58,6 +79,26 @@ func (m *MockAuthRepository) GetUserByID(id string) (*User, error) {
 	return nil, apperrors.New(apperrors.AccountNotFound)
 }
 
+func (m *MockAuthRepository) GetUserByExternalID(source, externalID string) (*User, error) {
+	if m.getUserErr != nil {
+		return nil, m.getUserErr
+	}
+	for _, u := range m.users {
+		if u.AuthSource == source && u.ExternalID == externalID {
+			return u, nil
+		}
+	}
+	return nil, apperrors.New(apperrors.AccountNotFound)
+}
+
+func (m *MockAuthRepository) DeleteUser(id string) error {
+	if _, ok := m.users[id]; !ok {
+		return apperrors.New(apperrors.AccountNotFound)
+	}
+	delete(m.users, id)
+	return nil
+}
+
 func (m *MockAuthRepository) UpdateUser(user *User) error {
 	if m.updateUserErr != nil {
 		return m.updateUserErr
@@ -69,6 +110,74 @@ func (m *MockAuthRepository) UpdateUser(user *User) error {
 	return nil
 }
 
+func (m *MockAuthRepository) GetUserByPhone(phone string) (*User, error) {
+	for _, u := range m.users {
+		if u.Phone != "" && u.Phone == phone {
+			return u, nil
+		}
+	}
+	return nil, apperrors.New(apperrors.AccountNotFound)
+}
+
+func (m *MockAuthRepository) UpdateUserPhone(userID, phone string, verifiedAt time.Time) error {
+	user, ok := m.users[userID]
+	if !ok {
+		return apperrors.New(apperrors.AccountNotFound)
+	}
+	for _, u := range m.users {
+		if u.ID != userID && u.Phone == phone {
+			return apperrors.New(apperrors.PhoneExists)
+		}
+	}
+	user.Phone = phone
+	user.PhoneVerifiedAt = &verifiedAt
+	return nil
+}
+
+func (m *MockAuthRepository) CreateLoginEvent(event *LoginEvent) error {
+	event.ID = "event-" + event.Identifier
+	event.CreatedAt = time.Now()
+	m.loginEvents = append(m.loginEvents, *event)
+	return nil
+}
+
+func (m *MockAuthRepository) GetLoginEvents(userID string, limit, offset int) ([]LoginEvent, int64, error) {
+	var events []LoginEvent
+	for i := len(m.loginEvents) - 1; i >= 0; i-- {
+		if m.loginEvents[i].UserID == userID {
+			events = append(events, m.loginEvents[i])
+		}
+	}
+	total := int64(len(events))
+	if offset >= len(events) {
+		return []LoginEvent{}, total, nil
+	}
+	end := offset + limit
+	if end > len(events) {
+		end = len(events)
+	}
+	return events[offset:end], total, nil
+}
+
+func (m *MockAuthRepository) CountSuccessfulLogins(userID string) (int64, error) {
+	var count int64
+	for _, event := range m.loginEvents {
+		if event.UserID == userID && event.Success {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *MockAuthRepository) HasSuccessfulLoginFrom(userID, ipAddress, userAgent string) (bool, error) {
+	for _, event := range m.loginEvents {
+		if event.UserID == userID && event.Success && event.IPAddress == ipAddress && event.UserAgent == userAgent {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // MockTokenManager for testing
 type MockTokenManager struct {
 	tokens map[string]bool
@@ -174,7 +283,7 @@ func TestAuthService_Register(t *testing.T) {
 
 			// Verify JWT manager can generate tokens
 			if !tt.expectError {
-				accessToken, refreshToken, err := jwtManager.GenerateTokenPair(user.ID, user.Email, user.Role)
+				accessToken, refreshToken, err := jwtManager.GenerateTokenPair(user.ID, user.Email)
 				if err != nil {
 					t.Errorf("failed to generate tokens: %v", err)
 				}
@@ -263,11 +372,292 @@ func TestAuthService_Login(t *testing.T) {
 	}
 }
 
+// mockLoginNotifier records suspicious login notifications
+type mockLoginNotifier struct {
+	notified []LoginEvent
+}
+
+func (m *mockLoginNotifier) NotifySuspiciousLogin(user *User, event *LoginEvent) error {
+	m.notified = append(m.notified, *event)
+	return nil
+}
+
+func TestAuthService_RecordLoginAttempt(t *testing.T) {
+	user := &User{ID: "user-id", Email: "test@example.com"}
+	office := LoginMetadata{IPAddress: "203.0.113.10", UserAgent: "Mozilla/5.0"}
+	elsewhere := LoginMetadata{IPAddress: "198.51.100.7", UserAgent: "curl/8.0"}
+
+	mockRepo := NewMockAuthRepository()
+	notifier := &mockLoginNotifier{}
+	useCase := NewAuthUseCase(mockRepo, nil, nil)
+	useCase.SetLoginNotifier(notifier)
+
+	t.Run("first login is not suspicious", func(t *testing.T) {
+		useCase.recordLoginAttempt(user, user.Email, LoginMethodPassword, office, "")
+
+		last := mockRepo.loginEvents[len(mockRepo.loginEvents)-1]
+		if !last.Success || last.Suspicious {
+			t.Errorf("expected successful, non-suspicious event, got %+v", last)
+		}
+		if len(notifier.notified) != 0 {
+			t.Errorf("expected no notification, got %d", len(notifier.notified))
+		}
+	})
+
+	t.Run("login from known source is not suspicious", func(t *testing.T) {
+		useCase.recordLoginAttempt(user, user.Email, LoginMethodPassword, office, "")
+
+		if last := mockRepo.loginEvents[len(mockRepo.loginEvents)-1]; last.Suspicious {
+			t.Error("login from known IP/user-agent pair should not be suspicious")
+		}
+		if len(notifier.notified) != 0 {
+			t.Errorf("expected no notification, got %d", len(notifier.notified))
+		}
+	})
+
+	t.Run("failed login is recorded but never flagged", func(t *testing.T) {
+		useCase.recordLoginAttempt(user, user.Email, LoginMethodPassword, elsewhere, apperrors.PasswordMismatch.String())
+
+		last := mockRepo.loginEvents[len(mockRepo.loginEvents)-1]
+		if last.Success || last.Suspicious {
+			t.Errorf("expected failed, non-suspicious event, got %+v", last)
+		}
+		if last.FailureReason != "PASSWORD_MISMATCH" {
+			t.Errorf("expected failure reason PASSWORD_MISMATCH, got %s", last.FailureReason)
+		}
+	})
+
+	t.Run("login from never-seen source is suspicious and notified", func(t *testing.T) {
+		useCase.recordLoginAttempt(user, user.Email, LoginMethodPassword, elsewhere, "")
+
+		if last := mockRepo.loginEvents[len(mockRepo.loginEvents)-1]; !last.Suspicious {
+			t.Error("login from new IP/user-agent pair should be suspicious")
+		}
+		if len(notifier.notified) != 1 {
+			t.Fatalf("expected 1 notification, got %d", len(notifier.notified))
+		}
+		if notifier.notified[0].IPAddress != elsewhere.IPAddress {
+			t.Errorf("expected notification for %s, got %s", elsewhere.IPAddress, notifier.notified[0].IPAddress)
+		}
+	})
+
+	t.Run("unknown account failures have no user", func(t *testing.T) {
+		useCase.recordLoginAttempt(nil, "ghost@example.com", LoginMethodPassword, office, apperrors.AccountNotFound.String())
+
+		if last := mockRepo.loginEvents[len(mockRepo.loginEvents)-1]; last.UserID != "" {
+			t.Errorf("expected empty user ID, got %s", last.UserID)
+		}
+	})
+
+	t.Run("history is newest first and paginated", func(t *testing.T) {
+		events, total, err := useCase.GetLoginHistory(user.ID, 1, 2)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if total != 4 {
+			t.Errorf("expected 4 events for user, got %d", total)
+		}
+		if len(events) != 2 || !events[0].Suspicious {
+			t.Errorf("expected newest (suspicious) event first, got %+v", events)
+		}
+	})
+}
+
+func TestAuthService_Username(t *testing.T) {
+	mockRepo := NewMockAuthRepository()
+	mockRepo.users["user-1"] = &User{ID: "user-1", Email: "john@example.com", Username: "JohnDoe"}
+	mockRepo.users["user-2"] = &User{ID: "user-2", Email: "jane@example.com"}
+	useCase := NewAuthUseCase(mockRepo, nil, nil)
+
+	t.Run("availability", func(t *testing.T) {
+		tests := []struct {
+			username      string
+			wantAvailable bool
+			wantReason    string
+		}{
+			{"janedoe", true, ""},
+			{"johndoe", false, UsernameReasonTaken},
+			{"Admin", false, UsernameReasonReserved},
+			{"1bad", false, UsernameReasonInvalid},
+			{"x", false, UsernameReasonInvalid},
+		}
+
+		for _, tt := range tests {
+			available, reason, err := useCase.CheckUsernameAvailability(tt.username)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tt.username, err)
+			}
+			if available != tt.wantAvailable || reason != tt.wantReason {
+				t.Errorf("%s: got (%v, %q), want (%v, %q)", tt.username, available, reason, tt.wantAvailable, tt.wantReason)
+			}
+		}
+	})
+
+	t.Run("find user by email or username", func(t *testing.T) {
+		user, err := findUserByIdentifier(mockRepo, "john@example.com")
+		if err != nil || user.ID != "user-1" {
+			t.Errorf("expected user-1 by email, got %v, %v", user, err)
+		}
+		user, err = findUserByIdentifier(mockRepo, "JOHNDOE")
+		if err != nil || user.ID != "user-1" {
+			t.Errorf("expected user-1 by case-insensitive username, got %v, %v", user, err)
+		}
+		if _, err := findUserByIdentifier(mockRepo, "nobody"); err == nil {
+			t.Error("expected error for unknown username")
+		}
+	})
+
+	t.Run("update profile username", func(t *testing.T) {
+		if _, err := useCase.UpdateProfile("user-2", "Jane", "johndoe"); err == nil {
+			t.Error("expected error when taking another user's username")
+		} else if appErr, ok := apperrors.IsAppError(err); !ok || appErr.Code != apperrors.UsernameExists {
+			t.Errorf("expected UsernameExists, got %v", err)
+		}
+
+		if _, err := useCase.UpdateProfile("user-2", "Jane", "root"); err == nil {
+			t.Error("expected error for reserved username")
+		}
+
+		user, err := useCase.UpdateProfile("user-1", "John", "johnDOE")
+		if err != nil {
+			t.Fatalf("changing case of own username should succeed: %v", err)
+		}
+		if user.Username != "johnDOE" {
+			t.Errorf("expected username johnDOE, got %s", user.Username)
+		}
+	})
+}
+
+// mockOTPStore issues a fixed code and remembers it per purpose and subject; with cooldown set,
+// a new code cannot be requested while one is outstanding
+type mockOTPStore struct {
+	codes    map[string]string
+	cooldown bool
+}
+
+func (m *mockOTPStore) Generate(purpose, subject string) (string, error) {
+	if _, ok := m.codes[purpose+"|"+subject]; ok && m.cooldown {
+		return "", apperrors.New(apperrors.RateLimitExceeded)
+	}
+	m.codes[purpose+"|"+subject] = "123456"
+	return "123456", nil
+}
+
+func (m *mockOTPStore) Verify(purpose, subject, code string) error {
+	stored, ok := m.codes[purpose+"|"+subject]
+	if !ok {
+		return apperrors.New(apperrors.OTPExpired)
+	}
+	if stored != code {
+		return apperrors.New(apperrors.InvalidOTP)
+	}
+	delete(m.codes, purpose+"|"+subject)
+	return nil
+}
+
+// mockSMSSender records sent messages
+type mockSMSSender struct {
+	sent []string
+}
+
+func (m *mockSMSSender) Send(to, message string) error {
+	m.sent = append(m.sent, to)
+	return nil
+}
+
+func TestAuthService_PhoneVerification(t *testing.T) {
+	mockRepo := NewMockAuthRepository()
+	mockRepo.users["user-1"] = &User{ID: "user-1", Email: "john@example.com"}
+	mockRepo.users["user-2"] = &User{ID: "user-2", Email: "jane@example.com", Phone: "+6281111111111"}
+	otp := &mockOTPStore{codes: map[string]string{}}
+	sender := &mockSMSSender{}
+
+	useCase := NewAuthUseCase(mockRepo, nil, nil)
+	useCase.SetPhoneVerification(otp, sender)
+
+	t.Run("phone owned by another user is rejected", func(t *testing.T) {
+		err := useCase.RequestPhoneVerification("user-1", "+6281111111111")
+		if appErr, ok := apperrors.IsAppError(err); !ok || appErr.Code != apperrors.PhoneExists {
+			t.Errorf("expected PhoneExists, got %v", err)
+		}
+	})
+
+	t.Run("code is bound to the number it was sent to", func(t *testing.T) {
+		if err := useCase.RequestPhoneVerification("user-1", "+6282222222222"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(sender.sent) != 1 || sender.sent[0] != "+6282222222222" {
+			t.Fatalf("expected one SMS to +6282222222222, got %v", sender.sent)
+		}
+
+		if _, err := useCase.VerifyPhone("user-1", "+6283333333333", "123456"); err == nil {
+			t.Error("expected error when verifying a different number")
+		}
+		if mockRepo.users["user-1"].Phone != "" {
+			t.Error("phone should not be saved before verification")
+		}
+	})
+
+	t.Run("correct code saves the verified phone", func(t *testing.T) {
+		user, err := useCase.VerifyPhone("user-1", "+6282222222222", "123456")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if user.Phone != "+6282222222222" || user.PhoneVerifiedAt == nil {
+			t.Errorf("expected verified phone, got %+v", user)
+		}
+	})
+
+	t.Run("login code is not sent to unverified or unknown numbers", func(t *testing.T) {
+		sent := len(sender.sent)
+		if err := useCase.RequestLoginOTP("+6281111111111"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := useCase.RequestLoginOTP("+6289999999999"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(sender.sent) != sent {
+			t.Errorf("expected no SMS, got %v", sender.sent[sent:])
+		}
+	})
+
+	t.Run("repeated login code request looks like one for an unknown number", func(t *testing.T) {
+		otp.cooldown = true
+		defer func() { otp.cooldown = false }()
+
+		sent := len(sender.sent)
+		for i := 0; i < 2; i++ {
+			if err := useCase.RequestLoginOTP("+6282222222222"); err != nil {
+				t.Fatalf("request %d: unexpected error: %v", i+1, err)
+			}
+		}
+		if len(sender.sent) != sent+1 {
+			t.Errorf("expected one SMS, got %v", sender.sent[sent:])
+		}
+	})
+
+	t.Run("wrong login code is recorded as a failed attempt", func(t *testing.T) {
+		if err := useCase.RequestLoginOTP("+6282222222222"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		_, _, err := useCase.LoginWithOTP("+6282222222222", "000000", LoginMetadata{IPAddress: "203.0.113.10"})
+		if appErr, ok := apperrors.IsAppError(err); !ok || appErr.Code != apperrors.InvalidOTP {
+			t.Errorf("expected InvalidOTP, got %v", err)
+		}
+
+		last := mockRepo.loginEvents[len(mockRepo.loginEvents)-1]
+		if last.Success || last.Method != LoginMethodSMSOTP || last.UserID != "user-1" {
+			t.Errorf("expected failed sms_otp event for user-1, got %+v", last)
+		}
+	})
+}
+
 func TestJWTManager_TokenGeneration(t *testing.T) {
 	jwtManager := security.NewJWTManager("test-secret-key", 24*time.Hour)
 
 	t.Run("generate and validate token pair", func(t *testing.T) {
-		accessToken, refreshToken, err := jwtManager.GenerateTokenPair("user-123", "test@example.com", "user")
+		accessToken, refreshToken, err := jwtManager.GenerateTokenPair("user-123", "test@example.com")
 		if err != nil {
 			t.Fatalf("failed to generate token pair: %v", err)
 		}
@@ -348,7 +738,6 @@ func TestMockRepository(t *testing.T) {
 			Email:    "test@example.com",
 			Name:     "Test User",
 			Password: "hashed-password",
-			Role:     "user",
 		}
 		err := repo.CreateUser(user)
 		if err != nil {
@@ -408,16 +797,104 @@ func BenchmarkPasswordHashing(b *testing.B) {
 func BenchmarkTokenGeneration(b *testing.B) {
 	jwtManager := security.NewJWTManager("test-secret", 24*time.Hour)
 	for i := 0; i < b.N; i++ {
-		_, _, _ = jwtManager.GenerateTokenPair("user-123", "test@example.com", "user")
+		_, _, _ = jwtManager.GenerateTokenPair("user-123", "test@example.com")
 	}
 }
 
 func BenchmarkTokenValidation(b *testing.B) {
 	jwtManager := security.NewJWTManager("test-secret", 24*time.Hour)
-	token, _, _ := jwtManager.GenerateTokenPair("user-123", "test@example.com", "user")
+	token, _, _ := jwtManager.GenerateTokenPair("user-123", "test@example.com")
 	
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		_, _ = jwtManager.ValidateToken(token)
 	}
 }
+
+// staticMemberships implements MembershipChecker from a set of "organization/user" pairs
+type staticMemberships map[string]bool
+
+func (m staticMemberships) IsMember(organizationID, userID string) (bool, error) {
+	return m[organizationID+"/"+userID], nil
+}
+
+func TestAuthService_SwitchOrganization(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := &database.RedisClient{Client: redis.NewClient(&redis.Options{Addr: mr.Addr()})}
+	jwtManager := security.NewJWTManager("test-secret", time.Hour)
+
+	mockRepo := NewMockAuthRepository()
+	mockRepo.users["user-1"] = &User{ID: "user-1", Email: "john@example.com"}
+	useCase := NewAuthUseCase(mockRepo, jwtManager, security.NewTokenManager(redisClient))
+
+	_, _, err := useCase.SwitchOrganization("user-1", "org-1")
+	if appErr, ok := apperrors.IsAppError(err); !ok || appErr.Code != apperrors.ConfigurationError {
+		t.Fatalf("expected ConfigurationError without a membership checker, got %v", err)
+	}
+
+	useCase.SetMembershipChecker(staticMemberships{"org-1/user-1": true})
+
+	_, _, err = useCase.SwitchOrganization("user-1", "org-2")
+	if appErr, ok := apperrors.IsAppError(err); !ok || appErr.Code != apperrors.NotOrganizationMember {
+		t.Fatalf("expected NotOrganizationMember, got %v", err)
+	}
+
+	accessToken, refreshToken, err := useCase.SwitchOrganization("user-1", "org-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	claims, _ := jwtManager.ValidateToken(accessToken)
+	if claims.OrganizationID != "org-1" {
+		t.Errorf("access token org_id = %q, want org-1", claims.OrganizationID)
+	}
+
+	// Refreshing keeps the active organization
+	accessToken, _, err = useCase.RefreshToken(refreshToken)
+	if err != nil {
+		t.Fatalf("unexpected refresh error: %v", err)
+	}
+	claims, _ = jwtManager.ValidateToken(accessToken)
+	if claims.OrganizationID != "org-1" {
+		t.Errorf("refreshed token org_id = %q, want org-1", claims.OrganizationID)
+	}
+
+	// An empty organization clears the tenant
+	accessToken, _, _ = useCase.SwitchOrganization("user-1", "")
+	claims, _ = jwtManager.ValidateToken(accessToken)
+	if claims.OrganizationID != "" {
+		t.Errorf("expected no org_id, got %q", claims.OrganizationID)
+	}
+}
+
+func TestAuthService_RegisterGrantsDefaultRole(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := &database.RedisClient{Client: redis.NewClient(&redis.Options{Addr: mr.Addr()})}
+
+	mockRepo := NewMockAuthRepository()
+	roles := &mockRoleAssigner{assigned: map[string][]string{}}
+	useCase := NewAuthUseCase(mockRepo, security.NewJWTManager("test-secret", time.Hour), security.NewTokenManager(redisClient))
+	useCase.SetRoleAssigner(roles)
+
+	user, _, _, err := useCase.Register("jane@example.com", "password123", "Jane", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := roles.assigned[user.ID]; len(got) != 1 || got[0] != DefaultRole {
+		t.Errorf("expected %s to be granted, got %v", DefaultRole, got)
+	}
+
+	// A failed grant leaves no account behind, so the email can sign up again
+	roles.err = apperrors.New(apperrors.DatabaseInsertFailed)
+	_, _, _, err = useCase.Register("john@example.com", "password123", "John", "")
+	if appErr, ok := apperrors.IsAppError(err); !ok || appErr.Code != apperrors.DatabaseInsertFailed {
+		t.Errorf("expected DatabaseInsertFailed, got %v", err)
+	}
+	if _, err := mockRepo.GetUserByEmail("john@example.com"); err == nil {
+		t.Error("expected the account to be deleted after the failed grant")
+	}
+
+	roles.err = nil
+	if _, _, _, err := useCase.Register("john@example.com", "password123", "John", ""); err != nil {
+		t.Errorf("expected registering again to succeed, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"strings"
+
+	"boilerplate-be/internal/shared/validator"
+)
+
+// Reasons reported by the username availability check
+const (
+	UsernameReasonInvalid  = "invalid_format"
+	UsernameReasonReserved = "reserved"
+	UsernameReasonTaken    = "taken"
+)
+
+// reservedUsernames cannot be registered because they could be mistaken for
+// the system, staff or routes. Matching is case-insensitive.
+var reservedUsernames = map[string]struct{}{
+	"admin":         {},
+	"administrator": {},
+	"root":          {},
+	"system":        {},
+	"sysadmin":      {},
+	"superadmin":    {},
+	"super_admin":   {},
+	"moderator":     {},
+	"support":       {},
+	"help":          {},
+	"security":      {},
+	"staff":         {},
+	"official":      {},
+	"api":           {},
+	"auth":          {},
+	"login":         {},
+	"logout":        {},
+	"register":      {},
+	"signup":        {},
+	"profile":       {},
+	"settings":      {},
+	"account":       {},
+	"me":            {},
+	"null":          {},
+	"undefined":     {},
+	"anonymous":     {},
+	"everyone":      {},
+	"noreply":       {},
+	"no-reply":      {},
+	"postmaster":    {},
+	"webmaster":     {},
+}
+
+// IsReservedUsername reports whether username is on the reserved list
+func IsReservedUsername(username string) bool {
+	_, reserved := reservedUsernames[strings.ToLower(username)]
+	return reserved
+}
+
+// normalizeUsername trims surrounding whitespace; comparison is case-insensitive in the database
+func normalizeUsername(username string) string {
+	return strings.TrimSpace(username)
+}
+
+// usernameUnavailableReason returns why a username cannot be used, or "" if its format is acceptable and it is not reserved
+func usernameUnavailableReason(username string) string {
+	if !validator.IsValidUsername(username) {
+		return UsernameReasonInvalid
+	}
+	if IsReservedUsername(username) {
+		return UsernameReasonReserved
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package organization
+
+import "boilerplate-be/internal/module/rbac"
+
+// OrganizationRepository defines the data access layer for organizations. Every query on
+// tenant-owned rows is filtered by organization ID.
+type OrganizationRepository interface {
+	// Create inserts the organization, adds the owner as a member and grants them the
+	// org_admin role inside it, atomically
+	Create(org *Organization, ownerID string) error
+	GetByID(id string) (*Organization, error)
+	GetForUser(userID string) ([]Organization, error)
+	Update(org *Organization) error
+	Delete(id string) error
+
+	// Membership operations
+	IsMember(organizationID, userID string) (bool, error)
+	GetMembers(organizationID string) ([]Member, error)
+	AddMember(organizationID, userID string) error
+	// RemoveMember also drops the user's role assignments inside the organization
+	RemoveMember(organizationID, userID string) error
+
+	// WithActor returns a repository whose changes to role assignments are recorded as made by the actor
+	WithActor(actor rbac.ChangeActor) OrganizationRepository
+}
+
+// OrganizationUseCase defines the business logic for organizations
+type OrganizationUseCase interface {
+	CreateOrganization(ownerID, name, slug string) (*Organization, error)
+	GetOrganizations(userID string) ([]Organization, error)
+	GetOrganization(id string) (*Organization, error)
+	UpdateOrganization(id, name string) (*Organization, error)
+	DeleteOrganization(id string) error
+
+	// Membership operations
+	IsMember(organizationID, userID string) (bool, error)
+	GetMembers(organizationID string) ([]Member, error)
+	AddMember(organizationID, userID string) error
+	RemoveMember(organizationID, userID string) error
+
+	// Tenant-scoped role operations; the user must be a member
+	GetMemberRoles(organizationID, userID string) ([]rbac.Role, error)
+	// AssignMemberRole refuses roles granting permissions the assigner lacks in the organization
+	AssignMemberRole(assignerID, organizationID, userID, roleID string) error
+	RemoveMemberRole(organizationID, userID, roleID string) error
+
+	// WithActor returns a use case whose changes to role assignments are recorded as made by the actor
+	WithActor(actor rbac.ChangeActor) OrganizationUseCase
+}
+
+// TenantRoleManager manages role assignments inside an organization, e.g. rbac.RBACUseCase
+type TenantRoleManager interface {
+	GetRolePermissions(roleID string) ([]rbac.Permission, error)
+	GetRoleAncestors(roleID string) ([]rbac.Role, error)
+	GetUserPermissionsInTenant(userID, organizationID string) ([]rbac.Permission, error)
+	GetUserDenyRules(userID, organizationID string) ([]rbac.DenyRule, error)
+	GetUserRolesInTenant(userID, organizationID string) ([]rbac.Role, error)
+	AssignRoleToUserInTenant(userID, roleID, organizationID string) error
+	RemoveRoleFromUserInTenant(userID, roleID, organizationID string) error
+	WithActor(actor rbac.ChangeActor) rbac.RBACUseCase
+}
@@ -0,0 +1,388 @@
+package organization
+
+import (
+	"boilerplate-be/internal/module/rbac"
+	"boilerplate-be/internal/shared/errors"
+	"boilerplate-be/internal/shared/response"
+	"boilerplate-be/internal/shared/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type OrganizationHandler struct {
+	orgUseCase OrganizationUseCase
+}
+
+// NewOrganizationHandler creates a new organization handler
+func NewOrganizationHandler(orgUseCase OrganizationUseCase) *OrganizationHandler {
+	return &OrganizationHandler{
+		orgUseCase: orgUseCase,
+	}
+}
+
+// useCase records the role assignment changes made through it as made by the caller, in the
+// current request
+func (h *OrganizationHandler) useCase(c *fiber.Ctx) OrganizationUseCase {
+	return h.orgUseCase.WithActor(rbac.RequestActor(c))
+}
+
+// ==================== Organization Endpoints ====================
+
+// GetMyOrganizations godoc
+// @Summary      List my organizations
+// @Description  Returns the organizations the current user is a member of
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  docs.SuccessResponse{data=[]docs.OrganizationResponse}
+// @Failure      401  {object}  docs.ErrorResponse
+// @Router       /organizations [get]
+func (h *OrganizationHandler) GetMyOrganizations(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	orgs, err := h.orgUseCase.GetOrganizations(userID)
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(response.CreateErrorResponse(c, errors.New(errors.InternalServerError)))
+	}
+
+	return c.JSON(response.CreateSuccessResponse(
+		c, "Daftar organisasi berhasil diambil", "Organizations retrieved successfully", ToOrganizationResponses(orgs),
+	))
+}
+
+// CreateOrganization godoc
+// @Summary      Create an organization
+// @Description  Creates an organization; the creator becomes its first member with the org_admin role inside it
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      docs.CreateOrganizationRequest  true  "Organization data"
+// @Success      201   {object}  docs.SuccessResponse{data=docs.OrganizationResponse}
+// @Failure      400   {object}  docs.ErrorResponse
+// @Failure      401   {object}  docs.ErrorResponse
+// @Failure      409   {object}  docs.ErrorResponse
+// @Router       /organizations [post]
+func (h *OrganizationHandler) CreateOrganization(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var req CreateOrganizationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.CreateErrorResponse(c, errors.New(errors.InvalidRequestBody)))
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		validationErrors := validator.FormatValidationErrorForResponseBilingual(err)
+		appErr := errors.NewWithDetails(errors.ValidationFailed, validationErrors)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	org, err := h.useCase(c).CreateOrganization(userID, req.Name, req.Slug)
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(response.CreateErrorResponse(c, errors.New(errors.InternalServerError)))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.CreateSuccessResponse(
+		c, "Organisasi berhasil dibuat", "Organization created successfully", ToOrganizationResponse(org), fiber.StatusCreated,
+	))
+}
+
+// GetOrganization godoc
+// @Summary      Get organization details
+// @Description  Returns an organization (requires orgs:read inside it)
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        orgId  path      string  true  "Organization ID"
+// @Success      200    {object}  docs.SuccessResponse{data=docs.OrganizationResponse}
+// @Failure      401    {object}  docs.ErrorResponse
+// @Failure      403    {object}  docs.ErrorResponse
+// @Failure      404    {object}  docs.ErrorResponse
+// @Router       /organizations/{orgId} [get]
+func (h *OrganizationHandler) GetOrganization(c *fiber.Ctx) error {
+	org, err := h.orgUseCase.GetOrganization(c.Params("orgId"))
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(response.CreateErrorResponse(c, errors.New(errors.InternalServerError)))
+	}
+
+	return c.JSON(response.CreateSuccessResponse(
+		c, "Organisasi berhasil diambil", "Organization retrieved successfully", ToOrganizationResponse(org),
+	))
+}
+
+// UpdateOrganization godoc
+// @Summary      Rename an organization
+// @Description  Updates an organization's name; the slug is fixed (requires orgs:update inside it)
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        orgId  path      string                          true  "Organization ID"
+// @Param        body   body      docs.UpdateOrganizationRequest  true  "Organization data"
+// @Success      200    {object}  docs.SuccessResponse{data=docs.OrganizationResponse}
+// @Failure      400    {object}  docs.ErrorResponse
+// @Failure      401    {object}  docs.ErrorResponse
+// @Failure      403    {object}  docs.ErrorResponse
+// @Failure      404    {object}  docs.ErrorResponse
+// @Router       /organizations/{orgId} [put]
+func (h *OrganizationHandler) UpdateOrganization(c *fiber.Ctx) error {
+	var req UpdateOrganizationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.CreateErrorResponse(c, errors.New(errors.InvalidRequestBody)))
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		validationErrors := validator.FormatValidationErrorForResponseBilingual(err)
+		appErr := errors.NewWithDetails(errors.ValidationFailed, validationErrors)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	org, err := h.useCase(c).UpdateOrganization(c.Params("orgId"), req.Name)
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(response.CreateErrorResponse(c, errors.New(errors.InternalServerError)))
+	}
+
+	return c.JSON(response.CreateSuccessResponse(
+		c, "Organisasi berhasil diperbarui", "Organization updated successfully", ToOrganizationResponse(org),
+	))
+}
+
+// DeleteOrganization godoc
+// @Summary      Delete an organization
+// @Description  Deletes an organization with its memberships and tenant-scoped role assignments (requires orgs:delete inside it)
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        orgId  path      string  true  "Organization ID"
+// @Success      200    {object}  docs.SuccessResponse
+// @Failure      401    {object}  docs.ErrorResponse
+// @Failure      403    {object}  docs.ErrorResponse
+// @Failure      404    {object}  docs.ErrorResponse
+// @Router       /organizations/{orgId} [delete]
+func (h *OrganizationHandler) DeleteOrganization(c *fiber.Ctx) error {
+	if err := h.useCase(c).DeleteOrganization(c.Params("orgId")); err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(response.CreateErrorResponse(c, errors.New(errors.InternalServerError)))
+	}
+
+	return c.JSON(response.CreateSuccessResponse(
+		c, "Organisasi berhasil dihapus", "Organization deleted successfully", nil,
+	))
+}
+
+// ==================== Member Endpoints ====================
+
+// GetMembers godoc
+// @Summary      List organization members
+// @Description  Returns the members of an organization (requires orgs:members:read inside it)
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        orgId  path      string  true  "Organization ID"
+// @Success      200    {object}  docs.SuccessResponse{data=[]docs.MemberResponse}
+// @Failure      401    {object}  docs.ErrorResponse
+// @Failure      403    {object}  docs.ErrorResponse
+// @Router       /organizations/{orgId}/members [get]
+func (h *OrganizationHandler) GetMembers(c *fiber.Ctx) error {
+	members, err := h.orgUseCase.GetMembers(c.Params("orgId"))
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(response.CreateErrorResponse(c, errors.New(errors.InternalServerError)))
+	}
+
+	return c.JSON(response.CreateSuccessResponse(
+		c, "Daftar anggota berhasil diambil", "Members retrieved successfully", ToMemberResponses(members),
+	))
+}
+
+// AddMember godoc
+// @Summary      Add organization member
+// @Description  Adds a user to an organization (requires orgs:members:write inside it)
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        orgId  path      string                 true  "Organization ID"
+// @Param        body   body      docs.AddMemberRequest  true  "Member data"
+// @Success      201    {object}  docs.SuccessResponse
+// @Failure      400    {object}  docs.ErrorResponse
+// @Failure      401    {object}  docs.ErrorResponse
+// @Failure      403    {object}  docs.ErrorResponse
+// @Failure      404    {object}  docs.ErrorResponse
+// @Router       /organizations/{orgId}/members [post]
+func (h *OrganizationHandler) AddMember(c *fiber.Ctx) error {
+	var req AddMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.CreateErrorResponse(c, errors.New(errors.InvalidRequestBody)))
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		validationErrors := validator.FormatValidationErrorForResponseBilingual(err)
+		appErr := errors.NewWithDetails(errors.ValidationFailed, validationErrors)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	if err := h.useCase(c).AddMember(c.Params("orgId"), req.UserID); err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(response.CreateErrorResponse(c, errors.New(errors.InternalServerError)))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.CreateSuccessResponse(
+		c, "Anggota berhasil ditambahkan", "Member added successfully", nil, fiber.StatusCreated,
+	))
+}
+
+// RemoveMember godoc
+// @Summary      Remove organization member
+// @Description  Removes a user and their role assignments from an organization (requires orgs:members:write inside it)
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        orgId   path      string  true  "Organization ID"
+// @Param        userId  path      string  true  "User ID"
+// @Success      200     {object}  docs.SuccessResponse
+// @Failure      401     {object}  docs.ErrorResponse
+// @Failure      403     {object}  docs.ErrorResponse
+// @Router       /organizations/{orgId}/members/{userId} [delete]
+func (h *OrganizationHandler) RemoveMember(c *fiber.Ctx) error {
+	if err := h.useCase(c).RemoveMember(c.Params("orgId"), c.Params("userId")); err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(response.CreateErrorResponse(c, errors.New(errors.InternalServerError)))
+	}
+
+	return c.JSON(response.CreateSuccessResponse(
+		c, "Anggota berhasil dihapus", "Member removed successfully", nil,
+	))
+}
+
+// ==================== Member Role Endpoints ====================
+
+// GetMemberRoles godoc
+// @Summary      Get member roles
+// @Description  Returns the roles a member holds inside the organization, excluding global roles (requires orgs:members:read inside it)
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        orgId   path      string  true  "Organization ID"
+// @Param        userId  path      string  true  "User ID"
+// @Success      200     {object}  docs.SuccessResponse{data=docs.MemberRolesResponse}
+// @Failure      401     {object}  docs.ErrorResponse
+// @Failure      403     {object}  docs.ErrorResponse
+// @Router       /organizations/{orgId}/members/{userId}/roles [get]
+func (h *OrganizationHandler) GetMemberRoles(c *fiber.Ctx) error {
+	organizationID := c.Params("orgId")
+	userID := c.Params("userId")
+
+	roles, err := h.orgUseCase.GetMemberRoles(organizationID, userID)
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(response.CreateErrorResponse(c, errors.New(errors.InternalServerError)))
+	}
+
+	resp := MemberRolesResponse{
+		OrganizationID: organizationID,
+		UserID:         userID,
+		Roles:          rbac.ToRoleResponses(roles),
+	}
+
+	return c.JSON(response.CreateSuccessResponse(
+		c, "Role anggota berhasil diambil", "Member roles retrieved successfully", resp,
+	))
+}
+
+// AssignMemberRole godoc
+// @Summary      Assign role to member
+// @Description  Assigns a role to a member inside the organization only. The caller must already hold every permission the role grants there (requires orgs:roles:assign inside it)
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        orgId   path      string                        true  "Organization ID"
+// @Param        userId  path      string                        true  "User ID"
+// @Param        body    body      docs.AssignMemberRoleRequest  true  "Role assignment"
+// @Success      201     {object}  docs.SuccessResponse
+// @Failure      400     {object}  docs.ErrorResponse
+// @Failure      401     {object}  docs.ErrorResponse
+// @Failure      403     {object}  docs.ErrorResponse
+// @Failure      404     {object}  docs.ErrorResponse
+// @Router       /organizations/{orgId}/members/{userId}/roles [post]
+func (h *OrganizationHandler) AssignMemberRole(c *fiber.Ctx) error {
+	assignerID := c.Locals("user_id").(string)
+
+	var req AssignMemberRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.CreateErrorResponse(c, errors.New(errors.InvalidRequestBody)))
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		validationErrors := validator.FormatValidationErrorForResponseBilingual(err)
+		appErr := errors.NewWithDetails(errors.ValidationFailed, validationErrors)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	if err := h.useCase(c).AssignMemberRole(assignerID, c.Params("orgId"), c.Params("userId"), req.RoleID); err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(response.CreateErrorResponse(c, errors.New(errors.InternalServerError)))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.CreateSuccessResponse(
+		c, "Role berhasil ditambahkan ke anggota", "Role assigned to member successfully", nil, fiber.StatusCreated,
+	))
+}
+
+// RemoveMemberRole godoc
+// @Summary      Remove role from member
+// @Description  Removes a role assigned to a member inside the organization; global roles are untouched (requires orgs:roles:assign inside it)
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        orgId   path      string  true  "Organization ID"
+// @Param        userId  path      string  true  "User ID"
+// @Param        roleId  path      string  true  "Role ID"
+// @Success      200     {object}  docs.SuccessResponse
+// @Failure      401     {object}  docs.ErrorResponse
+// @Failure      403     {object}  docs.ErrorResponse
+// @Router       /organizations/{orgId}/members/{userId}/roles/{roleId} [delete]
+func (h *OrganizationHandler) RemoveMemberRole(c *fiber.Ctx) error {
+	if err := h.useCase(c).RemoveMemberRole(c.Params("orgId"), c.Params("userId"), c.Params("roleId")); err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(response.CreateErrorResponse(c, errors.New(errors.InternalServerError)))
+	}
+
+	return c.JSON(response.CreateSuccessResponse(
+		c, "Role berhasil dihapus dari anggota", "Role removed from member successfully", nil,
+	))
+}
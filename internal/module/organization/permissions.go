@@ -0,0 +1,16 @@
+package organization
+
+import "boilerplate-be/internal/module/rbac"
+
+// RegisterPermissions declares the tenant administration permissions, granted inside a tenant
+// through org_admin
+func RegisterPermissions(registry *rbac.PermissionRegistry) {
+	registry.Declare("organization",
+		rbac.PermissionSpec{Name: "orgs:read", Description: "View organization details"},
+		rbac.PermissionSpec{Name: "orgs:update", Description: "Rename an organization"},
+		rbac.PermissionSpec{Name: "orgs:delete", Description: "Delete an organization"},
+		rbac.PermissionSpec{Name: "orgs:members:read", Description: "List organization members"},
+		rbac.PermissionSpec{Name: "orgs:members:write", Description: "Add and remove organization members"},
+		rbac.PermissionSpec{Name: "orgs:roles:assign", Description: "Assign roles within an organization"},
+	)
+}
@@ -0,0 +1,287 @@
+package organization
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"boilerplate-be/internal/database"
+	"boilerplate-be/internal/module/rbac"
+	"boilerplate-be/internal/shared/errors"
+	"boilerplate-be/internal/shared/security"
+	"boilerplate-be/internal/shared/utils"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type organizationRepository struct {
+	db          *sql.DB
+	txManager   *database.TxManager
+	cacheHelper *utils.CacheHelper
+	versions    *security.PermissionVersions
+	// actor is recorded with every change to role assignments; zero when unknown
+	actor rbac.ChangeActor
+}
+
+// NewOrganizationRepository creates a new organization repository; removing members bumps
+// their permission version since they lose the roles held in the organization
+func NewOrganizationRepository(db *sql.DB, cacheHelper *utils.CacheHelper, versions *security.PermissionVersions) OrganizationRepository {
+	return &organizationRepository{
+		db:          db,
+		txManager:   database.NewTxManager(db),
+		cacheHelper: cacheHelper,
+		versions:    versions,
+	}
+}
+
+// WithActor returns a copy of the repository recording its changes to role assignments as made
+// by the actor
+func (r *organizationRepository) WithActor(actor rbac.ChangeActor) OrganizationRepository {
+	copied := *r
+	copied.actor = actor
+	return &copied
+}
+
+// organizationColumns is the column list read by scanOrganization; queries alias organizations as o
+const organizationColumns = `o.id, o.name, o.slug, o.created_by, o.created_at, o.updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanOrganization reads a row selected with organizationColumns
+func scanOrganization(row rowScanner) (Organization, error) {
+	var org Organization
+	var createdBy sql.NullString
+	err := row.Scan(&org.ID, &org.Name, &org.Slug, &createdBy, &org.CreatedAt, &org.UpdatedAt)
+	org.CreatedBy = createdBy.String
+	return org, err
+}
+
+// ==================== Organization Operations ====================
+
+func (r *organizationRepository) Create(org *Organization, ownerID string) error {
+	org.ID = uuid.New().String()
+	org.CreatedBy = ownerID
+	org.CreatedAt = time.Now()
+	org.UpdatedAt = org.CreatedAt
+
+	return r.txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		exec := database.GetExecutor(ctx, r.db)
+		if err := r.actor.Apply(ctx, exec); err != nil {
+			return err
+		}
+
+		query := `INSERT INTO organizations (id, name, slug, created_by, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6)`
+		if _, err := exec.ExecContext(ctx, query, org.ID, org.Name, org.Slug, ownerID, org.CreatedAt, org.UpdatedAt); err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+				return errors.New(errors.Conflict)
+			}
+			return errors.Wrap(err, errors.DatabaseInsertFailed)
+		}
+
+		query = `INSERT INTO organization_members (organization_id, user_id, created_at) VALUES ($1, $2, $3)`
+		if _, err := exec.ExecContext(ctx, query, org.ID, ownerID, org.CreatedAt); err != nil {
+			return errors.Wrap(err, errors.DatabaseInsertFailed)
+		}
+
+		query = `
+			INSERT INTO user_roles (user_id, role_id, organization_id, created_at)
+			SELECT $1, id, $2, $3 FROM roles WHERE name = $4
+		`
+		result, err := exec.ExecContext(ctx, query, ownerID, org.ID, org.CreatedAt, rbac.RoleOrgAdmin)
+		if err != nil {
+			return errors.Wrap(err, errors.DatabaseInsertFailed)
+		}
+		if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+			// The org_admin role is seeded by migration; without it the owner could not manage the tenant
+			return errors.New(errors.ConfigurationError)
+		}
+
+		return nil
+	})
+}
+
+func (r *organizationRepository) GetByID(id string) (*Organization, error) {
+	query := `SELECT ` + organizationColumns + ` FROM organizations o WHERE o.id = $1`
+	org, err := scanOrganization(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New(errors.ResourceNotFound)
+		}
+		return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
+	}
+	return &org, nil
+}
+
+func (r *organizationRepository) GetForUser(userID string) ([]Organization, error) {
+	query := `
+		SELECT ` + organizationColumns + `
+		FROM organizations o
+		INNER JOIN organization_members om ON om.organization_id = o.id
+		WHERE om.user_id = $1
+		ORDER BY o.name
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
+	}
+	defer rows.Close()
+
+	var orgs []Organization
+	for rows.Next() {
+		org, err := scanOrganization(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.DatabaseScanFailed)
+		}
+		orgs = append(orgs, org)
+	}
+
+	return orgs, nil
+}
+
+func (r *organizationRepository) Update(org *Organization) error {
+	query := `UPDATE organizations SET name = $2 WHERE id = $1 RETURNING updated_at`
+	if err := r.db.QueryRow(query, org.ID, org.Name).Scan(&org.UpdatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return errors.New(errors.ResourceNotFound)
+		}
+		return errors.Wrap(err, errors.DatabaseUpdateFailed)
+	}
+	return nil
+}
+
+func (r *organizationRepository) Delete(id string) error {
+	userIDs, err := r.memberIDs(id)
+	if err != nil {
+		return err
+	}
+
+	err = r.txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		exec := database.GetExecutor(ctx, r.db)
+		if err := r.actor.Apply(ctx, exec); err != nil {
+			return err
+		}
+
+		// Members and tenant-scoped role assignments cascade
+		query := `DELETE FROM organizations WHERE id = $1`
+		result, err := exec.ExecContext(ctx, query, id)
+		if err != nil {
+			return errors.Wrap(err, errors.DatabaseDeleteFailed)
+		}
+
+		rowsAffected, _ := result.RowsAffected()
+		if rowsAffected == 0 {
+			return errors.New(errors.ResourceNotFound)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_ = r.versions.Bump(userIDs...)
+	for _, userID := range userIDs {
+		_ = r.cacheHelper.InvalidateUserCache(context.Background(), userID)
+	}
+	return nil
+}
+
+// ==================== Membership Operations ====================
+
+func (r *organizationRepository) IsMember(organizationID, userID string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM organization_members WHERE organization_id = $1 AND user_id = $2)`
+	var exists bool
+	if err := r.db.QueryRow(query, organizationID, userID).Scan(&exists); err != nil {
+		return false, errors.Wrap(err, errors.DatabaseQueryFailed)
+	}
+	return exists, nil
+}
+
+func (r *organizationRepository) GetMembers(organizationID string) ([]Member, error) {
+	query := `
+		SELECT u.id, u.name, u.email, om.created_at
+		FROM organization_members om
+		INNER JOIN users u ON u.id = om.user_id
+		WHERE om.organization_id = $1
+		ORDER BY u.name
+	`
+	rows, err := r.db.Query(query, organizationID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
+	}
+	defer rows.Close()
+
+	var members []Member
+	for rows.Next() {
+		var member Member
+		if err := rows.Scan(&member.UserID, &member.Name, &member.Email, &member.JoinedAt); err != nil {
+			return nil, errors.Wrap(err, errors.DatabaseScanFailed)
+		}
+		members = append(members, member)
+	}
+
+	return members, nil
+}
+
+func (r *organizationRepository) AddMember(organizationID, userID string) error {
+	query := `INSERT INTO organization_members (organization_id, user_id, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`
+	if _, err := r.db.Exec(query, organizationID, userID, time.Now()); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "foreign_key_violation" {
+			return errors.New(errors.AccountNotFound)
+		}
+		return errors.Wrap(err, errors.DatabaseInsertFailed)
+	}
+	return nil
+}
+
+func (r *organizationRepository) RemoveMember(organizationID, userID string) error {
+	err := r.txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		exec := database.GetExecutor(ctx, r.db)
+		if err := r.actor.Apply(ctx, exec); err != nil {
+			return err
+		}
+
+		query := `DELETE FROM organization_members WHERE organization_id = $1 AND user_id = $2`
+		result, err := exec.ExecContext(ctx, query, organizationID, userID)
+		if err != nil {
+			return errors.Wrap(err, errors.DatabaseDeleteFailed)
+		}
+		if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+			return errors.New(errors.NotOrganizationMember)
+		}
+
+		query = `DELETE FROM user_roles WHERE organization_id = $1 AND user_id = $2`
+		if _, err := exec.ExecContext(ctx, query, organizationID, userID); err != nil {
+			return errors.Wrap(err, errors.DatabaseDeleteFailed)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_ = r.versions.Bump(userID)
+	_ = r.cacheHelper.InvalidateUserCache(context.Background(), userID)
+	return nil
+}
+
+func (r *organizationRepository) memberIDs(organizationID string) ([]string, error) {
+	rows, err := r.db.Query(`SELECT user_id FROM organization_members WHERE organization_id = $1`, organizationID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
+	}
+	defer rows.Close()
+
+	var userIDs []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, errors.Wrap(err, errors.DatabaseScanFailed)
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, nil
+}
@@ -0,0 +1,22 @@
+package organization
+
+// CreateOrganizationRequest is the request body for creating an organization
+type CreateOrganizationRequest struct {
+	Name string `json:"name" validate:"required,min=2,max=255"`
+	Slug string `json:"slug" validate:"required,min=2,max=100,slug"`
+}
+
+// UpdateOrganizationRequest is the request body for renaming an organization
+type UpdateOrganizationRequest struct {
+	Name string `json:"name" validate:"required,min=2,max=255"`
+}
+
+// AddMemberRequest is the request body for adding a user to an organization
+type AddMemberRequest struct {
+	UserID string `json:"user_id" validate:"required,uuid"`
+}
+
+// AssignMemberRoleRequest is the request body for assigning a role inside an organization
+type AssignMemberRoleRequest struct {
+	RoleID string `json:"role_id" validate:"required,uuid"`
+}
@@ -0,0 +1,69 @@
+package organization
+
+import (
+	"time"
+
+	"boilerplate-be/internal/module/rbac"
+)
+
+// OrganizationResponse is the response for a single organization; its creator is only shown to
+// those who may update it
+type OrganizationResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	CreatedBy string    `json:"created_by,omitempty" authz:"orgs:update"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MemberResponse is the response for a single organization member; the email is only shown to
+// the member and to holders of users:read
+type MemberResponse struct {
+	UserID   string    `json:"user_id" authz:"owner"`
+	Name     string    `json:"name"`
+	Email    string    `json:"email,omitempty" authz:"self,users:read"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// MemberRolesResponse contains the roles a member holds inside an organization
+type MemberRolesResponse struct {
+	OrganizationID string              `json:"organization_id"`
+	UserID         string              `json:"user_id"`
+	Roles          []rbac.RoleResponse `json:"roles"`
+}
+
+// ToOrganizationResponse converts Organization entity to OrganizationResponse
+func ToOrganizationResponse(org *Organization) OrganizationResponse {
+	return OrganizationResponse{
+		ID:        org.ID,
+		Name:      org.Name,
+		Slug:      org.Slug,
+		CreatedBy: org.CreatedBy,
+		CreatedAt: org.CreatedAt,
+		UpdatedAt: org.UpdatedAt,
+	}
+}
+
+// ToOrganizationResponses converts slice of Organization to slice of OrganizationResponse
+func ToOrganizationResponses(orgs []Organization) []OrganizationResponse {
+	responses := make([]OrganizationResponse, len(orgs))
+	for i, org := range orgs {
+		responses[i] = ToOrganizationResponse(&org)
+	}
+	return responses
+}
+
+// ToMemberResponses converts slice of Member to slice of MemberResponse
+func ToMemberResponses(members []Member) []MemberResponse {
+	responses := make([]MemberResponse, len(members))
+	for i, member := range members {
+		responses[i] = MemberResponse{
+			UserID:   member.UserID,
+			Name:     member.Name,
+			Email:    member.Email,
+			JoinedAt: member.JoinedAt,
+		}
+	}
+	return responses
+}
@@ -0,0 +1,174 @@
+package organization
+
+import (
+	"boilerplate-be/internal/module/rbac"
+	"boilerplate-be/internal/shared/errors"
+
+	"github.com/google/uuid"
+)
+
+type organizationUseCase struct {
+	orgRepo OrganizationRepository
+	roles   TenantRoleManager
+}
+
+// NewOrganizationUseCase creates a new organization use case
+func NewOrganizationUseCase(orgRepo OrganizationRepository, roles TenantRoleManager) OrganizationUseCase {
+	return &organizationUseCase{
+		orgRepo: orgRepo,
+		roles:   roles,
+	}
+}
+
+func (u *organizationUseCase) WithActor(actor rbac.ChangeActor) OrganizationUseCase {
+	return &organizationUseCase{
+		orgRepo: u.orgRepo.WithActor(actor),
+		roles:   u.roles.WithActor(actor),
+	}
+}
+
+// ==================== Organization Operations ====================
+
+// CreateOrganization creates the organization with the owner as its first member and org_admin
+func (u *organizationUseCase) CreateOrganization(ownerID, name, slug string) (*Organization, error) {
+	org := &Organization{
+		Name: name,
+		Slug: slug,
+	}
+
+	if err := u.orgRepo.Create(org, ownerID); err != nil {
+		return nil, err
+	}
+
+	return org, nil
+}
+
+func (u *organizationUseCase) GetOrganizations(userID string) ([]Organization, error) {
+	return u.orgRepo.GetForUser(userID)
+}
+
+func (u *organizationUseCase) GetOrganization(id string) (*Organization, error) {
+	return u.orgRepo.GetByID(id)
+}
+
+func (u *organizationUseCase) UpdateOrganization(id, name string) (*Organization, error) {
+	org, err := u.orgRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	org.Name = name
+	if err := u.orgRepo.Update(org); err != nil {
+		return nil, err
+	}
+
+	return org, nil
+}
+
+func (u *organizationUseCase) DeleteOrganization(id string) error {
+	return u.orgRepo.Delete(id)
+}
+
+// ==================== Membership Operations ====================
+
+// IsMember reports membership; a malformed organization ID has no members
+func (u *organizationUseCase) IsMember(organizationID, userID string) (bool, error) {
+	if _, err := uuid.Parse(organizationID); err != nil {
+		return false, nil
+	}
+	return u.orgRepo.IsMember(organizationID, userID)
+}
+
+func (u *organizationUseCase) GetMembers(organizationID string) ([]Member, error) {
+	return u.orgRepo.GetMembers(organizationID)
+}
+
+func (u *organizationUseCase) AddMember(organizationID, userID string) error {
+	return u.orgRepo.AddMember(organizationID, userID)
+}
+
+func (u *organizationUseCase) RemoveMember(organizationID, userID string) error {
+	return u.orgRepo.RemoveMember(organizationID, userID)
+}
+
+// ==================== Tenant-Scoped Role Operations ====================
+
+func (u *organizationUseCase) GetMemberRoles(organizationID, userID string) ([]rbac.Role, error) {
+	if err := u.requireMember(organizationID, userID); err != nil {
+		return nil, err
+	}
+	return u.roles.GetUserRolesInTenant(userID, organizationID)
+}
+
+func (u *organizationUseCase) AssignMemberRole(assignerID, organizationID, userID, roleID string) error {
+	if err := u.requireMember(organizationID, userID); err != nil {
+		return err
+	}
+	if err := u.requireCoveredBy(assignerID, organizationID, roleID); err != nil {
+		return err
+	}
+	return u.roles.AssignRoleToUserInTenant(userID, roleID, organizationID)
+}
+
+func (u *organizationUseCase) RemoveMemberRole(organizationID, userID, roleID string) error {
+	if err := u.requireMember(organizationID, userID); err != nil {
+		return err
+	}
+	return u.roles.RemoveRoleFromUserInTenant(userID, roleID, organizationID)
+}
+
+// requireCoveredBy rejects a role whose permissions, including inherited ones, are not all held
+// by the assigner inside the organization, or are denied to them there, so tenant admins cannot
+// grant more than they have
+func (u *organizationUseCase) requireCoveredBy(assignerID, organizationID, roleID string) error {
+	roleIDs := []string{roleID}
+	ancestors, err := u.roles.GetRoleAncestors(roleID)
+	if err != nil {
+		return err
+	}
+	for _, ancestor := range ancestors {
+		roleIDs = append(roleIDs, ancestor.ID)
+	}
+
+	held, err := u.roles.GetUserPermissionsInTenant(assignerID, organizationID)
+	if err != nil {
+		return err
+	}
+	granted := make([]string, len(held))
+	for i, permission := range held {
+		granted[i] = permission.Name
+	}
+	denies, err := u.roles.GetUserDenyRules(assignerID, organizationID)
+	if err != nil {
+		return err
+	}
+	denied := make([]string, len(denies))
+	for i, rule := range denies {
+		denied[i] = rule.Pattern
+	}
+
+	for _, id := range roleIDs {
+		permissions, err := u.roles.GetRolePermissions(id)
+		if err != nil {
+			return err
+		}
+		for _, permission := range permissions {
+			if _, ok := rbac.Authorize(granted, denied, permission.Name); !ok {
+				return errors.New(errors.Forbidden)
+			}
+		}
+	}
+	return nil
+}
+
+// requireMember keeps role assignments inside a tenant limited to that tenant's members
+func (u *organizationUseCase) requireMember(organizationID, userID string) error {
+	isMember, err := u.IsMember(organizationID, userID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return errors.New(errors.NotOrganizationMember)
+	}
+	return nil
+}
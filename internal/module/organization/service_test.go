@@ -0,0 +1,327 @@
+package organization
+
+import (
+	"testing"
+
+	"boilerplate-be/internal/module/rbac"
+	"boilerplate-be/internal/shared/enum"
+	apperrors "boilerplate-be/internal/shared/errors"
+
+	"github.com/google/uuid"
+)
+
+// MockOrganizationRepository implements OrganizationRepository in memory for testing
+type MockOrganizationRepository struct {
+	orgs      map[string]*Organization
+	members   map[string]map[string]bool
+	actor     rbac.ChangeActor
+	removedBy map[string]rbac.ChangeActor // "organization/user" -> actor of the removal
+}
+
+func NewMockOrganizationRepository() *MockOrganizationRepository {
+	return &MockOrganizationRepository{
+		orgs:      make(map[string]*Organization),
+		members:   make(map[string]map[string]bool),
+		removedBy: make(map[string]rbac.ChangeActor),
+	}
+}
+
+func (m *MockOrganizationRepository) WithActor(actor rbac.ChangeActor) OrganizationRepository {
+	copied := *m
+	copied.actor = actor
+	return &copied
+}
+
+func (m *MockOrganizationRepository) Create(org *Organization, ownerID string) error {
+	org.ID = uuid.New().String()
+	org.CreatedBy = ownerID
+	m.orgs[org.ID] = org
+	return m.AddMember(org.ID, ownerID)
+}
+
+func (m *MockOrganizationRepository) GetByID(id string) (*Organization, error) {
+	if org, ok := m.orgs[id]; ok {
+		copied := *org
+		return &copied, nil
+	}
+	return nil, apperrors.New(apperrors.ResourceNotFound)
+}
+
+func (m *MockOrganizationRepository) GetForUser(userID string) ([]Organization, error) {
+	var orgs []Organization
+	for id, org := range m.orgs {
+		if m.members[id][userID] {
+			orgs = append(orgs, *org)
+		}
+	}
+	return orgs, nil
+}
+
+func (m *MockOrganizationRepository) Update(org *Organization) error {
+	m.orgs[org.ID] = org
+	return nil
+}
+
+func (m *MockOrganizationRepository) Delete(id string) error {
+	delete(m.orgs, id)
+	delete(m.members, id)
+	return nil
+}
+
+func (m *MockOrganizationRepository) IsMember(organizationID, userID string) (bool, error) {
+	return m.members[organizationID][userID], nil
+}
+
+func (m *MockOrganizationRepository) GetMembers(organizationID string) ([]Member, error) {
+	var members []Member
+	for userID := range m.members[organizationID] {
+		members = append(members, Member{UserID: userID})
+	}
+	return members, nil
+}
+
+func (m *MockOrganizationRepository) AddMember(organizationID, userID string) error {
+	if m.members[organizationID] == nil {
+		m.members[organizationID] = make(map[string]bool)
+	}
+	m.members[organizationID][userID] = true
+	return nil
+}
+
+func (m *MockOrganizationRepository) RemoveMember(organizationID, userID string) error {
+	if !m.members[organizationID][userID] {
+		return apperrors.New(apperrors.NotOrganizationMember)
+	}
+	delete(m.members[organizationID], userID)
+	m.removedBy[organizationID+"/"+userID] = m.actor
+	return nil
+}
+
+// mockTenantRoles implements TenantRoleManager; roles have fixed permissions and no parents.
+// Embedded RBACUseCase methods are not called.
+type mockTenantRoles struct {
+	rbac.RBACUseCase
+	rolePermissions map[string][]string
+	assigned        map[string][]string // "organization/user" -> role IDs
+	denies          map[string][]string // "organization/user" -> denied patterns
+	actor           rbac.ChangeActor
+	assignedBy      map[string]rbac.ChangeActor // "organization/user/role" -> actor of the grant
+}
+
+func (m *mockTenantRoles) WithActor(actor rbac.ChangeActor) rbac.RBACUseCase {
+	copied := *m
+	copied.actor = actor
+	return &copied
+}
+
+func (m *mockTenantRoles) GetRolePermissions(roleID string) ([]rbac.Permission, error) {
+	names, ok := m.rolePermissions[roleID]
+	if !ok {
+		return nil, apperrors.New(apperrors.ResourceNotFound)
+	}
+	permissions := make([]rbac.Permission, len(names))
+	for i, name := range names {
+		permissions[i] = rbac.Permission{Name: name}
+	}
+	return permissions, nil
+}
+
+func (m *mockTenantRoles) GetRoleAncestors(roleID string) ([]rbac.Role, error) {
+	return nil, nil
+}
+
+func (m *mockTenantRoles) GetUserPermissionsInTenant(userID, organizationID string) ([]rbac.Permission, error) {
+	var permissions []rbac.Permission
+	for _, roleID := range m.assigned[organizationID+"/"+userID] {
+		held, _ := m.GetRolePermissions(roleID)
+		permissions = append(permissions, held...)
+	}
+	return permissions, nil
+}
+
+func (m *mockTenantRoles) GetUserDenyRules(userID, organizationID string) ([]rbac.DenyRule, error) {
+	var rules []rbac.DenyRule
+	for _, pattern := range m.denies[organizationID+"/"+userID] {
+		rules = append(rules, rbac.DenyRule{Pattern: pattern})
+	}
+	return rules, nil
+}
+
+func (m *mockTenantRoles) GetUserRolesInTenant(userID, organizationID string) ([]rbac.Role, error) {
+	var roles []rbac.Role
+	for _, roleID := range m.assigned[organizationID+"/"+userID] {
+		roles = append(roles, rbac.Role{ID: roleID})
+	}
+	return roles, nil
+}
+
+func (m *mockTenantRoles) AssignRoleToUserInTenant(userID, roleID, organizationID string) error {
+	key := organizationID + "/" + userID
+	m.assigned[key] = append(m.assigned[key], roleID)
+	m.assignedBy[key+"/"+roleID] = m.actor
+	return nil
+}
+
+func (m *mockTenantRoles) RemoveRoleFromUserInTenant(userID, roleID, organizationID string) error {
+	key := organizationID + "/" + userID
+	kept := m.assigned[key][:0]
+	for _, id := range m.assigned[key] {
+		if id != roleID {
+			kept = append(kept, id)
+		}
+	}
+	m.assigned[key] = kept
+	return nil
+}
+
+func assertErrorCode(t *testing.T, err error, want enum.ErrorCode) {
+	t.Helper()
+	appErr, ok := apperrors.IsAppError(err)
+	if !ok || appErr.Code != want {
+		t.Fatalf("expected error code %v, got %v", want, err)
+	}
+}
+
+func TestOrganizationService_MemberRoles(t *testing.T) {
+	repo := NewMockOrganizationRepository()
+	roles := &mockTenantRoles{
+		rolePermissions: map[string][]string{
+			"org-admin":   {"orgs:read", "orgs:members:write", "orgs:roles:assign"},
+			"viewer":      {"orgs:read"},
+			"super-admin": {"*:*"},
+		},
+		assigned:   make(map[string][]string),
+		assignedBy: make(map[string]rbac.ChangeActor),
+	}
+	useCase := NewOrganizationUseCase(repo, roles)
+
+	org, err := useCase.CreateOrganization("alice", "Acme", "acme")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = roles.AssignRoleToUserInTenant("alice", "org-admin", org.ID)
+	_ = useCase.AddMember(org.ID, "bob")
+
+	// Roles can only be managed for members of the organization
+	assertErrorCode(t, useCase.AssignMemberRole("alice", org.ID, "carol", "viewer"), apperrors.NotOrganizationMember)
+	if _, err := useCase.GetMemberRoles(org.ID, "carol"); err == nil {
+		t.Error("expected listing roles of a non-member to fail")
+	}
+
+	// An assigner cannot grant permissions they do not hold in the organization
+	assertErrorCode(t, useCase.AssignMemberRole("alice", org.ID, "bob", "super-admin"), apperrors.Forbidden)
+	assertErrorCode(t, useCase.AssignMemberRole("alice", org.ID, "bob", "unknown"), apperrors.ResourceNotFound)
+
+	if err := useCase.AssignMemberRole("alice", org.ID, "bob", "viewer"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	memberRoles, _ := useCase.GetMemberRoles(org.ID, "bob")
+	if len(memberRoles) != 1 || memberRoles[0].ID != "viewer" {
+		t.Errorf("expected bob to hold viewer, got %v", memberRoles)
+	}
+
+	// Removing the member ends their membership
+	if err := useCase.RemoveMember(org.ID, "bob"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if isMember, _ := useCase.IsMember(org.ID, "bob"); isMember {
+		t.Error("expected bob to no longer be a member")
+	}
+}
+
+func TestOrganizationService_AssignMemberRoleRespectsDenies(t *testing.T) {
+	repo := NewMockOrganizationRepository()
+	roles := &mockTenantRoles{
+		rolePermissions: map[string][]string{
+			"org-admin": {"orgs:*"},
+			"viewer":    {"orgs:read"},
+			"editor":    {"orgs:write"},
+		},
+		assigned:   make(map[string][]string),
+		denies:     make(map[string][]string),
+		assignedBy: make(map[string]rbac.ChangeActor),
+	}
+	useCase := NewOrganizationUseCase(repo, roles)
+
+	org, _ := useCase.CreateOrganization("alice", "Acme", "acme")
+	_ = roles.AssignRoleToUserInTenant("alice", "org-admin", org.ID)
+	_ = useCase.AddMember(org.ID, "bob")
+	roles.denies[org.ID+"/alice"] = []string{"orgs:write"}
+
+	// The deny overrides the wildcard grant, so alice cannot hand the permission out
+	assertErrorCode(t, useCase.AssignMemberRole("alice", org.ID, "bob", "editor"), apperrors.Forbidden)
+	if err := useCase.AssignMemberRole("alice", org.ID, "bob", "viewer"); err != nil {
+		t.Errorf("expected a role without denied permissions to be assignable, got %v", err)
+	}
+}
+
+func TestOrganizationService_IsMember(t *testing.T) {
+	repo := NewMockOrganizationRepository()
+	useCase := NewOrganizationUseCase(repo, &mockTenantRoles{})
+
+	org, _ := useCase.CreateOrganization("alice", "Acme", "acme")
+
+	tests := []struct {
+		name           string
+		organizationID string
+		userID         string
+		want           bool
+	}{
+		{"creator is a member", org.ID, "alice", true},
+		{"other user is not", org.ID, "bob", false},
+		{"malformed organization ID", "not-a-uuid", "alice", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := useCase.IsMember(tt.organizationID, tt.userID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsMember(%s, %s) = %v, want %v", tt.organizationID, tt.userID, got, tt.want)
+			}
+		})
+	}
+
+	orgs, _ := useCase.GetOrganizations("alice")
+	if len(orgs) != 1 || orgs[0].Slug != "acme" {
+		t.Errorf("expected alice to see acme, got %v", orgs)
+	}
+	if orgs, _ := useCase.GetOrganizations("bob"); len(orgs) != 0 {
+		t.Errorf("expected bob to see no organizations, got %v", orgs)
+	}
+}
+
+func TestOrganizationService_WithActor(t *testing.T) {
+	repo := NewMockOrganizationRepository()
+	roles := &mockTenantRoles{
+		rolePermissions: map[string][]string{
+			"org-admin": {"orgs:read", "orgs:roles:assign"},
+			"viewer":    {"orgs:read"},
+		},
+		assigned:   make(map[string][]string),
+		assignedBy: make(map[string]rbac.ChangeActor),
+	}
+	useCase := NewOrganizationUseCase(repo, roles)
+
+	org, _ := useCase.CreateOrganization("alice", "Acme", "acme")
+	_ = roles.AssignRoleToUserInTenant("alice", "org-admin", org.ID)
+	_ = useCase.AddMember(org.ID, "bob")
+
+	actor := rbac.ChangeActor{UserID: "alice", RequestID: "req-1"}
+	acting := useCase.WithActor(actor)
+	if err := acting.AssignMemberRole("alice", org.ID, "bob", "viewer"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := acting.RemoveMember(org.ID, "bob"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := roles.assignedBy[org.ID+"/bob/viewer"]; got != actor {
+		t.Errorf("expected the grant to be recorded as made by %+v, got %+v", actor, got)
+	}
+	if got := repo.removedBy[org.ID+"/bob"]; got != actor {
+		t.Errorf("expected the removal to be recorded as made by %+v, got %+v", actor, got)
+	}
+}
@@ -0,0 +1,50 @@
+package policy
+
+import "boilerplate-be/internal/module/rbac"
+
+// Subject is the user a decision is made for; Denies override Permissions
+type Subject struct {
+	ID          string
+	Roles       []string
+	Permissions []string
+	Denies      []string
+	Attributes  map[string]interface{}
+}
+
+// Resource is the record a decision is made about, produced by a ResourceLoader
+type Resource struct {
+	Type       string
+	ID         string
+	OwnerID    string
+	Attributes map[string]interface{}
+}
+
+// Request bundles everything a policy may look at
+type Request struct {
+	Subject  Subject
+	Resource *Resource
+	Context  map[string]interface{}
+}
+
+// Policy decides whether a request is allowed
+type Policy interface {
+	Evaluate(req *Request) (bool, error)
+}
+
+// PolicyFunc adapts a plain function to Policy
+type PolicyFunc func(req *Request) (bool, error)
+
+func (f PolicyFunc) Evaluate(req *Request) (bool, error) {
+	return f(req)
+}
+
+// ResourceLoader fetches a resource by ID; it returns a ResourceNotFound app error when missing
+type ResourceLoader func(id string) (*Resource, error)
+
+// SubjectProvider supplies the roles, permissions and deny rules of a user; rbac.RBACUseCase
+// satisfies it
+type SubjectProvider interface {
+	GetUserRoles(userID string) ([]rbac.Role, error)
+	GetUserPermissions(userID string) ([]rbac.Permission, error)
+	GetUserDenyRules(userID, organizationID string) ([]rbac.DenyRule, error)
+}
@@ -0,0 +1,127 @@
+package policy
+
+import (
+	"log"
+	"sync"
+
+	"boilerplate-be/internal/shared/errors"
+)
+
+// Engine holds the named policies and resource loaders registered at startup
+type Engine struct {
+	subjects SubjectProvider
+
+	mu       sync.RWMutex
+	policies map[string]Policy
+	loaders  map[string]ResourceLoader
+}
+
+// NewEngine creates a policy engine that resolves subjects through the given provider
+func NewEngine(subjects SubjectProvider) *Engine {
+	return &Engine{
+		subjects: subjects,
+		policies: make(map[string]Policy),
+		loaders:  make(map[string]ResourceLoader),
+	}
+}
+
+// Register adds or replaces a named policy
+func (e *Engine) Register(name string, p Policy) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.policies[name] = p
+}
+
+// RegisterExpression parses an expression rule and registers it under name
+func (e *Engine) RegisterExpression(name, source string) error {
+	expr, err := ParseExpression(source)
+	if err != nil {
+		return err
+	}
+	e.Register(name, expr)
+	return nil
+}
+
+// RegisterLoader sets how resources of a type are fetched for RequirePolicy
+func (e *Engine) RegisterLoader(resourceType string, loader ResourceLoader) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.loaders[resourceType] = loader
+}
+
+// LoadResource fetches a resource through its registered loader
+func (e *Engine) LoadResource(resourceType, id string) (*Resource, error) {
+	e.mu.RLock()
+	loader, ok := e.loaders[resourceType]
+	e.mu.RUnlock()
+	if !ok {
+		log.Printf("policy: no loader registered for resource type %q", resourceType)
+		return nil, errors.New(errors.ConfigurationError)
+	}
+
+	resource, err := loader(id)
+	if err != nil {
+		return nil, err
+	}
+	if resource.Type == "" {
+		resource.Type = resourceType
+	}
+	return resource, nil
+}
+
+// Subject builds the subject for a user from their roles, permissions and global deny rules
+func (e *Engine) Subject(userID string) (Subject, error) {
+	subject := Subject{ID: userID}
+
+	roles, err := e.subjects.GetUserRoles(userID)
+	if err != nil {
+		return subject, err
+	}
+	for _, role := range roles {
+		subject.Roles = append(subject.Roles, role.Name)
+	}
+
+	permissions, err := e.subjects.GetUserPermissions(userID)
+	if err != nil {
+		return subject, err
+	}
+	for _, permission := range permissions {
+		subject.Permissions = append(subject.Permissions, permission.Name)
+	}
+
+	denies, err := e.subjects.GetUserDenyRules(userID, "")
+	if err != nil {
+		return subject, err
+	}
+	for _, rule := range denies {
+		subject.Denies = append(subject.Denies, rule.Pattern)
+	}
+
+	return subject, nil
+}
+
+// Authorize evaluates the named policy and returns Forbidden when it denies
+func (e *Engine) Authorize(policyName, userID string, resource *Resource, context map[string]interface{}) error {
+	e.mu.RLock()
+	p, ok := e.policies[policyName]
+	e.mu.RUnlock()
+	if !ok {
+		log.Printf("policy: policy %q is not registered", policyName)
+		return errors.New(errors.ConfigurationError)
+	}
+
+	subject, err := e.Subject(userID)
+	if err != nil {
+		return err
+	}
+
+	allowed, err := p.Evaluate(&Request{Subject: subject, Resource: resource, Context: context})
+	if err != nil {
+		log.Printf("policy: evaluating %q failed: %v", policyName, err)
+		return errors.Wrap(err, errors.InternalServerError)
+	}
+	if !allowed {
+		return errors.New(errors.Forbidden)
+	}
+	return nil
+}
@@ -0,0 +1,581 @@
+package policy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"boilerplate-be/internal/module/rbac"
+)
+
+// Expression rules are boolean expressions over subject, resource and context attributes:
+//
+//	own(profile) || has_permission("users:write")
+//	resource.status == "draft" && "editor" in subject.roles
+//	context.ip in ["10.0.0.1", "10.0.0.2"] && !(resource.locked == true)
+//
+// Operands are string, number, bool and null literals, [list] literals, and dotted paths
+// rooted at subject (id, roles, permissions, attributes), resource (type, id, owner_id,
+// attributes) or context. Operators are ||, &&, !, ==, !=, <, <=, >, >= and in.
+// Functions: own(type) is true when the subject owns a resource of that type (type may be
+// omitted); has_role(name) and has_permission(name) check the subject, the latter honouring
+// permission wildcards. A missing attribute evaluates to null.
+
+// Expression is a parsed rule; it implements Policy
+type Expression struct {
+	source string
+	root   node
+}
+
+// ParseExpression compiles a rule so syntax errors surface at registration, not per request
+func ParseExpression(source string) (*Expression, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("policy: unexpected %q at position %d", p.peek().text, p.peek().pos)
+	}
+
+	return &Expression{source: source, root: root}, nil
+}
+
+// MustParseExpression is ParseExpression for rules fixed at compile time
+func MustParseExpression(source string) *Expression {
+	expr, err := ParseExpression(source)
+	if err != nil {
+		panic(err)
+	}
+	return expr
+}
+
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Evaluate runs the rule; a non-boolean result is an error
+func (e *Expression) Evaluate(req *Request) (bool, error) {
+	value, err := e.root.eval(req)
+	if err != nil {
+		return false, err
+	}
+	result, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("policy: %q evaluates to %T, not bool", e.source, value)
+	}
+	return result, nil
+}
+
+// ==================== Tokenizer ====================
+
+type tokenKind int
+
+const (
+	tokenIdent tokenKind = iota
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+var operators = []string{"||", "&&", "==", "!=", "<=", ">=", "<", ">", "!"}
+
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(source); {
+		ch := rune(source[i])
+		switch {
+		case unicode.IsSpace(ch):
+			i++
+
+		case ch == '"' || ch == '\'':
+			end := i + 1
+			var text strings.Builder
+			for end < len(source) && rune(source[end]) != ch {
+				if source[end] == '\\' && end+1 < len(source) {
+					end++
+				}
+				text.WriteByte(source[end])
+				end++
+			}
+			if end >= len(source) {
+				return nil, fmt.Errorf("policy: unterminated string at position %d", i)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text.String(), pos: i})
+			i = end + 1
+
+		case unicode.IsDigit(ch) || (ch == '-' && i+1 < len(source) && unicode.IsDigit(rune(source[i+1]))):
+			end := i + 1
+			for end < len(source) && (unicode.IsDigit(rune(source[end])) || source[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: source[i:end], pos: i})
+			i = end
+
+		case unicode.IsLetter(ch) || ch == '_':
+			end := i + 1
+			for end < len(source) && (unicode.IsLetter(rune(source[end])) || unicode.IsDigit(rune(source[end])) || source[end] == '_' || source[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: source[i:end], pos: i})
+			i = end
+
+		case strings.ContainsRune("()[],", ch):
+			tokens = append(tokens, token{kind: tokenPunct, text: string(ch), pos: i})
+			i++
+
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(source[i:], op) {
+					tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("policy: unexpected character %q at position %d", ch, i)
+			}
+		}
+	}
+	return tokens, nil
+}
+
+// ==================== Parser ====================
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() token {
+	if p.done() {
+		return token{text: "end of expression", pos: -1}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) accept(text string) bool {
+	if !p.done() && p.tokens[p.pos].kind != tokenString && p.tokens[p.pos].text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		return fmt.Errorf("policy: expected %q, found %q", text, p.peek().text)
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.accept("!") {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">", "in"} {
+		if p.accept(op) {
+			right, err := p.parsePrimary()
+			if err != nil {
+				return nil, err
+			}
+			return compareNode{op: op, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	if p.done() {
+		return nil, fmt.Errorf("policy: unexpected end of expression")
+	}
+	tok := p.tokens[p.pos]
+
+	switch {
+	case tok.kind == tokenString:
+		p.pos++
+		return literalNode{value: tok.text}, nil
+
+	case tok.kind == tokenNumber:
+		p.pos++
+		number, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("policy: invalid number %q", tok.text)
+		}
+		return literalNode{value: number}, nil
+
+	case p.accept("("):
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return inner, p.expect(")")
+
+	case p.accept("["):
+		var items []node
+		for !p.accept("]") {
+			if len(items) > 0 {
+				if err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+			item, err := p.parsePrimary()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return listNode{items: items}, nil
+
+	case tok.kind == tokenIdent:
+		p.pos++
+		switch tok.text {
+		case "true":
+			return literalNode{value: true}, nil
+		case "false":
+			return literalNode{value: false}, nil
+		case "null":
+			return literalNode{value: nil}, nil
+		}
+		if p.accept("(") {
+			return p.parseCall(tok)
+		}
+		return pathNode{path: strings.Split(tok.text, ".")}, nil
+	}
+
+	return nil, fmt.Errorf("policy: unexpected %q at position %d", tok.text, tok.pos)
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	var args []string
+	for !p.accept(")") {
+		if len(args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		// Arguments are names, quoted or bare: own(profile) and own("profile") are the same
+		if p.done() || (p.peek().kind != tokenString && p.peek().kind != tokenIdent) {
+			return nil, fmt.Errorf("policy: %s() arguments must be names, found %q", name.text, p.peek().text)
+		}
+		args = append(args, p.tokens[p.pos].text)
+		p.pos++
+	}
+
+	switch name.text {
+	case "own":
+		if len(args) > 1 {
+			return nil, fmt.Errorf("policy: own() takes at most one resource type")
+		}
+		resourceType := ""
+		if len(args) == 1 {
+			resourceType = args[0]
+		}
+		return ownNode{resourceType: resourceType}, nil
+	case "has_role", "has_permission":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("policy: %s() takes exactly one argument", name.text)
+		}
+		return checkNode{fn: name.text, arg: args[0]}, nil
+	}
+
+	return nil, fmt.Errorf("policy: unknown function %s()", name.text)
+}
+
+// ==================== Evaluation ====================
+
+type node interface {
+	eval(req *Request) (interface{}, error)
+}
+
+type literalNode struct{ value interface{} }
+
+func (n literalNode) eval(*Request) (interface{}, error) { return n.value, nil }
+
+type listNode struct{ items []node }
+
+func (n listNode) eval(req *Request) (interface{}, error) {
+	values := make([]interface{}, len(n.items))
+	for i, item := range n.items {
+		value, err := item.eval(req)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+type pathNode struct{ path []string }
+
+func (n pathNode) eval(req *Request) (interface{}, error) {
+	var root map[string]interface{}
+	switch n.path[0] {
+	case "subject":
+		root = map[string]interface{}{
+			"id":          req.Subject.ID,
+			"roles":       toList(req.Subject.Roles),
+			"permissions": toList(req.Subject.Permissions),
+		}
+		mergeAttributes(root, req.Subject.Attributes)
+	case "resource":
+		root = map[string]interface{}{}
+		if req.Resource != nil {
+			root["type"] = req.Resource.Type
+			root["id"] = req.Resource.ID
+			root["owner_id"] = req.Resource.OwnerID
+			mergeAttributes(root, req.Resource.Attributes)
+		}
+	case "context":
+		root = req.Context
+	default:
+		return nil, fmt.Errorf("policy: unknown attribute root %q", n.path[0])
+	}
+
+	var value interface{} = root
+	for _, key := range n.path[1:] {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		value = normalize(object[key])
+	}
+	return value, nil
+}
+
+type logicalNode struct {
+	op          string
+	left, right node
+}
+
+func (n logicalNode) eval(req *Request) (interface{}, error) {
+	left, err := evalBool(n.left, req)
+	if err != nil {
+		return nil, err
+	}
+	// Short-circuit so the right side may assume the left held
+	if (n.op == "||" && left) || (n.op == "&&" && !left) {
+		return left, nil
+	}
+	return evalBool(n.right, req)
+}
+
+type notNode struct{ operand node }
+
+func (n notNode) eval(req *Request) (interface{}, error) {
+	value, err := evalBool(n.operand, req)
+	return !value, err
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n compareNode) eval(req *Request) (interface{}, error) {
+	left, err := n.left.eval(req)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "in":
+		list, ok := right.([]interface{})
+		if !ok {
+			return false, nil
+		}
+		for _, item := range list {
+			if equal(left, item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	// Ordering compares numbers with numbers and strings with strings; anything else is false
+	if a, ok := left.(float64); ok {
+		if b, ok := right.(float64); ok {
+			return order(n.op, compareFloat(a, b)), nil
+		}
+	}
+	if a, ok := left.(string); ok {
+		if b, ok := right.(string); ok {
+			return order(n.op, strings.Compare(a, b)), nil
+		}
+	}
+	return false, nil
+}
+
+type ownNode struct{ resourceType string }
+
+func (n ownNode) eval(req *Request) (interface{}, error) {
+	return Own(n.resourceType).Evaluate(req)
+}
+
+type checkNode struct{ fn, arg string }
+
+func (n checkNode) eval(req *Request) (interface{}, error) {
+	if n.fn == "has_role" {
+		for _, role := range req.Subject.Roles {
+			if role == n.arg {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	_, ok := rbac.Authorize(req.Subject.Permissions, req.Subject.Denies, n.arg)
+	return ok, nil
+}
+
+func evalBool(n node, req *Request) (bool, error) {
+	value, err := n.eval(req)
+	if err != nil {
+		return false, err
+	}
+	result, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("policy: expected a boolean operand, got %T", value)
+	}
+	return result, nil
+}
+
+func mergeAttributes(root map[string]interface{}, attributes map[string]interface{}) {
+	for key, value := range attributes {
+		if _, reserved := root[key]; !reserved {
+			root[key] = value
+		}
+	}
+}
+
+func toList(values []string) []interface{} {
+	list := make([]interface{}, len(values))
+	for i, value := range values {
+		list[i] = value
+	}
+	return list
+}
+
+// normalize maps Go attribute values onto the expression types
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case []string:
+		return toList(v)
+	}
+	return value
+}
+
+func equal(a, b interface{}) bool {
+	switch av := a.(type) {
+	case []interface{}, map[string]interface{}:
+		return false
+	default:
+		if _, ok := b.([]interface{}); ok {
+			return false
+		}
+		if _, ok := b.(map[string]interface{}); ok {
+			return false
+		}
+		return av == b
+	}
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func order(op string, cmp int) bool {
+	switch op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package policy
+
+import (
+	"testing"
+)
+
+func newTestRequest() *Request {
+	return &Request{
+		Subject: Subject{
+			ID:          "user-1",
+			Roles:       []string{"editor"},
+			Permissions: []string{"posts:*", "*:read"},
+			Denies:      []string{"posts:publish"},
+			Attributes:  map[string]interface{}{"department": "sales", "level": 3},
+		},
+		Resource: &Resource{
+			Type:       "post",
+			ID:         "post-1",
+			OwnerID:    "user-1",
+			Attributes: map[string]interface{}{"status": "draft", "views": 120, "tags": []string{"go", "api"}},
+		},
+		Context: map[string]interface{}{"ip": "10.0.0.1", "method": "PUT"},
+	}
+}
+
+func TestExpression_Evaluate(t *testing.T) {
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		// Ownership
+		{`own(post)`, true},
+		{`own("post")`, true},
+		{`own()`, true},
+		{`own(profile)`, false},
+
+		// Attribute comparisons
+		{`subject.id == resource.owner_id`, true},
+		{`resource.status == "draft"`, true},
+		{`resource.status != 'draft'`, false},
+		{`resource.views > 100`, true},
+		{`resource.views <= 100`, false},
+		{`subject.level >= 3 && subject.department == "sales"`, true},
+		{`resource.type == "post" && resource.id == "post-1"`, true},
+
+		// Membership
+		{`"editor" in subject.roles`, true},
+		{`"admin" in subject.roles`, false},
+		{`"go" in resource.tags`, true},
+		{`context.ip in ["10.0.0.1", "10.0.0.2"]`, true},
+		{`context.method in ["GET"]`, false},
+
+		// Functions
+		{`has_role(editor)`, true},
+		{`has_permission("posts:delete")`, true},
+		{`has_permission("users:read")`, true},
+		{`has_permission("users:write")`, false},
+		{`has_permission("posts:publish")`, false},
+
+		// Logic and precedence: && binds tighter than ||
+		{`false && false || true`, true},
+		{`false && (false || true)`, false},
+		{`!own(post) || resource.status == "draft"`, true},
+		{`!(own(post) && resource.status == "published")`, true},
+
+		// Missing attributes are null
+		{`resource.archived == null`, true},
+		{`context.missing.deeper == null`, true},
+		{`resource.archived == true`, false},
+
+		// Mismatched types never order
+		{`resource.status > 10`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := ParseExpression(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseExpression(%q) error: %v", tt.expr, err)
+			}
+
+			got, err := expr.Evaluate(newTestRequest())
+			if err != nil {
+				t.Fatalf("Evaluate(%q) error: %v", tt.expr, err)
+			}
+			if got != tt.want {
+				t.Errorf("Evaluate(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseExpression_Errors(t *testing.T) {
+	tests := []string{
+		``,
+		`own(post`,
+		`resource.status ==`,
+		`resource.status == "draft`,
+		`unknown_fn(x)`,
+		`own(a, b)`,
+		`has_role()`,
+		`resource.status # "x"`,
+		`(true`,
+		`true true`,
+	}
+
+	for _, source := range tests {
+		t.Run(source, func(t *testing.T) {
+			if _, err := ParseExpression(source); err == nil {
+				t.Errorf("expected ParseExpression(%q) to fail", source)
+			}
+		})
+	}
+}
+
+func TestExpression_EvaluateErrors(t *testing.T) {
+	tests := []string{
+		`resource.status`,         // not a boolean
+		`resource.status && true`, // non-boolean operand
+		`account.id == "x"`,       // unknown root
+	}
+
+	for _, source := range tests {
+		t.Run(source, func(t *testing.T) {
+			expr := MustParseExpression(source)
+			if _, err := expr.Evaluate(newTestRequest()); err == nil {
+				t.Errorf("expected Evaluate(%q) to fail", source)
+			}
+		})
+	}
+}
+
+func TestCombinators(t *testing.T) {
+	allow := PolicyFunc(func(*Request) (bool, error) { return true, nil })
+	deny := PolicyFunc(func(*Request) (bool, error) { return false, nil })
+	req := newTestRequest()
+
+	tests := []struct {
+		name   string
+		policy Policy
+		want   bool
+	}{
+		{"any of deny, allow", AnyOf(deny, allow), true},
+		{"any of deny", AnyOf(deny), false},
+		{"any of nothing", AnyOf(), false},
+		{"all of allow, allow", AllOf(allow, allow), true},
+		{"all of allow, deny", AllOf(allow, deny), false},
+		{"all of nothing", AllOf(), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.policy.Evaluate(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOwn_WithoutResource(t *testing.T) {
+	req := &Request{Subject: Subject{ID: "user-1"}}
+	if allowed, _ := Own("").Evaluate(req); allowed {
+		t.Error("expected own() to deny when no resource is loaded")
+	}
+
+	req.Resource = &Resource{Type: "post", ID: "post-1"}
+	if allowed, _ := Own("").Evaluate(req); allowed {
+		t.Error("expected own() to deny a resource without an owner")
+	}
+}
@@ -0,0 +1,46 @@
+package policy
+
+// Own allows a subject that owns the resource; an empty resourceType accepts any type
+func Own(resourceType string) Policy {
+	return PolicyFunc(func(req *Request) (bool, error) {
+		if req.Resource == nil || req.Resource.OwnerID == "" {
+			return false, nil
+		}
+		if resourceType != "" && req.Resource.Type != resourceType {
+			return false, nil
+		}
+		return req.Resource.OwnerID == req.Subject.ID, nil
+	})
+}
+
+// AnyOf allows when at least one policy allows; evaluation stops at the first allow
+func AnyOf(policies ...Policy) Policy {
+	return PolicyFunc(func(req *Request) (bool, error) {
+		for _, p := range policies {
+			allowed, err := p.Evaluate(req)
+			if err != nil {
+				return false, err
+			}
+			if allowed {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+}
+
+// AllOf allows only when every policy allows; evaluation stops at the first deny
+func AllOf(policies ...Policy) Policy {
+	return PolicyFunc(func(req *Request) (bool, error) {
+		for _, p := range policies {
+			allowed, err := p.Evaluate(req)
+			if err != nil {
+				return false, err
+			}
+			if !allowed {
+				return false, nil
+			}
+		}
+		return len(policies) > 0, nil
+	})
+}
@@ -0,0 +1,69 @@
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"boilerplate-be/internal/shared/utils"
+)
+
+// GrantCacheTTL is the longest a user's roles or permissions stay cached
+const GrantCacheTTL = 5 * time.Minute
+
+// UserVersions tracks a counter per user that changes whenever the user's roles or permissions
+// may have changed, e.g. security.PermissionVersions
+type UserVersions interface {
+	Current(userID string) (int64, error)
+	Bump(userIDs ...string) error
+}
+
+// grantCache caches users' roles and permissions under keys that include the user's version.
+// Invalidating bumps the version, so the very next read misses even if a reader that loaded
+// the old state is still about to write it: that write lands under the outdated key.
+type grantCache struct {
+	cacheHelper *utils.CacheHelper
+	versions    UserVersions
+}
+
+// key builds the versioned cache key; ok is false when the version cannot be read, in which
+// case the cache is bypassed rather than risking a stale entry
+func (c grantCache) key(userID, keyType string) (string, bool) {
+	version, err := c.versions.Current(userID)
+	if err != nil {
+		return "", false
+	}
+	return c.cacheHelper.BuildUserCacheKey(userID, fmt.Sprintf("%s:v%d", keyType, version)), true
+}
+
+// loadGrants returns the cached value or caches what fetch returns for ttl(). The key is
+// resolved before fetching, so a change committed during the fetch outdates it.
+func loadGrants[T any](c grantCache, userID, keyType string, ttl func() time.Duration, fetch func() (T, error)) (T, error) {
+	key, ok := c.key(userID, keyType)
+	if ok {
+		var cached T
+		if err := c.cacheHelper.GetJSON(context.Background(), key, &cached); err == nil {
+			return cached, nil
+		}
+	}
+
+	value, err := fetch()
+	if err != nil || !ok {
+		return value, err
+	}
+
+	_ = c.cacheHelper.CacheJSON(context.Background(), key, value, ttl())
+	return value, nil
+}
+
+// invalidate moves the users to a new version, then drops their outdated entries
+func (c grantCache) invalidate(userIDs []string) {
+	if len(userIDs) == 0 {
+		return
+	}
+
+	_ = c.versions.Bump(userIDs...)
+	for _, userID := range userIDs {
+		_ = c.cacheHelper.InvalidateUserCache(context.Background(), userID)
+	}
+}
@@ -0,0 +1,272 @@
+package rbac
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"boilerplate-be/internal/database"
+	"boilerplate-be/internal/shared/security"
+	"boilerplate-be/internal/shared/utils"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestGrantCache(t *testing.T) (grantCache, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	// No retries, so reads fail fast once the server is closed
+	client := &database.RedisClient{Client: redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})}
+	t.Cleanup(func() { client.Close() })
+	return grantCache{
+		cacheHelper: utils.NewCacheHelper(client, time.Minute),
+		versions:    security.NewPermissionVersions(client, time.Hour),
+	}, mr
+}
+
+func fixedTTL() time.Duration { return time.Minute }
+
+func TestGrantCache_RevokeAppliesOnNextRead(t *testing.T) {
+	cache, _ := newTestGrantCache(t)
+	stored := []string{"users:read", "users:delete"}
+	fetches := 0
+	fetch := func() ([]string, error) {
+		fetches++
+		return append([]string{}, stored...), nil
+	}
+
+	if got, _ := loadGrants(cache, "alice", "permissions", fixedTTL, fetch); len(got) != 2 {
+		t.Fatalf("expected both permissions, got %v", got)
+	}
+	if _, _ = loadGrants(cache, "alice", "permissions", fixedTTL, fetch); fetches != 1 {
+		t.Fatalf("expected the second read to be served from cache, fetched %d times", fetches)
+	}
+
+	// Revoking through a role change invalidates every holder of the role
+	stored = []string{"users:read"}
+	cache.invalidate([]string{"alice", "bob"})
+
+	got, _ := loadGrants(cache, "alice", "permissions", fixedTTL, fetch)
+	if len(got) != 1 || got[0] != "users:read" {
+		t.Errorf("expected the revoked permission to be gone on the next read, got %v", got)
+	}
+}
+
+func TestGrantCache_ConcurrentRevokeIsNotCachedOver(t *testing.T) {
+	cache, _ := newTestGrantCache(t)
+	stored := []string{"users:delete"}
+
+	// The revoke commits while the first reader is still loading the old permissions
+	racing := func() ([]string, error) {
+		old := append([]string{}, stored...)
+		stored = nil
+		cache.invalidate([]string{"alice"})
+		return old, nil
+	}
+	if got, _ := loadGrants(cache, "alice", "permissions", fixedTTL, racing); len(got) != 1 {
+		t.Fatalf("expected the racing reader to see the old state, got %v", got)
+	}
+
+	got, _ := loadGrants(cache, "alice", "permissions", fixedTTL, func() ([]string, error) {
+		return stored, nil
+	})
+	if len(got) != 0 {
+		t.Errorf("expected the stale load not to be served after the revoke, got %v", got)
+	}
+}
+
+func TestGrantCache_BypassedWithoutVersion(t *testing.T) {
+	cache, mr := newTestGrantCache(t)
+	fetches := 0
+	fetch := func() ([]string, error) {
+		fetches++
+		return []string{"users:read"}, nil
+	}
+
+	mr.Close()
+	for i := 0; i < 2; i++ {
+		if got, err := loadGrants(cache, "alice", "roles", fixedTTL, fetch); err != nil || len(got) != 1 {
+			t.Fatalf("expected the fetched value without Redis, got %v, %v", got, err)
+		}
+	}
+	if fetches != 2 {
+		t.Errorf("expected every read to fetch while Redis is unavailable, fetched %d times", fetches)
+	}
+}
+
+func TestGrantCache_StaleEntryNotServedAfterVersionExpiry(t *testing.T) {
+	cache, mr := newTestGrantCache(t)
+	stored := []string{"users:delete"}
+	longTTL := func() time.Duration { return 3 * time.Hour }
+
+	// A reader racing a revoke stores the old permissions under the version it resolved
+	cache.invalidate([]string{"alice"})
+	_, _ = loadGrants(cache, "alice", "permissions", longTTL, func() ([]string, error) {
+		old := stored
+		stored = nil
+		cache.invalidate([]string{"alice"})
+		return old, nil
+	})
+
+	// The version counter expires and a later change creates it again
+	mr.FastForward(2 * time.Hour)
+	if err := cache.versions.Bump("alice"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, _ := loadGrants(cache, "alice", "permissions", fixedTTL, func() ([]string, error) {
+		return stored, nil
+	})
+	if len(got) != 0 {
+		t.Errorf("expected the stale entry not to be served under a new version, got %v", got)
+	}
+}
+
+func TestRBACService_RevokeAppliesOnNextCheck(t *testing.T) {
+	cache, _ := newTestGrantCache(t)
+	db := &scriptedDB{
+		granted: map[string]string{"perm-read": "users:read", "perm-delete": "users:delete"},
+		holders: []string{"alice"},
+	}
+	repo := NewRBACRepository(sql.OpenDB(db), cache.cacheHelper, cache.versions)
+	useCase := NewRBACUseCase(repo)
+
+	for i := 0; i < 2; i++ {
+		if has, err := useCase.CheckUserPermission("alice", "users:delete"); err != nil || !has {
+			t.Fatalf("expected the granted permission to be allowed, got %v, %v", has, err)
+		}
+	}
+	if db.loads != 1 {
+		t.Fatalf("expected the second check to be served from cache, loaded %d times", db.loads)
+	}
+
+	if err := useCase.RemovePermissionFromRole("role-1", "perm-delete"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if has, err := useCase.CheckUserPermission("alice", "users:delete"); err != nil || has {
+		t.Errorf("expected the revoked permission to be denied on the next check, got %v, %v", has, err)
+	}
+	if has, _ := useCase.CheckUserPermission("alice", "users:read"); !has {
+		t.Error("expected the remaining permission to be allowed")
+	}
+}
+
+// scriptedDB is a database/sql connector answering the statements the repository issues to check
+// and revoke permissions and to sweep expired roles: every holder is granted the permissions in
+// granted through one role, and the expired assignments belong to expired
+type scriptedDB struct {
+	granted map[string]string // permission ID -> name
+	holders []string
+	expired []string
+	loads   int
+	// log lists the statements run, with BEGIN, COMMIT and ROLLBACK for transactions
+	log []string
+	// actors lists the actor IDs named through the change history settings
+	actors []string
+}
+
+func (d *scriptedDB) Connect(context.Context) (driver.Conn, error) { return scriptedConn{d}, nil }
+func (d *scriptedDB) Driver() driver.Driver                        { return d }
+func (d *scriptedDB) Open(string) (driver.Conn, error)             { return scriptedConn{d}, nil }
+
+type scriptedConn struct{ db *scriptedDB }
+
+func (c scriptedConn) Prepare(query string) (driver.Stmt, error) {
+	return scriptedStmt{db: c.db, query: query}, nil
+}
+func (c scriptedConn) Close() error { return nil }
+func (c scriptedConn) Begin() (driver.Tx, error) {
+	c.db.log = append(c.db.log, "BEGIN")
+	return scriptedTx{c.db}, nil
+}
+
+type scriptedTx struct{ db *scriptedDB }
+
+func (t scriptedTx) Commit() error {
+	t.db.log = append(t.db.log, "COMMIT")
+	return nil
+}
+
+func (t scriptedTx) Rollback() error {
+	t.db.log = append(t.db.log, "ROLLBACK")
+	return nil
+}
+
+type scriptedStmt struct {
+	db    *scriptedDB
+	query string
+}
+
+func (s scriptedStmt) Close() error  { return nil }
+func (s scriptedStmt) NumInput() int { return -1 }
+
+func (s scriptedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.log = append(s.db.log, s.query)
+	switch {
+	case s.query == actorSettingsSQL:
+		s.db.actors = append(s.db.actors, args[0].(string))
+		return driver.RowsAffected(0), nil
+	case strings.Contains(s.query, "DELETE FROM role_permissions"):
+		delete(s.db.granted, args[1].(string))
+		return driver.RowsAffected(1), nil
+	}
+	return nil, fmt.Errorf("unexpected statement: %s", s.query)
+}
+
+func (s scriptedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.log = append(s.db.log, s.query)
+	switch {
+	case strings.Contains(s.query, "WITH expired"):
+		rows := &scriptedRows{columns: []string{"user_id"}}
+		for _, userID := range s.db.expired {
+			rows.rows = append(rows.rows, []driver.Value{userID})
+		}
+		s.db.expired = nil
+		return rows, nil
+	case strings.Contains(s.query, "MIN(boundary)"):
+		return &scriptedRows{columns: []string{"min"}, rows: [][]driver.Value{{nil}}}, nil
+	case strings.Contains(s.query, "role_permission_denies"):
+		return &scriptedRows{columns: []string{"pattern", "role"}}, nil
+	case strings.Contains(s.query, "role_permissions rp"):
+		s.db.loads++
+		rows := &scriptedRows{columns: []string{"id", "name", "description", "resource", "action", "is_system", "created_at"}}
+		for id, name := range s.db.granted {
+			resource, action, _ := splitPermissionName(name)
+			rows.rows = append(rows.rows, []driver.Value{id, name, nil, resource, action, false, time.Now()})
+		}
+		slices.SortFunc(rows.rows, func(a, b []driver.Value) int { return strings.Compare(a[1].(string), b[1].(string)) })
+		return rows, nil
+	case strings.Contains(s.query, "descendants"):
+		rows := &scriptedRows{columns: []string{"user_id"}}
+		for _, userID := range s.db.holders {
+			rows.rows = append(rows.rows, []driver.Value{userID})
+		}
+		return rows, nil
+	}
+	return nil, fmt.Errorf("unexpected query: %s", s.query)
+}
+
+type scriptedRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *scriptedRows) Columns() []string { return r.columns }
+func (r *scriptedRows) Close() error      { return nil }
+
+func (r *scriptedRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
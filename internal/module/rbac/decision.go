@@ -0,0 +1,48 @@
+package rbac
+
+// PermissionDecision is the outcome of checking one permission for a user
+type PermissionDecision struct {
+	Permission string
+	Allowed    bool
+	// MatchedBy is the most specific held pattern covering the permission, when allowed
+	MatchedBy string
+	// Deny is the rule that refused the permission, overriding any grant
+	Deny *DenyRule
+	// Grants is filled only when explaining: the user's roles that grant the permission, even
+	// when a deny overrides them, or, when it is not granted, the roles that would grant it
+	Grants []PermissionGrant
+}
+
+// PermissionGrant explains how a role covers a permission
+type PermissionGrant struct {
+	// Role is the role that is or would be assigned to the user
+	Role string
+	// Path lists role names from Role up through its ancestors to the role holding Pattern
+	Path []string
+	// Pattern is the permission, possibly a wildcard, held by the last role of Path
+	Pattern string
+	// Assigned reports whether the user holds Role
+	Assigned bool
+	// OrganizationID is set when Role is assigned inside the active organization only
+	OrganizationID string
+	// Group is set when the user holds Role only through membership of this group
+	Group string
+}
+
+// explainGrant finds the closest role in chain, starting with the role itself and followed by
+// its ancestors nearest first, whose own permissions cover the required permission
+func explainGrant(chain []Role, rolePermissions map[string][]string, required string) (PermissionGrant, bool) {
+	for i, role := range chain {
+		pattern, ok := BestMatch(rolePermissions[role.ID], required)
+		if !ok {
+			continue
+		}
+
+		path := make([]string, i+1)
+		for j := range path {
+			path[j] = chain[j].Name
+		}
+		return PermissionGrant{Role: chain[0].Name, Path: path, Pattern: pattern}, true
+	}
+	return PermissionGrant{}, false
+}
@@ -0,0 +1,307 @@
+package rbac
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"boilerplate-be/internal/shared/errors"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Policy document formats accepted by ParsePolicyDocument and PolicyDocument.Encode
+const (
+	PolicyFormatYAML = "yaml"
+	PolicyFormatJSON = "json"
+)
+
+// PolicyDocument declares roles, permissions and role-permission mappings as code. A document
+// is self-contained: role parents and role permissions and denies must be declared in it.
+type PolicyDocument struct {
+	Permissions []PermissionSpec `json:"permissions" yaml:"permissions"`
+	Roles       []RoleSpec       `json:"roles" yaml:"roles"`
+}
+
+// PermissionSpec declares a permission by its resource:action name; an empty description
+// leaves the stored one unchanged
+type PermissionSpec struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// RoleSpec declares a role, the role it inherits from, its directly assigned permissions and the
+// permissions it denies; an empty description leaves the stored one unchanged, an empty parent
+// detaches the role
+type RoleSpec struct {
+	Name        string   `json:"name" yaml:"name"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Parent      string   `json:"parent,omitempty" yaml:"parent,omitempty"`
+	Permissions []string `json:"permissions,omitempty" yaml:"permissions,omitempty"`
+	Deny        []string `json:"deny,omitempty" yaml:"deny,omitempty"`
+}
+
+// ApplyOptions controls how ApplyPolicy reconciles the stored state with a document
+type ApplyOptions struct {
+	// Prune deletes roles, permissions, role-permission mappings and role denies missing from
+	// the document; built-in roles and permissions are always kept
+	Prune bool
+	// DryRun makes every change inside the transaction and then rolls it back
+	DryRun bool
+}
+
+// ChangeAction is what a PolicyChange does to its object
+type ChangeAction string
+
+const (
+	ChangeCreate ChangeAction = "create"
+	ChangeUpdate ChangeAction = "update"
+	ChangeDelete ChangeAction = "delete"
+)
+
+// Kinds of objects a PolicyChange applies to
+const (
+	ChangeKindPermission     = "permission"
+	ChangeKindRole           = "role"
+	ChangeKindRoleParent     = "role_parent"
+	ChangeKindRolePermission = "role_permission"
+	ChangeKindRoleDeny       = "role_deny"
+)
+
+// PolicyChange is one step towards the document's state. Name is the permission or role name;
+// Detail is the new description, the new parent, or the mapped or denied permission.
+type PolicyChange struct {
+	Action ChangeAction `json:"action"`
+	Kind   string       `json:"kind"`
+	Name   string       `json:"name"`
+	Detail string       `json:"detail,omitempty"`
+}
+
+func (c PolicyChange) String() string {
+	if c.Detail == "" {
+		return fmt.Sprintf("%s %s %s", c.Action, c.Kind, c.Name)
+	}
+	return fmt.Sprintf("%s %s %s: %s", c.Action, c.Kind, c.Name, c.Detail)
+}
+
+// PolicyPlan lists the changes ApplyPolicy made, or would make on a dry run
+type PolicyPlan struct {
+	DryRun  bool           `json:"dry_run"`
+	Changes []PolicyChange `json:"changes"`
+}
+
+// applyOrder is the order changes are planned and applied in: objects exist before they are
+// referenced and references are dropped before their objects are deleted
+var applyOrder = []struct {
+	kind   string
+	action ChangeAction
+}{
+	{ChangeKindPermission, ChangeCreate},
+	{ChangeKindPermission, ChangeUpdate},
+	{ChangeKindRole, ChangeCreate},
+	{ChangeKindRole, ChangeUpdate},
+	{ChangeKindRoleParent, ChangeUpdate},
+	{ChangeKindRolePermission, ChangeCreate},
+	{ChangeKindRoleDeny, ChangeCreate},
+	{ChangeKindRolePermission, ChangeDelete},
+	{ChangeKindRoleDeny, ChangeDelete},
+	{ChangeKindRole, ChangeDelete},
+	{ChangeKindPermission, ChangeDelete},
+}
+
+// ParsePolicyDocument decodes a YAML or JSON document, rejecting unknown fields
+func ParsePolicyDocument(data []byte, format string) (*PolicyDocument, error) {
+	var document PolicyDocument
+	var err error
+
+	switch format {
+	case PolicyFormatYAML:
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&document)
+	case PolicyFormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&document)
+	default:
+		err = fmt.Errorf("unsupported format %q", format)
+	}
+
+	if err != nil {
+		return nil, errors.WrapWithDetails(err, errors.InvalidPolicyDocument, []errors.ValidationErrorDetails{
+			{Field: "document", Message: err.Error()},
+		})
+	}
+	return &document, nil
+}
+
+// Encode serializes the document as YAML or JSON
+func (d *PolicyDocument) Encode(format string) ([]byte, error) {
+	switch format {
+	case PolicyFormatYAML:
+		return yaml.Marshal(d)
+	case PolicyFormatJSON:
+		return json.MarshalIndent(d, "", "  ")
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// Validate reports malformed, duplicate and undeclared names
+func (d *PolicyDocument) Validate() []errors.ValidationErrorDetails {
+	var problems []errors.ValidationErrorDetails
+	report := func(field, format string, args ...interface{}) {
+		problems = append(problems, errors.ValidationErrorDetails{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	permissions := make(map[string]bool, len(d.Permissions))
+	for i, permission := range d.Permissions {
+		field := fmt.Sprintf("permissions[%d].name", i)
+		if _, _, ok := splitPermissionName(permission.Name); !ok {
+			report(field, "%q is not a resource:action name", permission.Name)
+		} else if permissions[permission.Name] {
+			report(field, "permission %q is declared twice", permission.Name)
+		}
+		permissions[permission.Name] = true
+	}
+
+	roles := make(map[string]bool, len(d.Roles))
+	for i, role := range d.Roles {
+		field := fmt.Sprintf("roles[%d].name", i)
+		if strings.TrimSpace(role.Name) == "" {
+			report(field, "role name is required")
+		} else if roles[role.Name] {
+			report(field, "role %q is declared twice", role.Name)
+		}
+		roles[role.Name] = true
+	}
+
+	for i, role := range d.Roles {
+		if role.Parent != "" && !roles[role.Parent] {
+			report(fmt.Sprintf("roles[%d].parent", i), "parent role %q is not declared", role.Parent)
+		}
+		for j, name := range role.Permissions {
+			if !permissions[name] {
+				report(fmt.Sprintf("roles[%d].permissions[%d]", i, j), "permission %q is not declared", name)
+			}
+		}
+		for j, name := range role.Deny {
+			if !permissions[name] {
+				report(fmt.Sprintf("roles[%d].deny[%d]", i, j), "permission %q is not declared", name)
+			}
+		}
+	}
+
+	return problems
+}
+
+// splitPermissionName splits a permission name at its last colon, so orgs:members:read has
+// resource orgs:members and action read
+func splitPermissionName(name string) (resource, action string, ok bool) {
+	i := strings.LastIndex(name, ":")
+	if i <= 0 || i == len(name)-1 {
+		return "", "", false
+	}
+	return name[:i], name[i+1:], true
+}
+
+// diffPolicy plans the changes that turn current into desired, in applyOrder. Objects for which
+// protected returns true are never deleted.
+func diffPolicy(current, desired *PolicyDocument, prune bool, protected func(kind, name string) bool) []PolicyChange {
+	var changes []PolicyChange
+	add := func(action ChangeAction, kind, name, detail string) {
+		changes = append(changes, PolicyChange{Action: action, Kind: kind, Name: name, Detail: detail})
+	}
+
+	currentPermissions := make(map[string]PermissionSpec, len(current.Permissions))
+	for _, permission := range current.Permissions {
+		currentPermissions[permission.Name] = permission
+	}
+	desiredPermissions := make(map[string]bool, len(desired.Permissions))
+	for _, permission := range desired.Permissions {
+		desiredPermissions[permission.Name] = true
+		stored, exists := currentPermissions[permission.Name]
+		switch {
+		case !exists:
+			add(ChangeCreate, ChangeKindPermission, permission.Name, permission.Description)
+		case permission.Description != "" && permission.Description != stored.Description:
+			add(ChangeUpdate, ChangeKindPermission, permission.Name, permission.Description)
+		}
+	}
+
+	// diffMappings plans the changes to one role's permissions or denies
+	diffMappings := func(kind, role string, stored, desired []string) {
+		held := make(map[string]bool, len(stored))
+		for _, name := range stored {
+			held[name] = true
+		}
+		declared := make(map[string]bool, len(desired))
+		for _, name := range desired {
+			if !held[name] && !declared[name] {
+				add(ChangeCreate, kind, role, name)
+			}
+			declared[name] = true
+		}
+		if prune {
+			for _, name := range stored {
+				if !declared[name] {
+					add(ChangeDelete, kind, role, name)
+				}
+			}
+		}
+	}
+
+	currentRoles := make(map[string]RoleSpec, len(current.Roles))
+	for _, role := range current.Roles {
+		currentRoles[role.Name] = role
+	}
+	desiredRoles := make(map[string]bool, len(desired.Roles))
+	for _, role := range desired.Roles {
+		desiredRoles[role.Name] = true
+		stored, exists := currentRoles[role.Name]
+		switch {
+		case !exists:
+			add(ChangeCreate, ChangeKindRole, role.Name, role.Description)
+		case role.Description != "" && role.Description != stored.Description:
+			add(ChangeUpdate, ChangeKindRole, role.Name, role.Description)
+		}
+
+		if role.Parent != stored.Parent {
+			add(ChangeUpdate, ChangeKindRoleParent, role.Name, role.Parent)
+		}
+
+		diffMappings(ChangeKindRolePermission, role.Name, stored.Permissions, role.Permissions)
+		diffMappings(ChangeKindRoleDeny, role.Name, stored.Deny, role.Deny)
+	}
+
+	if prune {
+		for _, role := range current.Roles {
+			if !desiredRoles[role.Name] && !protected(ChangeKindRole, role.Name) {
+				add(ChangeDelete, ChangeKindRole, role.Name, "")
+			}
+		}
+		for _, permission := range current.Permissions {
+			if !desiredPermissions[permission.Name] && !protected(ChangeKindPermission, permission.Name) {
+				add(ChangeDelete, ChangeKindPermission, permission.Name, "")
+			}
+		}
+	}
+
+	// Stable sort keeps document order within each step
+	sort.SliceStable(changes, func(i, j int) bool {
+		return applyStep(changes[i]) < applyStep(changes[j])
+	})
+	return changes
+}
+
+// applyStep is the change's position in applyOrder
+func applyStep(change PolicyChange) int {
+	for i, step := range applyOrder {
+		if step.kind == change.Kind && step.action == change.Action {
+			return i
+		}
+	}
+	return len(applyOrder)
+}
@@ -0,0 +1,144 @@
+package rbac
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePolicyDocument(t *testing.T) {
+	yamlDocument := `
+permissions:
+  - name: reports:read
+    description: View reports
+roles:
+  - name: analyst
+    parent: user
+    permissions: [reports:read]
+`
+	document, err := ParsePolicyDocument([]byte(yamlDocument), PolicyFormatYAML)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := &PolicyDocument{
+		Permissions: []PermissionSpec{{Name: "reports:read", Description: "View reports"}},
+		Roles:       []RoleSpec{{Name: "analyst", Parent: "user", Permissions: []string{"reports:read"}}},
+	}
+	if !reflect.DeepEqual(document, want) {
+		t.Errorf("ParsePolicyDocument() = %+v, want %+v", document, want)
+	}
+
+	// Round trip through JSON
+	encoded, err := document.Encode(PolicyFormatJSON)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	decoded, err := ParsePolicyDocument(encoded, PolicyFormatJSON)
+	if err != nil || !reflect.DeepEqual(decoded, want) {
+		t.Errorf("JSON round trip = %+v, %v", decoded, err)
+	}
+
+	// Typos are rejected rather than silently ignored
+	if _, err := ParsePolicyDocument([]byte("roles:\n  - name: analyst\n    permisions: []\n"), PolicyFormatYAML); err == nil {
+		t.Error("expected unknown YAML field to be rejected")
+	}
+	if _, err := ParsePolicyDocument([]byte(`{"roles": [], "extra": true}`), PolicyFormatJSON); err == nil {
+		t.Error("expected unknown JSON field to be rejected")
+	}
+	if _, err := ParsePolicyDocument([]byte(`{}`), "toml"); err == nil {
+		t.Error("expected unsupported format to be rejected")
+	}
+}
+
+func TestPolicyDocument_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		document PolicyDocument
+		fields   []string
+	}{
+		{
+			name: "valid",
+			document: PolicyDocument{
+				Permissions: []PermissionSpec{{Name: "orgs:members:read"}},
+				Roles:       []RoleSpec{{Name: "user"}, {Name: "auditor", Parent: "user", Permissions: []string{"orgs:members:read"}}},
+			},
+		},
+		{
+			name:     "malformed permission names",
+			document: PolicyDocument{Permissions: []PermissionSpec{{Name: "reports"}, {Name: ":read"}, {Name: "reports:"}}},
+			fields:   []string{"permissions[0].name", "permissions[1].name", "permissions[2].name"},
+		},
+		{
+			name: "duplicates",
+			document: PolicyDocument{
+				Permissions: []PermissionSpec{{Name: "a:b"}, {Name: "a:b"}},
+				Roles:       []RoleSpec{{Name: "x"}, {Name: "x"}, {Name: " "}},
+			},
+			fields: []string{"permissions[1].name", "roles[1].name", "roles[2].name"},
+		},
+		{
+			name:     "undeclared references",
+			document: PolicyDocument{Roles: []RoleSpec{{Name: "x", Parent: "y", Permissions: []string{"a:b"}, Deny: []string{"c:d"}}}},
+			fields:   []string{"roles[0].parent", "roles[0].permissions[0]", "roles[0].deny[0]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields []string
+			for _, problem := range tt.document.Validate() {
+				fields = append(fields, problem.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("Validate() fields = %v, want %v", fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestDiffPolicy(t *testing.T) {
+	current := &PolicyDocument{
+		Permissions: []PermissionSpec{{Name: "*:*"}, {Name: "old:read"}, {Name: "users:read", Description: "View users"}},
+		Roles: []RoleSpec{
+			{Name: "legacy", Permissions: []string{"old:read"}},
+			{Name: "super_admin", Permissions: []string{"*:*"}},
+			{Name: "user", Permissions: []string{"users:read"}, Deny: []string{"old:read"}},
+		},
+	}
+	desired := &PolicyDocument{
+		Permissions: []PermissionSpec{{Name: "users:read", Description: "List users"}, {Name: "reports:read"}},
+		Roles: []RoleSpec{
+			{Name: "user", Permissions: []string{"reports:read"}, Deny: []string{"users:read"}},
+			{Name: "analyst", Parent: "user"},
+		},
+	}
+	protected := func(kind, name string) bool {
+		return (kind == ChangeKindRole && IsBuiltInRole(name)) || (kind == ChangeKindPermission && name == "*:*")
+	}
+
+	want := []PolicyChange{
+		{Action: ChangeCreate, Kind: ChangeKindPermission, Name: "reports:read"},
+		{Action: ChangeUpdate, Kind: ChangeKindPermission, Name: "users:read", Detail: "List users"},
+		{Action: ChangeCreate, Kind: ChangeKindRole, Name: "analyst"},
+		{Action: ChangeUpdate, Kind: ChangeKindRoleParent, Name: "analyst", Detail: "user"},
+		{Action: ChangeCreate, Kind: ChangeKindRolePermission, Name: "user", Detail: "reports:read"},
+		{Action: ChangeCreate, Kind: ChangeKindRoleDeny, Name: "user", Detail: "users:read"},
+		{Action: ChangeDelete, Kind: ChangeKindRolePermission, Name: "user", Detail: "users:read"},
+		{Action: ChangeDelete, Kind: ChangeKindRoleDeny, Name: "user", Detail: "old:read"},
+		{Action: ChangeDelete, Kind: ChangeKindRole, Name: "legacy"},
+		{Action: ChangeDelete, Kind: ChangeKindPermission, Name: "old:read"},
+	}
+	if got := diffPolicy(current, desired, true, protected); !reflect.DeepEqual(got, want) {
+		t.Errorf("diffPolicy(prune) =\n%v\nwant\n%v", got, want)
+	}
+
+	// Without prune nothing is deleted
+	for _, change := range diffPolicy(current, desired, false, protected) {
+		if change.Action == ChangeDelete {
+			t.Errorf("unexpected change without prune: %s", change)
+		}
+	}
+
+	if got := diffPolicy(current, current, true, protected); len(got) != 0 {
+		t.Errorf("expected no changes against itself, got %v", got)
+	}
+}
@@ -1,5 +1,10 @@
 package rbac
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // RBACRepository defines the data access layer for RBAC operations
 type RBACRepository interface {
 	// Role operations
@@ -10,26 +15,87 @@ type RBACRepository interface {
 	UpdateRole(role *Role) error
 	DeleteRole(id string) error
 
+	// Role hierarchy operations
+	SetRoleParent(roleID, parentID string) error
+	GetRoleAncestors(roleID string) ([]Role, error)
+	// GetRoleHeight returns how many levels of descendants the role has; 0 for a role no role inherits from
+	GetRoleHeight(roleID string) (int, error)
+
 	// Permission operations
 	GetPermissions() ([]Permission, error)
 	GetPermissionByID(id string) (*Permission, error)
 	GetPermissionByName(name string) (*Permission, error)
 	CreatePermission(permission *Permission) error
+	UpdatePermission(permission *Permission) error
+	// MarkPermissionSystem flags the permission as built-in, protecting it from deletion
+	MarkPermissionSystem(id string) error
+	DeletePermission(id string) error
 
 	// User-Role operations
 	GetUserRoles(userID string) ([]Role, error)
-	AssignRoleToUser(userID, roleID string) error
+	AssignRoleToUser(userID, roleID string, validity RoleValidity) error
 	RemoveRoleFromUser(userID, roleID string) error
+	DeleteExpiredUserRoles() ([]string, error)
 	HasRole(userID, roleName string) (bool, error)
 
+	// Tenant-scoped User-Role operations
+	GetUserRolesInTenant(userID, organizationID string) ([]Role, error)
+	AssignRoleToUserInTenant(userID, roleID, organizationID string) error
+	RemoveRoleFromUserInTenant(userID, roleID, organizationID string) error
+
+	// Group operations
+	GetGroups() ([]Group, error)
+	GetGroupByID(id string) (*Group, error)
+	CreateGroup(group *Group) error
+	UpdateGroup(group *Group) error
+	DeleteGroup(id string) error
+	GetGroupMembers(groupID string) ([]GroupMember, error)
+	AddUserToGroup(groupID, userID string) error
+	RemoveUserFromGroup(groupID, userID string) error
+	GetUserGroups(userID string) ([]Group, error)
+	GetGroupRoles(groupID string) ([]Role, error)
+	AssignRoleToGroup(groupID, roleID string) error
+	RemoveRoleFromGroup(groupID, roleID string) error
+
 	// Role-Permission operations
 	GetRolePermissions(roleID string) ([]Permission, error)
 	AssignPermissionToRole(roleID, permissionID string) error
 	RemovePermissionFromRole(roleID, permissionID string) error
 
+	// Deny operations; a deny overrides any grant covering the same permission
+	GetRoleDenies(roleID string) ([]Permission, error)
+	AddDenyToRole(roleID, permissionID string) error
+	RemoveDenyFromRole(roleID, permissionID string) error
+	GetUserDenies(userID string) ([]Permission, error)
+	AddDenyToUser(userID, permissionID string) error
+	RemoveDenyFromUser(userID, permissionID string) error
+	GetUserDenyRules(userID, organizationID string) ([]DenyRule, error)
+
+	// Separation of duty operations
+	GetRoleConflictSets() ([]RoleConflictSet, error)
+	GetRoleConflictSetByID(id string) (*RoleConflictSet, error)
+	CreateRoleConflictSet(set *RoleConflictSet) error
+	DeleteRoleConflictSet(id string) error
+	GetRoleConflictViolations() ([]RoleConflictViolation, error)
+
+	// Delegated administration operations
+	GetRoleManagers(roleID string) ([]Role, error)
+	SetRoleManagers(roleID string, managerRoleIDs []string) error
+	GetManagedRoles(managerRoleIDs []string) ([]Role, error)
+
 	// User permission check (aggregated from all user's roles)
 	GetUserPermissions(userID string) ([]Permission, error)
+	GetUserPermissionsInTenant(userID, organizationID string) ([]Permission, error)
 	HasPermission(userID, permissionName string) (bool, error)
+
+	// Change history operations; changes are recorded by database triggers
+	WithActor(actor ChangeActor) RBACRepository
+	GetChanges(filter ChangeFilter) ([]ChangeRecord, int64, error)
+	GetHistoryState(userID string) (map[string][]json.RawMessage, error)
+	GetUserChangesSince(userID string, since time.Time) ([]ChangeRecord, error)
+
+	// WithTransaction runs fn against a repository whose operations share one transaction
+	WithTransaction(fn func(repo RBACRepository) error) error
 }
 
 // RBACUseCase defines the business logic for RBAC operations
@@ -41,22 +107,100 @@ type RBACUseCase interface {
 	UpdateRole(id, name, description string) (*Role, error)
 	DeleteRole(id string) error
 
+	// Role hierarchy operations; a chain of inheriting roles is at most MaxRoleDepth roles deep
+	SetRoleParent(roleID, parentID string) (*Role, error)
+	GetRoleAncestors(roleID string) ([]Role, error)
+
 	// Permission operations
 	GetPermissions() ([]Permission, error)
+	CreatePermission(name, resource, action, description string) (*Permission, error)
+	// SeedPermissions creates the declared permissions that do not exist yet and returns them;
+	// every declared permission becomes built-in, so it cannot be deleted or pruned
+	SeedPermissions(permissions []PermissionSpec) ([]Permission, error)
+	UpdatePermission(id, name, resource, action, description string) (*Permission, error)
+	DeletePermission(id string) error
 
 	// User-Role operations
 	GetUserRoles(userID string) ([]Role, error)
-	AssignRoleToUser(userID, roleID string) error
+	AssignRoleToUser(userID, roleID string, validity RoleValidity) error
+	AssignRolesByName(userID string, roleNames []string) error
+	RemoveRolesByName(userID string, roleNames []string) error
 	RemoveRoleFromUser(userID, roleID string) error
+	SweepExpiredRoles() (int, error)
+
+	// Tenant-scoped User-Role operations
+	GetUserRolesInTenant(userID, organizationID string) ([]Role, error)
+	AssignRoleToUserInTenant(userID, roleID, organizationID string) error
+	RemoveRoleFromUserInTenant(userID, roleID, organizationID string) error
+
+	// Group operations; members hold the group's roles globally
+	GetGroups() ([]Group, error)
+	GetGroupByID(id string) (*Group, error)
+	CreateGroup(name, description string) (*Group, error)
+	UpdateGroup(id, name, description string) (*Group, error)
+	DeleteGroup(id string) error
+	GetGroupMembers(groupID string) ([]GroupMember, error)
+	AddUserToGroup(groupID, userID string) error
+	RemoveUserFromGroup(groupID, userID string) error
+	GetUserGroups(userID string) ([]Group, error)
+	GetGroupRoles(groupID string) ([]Role, error)
+	AssignRoleToGroup(groupID, roleID string) error
+	RemoveRoleFromGroup(groupID, roleID string) error
 
 	// Role-Permission operations
 	GetRolePermissions(roleID string) ([]Permission, error)
 	AssignPermissionToRole(roleID, permissionID string) error
 	RemovePermissionFromRole(roleID, permissionID string) error
 
-	// Permission checking
+	// Deny operations; a deny overrides any grant covering the same permission
+	GetRoleDenies(roleID string) ([]Permission, error)
+	AddDenyToRole(roleID, permissionID string) error
+	RemoveDenyFromRole(roleID, permissionID string) error
+	GetUserDenies(userID string) ([]Permission, error)
+	AddDenyToUser(userID, permissionID string) error
+	RemoveDenyFromUser(userID, permissionID string) error
+	// GetUserDenyRules lists the role and per-user deny rules that apply to the user
+	GetUserDenyRules(userID, organizationID string) ([]DenyRule, error)
+
+	// Separation of duty operations; role assignments that would give a user two roles of one
+	// conflict set fail with SeparationOfDutyViolation
+	GetRoleConflictSets() ([]RoleConflictSet, error)
+	GetRoleConflictSetByID(id string) (*RoleConflictSet, error)
+	CreateRoleConflictSet(name, description string, roleIDs []string) (*RoleConflictSet, error)
+	DeleteRoleConflictSet(id string) error
+	GetRoleConflictViolations() ([]RoleConflictViolation, error)
+
+	// Delegated administration; holders of a manager role may assign and remove the roles it
+	// manages, but only roles whose every permission they hold themselves
+	GetRoleManagers(roleID string) ([]Role, error)
+	SetRoleManagers(roleID string, managerRoleIDs []string) ([]Role, error)
+	GetManageableRoles(managerID string) ([]Role, error)
+	AssignManagedRole(managerID, userID, roleID string, validity RoleValidity) error
+	RemoveManagedRole(managerID, userID, roleID string) error
+
+	// Permission checking; denies win over grants
 	CheckUserRole(userID string, roles ...string) (bool, error)
 	CheckUserPermission(userID string, permissions ...string) (bool, error)
 	GetUserPermissions(userID string) ([]Permission, error)
+	CheckUserPermissionInTenant(userID, organizationID string, permissions ...string) (bool, error)
+	GetUserPermissionsInTenant(userID, organizationID string) ([]Permission, error)
+	// CheckPermissions decides each permission separately; organizationID may be empty
+	CheckPermissions(userID, organizationID string, permissions []string) ([]PermissionDecision, error)
+	// ExplainPermissions is CheckPermissions with the roles that grant, or would grant, each permission
+	ExplainPermissions(userID, organizationID string, permissions []string) ([]PermissionDecision, error)
+	// TokenAuthorization lists global role names, compacted permissions and deny patterns for access tokens
+	TokenAuthorization(userID, organizationID string) (roles []string, permissions []string, denies []string, err error)
+
+	// Change history; WithActor returns a use case whose changes are recorded as made by the actor
+	WithActor(actor ChangeActor) RBACUseCase
+	GetChanges(filter ChangeFilter) ([]ChangeRecord, int64, error)
+	// GetPermissionsAt reconstructs what the user could do at the time; organizationID may be empty
+	GetPermissionsAt(userID, organizationID string, at time.Time) (*PermissionSnapshot, error)
+	DiffPermissions(userID, organizationID string, from, to time.Time) (*PermissionDiff, error)
+
+	// Policy document operations
+	ExportPolicy() (*PolicyDocument, error)
+	// ApplyPolicy makes the stored state match the document in one transaction and returns the changes
+	ApplyPolicy(document *PolicyDocument, options ApplyOptions) (*PolicyPlan, error)
 }
 
@@ -4,12 +4,45 @@ import (
 	"time"
 )
 
-// Role represents a user role in the system
+// MaxRoleDepth is the deepest role hierarchy followed when resolving inherited permissions
+const MaxRoleDepth = 32
+
+// RoleOrgAdmin is granted inside an organization to the user who creates it
+const RoleOrgAdmin = "org_admin"
+
+// IsBuiltInRole reports whether the role is seeded by migration and referenced by name in code;
+// built-in roles cannot be deleted
+func IsBuiltInRole(name string) bool {
+	return name == "super_admin" || name == "user" || name == RoleOrgAdmin
+}
+
+// Role represents a user role in the system; a role inherits the permissions of its parent chain
 type Role struct {
 	ID          string    `json:"id"`
 	Name        string    `json:"name"`
 	Description string    `json:"description,omitempty"`
+	ParentID    string    `json:"parent_id,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
+
+	// ValidFrom and ValidUntil bound a user's assignment of the role; set only on user roles
+	ValidFrom  *time.Time `json:"valid_from,omitempty"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
+	// Group names the group a user holds the role through; empty when assigned directly
+	Group string `json:"group,omitempty"`
+}
+
+// RoleValidity bounds when a role assignment is active; a nil bound is open
+type RoleValidity struct {
+	From  *time.Time
+	Until *time.Time
+}
+
+// ActiveAt reports whether an assignment with this validity is active at t
+func (v RoleValidity) ActiveAt(t time.Time) bool {
+	if v.From != nil && t.Before(*v.From) {
+		return false
+	}
+	return v.Until == nil || t.Before(*v.Until)
 }
 
 // Permission represents a permission that can be assigned to roles
@@ -19,14 +52,83 @@ type Permission struct {
 	Description string    `json:"description,omitempty"`
 	Resource    string    `json:"resource"`
 	Action      string    `json:"action"`
+	IsSystem    bool      `json:"is_system"`
 	CreatedAt   time.Time `json:"created_at"`
 }
 
+// PermissionName builds the canonical resource:action permission name
+func PermissionName(resource, action string) string {
+	return resource + ":" + action
+}
+
+// permissionNames lists the names of the given permissions
+func permissionNames(permissions []Permission) []string {
+	names := make([]string, len(permissions))
+	for i, permission := range permissions {
+		names[i] = permission.Name
+	}
+	return names
+}
+
+// DenyRule refuses a permission pattern whatever the user's grants. Role names the role the
+// rule is attached to, directly or through inheritance; it is empty for a per-user override.
+type DenyRule struct {
+	Pattern string `json:"pattern"`
+	Role    string `json:"role,omitempty"`
+}
+
+// denyPatterns lists the patterns of the given deny rules
+func denyPatterns(rules []DenyRule) []string {
+	patterns := make([]string, len(rules))
+	for i, rule := range rules {
+		patterns[i] = rule.Pattern
+	}
+	return patterns
+}
+
 // UserRole represents the many-to-many relationship between users and roles
 type UserRole struct {
-	UserID    string    `json:"user_id"`
-	RoleID    string    `json:"role_id"`
-	CreatedAt time.Time `json:"created_at"`
+	UserID     string     `json:"user_id"`
+	RoleID     string     `json:"role_id"`
+	ValidFrom  *time.Time `json:"valid_from,omitempty"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Group is a set of users that hold the group's roles for as long as they are members
+type Group struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// GroupMember is a user in a group
+type GroupMember struct {
+	UserID   string    `json:"user_id"`
+	Name     string    `json:"name"`
+	Email    string    `json:"email"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// RoleConflictSet is a static separation-of-duty constraint: no user may hold more than one of
+// its roles, directly, through a group or by inheritance
+type RoleConflictSet struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Roles       []Role    `json:"roles"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// RoleConflictViolation is a user who holds several roles of one conflict set
+type RoleConflictViolation struct {
+	ConflictSet string   `json:"conflict_set"`
+	UserID      string   `json:"user_id"`
+	Name        string   `json:"name"`
+	Email       string   `json:"email"`
+	Roles       []string `json:"roles"`
 }
 
 // RolePermission represents the many-to-many relationship between roles and permissions
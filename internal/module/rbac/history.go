@@ -0,0 +1,338 @@
+package rbac
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Actions recorded in the change history, as named by the database trigger
+const (
+	HistoryInsert = "INSERT"
+	HistoryUpdate = "UPDATE"
+	HistoryDelete = "DELETE"
+)
+
+// ChangeActor names the user and request behind RBAC changes; both are stored with every row
+// the changes touch
+type ChangeActor struct {
+	UserID    string
+	RequestID string
+}
+
+// SystemActorID is the actor_id of changes the application makes on its own, such as removing
+// expired role assignments
+const SystemActorID = "00000000-0000-0000-0000-000000000000"
+
+// SystemActor names the application as the actor of a background job's changes; the request ID
+// names the job and is unique per run
+func SystemActor(job string) ChangeActor {
+	return ChangeActor{UserID: SystemActorID, RequestID: job + ":" + uuid.New().String()}
+}
+
+// ChangeRecord is one row changed in an RBAC table, as it was before and after the change.
+// OldRow is null for inserts and NewRow for deletes.
+type ChangeRecord struct {
+	ID        int64           `json:"id"`
+	Table     string          `json:"table"`
+	Action    string          `json:"action"`
+	OldRow    json.RawMessage `json:"old_row"`
+	NewRow    json.RawMessage `json:"new_row"`
+	ActorID   string          `json:"actor_id,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
+	ChangedAt time.Time       `json:"changed_at"`
+}
+
+// ChangeFilter selects and pages the change history; zero values do not filter
+type ChangeFilter struct {
+	Table     string
+	ActorID   string
+	RequestID string
+	// UserID matches changes to the user's role assignments, denies and group memberships
+	UserID   string
+	Since    *time.Time
+	Until    *time.Time
+	Page     int
+	PageSize int
+}
+
+// PermissionSnapshot is what a user could do at a point in time: the roles they held directly
+// or through groups, and the permissions and denies of those roles and their ancestors
+type PermissionSnapshot struct {
+	UserID         string    `json:"user_id"`
+	OrganizationID string    `json:"organization_id,omitempty"`
+	At             time.Time `json:"at"`
+	Roles          []string  `json:"roles"`
+	Permissions    []string  `json:"permissions"`
+	Denies         []string  `json:"denies"`
+}
+
+// NameDiff lists the names gained and lost between two snapshots
+type NameDiff struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// PermissionDiff compares what a user could do at two points in time
+type PermissionDiff struct {
+	From        PermissionSnapshot `json:"from"`
+	To          PermissionSnapshot `json:"to"`
+	Roles       NameDiff           `json:"roles"`
+	Permissions NameDiff           `json:"permissions"`
+	Denies      NameDiff           `json:"denies"`
+}
+
+// historyKeys lists the tables a user's permissions are resolved from and the columns
+// identifying their rows
+var historyKeys = map[string][]string{
+	"roles":                  {"id"},
+	"permissions":            {"id"},
+	"role_permissions":       {"role_id", "permission_id"},
+	"role_permission_denies": {"role_id", "permission_id"},
+	"user_roles":             {"user_id", "role_id", "organization_id"},
+	"user_permission_denies": {"user_id", "permission_id"},
+	"group_members":          {"group_id", "user_id"},
+	"group_roles":            {"group_id", "role_id"},
+}
+
+// HistoryTables returns the tables a user's permissions are resolved from, sorted
+func HistoryTables() []string {
+	tables := make([]string, 0, len(historyKeys))
+	for table := range historyKeys {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+// isUserHistoryTable reports whether the table's rows belong to one user
+func isUserHistoryTable(table string) bool {
+	return table == "user_roles" || table == "user_permission_denies" || table == "group_members"
+}
+
+// historyRow is a table row decoded from JSON
+type historyRow map[string]interface{}
+
+func (r historyRow) text(column string) string {
+	if value, ok := r[column].(string); ok {
+		return value
+	}
+	return ""
+}
+
+// time parses a timestamp column; NULL and malformed values are nil
+func (r historyRow) time(column string) *time.Time {
+	parsed, err := time.Parse(time.RFC3339Nano, r.text(column))
+	if err != nil {
+		return nil
+	}
+	return &parsed
+}
+
+// historyState holds rows by table and key
+type historyState map[string]map[string]historyRow
+
+// newHistoryState decodes the current rows returned by GetHistoryState
+func newHistoryState(tables map[string][]json.RawMessage) (historyState, error) {
+	state := make(historyState, len(historyKeys))
+	for table := range historyKeys {
+		state[table] = make(map[string]historyRow)
+	}
+	for table, rows := range tables {
+		for _, data := range rows {
+			row, err := decodeHistoryRow(data)
+			if err != nil {
+				return nil, err
+			}
+			state.put(table, row)
+		}
+	}
+	return state, nil
+}
+
+func decodeHistoryRow(data json.RawMessage) (historyRow, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	var row historyRow
+	if err := json.Unmarshal(data, &row); err != nil {
+		return nil, fmt.Errorf("decode history row: %w", err)
+	}
+	return row, nil
+}
+
+func (s historyState) key(table string, row historyRow) string {
+	parts := make([]string, len(historyKeys[table]))
+	for i, column := range historyKeys[table] {
+		parts[i] = row.text(column)
+	}
+	return strings.Join(parts, "|")
+}
+
+func (s historyState) put(table string, row historyRow) {
+	if rows, ok := s[table]; ok && row != nil {
+		rows[s.key(table, row)] = row
+	}
+}
+
+func (s historyState) remove(table string, row historyRow) {
+	if rows, ok := s[table]; ok && row != nil {
+		delete(rows, s.key(table, row))
+	}
+}
+
+// undo reverts one change, turning the state after it into the state before it
+func (s historyState) undo(change ChangeRecord) error {
+	oldRow, err := decodeHistoryRow(change.OldRow)
+	if err != nil {
+		return err
+	}
+	newRow, err := decodeHistoryRow(change.NewRow)
+	if err != nil {
+		return err
+	}
+	// Removing the new row first also undoes updates that change the key
+	s.remove(change.Table, newRow)
+	s.put(change.Table, oldRow)
+	return nil
+}
+
+// snapshot resolves the user's roles, permissions and denies the way effectiveRolesSQL and
+// GetUserDenyRules do, with role validity checked at the snapshot's time
+func (s historyState) snapshot(userID, organizationID string, at time.Time) PermissionSnapshot {
+	held := make(map[string]bool)
+	for _, row := range s["user_roles"] {
+		if row.text("user_id") != userID {
+			continue
+		}
+		if org := row.text("organization_id"); org != "" && org != organizationID {
+			continue
+		}
+		validity := RoleValidity{From: row.time("valid_from"), Until: row.time("valid_until")}
+		if validity.ActiveAt(at) {
+			held[row.text("role_id")] = true
+		}
+	}
+	groups := make(map[string]bool)
+	for _, row := range s["group_members"] {
+		if row.text("user_id") == userID {
+			groups[row.text("group_id")] = true
+		}
+	}
+	for _, row := range s["group_roles"] {
+		if groups[row.text("group_id")] {
+			held[row.text("role_id")] = true
+		}
+	}
+
+	effective := make(map[string]bool)
+	for roleID := range held {
+		id := roleID
+		for depth := 0; id != "" && depth < MaxRoleDepth; depth++ {
+			role, ok := s["roles"][id]
+			if !ok {
+				break
+			}
+			effective[id] = true
+			id = role.text("parent_id")
+		}
+	}
+
+	snapshot := PermissionSnapshot{
+		UserID:         userID,
+		OrganizationID: organizationID,
+		At:             at,
+		Roles:          s.names("roles", held),
+		Permissions:    s.permissionNames("role_permissions", "role_id", effective),
+		Denies:         s.permissionNames("role_permission_denies", "role_id", effective),
+	}
+	userDenies := s.permissionNames("user_permission_denies", "user_id", map[string]bool{userID: true})
+	snapshot.Denies = mergeNames(snapshot.Denies, userDenies)
+	return snapshot
+}
+
+// names returns the sorted names of the table's rows with the given IDs
+func (s historyState) names(table string, ids map[string]bool) []string {
+	names := []string{}
+	for id := range ids {
+		if row, ok := s[table][id]; ok {
+			names = append(names, row.text("name"))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// permissionNames returns the sorted names of the permissions the mapping table links to the owners
+func (s historyState) permissionNames(table, ownerColumn string, owners map[string]bool) []string {
+	ids := make(map[string]bool)
+	for _, row := range s[table] {
+		if owners[row.text(ownerColumn)] {
+			ids[row.text("permission_id")] = true
+		}
+	}
+	return s.names("permissions", ids)
+}
+
+// mergeNames merges two sorted name lists, dropping duplicates
+func mergeNames(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	merged := []string{}
+	for _, name := range append(append([]string{}, a...), b...) {
+		if !seen[name] {
+			seen[name] = true
+			merged = append(merged, name)
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
+
+// diffNames lists the names in to but not from, and in from but not to
+func diffNames(from, to []string) NameDiff {
+	diff := NameDiff{Added: []string{}, Removed: []string{}}
+	inFrom := make(map[string]bool, len(from))
+	for _, name := range from {
+		inFrom[name] = true
+	}
+	inTo := make(map[string]bool, len(to))
+	for _, name := range to {
+		inTo[name] = true
+		if !inFrom[name] {
+			diff.Added = append(diff.Added, name)
+		}
+	}
+	for _, name := range from {
+		if !inTo[name] {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+	return diff
+}
+
+// reconstructPermissions takes the user's snapshots at each of the times by undoing, newest
+// first, the changes made after each time. changes must hold every change after the earliest
+// time, oldest first.
+func reconstructPermissions(state historyState, changes []ChangeRecord, userID, organizationID string, times ...time.Time) ([]PermissionSnapshot, error) {
+	order := make([]int, len(times))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return times[order[i]].After(times[order[j]]) })
+
+	snapshots := make([]PermissionSnapshot, len(times))
+	next := len(changes) - 1
+	for _, i := range order {
+		for ; next >= 0 && changes[next].ChangedAt.After(times[i]); next-- {
+			if err := state.undo(changes[next]); err != nil {
+				return nil, err
+			}
+		}
+		snapshots[i] = state.snapshot(userID, organizationID, times[i])
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,208 @@
+package rbac
+
+import (
+	"database/sql"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordChange appends a change to the mock's history; a nil row encodes as JSON null
+func (m *MockRBACRepository) recordChange(table, action string, at time.Time, oldRow, newRow map[string]interface{}) {
+	encode := func(row map[string]interface{}) json.RawMessage {
+		data, _ := json.Marshal(row)
+		return data
+	}
+	m.changes = append(m.changes, ChangeRecord{
+		ID:        int64(len(m.changes) + 1),
+		Table:     table,
+		Action:    action,
+		OldRow:    encode(oldRow),
+		NewRow:    encode(newRow),
+		ChangedAt: at,
+	})
+}
+
+func TestRBACService_PermissionHistory(t *testing.T) {
+	repo := NewMockRBACRepository()
+	viewer := repo.addRole("viewer")
+	editor := repo.addRole("editor")
+	auditor := repo.addRole("auditor")
+	_ = repo.SetRoleParent(editor.ID, viewer.ID)
+	usersRead := repo.addPermission("users:read", "users", "read", false)
+	usersWrite := repo.addPermission("users:write", "users", "write", false)
+	reportsExport := repo.addPermission("reports:export", "reports", "export", false)
+	logsRead := repo.addPermission("logs:read", "logs", "read", false)
+	_ = repo.AssignPermissionToRole(viewer.ID, usersRead.ID)
+	_ = repo.AssignPermissionToRole(viewer.ID, reportsExport.ID)
+	_ = repo.AssignPermissionToRole(editor.ID, usersWrite.ID)
+	_ = repo.AssignPermissionToRole(auditor.ID, logsRead.ID)
+	_ = repo.AddDenyToRole(editor.ID, reportsExport.ID)
+	ops := &Group{Name: "ops"}
+	_ = repo.CreateGroup(ops)
+	_ = repo.AssignRoleToGroup(ops.ID, auditor.ID)
+
+	// Current state: alice holds editor until the end of the year and auditor through ops
+	endOfYear := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+	_ = repo.AssignRoleToUser("alice", editor.ID, RoleValidity{Until: &endOfYear})
+	_ = repo.AddUserToGroup(ops.ID, "alice")
+	_ = repo.AssignRoleToUser("bob", auditor.ID, RoleValidity{})
+
+	day := func(d int) time.Time { return time.Date(2025, 12, d, 0, 0, 0, 0, time.UTC) }
+	userRole := func(userID, roleID string) map[string]interface{} {
+		return map[string]interface{}{"user_id": userID, "role_id": roleID, "organization_id": nil}
+	}
+	editorAssignment := userRole("alice", editor.ID)
+	editorAssignment["valid_until"] = endOfYear
+
+	repo.recordChange("user_roles", HistoryInsert, day(1), nil, userRole("alice", viewer.ID))
+	repo.recordChange("user_roles", HistoryInsert, day(5), nil, editorAssignment)
+	repo.recordChange("roles", HistoryUpdate, day(6),
+		map[string]interface{}{"id": editor.ID, "name": "author", "parent_id": viewer.ID},
+		map[string]interface{}{"id": editor.ID, "name": "editor", "parent_id": viewer.ID},
+	)
+	repo.recordChange("group_members", HistoryInsert, day(9), nil, map[string]interface{}{"group_id": ops.ID, "user_id": "alice"})
+	repo.recordChange("user_roles", HistoryDelete, day(10), userRole("alice", viewer.ID), nil)
+	repo.recordChange("user_roles", HistoryInsert, day(11), nil, userRole("bob", auditor.ID))
+	repo.recordChange("role_permission_denies", HistoryInsert, day(12), nil,
+		map[string]interface{}{"role_id": editor.ID, "permission_id": reportsExport.ID},
+	)
+
+	useCase := NewRBACUseCase(repo)
+
+	tests := []struct {
+		name        string
+		at          time.Time
+		roles       []string
+		permissions []string
+		denies      []string
+	}{
+		{"before any grant", day(1).Add(-time.Hour), []string{}, []string{}, []string{}},
+		{"viewer only", day(3), []string{"viewer"}, []string{"reports:export", "users:read"}, []string{}},
+		{"editor under its old name", day(5).Add(12 * time.Hour), []string{"author", "viewer"}, []string{"reports:export", "users:read", "users:write"}, []string{}},
+		{"group role, viewer inherited", day(10).Add(time.Hour), []string{"auditor", "editor"}, []string{"logs:read", "reports:export", "users:read", "users:write"}, []string{}},
+		{"deny added", day(13), []string{"auditor", "editor"}, []string{"logs:read", "reports:export", "users:read", "users:write"}, []string{"reports:export"}},
+		{"editor assignment expired", endOfYear, []string{"auditor"}, []string{"logs:read"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot, err := useCase.GetPermissionsAt("alice", "", tt.at)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(snapshot.Roles, tt.roles) {
+				t.Errorf("roles = %v, want %v", snapshot.Roles, tt.roles)
+			}
+			if !slices.Equal(snapshot.Permissions, tt.permissions) {
+				t.Errorf("permissions = %v, want %v", snapshot.Permissions, tt.permissions)
+			}
+			if !slices.Equal(snapshot.Denies, tt.denies) {
+				t.Errorf("denies = %v, want %v", snapshot.Denies, tt.denies)
+			}
+		})
+	}
+
+	// Other users' changes are not undone for alice, and alice's are not undone for bob
+	bob, err := useCase.GetPermissionsAt("bob", "", day(10))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(bob.Roles) != 0 {
+		t.Errorf("expected bob to hold no roles before day 11, got %v", bob.Roles)
+	}
+}
+
+func TestRBACService_DiffPermissions(t *testing.T) {
+	repo := NewMockRBACRepository()
+	viewer := repo.addRole("viewer")
+	editor := repo.addRole("editor")
+	usersRead := repo.addPermission("users:read", "users", "read", false)
+	usersWrite := repo.addPermission("users:write", "users", "write", false)
+	usersDelete := repo.addPermission("users:delete", "users", "delete", false)
+	_ = repo.AssignPermissionToRole(viewer.ID, usersRead.ID)
+	_ = repo.AssignPermissionToRole(editor.ID, usersRead.ID)
+	_ = repo.AssignPermissionToRole(editor.ID, usersWrite.ID)
+	_ = repo.AssignRoleToUser("alice", editor.ID, RoleValidity{})
+	_ = repo.AddDenyToUser("alice", usersDelete.ID)
+
+	day := func(d int) time.Time { return time.Date(2025, 12, d, 0, 0, 0, 0, time.UTC) }
+	userRole := func(roleID string) map[string]interface{} {
+		return map[string]interface{}{"user_id": "alice", "role_id": roleID, "organization_id": nil}
+	}
+	repo.recordChange("user_roles", HistoryInsert, day(1), nil, userRole(viewer.ID))
+	repo.recordChange("user_roles", HistoryDelete, day(5), userRole(viewer.ID), nil)
+	repo.recordChange("user_roles", HistoryInsert, day(5), nil, userRole(editor.ID))
+	repo.recordChange("user_permission_denies", HistoryInsert, day(7), nil,
+		map[string]interface{}{"user_id": "alice", "permission_id": usersDelete.ID},
+	)
+
+	useCase := NewRBACUseCase(repo)
+
+	diff, err := useCase.DiffPermissions("alice", "", day(3), day(8))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !slices.Equal(diff.From.Roles, []string{"viewer"}) || !slices.Equal(diff.To.Roles, []string{"editor"}) {
+		t.Errorf("roles went from %v to %v, want [viewer] to [editor]", diff.From.Roles, diff.To.Roles)
+	}
+	if !slices.Equal(diff.Roles.Added, []string{"editor"}) || !slices.Equal(diff.Roles.Removed, []string{"viewer"}) {
+		t.Errorf("unexpected role diff %+v", diff.Roles)
+	}
+	if !slices.Equal(diff.Permissions.Added, []string{"users:write"}) || len(diff.Permissions.Removed) != 0 {
+		t.Errorf("unexpected permission diff %+v", diff.Permissions)
+	}
+	if !slices.Equal(diff.Denies.Added, []string{"users:delete"}) || len(diff.Denies.Removed) != 0 {
+		t.Errorf("unexpected deny diff %+v", diff.Denies)
+	}
+
+	// Swapping the times swaps the diff
+	reversed, err := useCase.DiffPermissions("alice", "", day(8), day(3))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(reversed.Roles.Added, diff.Roles.Removed) || !slices.Equal(reversed.Permissions.Removed, diff.Permissions.Added) {
+		t.Errorf("expected the reversed diff to mirror %+v, got %+v", diff, reversed)
+	}
+}
+
+func TestRBACRepository_DeleteExpiredUserRolesNamesActor(t *testing.T) {
+	cache, _ := newTestGrantCache(t)
+	db := &scriptedDB{expired: []string{"alice", "bob"}}
+	repo := NewRBACRepository(sql.OpenDB(db), cache.cacheHelper, cache.versions)
+
+	before, _ := cache.versions.Current("alice")
+	actor := SystemActor("expiry-sweeper")
+	userIDs, err := repo.WithActor(actor).DeleteExpiredUserRoles()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(userIDs, []string{"alice", "bob"}) {
+		t.Errorf("expected alice and bob, got %v", userIDs)
+	}
+
+	// The settings are transaction-local, so the delete must follow them in the same transaction
+	if len(db.log) != 4 || db.log[0] != "BEGIN" || db.log[1] != actorSettingsSQL ||
+		!strings.Contains(db.log[2], "WITH expired") || db.log[3] != "COMMIT" {
+		t.Errorf("expected the delete to run after the actor settings in one transaction, got %q", db.log)
+	}
+	if !slices.Equal(db.actors, []string{SystemActorID}) {
+		t.Errorf("expected the system actor to be named, got %v", db.actors)
+	}
+	if after, _ := cache.versions.Current("alice"); after == before {
+		t.Error("expected the affected users' cache to be invalidated after the commit")
+	}
+}
+
+func TestSystemActor(t *testing.T) {
+	first, second := SystemActor("expiry-sweeper"), SystemActor("expiry-sweeper")
+	if first.UserID != SystemActorID || !strings.HasPrefix(first.RequestID, "expiry-sweeper:") {
+		t.Errorf("unexpected system actor %+v", first)
+	}
+	if first.RequestID == second.RequestID {
+		t.Error("expected every run to get its own request ID")
+	}
+}
@@ -0,0 +1,168 @@
+package rbac
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Wildcard matches any segment in a permission pattern
+const Wildcard = "*"
+
+// Permission names are resource:action where the resource may be nested (orgs:billing:read
+// has resource orgs:billing and action read). Granted permissions may use wildcards:
+//
+//   - an action of * matches every action on the resource (users:* matches users:read)
+//   - a * inside the resource matches exactly one resource segment (orgs:*:read matches
+//     orgs:billing:read but not orgs:billing:invoices:read)
+//   - a * as the last resource segment matches one or more remaining segments, so *:read
+//     matches users:read and orgs:billing:read, and orgs:*:* matches everything under orgs
+//
+// The required permission passed to MatchPermission is always a concrete name.
+
+// splitPermission separates a permission name into resource segments and action
+func splitPermission(name string) ([]string, string, bool) {
+	segments := strings.Split(name, ":")
+	if len(segments) < 2 {
+		return nil, "", false
+	}
+	for _, segment := range segments {
+		if segment == "" {
+			return nil, "", false
+		}
+	}
+	return segments[:len(segments)-1], segments[len(segments)-1], true
+}
+
+// MatchPermission reports whether the granted pattern covers the required permission
+func MatchPermission(pattern, required string) bool {
+	if pattern == required {
+		return true
+	}
+
+	patternResource, patternAction, ok := splitPermission(pattern)
+	if !ok {
+		return false
+	}
+	requiredResource, requiredAction, ok := splitPermission(required)
+	if !ok {
+		return false
+	}
+
+	if patternAction != Wildcard && patternAction != requiredAction {
+		return false
+	}
+
+	return matchResource(patternResource, requiredResource)
+}
+
+func matchResource(pattern, required []string) bool {
+	for i, segment := range pattern {
+		last := i == len(pattern)-1
+		if i >= len(required) {
+			return false
+		}
+		if segment == Wildcard && last {
+			return true
+		}
+		if segment != Wildcard && segment != required[i] {
+			return false
+		}
+	}
+	return len(pattern) == len(required)
+}
+
+// patternRank orders how specifically a pattern names a permission; larger is more specific
+type patternRank struct {
+	literals      int
+	firstWildcard int
+	bounded       bool
+}
+
+func rankPattern(pattern string) patternRank {
+	resource, action, _ := splitPermission(pattern)
+	segments := append(append([]string{}, resource...), action)
+
+	rank := patternRank{firstWildcard: len(segments), bounded: true}
+	for i, segment := range segments {
+		if segment != Wildcard {
+			rank.literals++
+			continue
+		}
+		if i < rank.firstWildcard {
+			rank.firstWildcard = i
+		}
+	}
+	// A trailing resource wildcard spans any depth
+	if len(resource) > 0 && resource[len(resource)-1] == Wildcard {
+		rank.bounded = false
+	}
+	return rank
+}
+
+// MoreSpecific reports whether pattern a names permissions more precisely than pattern b.
+// Precedence: more literal segments, then the later the first wildcard (a concrete resource
+// beats a concrete action), then a bounded resource over a trailing any-depth wildcard,
+// then lexical order to keep the result deterministic.
+func MoreSpecific(a, b string) bool {
+	rankA, rankB := rankPattern(a), rankPattern(b)
+	if rankA.literals != rankB.literals {
+		return rankA.literals > rankB.literals
+	}
+	if rankA.firstWildcard != rankB.firstWildcard {
+		return rankA.firstWildcard > rankB.firstWildcard
+	}
+	if rankA.bounded != rankB.bounded {
+		return rankA.bounded
+	}
+	return a < b
+}
+
+// BestMatch returns the most specific granted pattern that covers the required permission
+func BestMatch(granted []string, required string) (string, bool) {
+	var matches []string
+	for _, pattern := range granted {
+		if MatchPermission(pattern, required) {
+			matches = append(matches, pattern)
+		}
+	}
+	if len(matches) == 0 {
+		return "", false
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return MoreSpecific(matches[i], matches[j]) })
+	return matches[0], true
+}
+
+// CompactPermissions drops granted patterns covered by another granted pattern, such as
+// users:read next to users:*, and returns the rest sorted. Access decisions are unchanged.
+func CompactPermissions(granted []string) []string {
+	unique := append([]string{}, granted...)
+	sort.Strings(unique)
+	unique = slices.Compact(unique)
+
+	compact := make([]string, 0, len(unique))
+	for _, name := range unique {
+		covered := false
+		for _, other := range unique {
+			if other != name && MatchPermission(other, name) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			compact = append(compact, name)
+		}
+	}
+	return compact
+}
+
+// Authorize decides the required permission with deny-wins semantics: a denied pattern covering
+// it refuses access however specific the grant. It returns the most specific covering deny
+// when refused, otherwise the most specific covering grant.
+func Authorize(granted, denied []string, required string) (string, bool) {
+	if pattern, ok := BestMatch(denied, required); ok {
+		return pattern, false
+	}
+	return BestMatch(granted, required)
+}
@@ -0,0 +1,207 @@
+package rbac
+
+import (
+	"slices"
+	"sort"
+	"testing"
+)
+
+func TestMatchPermission(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		required string
+		want     bool
+	}{
+		// Exact
+		{"users:read", "users:read", true},
+		{"users:read", "users:write", false},
+		{"users:read", "roles:read", false},
+
+		// Action wildcard
+		{"users:*", "users:read", true},
+		{"users:*", "users:delete", true},
+		{"users:*", "roles:read", false},
+		{"users:*", "users:sessions:read", false},
+
+		// Resource wildcard spans any depth when it is the last resource segment
+		{"*:read", "users:read", true},
+		{"*:read", "orgs:billing:read", true},
+		{"*:read", "users:write", false},
+		{"*:*", "users:read", true},
+		{"*:*", "orgs:billing:invoices:delete", true},
+
+		// Nested resources
+		{"orgs:billing:read", "orgs:billing:read", true},
+		{"orgs:billing:read", "orgs:read", false},
+		{"orgs:*:read", "orgs:billing:read", true},
+		{"orgs:*:read", "orgs:billing:invoices:read", true},
+		{"orgs:*:read", "orgs:read", false},
+		{"orgs:*:*", "orgs:members:delete", true},
+		{"orgs:*:*", "users:read", false},
+
+		// An inner wildcard matches exactly one segment
+		{"orgs:*:invoices:read", "orgs:billing:invoices:read", true},
+		{"orgs:*:invoices:read", "orgs:invoices:read", false},
+		{"orgs:*:invoices:read", "orgs:a:b:invoices:read", false},
+
+		// Names without an action only ever match themselves
+		{"users", "users", true},
+		{"*", "users:read", false},
+		{"users:*", "users", false},
+		{"users::read", "users:read", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" vs "+tt.required, func(t *testing.T) {
+			if got := MatchPermission(tt.pattern, tt.required); got != tt.want {
+				t.Errorf("MatchPermission(%q, %q) = %v, want %v", tt.pattern, tt.required, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoreSpecific_Precedence(t *testing.T) {
+	// Most specific first
+	ordered := []string{
+		"orgs:billing:read",
+		"orgs:billing:*",
+		"orgs:*:read",
+		"orgs:*:*",
+		"*:read",
+		"*:*",
+	}
+
+	shuffled := []string{"*:*", "orgs:*:read", "*:read", "orgs:billing:read", "orgs:*:*", "orgs:billing:*"}
+	sort.Slice(shuffled, func(i, j int) bool { return MoreSpecific(shuffled[i], shuffled[j]) })
+
+	for i := range ordered {
+		if shuffled[i] != ordered[i] {
+			t.Fatalf("precedence order = %v, want %v", shuffled, ordered)
+		}
+	}
+
+	pairs := []struct {
+		a, b string
+	}{
+		{"users:read", "users:*"},         // literal action beats wildcard action
+		{"users:*", "*:read"},             // concrete resource beats concrete action
+		{"orgs:*:read", "*:read"},         // more literal segments
+		{"orgs:billing:*", "orgs:*:read"}, // same literals, wildcard appears later
+	}
+	for _, pair := range pairs {
+		if !MoreSpecific(pair.a, pair.b) {
+			t.Errorf("expected %q to be more specific than %q", pair.a, pair.b)
+		}
+		if MoreSpecific(pair.b, pair.a) {
+			t.Errorf("expected %q not to be more specific than %q", pair.b, pair.a)
+		}
+	}
+}
+
+func TestBestMatch(t *testing.T) {
+	granted := []string{"*:*", "*:read", "users:*", "users:read", "orgs:*:read"}
+
+	tests := []struct {
+		required string
+		want     string
+	}{
+		{"users:read", "users:read"},
+		{"users:delete", "users:*"},
+		{"roles:read", "*:read"},
+		{"orgs:billing:read", "orgs:*:read"},
+		{"orgs:billing:write", "*:*"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.required, func(t *testing.T) {
+			got, ok := BestMatch(granted, tt.required)
+			if !ok || got != tt.want {
+				t.Errorf("BestMatch(%q) = %q, %v; want %q", tt.required, got, ok, tt.want)
+			}
+		})
+	}
+
+	if _, ok := BestMatch([]string{"users:read"}, "users:write"); ok {
+		t.Error("expected no match")
+	}
+}
+
+func TestAuthorize_DenyWins(t *testing.T) {
+	granted := []string{"users:delete", "users:*", "*:read"}
+	denied := []string{"users:*", "orgs:*:write"}
+
+	tests := []struct {
+		required string
+		want     string
+		allowed  bool
+	}{
+		// A broad deny beats an exact grant
+		{"users:delete", "users:*", false},
+		{"orgs:billing:write", "orgs:*:write", false},
+		{"roles:read", "*:read", true},
+		{"roles:write", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.required, func(t *testing.T) {
+			got, allowed := Authorize(granted, denied, tt.required)
+			if got != tt.want || allowed != tt.allowed {
+				t.Errorf("Authorize(%q) = %q, %v; want %q, %v", tt.required, got, allowed, tt.want, tt.allowed)
+			}
+		})
+	}
+}
+
+func TestCompactPermissions(t *testing.T) {
+	tests := []struct {
+		name    string
+		granted []string
+		want    []string
+	}{
+		{"covered names dropped", []string{"users:read", "users:*", "profile:read"}, []string{"profile:read", "users:*"}},
+		{"everything under *:*", []string{"users:read", "*:*", "orgs:*:read"}, []string{"*:*"}},
+		{"nested wildcard covers deeper", []string{"orgs:*:*", "orgs:billing:read", "orgs:*:read"}, []string{"orgs:*:*"}},
+		{"duplicates collapse", []string{"users:read", "users:read"}, []string{"users:read"}},
+		{"unrelated kept", []string{"users:read", "users:write"}, []string{"users:read", "users:write"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CompactPermissions(tt.granted)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("CompactPermissions(%v) = %v, want %v", tt.granted, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRBACService_CheckUserPermissionWildcard(t *testing.T) {
+	repo := NewMockRBACRepository()
+	editor := repo.addRole("editor")
+	usersAll := repo.addPermission("users:*", "users", "*", false)
+	readAll := repo.addPermission("*:read", "*", "read", false)
+	_ = repo.AssignPermissionToRole(editor.ID, usersAll.ID)
+	_ = repo.AssignPermissionToRole(editor.ID, readAll.ID)
+	_ = repo.AssignRoleToUser("editor-user", editor.ID, RoleValidity{})
+	useCase := NewRBACUseCase(repo)
+
+	tests := []struct {
+		permissions []string
+		want        bool
+	}{
+		{[]string{"users:delete"}, true},
+		{[]string{"orgs:billing:read"}, true},
+		{[]string{"roles:write"}, false},
+		{[]string{"roles:write", "roles:read"}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := useCase.CheckUserPermission("editor-user", tt.permissions...)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("CheckUserPermission(%v) = %v, want %v", tt.permissions, got, tt.want)
+		}
+	}
+}
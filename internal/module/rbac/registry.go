@@ -0,0 +1,163 @@
+package rbac
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PermissionRegistry collects the permissions modules declare and the permissions their routes
+// require. It is filled in at startup, before the server accepts requests, and read-only after.
+type PermissionRegistry struct {
+	permissions []RegisteredPermission
+	index       map[string]int
+	routes      []RouteRequirement
+	problems    []string
+}
+
+// RegisteredPermission is a permission declared by a module
+type RegisteredPermission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Module      string `json:"module"`
+}
+
+// RouteRequirement is a route and the permissions it requires; holding any of them is enough
+type RouteRequirement struct {
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	Permissions []string `json:"permissions"`
+}
+
+// PermissionMatrixEntry is a declared permission and the routes requiring it, as "METHOD /path";
+// permissions without routes are checked in code, e.g. by access policies
+type PermissionMatrixEntry struct {
+	RegisteredPermission
+	Routes []string `json:"routes"`
+}
+
+// PermissionMatrix reports which routes require which permissions
+type PermissionMatrix struct {
+	Permissions []PermissionMatrixEntry `json:"permissions"`
+	Routes      []RouteRequirement      `json:"routes"`
+}
+
+// NewPermissionRegistry creates an empty registry
+func NewPermissionRegistry() *PermissionRegistry {
+	return &PermissionRegistry{index: make(map[string]int)}
+}
+
+// Declare registers permissions owned by a module. Declaring a permission twice with the same
+// description is harmless; a different description is reported by Verify.
+func (r *PermissionRegistry) Declare(module string, permissions ...PermissionSpec) {
+	for _, permission := range permissions {
+		if i, ok := r.index[permission.Name]; ok {
+			if declared := r.permissions[i]; declared.Description != permission.Description {
+				r.problems = append(r.problems, fmt.Sprintf(
+					"permission %q is declared by %s and %s with different descriptions",
+					permission.Name, declared.Module, module,
+				))
+			}
+			continue
+		}
+		r.index[permission.Name] = len(r.permissions)
+		r.permissions = append(r.permissions, RegisteredPermission{
+			Name:        permission.Name,
+			Description: permission.Description,
+			Module:      module,
+		})
+	}
+}
+
+// Require records that a route requires any of the permissions
+func (r *PermissionRegistry) Require(method, path string, permissions ...string) {
+	r.routes = append(r.routes, RouteRequirement{
+		Method:      method,
+		Path:        path,
+		Permissions: append([]string(nil), permissions...),
+	})
+}
+
+// Permissions returns the declared permissions in declaration order
+func (r *PermissionRegistry) Permissions() []PermissionSpec {
+	specs := make([]PermissionSpec, len(r.permissions))
+	for i, permission := range r.permissions {
+		specs[i] = PermissionSpec{Name: permission.Name, Description: permission.Description}
+	}
+	return specs
+}
+
+// Verify reports malformed and conflicting declarations and routes requiring a permission no
+// module declared, so a typo in a route fails startup instead of forbidding every request
+func (r *PermissionRegistry) Verify() error {
+	problems := append([]string(nil), r.problems...)
+
+	for _, permission := range r.permissions {
+		if _, _, ok := splitPermissionName(permission.Name); !ok {
+			problems = append(problems, fmt.Sprintf(
+				"permission %q declared by %s is not a resource:action name", permission.Name, permission.Module,
+			))
+		}
+	}
+
+	for _, route := range r.routes {
+		if len(route.Permissions) == 0 {
+			problems = append(problems, fmt.Sprintf("%s %s requires no permission", route.Method, route.Path))
+		}
+		for _, name := range route.Permissions {
+			if _, ok := r.index[name]; !ok {
+				problems = append(problems, fmt.Sprintf(
+					"%s %s requires undeclared permission %q", route.Method, route.Path, name,
+				))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("rbac: invalid permission registry:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// Matrix lists declared permissions sorted by name with the routes requiring them, and routes
+// sorted by path and method
+func (r *PermissionRegistry) Matrix() PermissionMatrix {
+	matrix := PermissionMatrix{
+		Permissions: make([]PermissionMatrixEntry, len(r.permissions)),
+		Routes:      append([]RouteRequirement{}, r.routes...),
+	}
+
+	sort.SliceStable(matrix.Routes, func(i, j int) bool {
+		if matrix.Routes[i].Path != matrix.Routes[j].Path {
+			return matrix.Routes[i].Path < matrix.Routes[j].Path
+		}
+		return matrix.Routes[i].Method < matrix.Routes[j].Method
+	})
+
+	for i, permission := range r.permissions {
+		matrix.Permissions[i] = PermissionMatrixEntry{RegisteredPermission: permission, Routes: []string{}}
+	}
+	for _, route := range matrix.Routes {
+		for _, name := range route.Permissions {
+			if i, ok := r.index[name]; ok {
+				matrix.Permissions[i].Routes = append(matrix.Permissions[i].Routes, route.Method+" "+route.Path)
+			}
+		}
+	}
+
+	sort.Slice(matrix.Permissions, func(i, j int) bool {
+		return matrix.Permissions[i].Name < matrix.Permissions[j].Name
+	})
+	return matrix
+}
+
+// RegisterPermissions declares the permissions guarding role and permission administration
+func RegisterPermissions(registry *PermissionRegistry) {
+	registry.Declare("rbac",
+		PermissionSpec{Name: "roles:read", Description: "View roles"},
+		PermissionSpec{Name: "roles:write", Description: "Create and update roles"},
+		PermissionSpec{Name: "roles:delete", Description: "Delete roles"},
+		PermissionSpec{Name: "permissions:read", Description: "View permissions"},
+		PermissionSpec{Name: "permissions:assign", Description: "Assign permissions to roles"},
+	)
+}
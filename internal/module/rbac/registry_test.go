@@ -0,0 +1,135 @@
+package rbac
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	apperrors "boilerplate-be/internal/shared/errors"
+)
+
+func TestPermissionRegistry_Verify(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(r *PermissionRegistry)
+		problem string
+	}{
+		{"declared references", func(r *PermissionRegistry) {
+			r.Declare("users", PermissionSpec{Name: "users:read", Description: "View users"})
+			r.Declare("auth", PermissionSpec{Name: "users:read", Description: "View users"})
+			r.Require("GET", "/users", "users:read")
+		}, ""},
+		{"undeclared reference", func(r *PermissionRegistry) {
+			r.Declare("users", PermissionSpec{Name: "users:read"})
+			r.Require("GET", "/users", "user:read")
+		}, `GET /users requires undeclared permission "user:read"`},
+		{"route without permissions", func(r *PermissionRegistry) {
+			r.Require("GET", "/users")
+		}, "GET /users requires no permission"},
+		{"malformed name", func(r *PermissionRegistry) {
+			r.Declare("users", PermissionSpec{Name: "users"})
+		}, `permission "users" declared by users is not a resource:action name`},
+		{"conflicting declarations", func(r *PermissionRegistry) {
+			r.Declare("users", PermissionSpec{Name: "users:read", Description: "View users"})
+			r.Declare("auth", PermissionSpec{Name: "users:read", Description: "Read users"})
+		}, `permission "users:read" is declared by users and auth with different descriptions`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewPermissionRegistry()
+			tt.setup(registry)
+			err := registry.Verify()
+			if tt.problem == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.problem) {
+				t.Fatalf("expected %q to be reported, got %v", tt.problem, err)
+			}
+		})
+	}
+}
+
+func TestPermissionRegistry_Matrix(t *testing.T) {
+	registry := NewPermissionRegistry()
+	registry.Declare("users",
+		PermissionSpec{Name: "users:write"},
+		PermissionSpec{Name: "users:read"},
+		PermissionSpec{Name: "users:export"},
+	)
+	registry.Require("PUT", "/users/:id", "users:write")
+	registry.Require("GET", "/users", "users:read", "users:write")
+	registry.Require("GET", "/users/:id", "users:read")
+
+	matrix := registry.Matrix()
+	var routes []string
+	for _, route := range matrix.Routes {
+		routes = append(routes, route.Method+" "+route.Path)
+	}
+	if want := []string{"GET /users", "GET /users/:id", "PUT /users/:id"}; !slices.Equal(routes, want) {
+		t.Errorf("routes = %v, want %v", routes, want)
+	}
+
+	want := map[string][]string{
+		"users:export": {},
+		"users:read":   {"GET /users", "GET /users/:id"},
+		"users:write":  {"GET /users", "PUT /users/:id"},
+	}
+	var names []string
+	for _, entry := range matrix.Permissions {
+		names = append(names, entry.Name)
+		if !slices.Equal(entry.Routes, want[entry.Name]) {
+			t.Errorf("%s routes = %v, want %v", entry.Name, entry.Routes, want[entry.Name])
+		}
+	}
+	if !slices.IsSorted(names) || len(names) != 3 {
+		t.Errorf("expected permissions sorted by name, got %v", names)
+	}
+}
+
+func TestRBACService_SeedPermissions(t *testing.T) {
+	repo := NewMockRBACRepository()
+	repo.addPermission("users:read", "users", "read", false)
+	useCase := NewRBACUseCase(repo)
+
+	registry := NewPermissionRegistry()
+	registry.Declare("users",
+		PermissionSpec{Name: "users:read", Description: "View users"},
+		PermissionSpec{Name: "users:export", Description: "Export users"},
+	)
+	registry.Declare("organization", PermissionSpec{Name: "orgs:members:read", Description: "List members"})
+
+	created, err := useCase.SeedPermissions(registry.Permissions())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(created) != 2 || created[0].Name != "users:export" || created[1].Resource != "orgs:members" {
+		t.Errorf("unexpected created permissions: %+v", created)
+	}
+
+	// Seeding again creates nothing
+	created, err = useCase.SeedPermissions(registry.Permissions())
+	if err != nil || len(created) != 0 {
+		t.Errorf("expected nothing to be created, got %+v, %v", created, err)
+	}
+
+	// Declared permissions are built-in, including ones that existed before seeding
+	for _, name := range []string{"users:read", "users:export", "orgs:members:read"} {
+		permission, err := repo.GetPermissionByName(name)
+		if err != nil || !permission.IsSystem {
+			t.Errorf("expected %s to be a built-in permission, got %+v, %v", name, permission, err)
+			continue
+		}
+		assertErrorCode(t, useCase.DeletePermission(permission.ID), apperrors.SystemPermissionProtected)
+	}
+
+	if _, err := useCase.ApplyPolicy(&PolicyDocument{}, ApplyOptions{Prune: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := repo.GetPermissionByName("users:read"); err != nil {
+		t.Error("expected declared permission to survive pruning")
+	}
+}
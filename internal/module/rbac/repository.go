@@ -3,32 +3,175 @@ package rbac
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
+	"boilerplate-be/internal/database"
 	"boilerplate-be/internal/shared/errors"
 	"boilerplate-be/internal/shared/utils"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+// sqlExecutor is satisfied by both *sql.DB and *sql.Tx
+type sqlExecutor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// actorExecutor names the actor of every write in the rbac.actor_id and rbac.request_id
+// settings the change history triggers read. The settings are transaction-local, so an Exec
+// outside a transaction runs in one of its own. Rows cannot outlive such a transaction, so Query
+// and QueryRow only name the actor inside one: writes returning rows must run in WithTransaction.
+type actorExecutor struct {
+	sqlExecutor
+	actor ChangeActor
+}
+
+func (e actorExecutor) Exec(query string, args ...interface{}) (sql.Result, error) {
+	if db, ok := e.sqlExecutor.(*sql.DB); ok {
+		tx, err := db.Begin()
+		if err != nil {
+			return nil, err
+		}
+		result, err := actorExecutor{sqlExecutor: tx, actor: e.actor}.Exec(query, args...)
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+		return result, tx.Commit()
+	}
+
+	if _, err := e.sqlExecutor.Exec(actorSettingsSQL, e.actor.UserID, e.actor.RequestID); err != nil {
+		return nil, err
+	}
+	return e.sqlExecutor.Exec(query, args...)
+}
+
+func (e actorExecutor) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	if _, ok := e.sqlExecutor.(*sql.DB); !ok {
+		if _, err := e.sqlExecutor.Exec(actorSettingsSQL, e.actor.UserID, e.actor.RequestID); err != nil {
+			return nil, err
+		}
+	}
+	return e.sqlExecutor.Query(query, args...)
+}
+
+func (e actorExecutor) QueryRow(query string, args ...interface{}) *sql.Row {
+	if _, ok := e.sqlExecutor.(*sql.DB); !ok {
+		// A failure aborts the transaction, so the query below reports it
+		_, _ = e.sqlExecutor.Exec(actorSettingsSQL, e.actor.UserID, e.actor.RequestID)
+	}
+	return e.sqlExecutor.QueryRow(query, args...)
+}
+
+// actorSettingsSQL sets the transaction-local settings the change history triggers read
+const actorSettingsSQL = `SELECT set_config('rbac.actor_id', $1, true), set_config('rbac.request_id', $2, true)`
+
+// Apply names the actor of the RBAC rows later written in the transaction exec belongs to, for
+// repositories outside this package that write them, e.g. through cascades. A zero actor is a
+// no-op.
+func (a ChangeActor) Apply(ctx context.Context, exec database.Executor) error {
+	if a == (ChangeActor{}) {
+		return nil
+	}
+	if _, err := exec.ExecContext(ctx, actorSettingsSQL, a.UserID, a.RequestID); err != nil {
+		return errors.Wrap(err, errors.DatabaseQueryFailed)
+	}
+	return nil
+}
+
 type rbacRepository struct {
-	db          *sql.DB
-	cacheHelper *utils.CacheHelper
+	db        sqlExecutor
+	txManager *database.TxManager
+	cache     grantCache
+	// actor is recorded with every change; zero when unknown
+	actor ChangeActor
+
+	// staleUsers collects users whose cache is dropped once the surrounding transaction
+	// commits; nil outside WithTransaction
+	staleUsers *[]string
 }
 
-// NewRBACRepository creates a new RBAC repository
-func NewRBACRepository(db *sql.DB, cacheHelper *utils.CacheHelper) RBACRepository {
+// NewRBACRepository creates a new RBAC repository. Cached roles and permissions are keyed by
+// the user's version, which every change to the user's roles or permissions bumps.
+func NewRBACRepository(db *sql.DB, cacheHelper *utils.CacheHelper, versions UserVersions) RBACRepository {
 	return &rbacRepository{
-		db:          db,
-		cacheHelper: cacheHelper,
+		db:        db,
+		txManager: database.NewTxManager(db),
+		cache:     grantCache{cacheHelper: cacheHelper, versions: versions},
+	}
+}
+
+// WithTransaction runs fn against a copy of the repository bound to a single transaction.
+// Cached roles and permissions are invalidated only after the transaction commits, so
+// concurrent readers never cache uncommitted state. Nested calls join the outer transaction.
+func (r *rbacRepository) WithTransaction(fn func(repo RBACRepository) error) error {
+	if r.staleUsers != nil {
+		return fn(r)
+	}
+
+	var staleUsers []string
+	err := r.txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		return fn(&rbacRepository{
+			db:         r.bindActor(database.GetTx(ctx)),
+			txManager:  r.txManager,
+			cache:      r.cache,
+			actor:      r.actor,
+			staleUsers: &staleUsers,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	r.invalidateUsers(staleUsers)
+	return nil
+}
+
+// WithActor returns a copy of the repository recording its changes as made by the actor
+func (r *rbacRepository) WithActor(actor ChangeActor) RBACRepository {
+	copied := *r
+	copied.actor = actor
+	if inner, ok := r.db.(actorExecutor); ok {
+		copied.db = copied.bindActor(inner.sqlExecutor)
+	} else {
+		copied.db = copied.bindActor(r.db)
 	}
+	return &copied
+}
+
+// bindActor wraps db so its writes record the repository's actor
+func (r *rbacRepository) bindActor(db sqlExecutor) sqlExecutor {
+	if r.actor == (ChangeActor{}) {
+		return db
+	}
+	return actorExecutor{sqlExecutor: db, actor: r.actor}
 }
 
 // ==================== Role Operations ====================
 
-func (r *rbacRepository) GetRoles() ([]Role, error) {
-	query := `SELECT id, name, description, created_at FROM roles ORDER BY name`
-	rows, err := r.db.Query(query)
+// roleColumns is the column list read by scanRole; queries alias roles as r
+const roleColumns = `r.id, r.name, r.description, r.parent_id, r.created_at`
+
+// scanRole reads a row selected with roleColumns
+func scanRole(row rowScanner) (Role, error) {
+	var role Role
+	var description, parentID sql.NullString
+	err := row.Scan(&role.ID, &role.Name, &description, &parentID, &role.CreatedAt)
+	role.Description = description.String
+	role.ParentID = parentID.String
+	return role, err
+}
+
+// queryRoles runs a query selecting roleColumns and collects the rows
+func (r *rbacRepository) queryRoles(query string, args ...interface{}) ([]Role, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
 	}
@@ -36,54 +179,49 @@ func (r *rbacRepository) GetRoles() ([]Role, error) {
 
 	var roles []Role
 	for rows.Next() {
-		var role Role
-		var description sql.NullString
-		if err := rows.Scan(&role.ID, &role.Name, &description, &role.CreatedAt); err != nil {
+		role, err := scanRole(rows)
+		if err != nil {
 			return nil, errors.Wrap(err, errors.DatabaseScanFailed)
 		}
-		role.Description = description.String
 		roles = append(roles, role)
 	}
 
 	return roles, nil
 }
 
-func (r *rbacRepository) GetRoleByID(id string) (*Role, error) {
-	query := `SELECT id, name, description, created_at FROM roles WHERE id = $1`
-	var role Role
-	var description sql.NullString
-	err := r.db.QueryRow(query, id).Scan(&role.ID, &role.Name, &description, &role.CreatedAt)
+// getRole runs a query selecting a single role
+func (r *rbacRepository) getRole(query string, arg string) (*Role, error) {
+	role, err := scanRole(r.db.QueryRow(query, arg))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.New(errors.ResourceNotFound)
 		}
 		return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
 	}
-	role.Description = description.String
 	return &role, nil
 }
 
+func (r *rbacRepository) GetRoles() ([]Role, error) {
+	query := `SELECT ` + roleColumns + ` FROM roles r ORDER BY r.name`
+	return r.queryRoles(query)
+}
+
+func (r *rbacRepository) GetRoleByID(id string) (*Role, error) {
+	query := `SELECT ` + roleColumns + ` FROM roles r WHERE r.id = $1`
+	return r.getRole(query, id)
+}
+
 func (r *rbacRepository) GetRoleByName(name string) (*Role, error) {
-	query := `SELECT id, name, description, created_at FROM roles WHERE name = $1`
-	var role Role
-	var description sql.NullString
-	err := r.db.QueryRow(query, name).Scan(&role.ID, &role.Name, &description, &role.CreatedAt)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, errors.New(errors.ResourceNotFound)
-		}
-		return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
-	}
-	role.Description = description.String
-	return &role, nil
+	query := `SELECT ` + roleColumns + ` FROM roles r WHERE r.name = $1`
+	return r.getRole(query, name)
 }
 
 func (r *rbacRepository) CreateRole(role *Role) error {
 	role.ID = uuid.New().String()
 	role.CreatedAt = time.Now()
 
-	query := `INSERT INTO roles (id, name, description, created_at) VALUES ($1, $2, $3, $4)`
-	_, err := r.db.Exec(query, role.ID, role.Name, role.Description, role.CreatedAt)
+	query := `INSERT INTO roles (id, name, description, parent_id, created_at) VALUES ($1, $2, $3, $4, $5)`
+	_, err := r.db.Exec(query, role.ID, role.Name, role.Description, nullableID(role.ParentID), role.CreatedAt)
 	if err != nil {
 		return errors.Wrap(err, errors.DatabaseInsertFailed)
 	}
@@ -101,10 +239,18 @@ func (r *rbacRepository) UpdateRole(role *Role) error {
 	if rowsAffected == 0 {
 		return errors.New(errors.ResourceNotFound)
 	}
+
+	// Cached roles and role checks use the name
+	r.invalidateRoleHolders(role.ID)
 	return nil
 }
 
 func (r *rbacRepository) DeleteRole(id string) error {
+	userIDs, err := r.roleHolderIDs(id)
+	if err != nil {
+		return err
+	}
+
 	query := `DELETE FROM roles WHERE id = $1`
 	result, err := r.db.Exec(query, id)
 	if err != nil {
@@ -115,14 +261,104 @@ func (r *rbacRepository) DeleteRole(id string) error {
 	if rowsAffected == 0 {
 		return errors.New(errors.ResourceNotFound)
 	}
+
+	r.invalidateUsers(userIDs)
+	return nil
+}
+
+// ==================== Role Hierarchy ====================
+
+// maxRoleDepthSQL bounds every recursive hierarchy query
+var maxRoleDepthSQL = strconv.Itoa(MaxRoleDepth)
+
+// nullableID maps an empty ID to NULL
+func nullableID(id string) interface{} {
+	if id == "" {
+		return nil
+	}
+	return id
+}
+
+func (r *rbacRepository) SetRoleParent(roleID, parentID string) error {
+	query := `UPDATE roles SET parent_id = $2 WHERE id = $1`
+	result, err := r.db.Exec(query, roleID, nullableID(parentID))
+	if err != nil {
+		return errors.Wrap(err, errors.DatabaseUpdateFailed)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return errors.New(errors.ResourceNotFound)
+	}
+
+	// Holders of the role and of every descendant now inherit a different set
+	r.invalidateRoleHolders(roleID)
 	return nil
 }
 
+func (r *rbacRepository) GetRoleHeight(roleID string) (int, error) {
+	query := `
+		WITH RECURSIVE descendants AS (
+			SELECT id, 0 AS depth FROM roles WHERE id = $1
+			UNION
+			SELECT child.id, d.depth + 1
+			FROM roles child
+			INNER JOIN descendants d ON child.parent_id = d.id
+			WHERE d.depth < ` + maxRoleDepthSQL + `
+		)
+		SELECT COALESCE(MAX(depth), 0) FROM descendants
+	`
+	var height int
+	if err := r.db.QueryRow(query, roleID).Scan(&height); err != nil {
+		return 0, errors.Wrap(err, errors.DatabaseQueryFailed)
+	}
+	return height, nil
+}
+
+func (r *rbacRepository) GetRoleAncestors(roleID string) ([]Role, error) {
+	// The depth bound stops the recursion even if a cycle was written to the table directly
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT parent.id, parent.parent_id, 1 AS depth
+			FROM roles child
+			INNER JOIN roles parent ON parent.id = child.parent_id
+			WHERE child.id = $1
+			UNION
+			SELECT parent.id, parent.parent_id, a.depth + 1
+			FROM roles parent
+			INNER JOIN ancestors a ON parent.id = a.parent_id
+			WHERE a.depth < ` + maxRoleDepthSQL + `
+		)
+		SELECT ` + roleColumns + `
+		FROM roles r
+		INNER JOIN (SELECT id, MIN(depth) AS depth FROM ancestors GROUP BY id) a ON a.id = r.id
+		ORDER BY a.depth
+	`
+	return r.queryRoles(query, roleID)
+}
+
 // ==================== Permission Operations ====================
 
-func (r *rbacRepository) GetPermissions() ([]Permission, error) {
-	query := `SELECT id, name, description, resource, action, created_at FROM permissions ORDER BY resource, action`
-	rows, err := r.db.Query(query)
+// permissionColumns is the column list read by scanPermission; queries alias permissions as p
+const permissionColumns = `p.id, p.name, p.description, p.resource, p.action, p.is_system, p.created_at`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPermission reads a row selected with permissionColumns
+func scanPermission(row rowScanner) (Permission, error) {
+	var permission Permission
+	var description sql.NullString
+	err := row.Scan(&permission.ID, &permission.Name, &description, &permission.Resource, &permission.Action, &permission.IsSystem, &permission.CreatedAt)
+	permission.Description = description.String
+	return permission, err
+}
+
+// queryPermissions runs a query selecting permissionColumns and collects the rows
+func (r *rbacRepository) queryPermissions(query string, args ...interface{}) ([]Permission, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
 	}
@@ -130,239 +366,1178 @@ func (r *rbacRepository) GetPermissions() ([]Permission, error) {
 
 	var permissions []Permission
 	for rows.Next() {
-		var permission Permission
-		var description sql.NullString
-		if err := rows.Scan(&permission.ID, &permission.Name, &description, &permission.Resource, &permission.Action, &permission.CreatedAt); err != nil {
+		permission, err := scanPermission(rows)
+		if err != nil {
 			return nil, errors.Wrap(err, errors.DatabaseScanFailed)
 		}
-		permission.Description = description.String
 		permissions = append(permissions, permission)
 	}
 
 	return permissions, nil
 }
 
-func (r *rbacRepository) GetPermissionByID(id string) (*Permission, error) {
-	query := `SELECT id, name, description, resource, action, created_at FROM permissions WHERE id = $1`
-	var permission Permission
-	var description sql.NullString
-	err := r.db.QueryRow(query, id).Scan(&permission.ID, &permission.Name, &description, &permission.Resource, &permission.Action, &permission.CreatedAt)
+// getPermission runs a query selecting a single permission
+func (r *rbacRepository) getPermission(query string, arg string) (*Permission, error) {
+	permission, err := scanPermission(r.db.QueryRow(query, arg))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.New(errors.ResourceNotFound)
 		}
 		return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
 	}
-	permission.Description = description.String
 	return &permission, nil
 }
 
+func (r *rbacRepository) GetPermissions() ([]Permission, error) {
+	query := `SELECT ` + permissionColumns + ` FROM permissions p ORDER BY p.resource, p.action`
+	return r.queryPermissions(query)
+}
+
+func (r *rbacRepository) GetPermissionByID(id string) (*Permission, error) {
+	query := `SELECT ` + permissionColumns + ` FROM permissions p WHERE p.id = $1`
+	return r.getPermission(query, id)
+}
+
 func (r *rbacRepository) GetPermissionByName(name string) (*Permission, error) {
-	query := `SELECT id, name, description, resource, action, created_at FROM permissions WHERE name = $1`
-	var permission Permission
-	var description sql.NullString
-	err := r.db.QueryRow(query, name).Scan(&permission.ID, &permission.Name, &description, &permission.Resource, &permission.Action, &permission.CreatedAt)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, errors.New(errors.ResourceNotFound)
-		}
-		return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
-	}
-	permission.Description = description.String
-	return &permission, nil
+	query := `SELECT ` + permissionColumns + ` FROM permissions p WHERE p.name = $1`
+	return r.getPermission(query, name)
 }
 
 func (r *rbacRepository) CreatePermission(permission *Permission) error {
 	permission.ID = uuid.New().String()
 	permission.CreatedAt = time.Now()
 
-	query := `INSERT INTO permissions (id, name, description, resource, action, created_at) VALUES ($1, $2, $3, $4, $5, $6)`
-	_, err := r.db.Exec(query, permission.ID, permission.Name, permission.Description, permission.Resource, permission.Action, permission.CreatedAt)
+	query := `INSERT INTO permissions (id, name, description, resource, action, is_system, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := r.db.Exec(query, permission.ID, permission.Name, permission.Description, permission.Resource, permission.Action, permission.IsSystem, permission.CreatedAt)
 	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			return errors.New(errors.PermissionExists)
+		}
 		return errors.Wrap(err, errors.DatabaseInsertFailed)
 	}
 	return nil
 }
 
-// ==================== User-Role Operations ====================
-
-func (r *rbacRepository) GetUserRoles(userID string) ([]Role, error) {
-	cacheKey := r.cacheHelper.BuildUserCacheKey(userID, "roles")
-
-	// Try cache first
-	var cachedRoles []Role
-	if err := r.cacheHelper.GetJSON(context.Background(), cacheKey, &cachedRoles); err == nil {
-		return cachedRoles, nil
-	}
-
-	query := `
-		SELECT r.id, r.name, r.description, r.created_at
-		FROM roles r
-		INNER JOIN user_roles ur ON r.id = ur.role_id
-		WHERE ur.user_id = $1
-		ORDER BY r.name
-	`
-	rows, err := r.db.Query(query, userID)
+func (r *rbacRepository) UpdatePermission(permission *Permission) error {
+	query := `UPDATE permissions SET name = $2, description = $3, resource = $4, action = $5 WHERE id = $1`
+	result, err := r.db.Exec(query, permission.ID, permission.Name, permission.Description, permission.Resource, permission.Action)
 	if err != nil {
-		return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
-	}
-	defer rows.Close()
-
-	var roles []Role
-	for rows.Next() {
-		var role Role
-		var description sql.NullString
-		if err := rows.Scan(&role.ID, &role.Name, &description, &role.CreatedAt); err != nil {
-			return nil, errors.Wrap(err, errors.DatabaseScanFailed)
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			return errors.New(errors.PermissionExists)
 		}
-		role.Description = description.String
-		roles = append(roles, role)
+		return errors.Wrap(err, errors.DatabaseUpdateFailed)
 	}
 
-	// Cache the result
-	_ = r.cacheHelper.CacheJSON(context.Background(), cacheKey, roles, 5*time.Minute)
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return errors.New(errors.ResourceNotFound)
+	}
 
-	return roles, nil
+	// Cached permission lists carry the old name
+	r.invalidatePermissionHolders(permission.ID)
+	return nil
 }
 
-func (r *rbacRepository) AssignRoleToUser(userID, roleID string) error {
-	query := `INSERT INTO user_roles (user_id, role_id, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`
-	_, err := r.db.Exec(query, userID, roleID, time.Now())
+func (r *rbacRepository) MarkPermissionSystem(id string) error {
+	query := `UPDATE permissions SET is_system = TRUE WHERE id = $1`
+	result, err := r.db.Exec(query, id)
 	if err != nil {
-		return errors.Wrap(err, errors.DatabaseInsertFailed)
+		return errors.Wrap(err, errors.DatabaseUpdateFailed)
 	}
 
-	// Invalidate cache
-	_ = r.cacheHelper.InvalidateUserCache(context.Background(), userID)
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return errors.New(errors.ResourceNotFound)
+	}
 	return nil
 }
 
-func (r *rbacRepository) RemoveRoleFromUser(userID, roleID string) error {
-	query := `DELETE FROM user_roles WHERE user_id = $1 AND role_id = $2`
-	_, err := r.db.Exec(query, userID, roleID)
+func (r *rbacRepository) DeletePermission(id string) error {
+	// Collect holders first; the cascade removes the role_permissions rows that link them
+	userIDs, err := r.permissionHolderIDs(id)
 	if err != nil {
-		return errors.Wrap(err, errors.DatabaseDeleteFailed)
+		return err
 	}
 
-	// Invalidate cache
-	_ = r.cacheHelper.InvalidateUserCache(context.Background(), userID)
-	return nil
-}
-
-func (r *rbacRepository) HasRole(userID, roleName string) (bool, error) {
-	roles, err := r.GetUserRoles(userID)
+	query := `DELETE FROM permissions WHERE id = $1`
+	result, err := r.db.Exec(query, id)
 	if err != nil {
-		return false, err
+		return errors.Wrap(err, errors.DatabaseDeleteFailed)
 	}
 
-	for _, role := range roles {
-		if role.Name == roleName {
-			return true, nil
-		}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return errors.New(errors.ResourceNotFound)
 	}
-	return false, nil
+
+	r.invalidateUsers(userIDs)
+	return nil
 }
 
-// ==================== Role-Permission Operations ====================
+// ==================== Cache Invalidation ====================
 
-func (r *rbacRepository) GetRolePermissions(roleID string) ([]Permission, error) {
+// permissionHolderIDs returns the users granted or denied the permission through any of their
+// roles, directly or inherited from an ancestor role and whether assigned directly or through a
+// group, plus the users with a per-user deny of it
+func (r *rbacRepository) permissionHolderIDs(permissionID string) ([]string, error) {
 	query := `
-		SELECT p.id, p.name, p.description, p.resource, p.action, p.created_at
-		FROM permissions p
-		INNER JOIN role_permissions rp ON p.id = rp.permission_id
-		WHERE rp.role_id = $1
-		ORDER BY p.resource, p.action
+		WITH RECURSIVE referencing AS (
+			SELECT id, 1 AS depth FROM roles WHERE id IN (
+				SELECT role_id FROM role_permissions WHERE permission_id = $1
+				UNION
+				SELECT role_id FROM role_permission_denies WHERE permission_id = $1
+			)
+			UNION
+			SELECT child.id, ref.depth + 1
+			FROM roles child
+			INNER JOIN referencing ref ON child.parent_id = ref.id
+			WHERE ref.depth < ` + maxRoleDepthSQL + `
+		)
+		` + holdersOfRolesSQL("referencing") + `
+		UNION
+		SELECT user_id FROM user_permission_denies WHERE permission_id = $1
+	`
+	return r.queryUserIDs(query, permissionID)
+}
+
+// roleHolderIDs returns the users assigned the role or any role that inherits from it, directly
+// or through a group
+func (r *rbacRepository) roleHolderIDs(roleID string) ([]string, error) {
+	query := `
+		WITH RECURSIVE descendants AS (
+			SELECT id, 1 AS depth FROM roles WHERE id = $1
+			UNION
+			SELECT child.id, d.depth + 1
+			FROM roles child
+			INNER JOIN descendants d ON child.parent_id = d.id
+			WHERE d.depth < ` + maxRoleDepthSQL + `
+		)
+		` + holdersOfRolesSQL("descendants") + `
+	`
+	return r.queryUserIDs(query, roleID)
+}
+
+// holdersOfRolesSQL selects the distinct users assigned any role in the roles CTE, directly or
+// through a group
+func holdersOfRolesSQL(roles string) string {
+	return `
+		SELECT user_id FROM user_roles WHERE role_id IN (SELECT id FROM ` + roles + `)
+		UNION
+		SELECT gm.user_id FROM group_members gm
+		INNER JOIN group_roles gr ON gr.group_id = gm.group_id
+		WHERE gr.role_id IN (SELECT id FROM ` + roles + `)
 	`
-	rows, err := r.db.Query(query, roleID)
+}
+
+// groupMemberIDs returns the users in the group
+func (r *rbacRepository) groupMemberIDs(groupID string) ([]string, error) {
+	query := `SELECT user_id FROM group_members WHERE group_id = $1`
+	return r.queryUserIDs(query, groupID)
+}
+
+func (r *rbacRepository) queryUserIDs(query string, args ...interface{}) ([]string, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
 	}
 	defer rows.Close()
 
-	var permissions []Permission
+	var userIDs []string
 	for rows.Next() {
-		var permission Permission
-		var description sql.NullString
-		if err := rows.Scan(&permission.ID, &permission.Name, &description, &permission.Resource, &permission.Action, &permission.CreatedAt); err != nil {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
 			return nil, errors.Wrap(err, errors.DatabaseScanFailed)
 		}
-		permission.Description = description.String
-		permissions = append(permissions, permission)
+		userIDs = append(userIDs, userID)
 	}
 
-	return permissions, nil
+	return userIDs, nil
 }
 
-func (r *rbacRepository) AssignPermissionToRole(roleID, permissionID string) error {
-	query := `INSERT INTO role_permissions (role_id, permission_id, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`
-	_, err := r.db.Exec(query, roleID, permissionID, time.Now())
+// invalidatePermissionHolders drops cached roles and permissions for every holder of the permission
+func (r *rbacRepository) invalidatePermissionHolders(permissionID string) {
+	userIDs, err := r.permissionHolderIDs(permissionID)
 	if err != nil {
-		return errors.Wrap(err, errors.DatabaseInsertFailed)
+		return
 	}
-	return nil
+	r.invalidateUsers(userIDs)
 }
 
-func (r *rbacRepository) RemovePermissionFromRole(roleID, permissionID string) error {
-	query := `DELETE FROM role_permissions WHERE role_id = $1 AND permission_id = $2`
-	_, err := r.db.Exec(query, roleID, permissionID)
+// invalidateRoleHolders drops cached roles and permissions for every user assigned the role
+func (r *rbacRepository) invalidateRoleHolders(roleID string) {
+	userIDs, err := r.roleHolderIDs(roleID)
 	if err != nil {
-		return errors.Wrap(err, errors.DatabaseDeleteFailed)
+		return
 	}
-	return nil
+	r.invalidateUsers(userIDs)
 }
 
-// ==================== User Permission Check ====================
-
-func (r *rbacRepository) GetUserPermissions(userID string) ([]Permission, error) {
-	cacheKey := r.cacheHelper.BuildUserCacheKey(userID, "permissions")
+// invalidateGroupMembers drops cached roles and permissions for every member of the group
+func (r *rbacRepository) invalidateGroupMembers(groupID string) {
+	userIDs, err := r.groupMemberIDs(groupID)
+	if err != nil {
+		return
+	}
+	r.invalidateUsers(userIDs)
+}
 
-	// Try cache first
-	var cachedPermissions []Permission
-	if err := r.cacheHelper.GetJSON(context.Background(), cacheKey, &cachedPermissions); err == nil {
-		return cachedPermissions, nil
+// invalidateUsers outdates the users' cached roles and permissions, once the surrounding
+// transaction commits when inside WithTransaction
+func (r *rbacRepository) invalidateUsers(userIDs []string) {
+	if r.staleUsers != nil {
+		*r.staleUsers = append(*r.staleUsers, userIDs...)
+		return
 	}
+	r.cache.invalidate(userIDs)
+}
 
-	query := `
-		SELECT DISTINCT p.id, p.name, p.description, p.resource, p.action, p.created_at
-		FROM permissions p
-		INNER JOIN role_permissions rp ON p.id = rp.permission_id
-		INNER JOIN user_roles ur ON rp.role_id = ur.role_id
-		WHERE ur.user_id = $1
-		ORDER BY p.resource, p.action
-	`
-	rows, err := r.db.Query(query, userID)
+// ==================== User-Role Operations ====================
+
+// activeGrantSQL restricts user_roles (aliased ur) to assignments active now
+const activeGrantSQL = `(ur.valid_from IS NULL OR ur.valid_from <= NOW()) AND (ur.valid_until IS NULL OR ur.valid_until > NOW())`
+
+// userRoleColumns is roleColumns plus the assignment's validity window and the group it comes
+// through; queries alias the assignments as ur
+const userRoleColumns = roleColumns + `, ur.valid_from, ur.valid_until, ur.group_name`
+
+// queryUserRoles runs a query selecting userRoleColumns and collects the rows
+func (r *rbacRepository) queryUserRoles(query string, args ...interface{}) ([]Role, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
 	}
 	defer rows.Close()
 
-	var permissions []Permission
+	var roles []Role
 	for rows.Next() {
-		var permission Permission
-		var description sql.NullString
-		if err := rows.Scan(&permission.ID, &permission.Name, &description, &permission.Resource, &permission.Action, &permission.CreatedAt); err != nil {
+		var role Role
+		var description, parentID sql.NullString
+		var validFrom, validUntil sql.NullTime
+		var group sql.NullString
+		if err := rows.Scan(&role.ID, &role.Name, &description, &parentID, &role.CreatedAt, &validFrom, &validUntil, &group); err != nil {
 			return nil, errors.Wrap(err, errors.DatabaseScanFailed)
 		}
-		permission.Description = description.String
-		permissions = append(permissions, permission)
+		role.Description = description.String
+		role.ParentID = parentID.String
+		role.Group = group.String
+		if validFrom.Valid {
+			role.ValidFrom = &validFrom.Time
+		}
+		if validUntil.Valid {
+			role.ValidUntil = &validUntil.Time
+		}
+		roles = append(roles, role)
 	}
 
-	// Cache the result
-	_ = r.cacheHelper.CacheJSON(context.Background(), cacheKey, permissions, 5*time.Minute)
-
-	return permissions, nil
+	return roles, nil
 }
 
-func (r *rbacRepository) HasPermission(userID, permissionName string) (bool, error) {
-	permissions, err := r.GetUserPermissions(userID)
-	if err != nil {
-		return false, err
-	}
+// grantCacheTTL caps the cache lifetime of a user's roles and permissions at the next moment one
+// of their assignments starts or ends, so temporary grants take effect and lapse on time
+func (r *rbacRepository) grantCacheTTL(userID string) time.Duration {
+	ttl := GrantCacheTTL
 
-	for _, permission := range permissions {
-		if permission.Name == permissionName {
-			return true, nil
+	query := `
+		SELECT MIN(boundary) FROM (
+			SELECT valid_from AS boundary FROM user_roles WHERE user_id = $1 AND valid_from > NOW()
+			UNION ALL
+			SELECT valid_until FROM user_roles WHERE user_id = $1 AND valid_until > NOW()
+		) boundaries
+	`
+	var next sql.NullTime
+	if err := r.db.QueryRow(query, userID).Scan(&next); err != nil || !next.Valid {
+		return ttl
+	}
+
+	if untilNext := time.Until(next.Time); untilNext < ttl {
+		ttl = untilNext
+	}
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+	return ttl
+}
+
+// grantTTL defers grantCacheTTL until a cache miss is stored
+func (r *rbacRepository) grantTTL(userID string) func() time.Duration {
+	return func() time.Duration { return r.grantCacheTTL(userID) }
+}
+
+// GetUserRoles returns the user's active global roles, assigned directly or through their groups.
+// A role held both ways is listed once, as the direct assignment.
+func (r *rbacRepository) GetUserRoles(userID string) ([]Role, error) {
+	query := `
+		SELECT DISTINCT ON (r.name) ` + userRoleColumns + `
+		FROM roles r
+		INNER JOIN (
+			SELECT ur.role_id, ur.valid_from, ur.valid_until, NULL AS group_name
+			FROM user_roles ur
+			WHERE ur.user_id = $1 AND ur.organization_id IS NULL AND ` + activeGrantSQL + `
+			UNION ALL
+			SELECT gr.role_id, NULL, NULL, g.name
+			FROM group_roles gr
+			INNER JOIN group_members gm ON gm.group_id = gr.group_id
+			INNER JOIN groups g ON g.id = gr.group_id
+			WHERE gm.user_id = $1
+		) ur ON r.id = ur.role_id
+		ORDER BY r.name, ur.group_name NULLS FIRST
+	`
+	return loadGrants(r.cache, userID, "roles", r.grantTTL(userID), func() ([]Role, error) {
+		return r.queryUserRoles(query, userID)
+	})
+}
+
+// AssignRoleToUser grants the role globally; re-assigning replaces the validity window
+func (r *rbacRepository) AssignRoleToUser(userID, roleID string, validity RoleValidity) error {
+	query := `
+		INSERT INTO user_roles (user_id, role_id, valid_from, valid_until, created_at) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, role_id) WHERE organization_id IS NULL
+		DO UPDATE SET valid_from = EXCLUDED.valid_from, valid_until = EXCLUDED.valid_until
+	`
+	_, err := r.db.Exec(query, userID, roleID, validity.From, validity.Until, time.Now())
+	if err != nil {
+		return errors.Wrap(err, errors.DatabaseInsertFailed)
+	}
+
+	// Invalidate cache
+	r.invalidateUsers([]string{userID})
+	return nil
+}
+
+func (r *rbacRepository) RemoveRoleFromUser(userID, roleID string) error {
+	query := `DELETE FROM user_roles WHERE user_id = $1 AND role_id = $2 AND organization_id IS NULL`
+	_, err := r.db.Exec(query, userID, roleID)
+	if err != nil {
+		return errors.Wrap(err, errors.DatabaseDeleteFailed)
+	}
+
+	// Invalidate cache
+	r.invalidateUsers([]string{userID})
+	return nil
+}
+
+// ==================== Tenant-Scoped User-Role Operations ====================
+
+// GetUserRolesInTenant returns only the roles assigned to the user inside the organization
+func (r *rbacRepository) GetUserRolesInTenant(userID, organizationID string) ([]Role, error) {
+	query := `
+		SELECT ` + userRoleColumns + `
+		FROM roles r
+		INNER JOIN (
+			SELECT ur.role_id, ur.valid_from, ur.valid_until, NULL::text AS group_name
+			FROM user_roles ur
+			WHERE ur.user_id = $1 AND ur.organization_id = $2 AND ` + activeGrantSQL + `
+		) ur ON r.id = ur.role_id
+		ORDER BY r.name
+	`
+	return r.queryUserRoles(query, userID, organizationID)
+}
+
+func (r *rbacRepository) AssignRoleToUserInTenant(userID, roleID, organizationID string) error {
+	query := `INSERT INTO user_roles (user_id, role_id, organization_id, created_at) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`
+	_, err := r.db.Exec(query, userID, roleID, organizationID, time.Now())
+	if err != nil {
+		return errors.Wrap(err, errors.DatabaseInsertFailed)
+	}
+
+	r.invalidateUsers([]string{userID})
+	return nil
+}
+
+func (r *rbacRepository) RemoveRoleFromUserInTenant(userID, roleID, organizationID string) error {
+	query := `DELETE FROM user_roles WHERE user_id = $1 AND role_id = $2 AND organization_id = $3`
+	_, err := r.db.Exec(query, userID, roleID, organizationID)
+	if err != nil {
+		return errors.Wrap(err, errors.DatabaseDeleteFailed)
+	}
+
+	r.invalidateUsers([]string{userID})
+	return nil
+}
+
+// DeleteExpiredUserRoles removes assignments whose valid_until has passed and returns the
+// affected users after invalidating their cached roles and permissions. The delete returns rows,
+// so it runs in a transaction for the actor to be recorded.
+func (r *rbacRepository) DeleteExpiredUserRoles() ([]string, error) {
+	query := `
+		WITH expired AS (DELETE FROM user_roles WHERE valid_until <= NOW() RETURNING user_id)
+		SELECT DISTINCT user_id FROM expired
+	`
+	var userIDs []string
+	err := r.WithTransaction(func(repo RBACRepository) error {
+		txRepo := repo.(*rbacRepository)
+		var err error
+		if userIDs, err = txRepo.queryUserIDs(query); err != nil {
+			return err
+		}
+		txRepo.invalidateUsers(userIDs)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
+func (r *rbacRepository) HasRole(userID, roleName string) (bool, error) {
+	roles, err := r.GetUserRoles(userID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, role := range roles {
+		if role.Name == roleName {
+			return true, nil
 		}
 	}
 	return false, nil
 }
+
+// ==================== Group Operations ====================
+
+// groupColumns is the column list read by scanGroup; queries alias groups as g
+const groupColumns = `g.id, g.name, g.description, g.created_at, g.updated_at`
+
+// scanGroup reads a row selected with groupColumns
+func scanGroup(row rowScanner) (Group, error) {
+	var group Group
+	var description sql.NullString
+	err := row.Scan(&group.ID, &group.Name, &description, &group.CreatedAt, &group.UpdatedAt)
+	group.Description = description.String
+	return group, err
+}
+
+// queryGroups runs a query selecting groupColumns and collects the rows
+func (r *rbacRepository) queryGroups(query string, args ...interface{}) ([]Group, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
+	}
+	defer rows.Close()
+
+	var groups []Group
+	for rows.Next() {
+		group, err := scanGroup(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.DatabaseScanFailed)
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+func (r *rbacRepository) GetGroups() ([]Group, error) {
+	query := `SELECT ` + groupColumns + ` FROM groups g ORDER BY g.name`
+	return r.queryGroups(query)
+}
+
+func (r *rbacRepository) GetGroupByID(id string) (*Group, error) {
+	query := `SELECT ` + groupColumns + ` FROM groups g WHERE g.id = $1`
+	group, err := scanGroup(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New(errors.ResourceNotFound)
+		}
+		return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
+	}
+	return &group, nil
+}
+
+func (r *rbacRepository) CreateGroup(group *Group) error {
+	group.ID = uuid.New().String()
+	group.CreatedAt = time.Now()
+	group.UpdatedAt = group.CreatedAt
+
+	query := `INSERT INTO groups (id, name, description, created_at, updated_at) VALUES ($1, $2, $3, $4, $5)`
+	_, err := r.db.Exec(query, group.ID, group.Name, group.Description, group.CreatedAt, group.UpdatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			return errors.New(errors.Conflict)
+		}
+		return errors.Wrap(err, errors.DatabaseInsertFailed)
+	}
+	return nil
+}
+
+func (r *rbacRepository) UpdateGroup(group *Group) error {
+	group.UpdatedAt = time.Now()
+
+	query := `UPDATE groups SET name = $2, description = $3 WHERE id = $1`
+	result, err := r.db.Exec(query, group.ID, group.Name, group.Description)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			return errors.New(errors.Conflict)
+		}
+		return errors.Wrap(err, errors.DatabaseUpdateFailed)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return errors.New(errors.ResourceNotFound)
+	}
+
+	// Cached roles name the group they come through
+	r.invalidateGroupMembers(group.ID)
+	return nil
+}
+
+func (r *rbacRepository) DeleteGroup(id string) error {
+	// Collect members first; the cascade removes their memberships
+	userIDs, err := r.groupMemberIDs(id)
+	if err != nil {
+		return err
+	}
+
+	query := `DELETE FROM groups WHERE id = $1`
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return errors.Wrap(err, errors.DatabaseDeleteFailed)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return errors.New(errors.ResourceNotFound)
+	}
+
+	r.invalidateUsers(userIDs)
+	return nil
+}
+
+func (r *rbacRepository) GetGroupMembers(groupID string) ([]GroupMember, error) {
+	query := `
+		SELECT u.id, u.name, u.email, gm.created_at
+		FROM group_members gm
+		INNER JOIN users u ON u.id = gm.user_id
+		WHERE gm.group_id = $1
+		ORDER BY u.name
+	`
+	rows, err := r.db.Query(query, groupID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
+	}
+	defer rows.Close()
+
+	var members []GroupMember
+	for rows.Next() {
+		var member GroupMember
+		if err := rows.Scan(&member.UserID, &member.Name, &member.Email, &member.JoinedAt); err != nil {
+			return nil, errors.Wrap(err, errors.DatabaseScanFailed)
+		}
+		members = append(members, member)
+	}
+
+	return members, nil
+}
+
+func (r *rbacRepository) AddUserToGroup(groupID, userID string) error {
+	query := `INSERT INTO group_members (group_id, user_id, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`
+	if _, err := r.db.Exec(query, groupID, userID, time.Now()); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "foreign_key_violation" {
+			return errors.New(errors.AccountNotFound)
+		}
+		return errors.Wrap(err, errors.DatabaseInsertFailed)
+	}
+
+	r.invalidateUsers([]string{userID})
+	return nil
+}
+
+func (r *rbacRepository) RemoveUserFromGroup(groupID, userID string) error {
+	query := `DELETE FROM group_members WHERE group_id = $1 AND user_id = $2`
+	if _, err := r.db.Exec(query, groupID, userID); err != nil {
+		return errors.Wrap(err, errors.DatabaseDeleteFailed)
+	}
+
+	r.invalidateUsers([]string{userID})
+	return nil
+}
+
+func (r *rbacRepository) GetUserGroups(userID string) ([]Group, error) {
+	query := `
+		SELECT ` + groupColumns + `
+		FROM groups g
+		INNER JOIN group_members gm ON gm.group_id = g.id
+		WHERE gm.user_id = $1
+		ORDER BY g.name
+	`
+	return r.queryGroups(query, userID)
+}
+
+func (r *rbacRepository) GetGroupRoles(groupID string) ([]Role, error) {
+	query := `
+		SELECT ` + roleColumns + `
+		FROM roles r
+		INNER JOIN group_roles gr ON gr.role_id = r.id
+		WHERE gr.group_id = $1
+		ORDER BY r.name
+	`
+	return r.queryRoles(query, groupID)
+}
+
+func (r *rbacRepository) AssignRoleToGroup(groupID, roleID string) error {
+	query := `INSERT INTO group_roles (group_id, role_id, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`
+	if _, err := r.db.Exec(query, groupID, roleID, time.Now()); err != nil {
+		return errors.Wrap(err, errors.DatabaseInsertFailed)
+	}
+
+	r.invalidateGroupMembers(groupID)
+	return nil
+}
+
+func (r *rbacRepository) RemoveRoleFromGroup(groupID, roleID string) error {
+	query := `DELETE FROM group_roles WHERE group_id = $1 AND role_id = $2`
+	if _, err := r.db.Exec(query, groupID, roleID); err != nil {
+		return errors.Wrap(err, errors.DatabaseDeleteFailed)
+	}
+
+	r.invalidateGroupMembers(groupID)
+	return nil
+}
+
+// ==================== Role-Permission Operations ====================
+
+func (r *rbacRepository) GetRolePermissions(roleID string) ([]Permission, error) {
+	query := `
+		SELECT ` + permissionColumns + `
+		FROM permissions p
+		INNER JOIN role_permissions rp ON p.id = rp.permission_id
+		WHERE rp.role_id = $1
+		ORDER BY p.resource, p.action
+	`
+	return r.queryPermissions(query, roleID)
+}
+
+func (r *rbacRepository) AssignPermissionToRole(roleID, permissionID string) error {
+	query := `INSERT INTO role_permissions (role_id, permission_id, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`
+	_, err := r.db.Exec(query, roleID, permissionID, time.Now())
+	if err != nil {
+		return errors.Wrap(err, errors.DatabaseInsertFailed)
+	}
+
+	r.invalidateRoleHolders(roleID)
+	return nil
+}
+
+func (r *rbacRepository) RemovePermissionFromRole(roleID, permissionID string) error {
+	query := `DELETE FROM role_permissions WHERE role_id = $1 AND permission_id = $2`
+	_, err := r.db.Exec(query, roleID, permissionID)
+	if err != nil {
+		return errors.Wrap(err, errors.DatabaseDeleteFailed)
+	}
+
+	r.invalidateRoleHolders(roleID)
+	return nil
+}
+
+// ==================== Deny Operations ====================
+
+func (r *rbacRepository) GetRoleDenies(roleID string) ([]Permission, error) {
+	query := `
+		SELECT ` + permissionColumns + `
+		FROM permissions p
+		INNER JOIN role_permission_denies d ON p.id = d.permission_id
+		WHERE d.role_id = $1
+		ORDER BY p.resource, p.action
+	`
+	return r.queryPermissions(query, roleID)
+}
+
+func (r *rbacRepository) AddDenyToRole(roleID, permissionID string) error {
+	query := `INSERT INTO role_permission_denies (role_id, permission_id, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`
+	if _, err := r.db.Exec(query, roleID, permissionID, time.Now()); err != nil {
+		return errors.Wrap(err, errors.DatabaseInsertFailed)
+	}
+
+	r.invalidateRoleHolders(roleID)
+	return nil
+}
+
+func (r *rbacRepository) RemoveDenyFromRole(roleID, permissionID string) error {
+	query := `DELETE FROM role_permission_denies WHERE role_id = $1 AND permission_id = $2`
+	if _, err := r.db.Exec(query, roleID, permissionID); err != nil {
+		return errors.Wrap(err, errors.DatabaseDeleteFailed)
+	}
+
+	r.invalidateRoleHolders(roleID)
+	return nil
+}
+
+// GetUserDenies returns the permissions denied to the user by per-user overrides only
+func (r *rbacRepository) GetUserDenies(userID string) ([]Permission, error) {
+	query := `
+		SELECT ` + permissionColumns + `
+		FROM permissions p
+		INNER JOIN user_permission_denies d ON p.id = d.permission_id
+		WHERE d.user_id = $1
+		ORDER BY p.resource, p.action
+	`
+	return r.queryPermissions(query, userID)
+}
+
+func (r *rbacRepository) AddDenyToUser(userID, permissionID string) error {
+	query := `INSERT INTO user_permission_denies (user_id, permission_id, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`
+	if _, err := r.db.Exec(query, userID, permissionID, time.Now()); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "foreign_key_violation" {
+			return errors.New(errors.AccountNotFound)
+		}
+		return errors.Wrap(err, errors.DatabaseInsertFailed)
+	}
+
+	r.invalidateUsers([]string{userID})
+	return nil
+}
+
+func (r *rbacRepository) RemoveDenyFromUser(userID, permissionID string) error {
+	query := `DELETE FROM user_permission_denies WHERE user_id = $1 AND permission_id = $2`
+	if _, err := r.db.Exec(query, userID, permissionID); err != nil {
+		return errors.Wrap(err, errors.DatabaseDeleteFailed)
+	}
+
+	r.invalidateUsers([]string{userID})
+	return nil
+}
+
+// GetUserDenyRules returns every deny rule that applies to the user: those of their effective
+// roles, inside the organization when organizationID is set, and their per-user overrides
+func (r *rbacRepository) GetUserDenyRules(userID, organizationID string) ([]DenyRule, error) {
+	keyType := "denies"
+	if organizationID != "" {
+		keyType += ":" + organizationID
+	}
+	query := `
+		WITH RECURSIVE ` + effectiveRolesSQL + `
+		SELECT p.name, r.name
+		FROM role_permission_denies d
+		INNER JOIN permissions p ON p.id = d.permission_id
+		INNER JOIN roles r ON r.id = d.role_id
+		WHERE d.role_id IN (SELECT id FROM effective_roles)
+		UNION
+		SELECT p.name, NULL
+		FROM user_permission_denies d
+		INNER JOIN permissions p ON p.id = d.permission_id
+		WHERE d.user_id = $1
+		ORDER BY 1, 2 NULLS FIRST
+	`
+	return loadGrants(r.cache, userID, keyType, r.grantTTL(userID), func() ([]DenyRule, error) {
+		rows, err := r.db.Query(query, userID, nullableID(organizationID))
+		if err != nil {
+			return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
+		}
+		defer rows.Close()
+
+		var rules []DenyRule
+		for rows.Next() {
+			var rule DenyRule
+			var role sql.NullString
+			if err := rows.Scan(&rule.Pattern, &role); err != nil {
+				return nil, errors.Wrap(err, errors.DatabaseScanFailed)
+			}
+			rule.Role = role.String
+			rules = append(rules, rule)
+		}
+		return rules, nil
+	})
+}
+
+// ==================== Separation of Duty Operations ====================
+
+// GetRoleConflictSets returns every conflict set with its roles, sorted by name
+func (r *rbacRepository) GetRoleConflictSets() ([]RoleConflictSet, error) {
+	query := `SELECT id, name, description, created_at FROM role_conflict_sets ORDER BY name`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
+	}
+	defer rows.Close()
+
+	var sets []RoleConflictSet
+	for rows.Next() {
+		var set RoleConflictSet
+		var description sql.NullString
+		if err := rows.Scan(&set.ID, &set.Name, &description, &set.CreatedAt); err != nil {
+			return nil, errors.Wrap(err, errors.DatabaseScanFailed)
+		}
+		set.Description = description.String
+		sets = append(sets, set)
+	}
+	// The loop above closed rows, so the connection is free for the role queries
+	for i := range sets {
+		if sets[i].Roles, err = r.conflictSetRoles(sets[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return sets, nil
+}
+
+func (r *rbacRepository) GetRoleConflictSetByID(id string) (*RoleConflictSet, error) {
+	query := `SELECT id, name, description, created_at FROM role_conflict_sets WHERE id = $1`
+	var set RoleConflictSet
+	var description sql.NullString
+	if err := r.db.QueryRow(query, id).Scan(&set.ID, &set.Name, &description, &set.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New(errors.ResourceNotFound)
+		}
+		return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
+	}
+	set.Description = description.String
+
+	roles, err := r.conflictSetRoles(set.ID)
+	if err != nil {
+		return nil, err
+	}
+	set.Roles = roles
+	return &set, nil
+}
+
+func (r *rbacRepository) conflictSetRoles(setID string) ([]Role, error) {
+	query := `
+		SELECT ` + roleColumns + `
+		FROM roles r
+		INNER JOIN role_conflict_set_roles sr ON sr.role_id = r.id
+		WHERE sr.set_id = $1
+		ORDER BY r.name
+	`
+	return r.queryRoles(query, setID)
+}
+
+// CreateRoleConflictSet stores the set and its roles; call it inside WithTransaction so a
+// failing role insert leaves no partial set
+func (r *rbacRepository) CreateRoleConflictSet(set *RoleConflictSet) error {
+	set.ID = uuid.New().String()
+	set.CreatedAt = time.Now()
+
+	query := `INSERT INTO role_conflict_sets (id, name, description, created_at) VALUES ($1, $2, $3, $4)`
+	if _, err := r.db.Exec(query, set.ID, set.Name, set.Description, set.CreatedAt); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			return errors.New(errors.Conflict)
+		}
+		return errors.Wrap(err, errors.DatabaseInsertFailed)
+	}
+
+	query = `INSERT INTO role_conflict_set_roles (set_id, role_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	for _, role := range set.Roles {
+		if _, err := r.db.Exec(query, set.ID, role.ID); err != nil {
+			return errors.Wrap(err, errors.DatabaseInsertFailed)
+		}
+	}
+	return nil
+}
+
+func (r *rbacRepository) DeleteRoleConflictSet(id string) error {
+	query := `DELETE FROM role_conflict_sets WHERE id = $1`
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return errors.Wrap(err, errors.DatabaseDeleteFailed)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return errors.New(errors.ResourceNotFound)
+	}
+	return nil
+}
+
+// GetRoleConflictViolations lists users holding several roles of a conflict set through their
+// global roles, directly, through a group or by inheritance. Assignments that have not started
+// yet count, so conflicts are reported before they take effect.
+func (r *rbacRepository) GetRoleConflictViolations() ([]RoleConflictViolation, error) {
+	query := `
+		WITH RECURSIVE held AS (
+			SELECT ur.user_id, ur.role_id, 1 AS depth
+			FROM user_roles ur
+			WHERE ur.organization_id IS NULL AND (ur.valid_until IS NULL OR ur.valid_until > NOW())
+			UNION
+			SELECT gm.user_id, gr.role_id, 1
+			FROM group_members gm
+			INNER JOIN group_roles gr ON gr.group_id = gm.group_id
+			UNION
+			SELECT h.user_id, r.parent_id, h.depth + 1
+			FROM held h
+			INNER JOIN roles r ON r.id = h.role_id
+			WHERE r.parent_id IS NOT NULL AND h.depth < ` + maxRoleDepthSQL + `
+		)
+		SELECT s.name, u.id, u.name, u.email, array_agg(DISTINCT r.name ORDER BY r.name)
+		FROM held h
+		INNER JOIN role_conflict_set_roles sr ON sr.role_id = h.role_id
+		INNER JOIN role_conflict_sets s ON s.id = sr.set_id
+		INNER JOIN roles r ON r.id = h.role_id
+		INNER JOIN users u ON u.id = h.user_id
+		GROUP BY s.name, u.id, u.name, u.email
+		HAVING COUNT(DISTINCT h.role_id) > 1
+		ORDER BY s.name, u.name
+	`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
+	}
+	defer rows.Close()
+
+	var violations []RoleConflictViolation
+	for rows.Next() {
+		var violation RoleConflictViolation
+		if err := rows.Scan(&violation.ConflictSet, &violation.UserID, &violation.Name, &violation.Email, pq.Array(&violation.Roles)); err != nil {
+			return nil, errors.Wrap(err, errors.DatabaseScanFailed)
+		}
+		violations = append(violations, violation)
+	}
+
+	return violations, nil
+}
+
+// ==================== Delegated Administration Operations ====================
+
+// GetRoleManagers returns the roles whose holders may manage the role
+func (r *rbacRepository) GetRoleManagers(roleID string) ([]Role, error) {
+	query := `
+		SELECT ` + roleColumns + `
+		FROM roles r
+		INNER JOIN role_managers rm ON rm.manager_role_id = r.id
+		WHERE rm.role_id = $1
+		ORDER BY r.name
+	`
+	return r.queryRoles(query, roleID)
+}
+
+// SetRoleManagers replaces the role's manager roles; call it inside WithTransaction so the old
+// managers are never lost without the new ones
+func (r *rbacRepository) SetRoleManagers(roleID string, managerRoleIDs []string) error {
+	if _, err := r.db.Exec(`DELETE FROM role_managers WHERE role_id = $1`, roleID); err != nil {
+		return errors.Wrap(err, errors.DatabaseDeleteFailed)
+	}
+
+	query := `INSERT INTO role_managers (role_id, manager_role_id, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`
+	now := time.Now()
+	for _, managerRoleID := range managerRoleIDs {
+		if _, err := r.db.Exec(query, roleID, managerRoleID, now); err != nil {
+			return errors.Wrap(err, errors.DatabaseInsertFailed)
+		}
+	}
+	return nil
+}
+
+// GetManagedRoles returns the roles managed by any of the given roles
+func (r *rbacRepository) GetManagedRoles(managerRoleIDs []string) ([]Role, error) {
+	query := `
+		SELECT ` + roleColumns + `
+		FROM roles r
+		WHERE r.id IN (SELECT role_id FROM role_managers WHERE manager_role_id = ANY($1))
+		ORDER BY r.name
+	`
+	return r.queryRoles(query, pq.Array(managerRoleIDs))
+}
+
+// ==================== User Permission Check ====================
+
+func (r *rbacRepository) GetUserPermissions(userID string) ([]Permission, error) {
+	return r.userPermissions(userID, "")
+}
+
+// GetUserPermissionsInTenant returns the permissions of the user's global roles combined with
+// the roles assigned to them inside the organization
+func (r *rbacRepository) GetUserPermissionsInTenant(userID, organizationID string) ([]Permission, error) {
+	return r.userPermissions(userID, organizationID)
+}
+
+// effectiveRolesSQL defines the effective_roles CTE for user $1 and organization $2: the user's
+// active roles, including those held through groups, plus all of their ancestors. A NULL $2
+// never equals organization_id, so without a tenant only global assignments are used.
+var effectiveRolesSQL = `
+	effective_roles AS (
+		SELECT r.id, r.parent_id, 1 AS depth
+		FROM roles r
+		WHERE r.id IN (
+			SELECT ur.role_id FROM user_roles ur
+			WHERE ur.user_id = $1 AND (ur.organization_id IS NULL OR ur.organization_id = $2::uuid)
+				AND ` + activeGrantSQL + `
+			UNION
+			SELECT gr.role_id FROM group_roles gr
+			INNER JOIN group_members gm ON gm.group_id = gr.group_id
+			WHERE gm.user_id = $1
+		)
+		UNION
+		SELECT parent.id, parent.parent_id, er.depth + 1
+		FROM roles parent
+		INNER JOIN effective_roles er ON parent.id = er.parent_id
+		WHERE er.depth < ` + maxRoleDepthSQL + `
+	)
+`
+
+// userPermissions resolves permissions from the user's global roles, including those held through
+// groups, and, when organizationID is set, the roles assigned inside that organization
+func (r *rbacRepository) userPermissions(userID, organizationID string) ([]Permission, error) {
+	keyType := "permissions"
+	if organizationID != "" {
+		keyType += ":" + organizationID
+	}
+	query := `
+		WITH RECURSIVE ` + effectiveRolesSQL + `
+		SELECT DISTINCT ` + permissionColumns + `
+		FROM permissions p
+		INNER JOIN role_permissions rp ON p.id = rp.permission_id
+		WHERE rp.role_id IN (SELECT id FROM effective_roles)
+		ORDER BY p.resource, p.action
+	`
+	return loadGrants(r.cache, userID, keyType, r.grantTTL(userID), func() ([]Permission, error) {
+		return r.queryPermissions(query, userID, nullableID(organizationID))
+	})
+}
+
+func (r *rbacRepository) HasPermission(userID, permissionName string) (bool, error) {
+	permissions, err := r.GetUserPermissions(userID)
+	if err != nil {
+		return false, err
+	}
+
+	denies, err := r.GetUserDenyRules(userID, "")
+	if err != nil {
+		return false, err
+	}
+
+	_, ok := Authorize(permissionNames(permissions), denyPatterns(denies), permissionName)
+	return ok, nil
+}
+
+// ==================== Change History Operations ====================
+
+// changeColumns is the column list read by scanChange
+const changeColumns = `id, table_name, action, old_row, new_row, actor_id, request_id, changed_at`
+
+// scanChange reads a row selected with changeColumns
+func scanChange(row rowScanner) (ChangeRecord, error) {
+	var change ChangeRecord
+	var oldRow, newRow []byte
+	var actorID, requestID sql.NullString
+	err := row.Scan(&change.ID, &change.Table, &change.Action, &oldRow, &newRow, &actorID, &requestID, &change.ChangedAt)
+	change.OldRow = nullableJSON(oldRow)
+	change.NewRow = nullableJSON(newRow)
+	change.ActorID = actorID.String
+	change.RequestID = requestID.String
+	return change, err
+}
+
+// nullableJSON turns a NULL JSON column into a JSON null
+func nullableJSON(data []byte) json.RawMessage {
+	if data == nil {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(data)
+}
+
+// queryChanges runs a query selecting changeColumns and collects the rows
+func (r *rbacRepository) queryChanges(query string, args ...interface{}) ([]ChangeRecord, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
+	}
+	defer rows.Close()
+
+	changes := []ChangeRecord{}
+	for rows.Next() {
+		change, err := scanChange(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.DatabaseScanFailed)
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// GetChanges returns a page of the change history, newest first
+func (r *rbacRepository) GetChanges(filter ChangeFilter) ([]ChangeRecord, int64, error) {
+	var conditions []string
+	var args []interface{}
+	param := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Table != "" {
+		conditions = append(conditions, "table_name = "+param(filter.Table))
+	}
+	if filter.ActorID != "" {
+		conditions = append(conditions, "actor_id = "+param(filter.ActorID))
+	}
+	if filter.RequestID != "" {
+		conditions = append(conditions, "request_id = "+param(filter.RequestID))
+	}
+	if filter.UserID != "" {
+		conditions = append(conditions, "COALESCE(new_row, old_row)->>'user_id' = "+param(filter.UserID))
+	}
+	if filter.Since != nil {
+		conditions = append(conditions, "changed_at >= "+param(*filter.Since))
+	}
+	if filter.Until != nil {
+		conditions = append(conditions, "changed_at < "+param(*filter.Until))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM rbac_changes `+where, args...).Scan(&total); err != nil {
+		return nil, 0, errors.Wrap(err, errors.DatabaseQueryFailed)
+	}
+
+	query := fmt.Sprintf(
+		`SELECT %s FROM rbac_changes %s ORDER BY id DESC LIMIT %s OFFSET %s`,
+		changeColumns, where, param(filter.PageSize), param((filter.Page-1)*filter.PageSize),
+	)
+	changes, err := r.queryChanges(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return changes, total, nil
+}
+
+// GetHistoryState returns the current rows of every table in historyKeys as JSON, limited to the
+// user's own rows in tables holding one row per user
+func (r *rbacRepository) GetHistoryState(userID string) (map[string][]json.RawMessage, error) {
+	state := make(map[string][]json.RawMessage, len(historyKeys))
+	for _, table := range HistoryTables() {
+		// Table names come from historyKeys, never from input
+		query := `SELECT to_jsonb(t) FROM ` + table + ` t`
+		var args []interface{}
+		if isUserHistoryTable(table) {
+			query += ` WHERE t.user_id = $1`
+			args = append(args, userID)
+		}
+
+		rows, err := r.db.Query(query, args...)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
+		}
+		for rows.Next() {
+			var row []byte
+			if err := rows.Scan(&row); err != nil {
+				rows.Close()
+				return nil, errors.Wrap(err, errors.DatabaseScanFailed)
+			}
+			state[table] = append(state[table], json.RawMessage(row))
+		}
+		rows.Close()
+	}
+	return state, nil
+}
+
+// GetUserChangesSince returns, oldest first, the changes after since to the tables in
+// historyKeys, leaving out other users' rows
+func (r *rbacRepository) GetUserChangesSince(userID string, since time.Time) ([]ChangeRecord, error) {
+	query := `
+		SELECT ` + changeColumns + `
+		FROM rbac_changes
+		WHERE changed_at > $1 AND table_name = ANY($2)
+			AND (table_name <> ALL($3) OR COALESCE(new_row, old_row)->>'user_id' = $4)
+		ORDER BY id
+	`
+	var userTables []string
+	for _, table := range HistoryTables() {
+		if isUserHistoryTable(table) {
+			userTables = append(userTables, table)
+		}
+	}
+	return r.queryChanges(query, since, pq.Array(HistoryTables()), pq.Array(userTables), userID)
+}
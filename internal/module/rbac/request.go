@@ -1,5 +1,7 @@
 package rbac
 
+import "time"
+
 // CreateRoleRequest is the request body for creating a new role
 type CreateRoleRequest struct {
 	Name        string `json:"name" validate:"required,min=2,max=50"`
@@ -12,12 +14,117 @@ type UpdateRoleRequest struct {
 	Description string `json:"description" validate:"max=255"`
 }
 
-// AssignRoleRequest is the request body for assigning a role to a user
-type AssignRoleRequest struct {
+// AssignRoleToUserRequest is the request body for assigning a role to a user. Omitting
+// valid_from makes the role active immediately; omitting valid_until makes it permanent
+type AssignRoleToUserRequest struct {
+	RoleID     string     `json:"role_id" validate:"required,uuid"`
+	ValidFrom  *time.Time `json:"valid_from"`
+	ValidUntil *time.Time `json:"valid_until"`
+}
+
+// CreateGroupRequest is the request body for creating a group
+type CreateGroupRequest struct {
+	Name        string `json:"name" validate:"required,min=2,max=100"`
+	Description string `json:"description" validate:"max=255"`
+}
+
+// UpdateGroupRequest is the request body for updating a group; empty fields are left unchanged
+type UpdateGroupRequest struct {
+	Name        string `json:"name" validate:"omitempty,min=2,max=100"`
+	Description string `json:"description" validate:"max=255"`
+}
+
+// AddGroupMemberRequest is the request body for adding a user to a group
+type AddGroupMemberRequest struct {
+	UserID string `json:"user_id" validate:"required,uuid"`
+}
+
+// AssignRoleToGroupRequest is the request body for assigning a role to a group
+type AssignRoleToGroupRequest struct {
 	RoleID string `json:"role_id" validate:"required,uuid"`
 }
 
+// CreateRoleConflictSetRequest is the request body for making roles mutually exclusive
+type CreateRoleConflictSetRequest struct {
+	Name        string   `json:"name" validate:"required,min=2,max=100"`
+	Description string   `json:"description" validate:"max=255"`
+	RoleIDs     []string `json:"role_ids" validate:"required,min=2,unique,dive,uuid"`
+}
+
+// SetRoleManagersRequest is the request body for replacing the roles that manage a role
+type SetRoleManagersRequest struct {
+	RoleIDs []string `json:"role_ids" validate:"dive,uuid"`
+}
+
 // AssignPermissionRequest is the request body for assigning a permission to a role
 type AssignPermissionRequest struct {
 	PermissionID string `json:"permission_id" validate:"required,uuid"`
 }
+
+// SetRoleParentRequest is the request body for setting a role's parent; an empty parent_id clears it
+type SetRoleParentRequest struct {
+	ParentID string `json:"parent_id" validate:"omitempty,uuid"`
+}
+
+// CreatePermissionRequest is the request body for creating a permission; name must be resource:action.
+// The resource may be nested (orgs:billing) and any segment may be the * wildcard
+type CreatePermissionRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Resource    string `json:"resource" validate:"required,max=50,permission_resource"`
+	Action      string `json:"action" validate:"required,max=20,permission_segment"`
+	Description string `json:"description" validate:"max=255"`
+}
+
+// UpdatePermissionRequest is the request body for updating a permission; empty fields are left unchanged
+type UpdatePermissionRequest struct {
+	Name        string `json:"name" validate:"omitempty,max=100"`
+	Resource    string `json:"resource" validate:"omitempty,max=50,permission_resource"`
+	Action      string `json:"action" validate:"omitempty,max=20,permission_segment"`
+	Description string `json:"description" validate:"max=255"`
+}
+
+// ExportPolicyRequest selects the format of the exported policy document
+type ExportPolicyRequest struct {
+	Format string `query:"format" validate:"omitempty,oneof=yaml json"`
+}
+
+// ApplyPolicyRequest holds the options for applying the policy document sent as the body
+type ApplyPolicyRequest struct {
+	DryRun bool `query:"dry_run"`
+	Prune  bool `query:"prune"`
+}
+
+// CheckPermissionsRequest is the request body for checking several permissions at once.
+// Explaining and checking another user's permissions are reserved for super admins
+type CheckPermissionsRequest struct {
+	Permissions []string `json:"permissions" validate:"required,min=1,max=50,dive,required,max=100"`
+	Explain     bool     `json:"explain"`
+	UserID      string   `json:"user_id" validate:"omitempty,uuid"`
+}
+
+// ListChangesRequest holds the query parameters of the RBAC change history; times are RFC 3339
+type ListChangesRequest struct {
+	Page      int    `query:"page" validate:"omitempty,min=1"`
+	PageSize  int    `query:"page_size" validate:"omitempty,min=1,max=100"`
+	Table     string `query:"table" validate:"omitempty,oneof=roles permissions role_permissions role_permission_denies user_roles user_permission_denies groups group_members group_roles role_conflict_sets role_conflict_set_roles role_managers"`
+	ActorID   string `query:"actor_id" validate:"omitempty,uuid"`
+	UserID    string `query:"user_id" validate:"omitempty,uuid"`
+	RequestID string `query:"request_id" validate:"max=100"`
+	Since     string `query:"since" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Until     string `query:"until" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+// PermissionsAtRequest selects the point in time, RFC 3339, and the organization whose roles
+// are included besides the global ones
+type PermissionsAtRequest struct {
+	At             string `query:"at" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
+	OrganizationID string `query:"organization_id" validate:"omitempty,uuid"`
+}
+
+// PermissionDiffRequest selects the two points in time, RFC 3339, and the organization whose
+// roles are included besides the global ones
+type PermissionDiffRequest struct {
+	From           string `query:"from" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
+	To             string `query:"to" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
+	OrganizationID string `query:"organization_id" validate:"omitempty,uuid"`
+}
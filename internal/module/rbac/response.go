@@ -4,10 +4,14 @@ import "time"
 
 // RoleResponse is the response for a single role
 type RoleResponse struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	ParentID    string     `json:"parent_id,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ValidFrom   *time.Time `json:"valid_from,omitempty"`
+	ValidUntil  *time.Time `json:"valid_until,omitempty"`
+	Group       string     `json:"group,omitempty"`
 }
 
 // PermissionResponse is the response for a single permission
@@ -17,6 +21,7 @@ type PermissionResponse struct {
 	Description string    `json:"description,omitempty"`
 	Resource    string    `json:"resource"`
 	Action      string    `json:"action"`
+	IsSystem    bool      `json:"is_system"`
 	CreatedAt   time.Time `json:"created_at"`
 }
 
@@ -24,6 +29,7 @@ type PermissionResponse struct {
 type RoleWithPermissionsResponse struct {
 	RoleResponse
 	Permissions []PermissionResponse `json:"permissions"`
+	Ancestors   []RoleResponse       `json:"ancestors"`
 }
 
 // UserRolesResponse contains user's roles
@@ -32,13 +38,53 @@ type UserRolesResponse struct {
 	Roles  []RoleResponse `json:"roles"`
 }
 
+// UserDeniesResponse contains the permissions denied to a user by per-user overrides
+type UserDeniesResponse struct {
+	UserID string               `json:"user_id"`
+	Denies []PermissionResponse `json:"denies"`
+}
+
+// GroupResponse is the response for a single group
+type GroupResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// GroupDetailResponse includes a group with its members and roles
+type GroupDetailResponse struct {
+	GroupResponse
+	Members []GroupMemberResponse `json:"members"`
+	Roles   []RoleResponse        `json:"roles"`
+}
+
+// GroupMemberResponse is the response for a single group member
+type GroupMemberResponse struct {
+	UserID   string    `json:"user_id"`
+	Name     string    `json:"name"`
+	Email    string    `json:"email"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// UserGroupsResponse contains the groups a user belongs to
+type UserGroupsResponse struct {
+	UserID string          `json:"user_id"`
+	Groups []GroupResponse `json:"groups"`
+}
+
 // ToRoleResponse converts Role entity to RoleResponse
 func ToRoleResponse(role *Role) RoleResponse {
 	return RoleResponse{
 		ID:          role.ID,
 		Name:        role.Name,
 		Description: role.Description,
+		ParentID:    role.ParentID,
 		CreatedAt:   role.CreatedAt,
+		ValidFrom:   role.ValidFrom,
+		ValidUntil:  role.ValidUntil,
+		Group:       role.Group,
 	}
 }
 
@@ -59,6 +105,7 @@ func ToPermissionResponse(permission *Permission) PermissionResponse {
 		Description: permission.Description,
 		Resource:    permission.Resource,
 		Action:      permission.Action,
+		IsSystem:    permission.IsSystem,
 		CreatedAt:   permission.CreatedAt,
 	}
 }
@@ -71,3 +118,149 @@ func ToPermissionResponses(permissions []Permission) []PermissionResponse {
 	}
 	return result
 }
+
+// ToGroupResponse converts Group entity to GroupResponse
+func ToGroupResponse(group *Group) GroupResponse {
+	return GroupResponse{
+		ID:          group.ID,
+		Name:        group.Name,
+		Description: group.Description,
+		CreatedAt:   group.CreatedAt,
+		UpdatedAt:   group.UpdatedAt,
+	}
+}
+
+// ToGroupResponses converts slice of Group entities to slice of GroupResponse
+func ToGroupResponses(groups []Group) []GroupResponse {
+	result := make([]GroupResponse, len(groups))
+	for i, group := range groups {
+		result[i] = ToGroupResponse(&group)
+	}
+	return result
+}
+
+// ToGroupMemberResponses converts slice of GroupMember to slice of GroupMemberResponse
+func ToGroupMemberResponses(members []GroupMember) []GroupMemberResponse {
+	result := make([]GroupMemberResponse, len(members))
+	for i, member := range members {
+		result[i] = GroupMemberResponse{
+			UserID:   member.UserID,
+			Name:     member.Name,
+			Email:    member.Email,
+			JoinedAt: member.JoinedAt,
+		}
+	}
+	return result
+}
+
+// RoleConflictSetResponse is the response for a single separation-of-duty conflict set
+type RoleConflictSetResponse struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Roles       []RoleResponse `json:"roles"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// RoleConflictViolationResponse is a user holding several roles of one conflict set
+type RoleConflictViolationResponse struct {
+	ConflictSet string   `json:"conflict_set"`
+	UserID      string   `json:"user_id"`
+	Name        string   `json:"name"`
+	Email       string   `json:"email"`
+	Roles       []string `json:"roles"`
+}
+
+// ToRoleConflictSetResponse converts RoleConflictSet entity to RoleConflictSetResponse
+func ToRoleConflictSetResponse(set *RoleConflictSet) RoleConflictSetResponse {
+	return RoleConflictSetResponse{
+		ID:          set.ID,
+		Name:        set.Name,
+		Description: set.Description,
+		Roles:       ToRoleResponses(set.Roles),
+		CreatedAt:   set.CreatedAt,
+	}
+}
+
+// ToRoleConflictSetResponses converts slice of RoleConflictSet to slice of RoleConflictSetResponse
+func ToRoleConflictSetResponses(sets []RoleConflictSet) []RoleConflictSetResponse {
+	result := make([]RoleConflictSetResponse, len(sets))
+	for i, set := range sets {
+		result[i] = ToRoleConflictSetResponse(&set)
+	}
+	return result
+}
+
+// ToRoleConflictViolationResponses converts slice of RoleConflictViolation to slice of RoleConflictViolationResponse
+func ToRoleConflictViolationResponses(violations []RoleConflictViolation) []RoleConflictViolationResponse {
+	result := make([]RoleConflictViolationResponse, len(violations))
+	for i, violation := range violations {
+		result[i] = RoleConflictViolationResponse{
+			ConflictSet: violation.ConflictSet,
+			UserID:      violation.UserID,
+			Name:        violation.Name,
+			Email:       violation.Email,
+			Roles:       violation.Roles,
+		}
+	}
+	return result
+}
+
+// PermissionGrantResponse explains how a role grants, or would grant, a permission
+type PermissionGrantResponse struct {
+	Role           string   `json:"role"`
+	Path           []string `json:"path"`
+	Pattern        string   `json:"pattern"`
+	Assigned       bool     `json:"assigned"`
+	OrganizationID string   `json:"organization_id,omitempty"`
+	Group          string   `json:"group,omitempty"`
+}
+
+// DenyRuleResponse names the deny rule that refused a permission; an empty role means a
+// per-user override
+type DenyRuleResponse struct {
+	Pattern string `json:"pattern"`
+	Role    string `json:"role,omitempty"`
+}
+
+// PermissionDecisionResponse is the outcome of checking one permission
+type PermissionDecisionResponse struct {
+	Permission string                    `json:"permission"`
+	Allowed    bool                      `json:"allowed"`
+	MatchedBy  string                    `json:"matched_by,omitempty"`
+	DeniedBy   *DenyRuleResponse         `json:"denied_by,omitempty"`
+	Grants     []PermissionGrantResponse `json:"grants,omitempty"`
+}
+
+// PermissionCheckResponse contains the decision for every checked permission
+type PermissionCheckResponse struct {
+	UserID         string                       `json:"user_id"`
+	OrganizationID string                       `json:"organization_id,omitempty"`
+	Results        []PermissionDecisionResponse `json:"results"`
+}
+
+// ToPermissionDecisionResponses converts slice of PermissionDecision to slice of PermissionDecisionResponse
+func ToPermissionDecisionResponses(decisions []PermissionDecision) []PermissionDecisionResponse {
+	result := make([]PermissionDecisionResponse, len(decisions))
+	for i, decision := range decisions {
+		result[i] = PermissionDecisionResponse{
+			Permission: decision.Permission,
+			Allowed:    decision.Allowed,
+			MatchedBy:  decision.MatchedBy,
+		}
+		if decision.Deny != nil {
+			result[i].DeniedBy = &DenyRuleResponse{Pattern: decision.Deny.Pattern, Role: decision.Deny.Role}
+		}
+		for _, grant := range decision.Grants {
+			result[i].Grants = append(result[i].Grants, PermissionGrantResponse{
+				Role:           grant.Role,
+				Path:           grant.Path,
+				Pattern:        grant.Pattern,
+				Assigned:       grant.Assigned,
+				OrganizationID: grant.OrganizationID,
+				Group:          grant.Group,
+			})
+		}
+	}
+	return result
+}
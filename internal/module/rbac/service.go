@@ -1,11 +1,19 @@
 package rbac
 
 import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
 	"boilerplate-be/internal/shared/errors"
 )
 
 type rbacUseCase struct {
 	rbacRepo RBACRepository
+	// actor is recorded with every change; zero when unknown
+	actor ChangeActor
 }
 
 // NewRBACUseCase creates a new RBAC use case
@@ -71,38 +79,415 @@ func (u *rbacUseCase) DeleteRole(id string) error {
 	}
 
 	// Prevent deletion of system roles
-	if role.Name == "super_admin" || role.Name == "user" {
+	if IsBuiltInRole(role.Name) {
 		return errors.New(errors.Forbidden)
 	}
 
 	return u.rbacRepo.DeleteRole(id)
 }
 
+// ==================== Role Hierarchy Operations ====================
+
+// SetRoleParent makes parentID the parent of roleID; an empty parentID detaches the role
+func (u *rbacUseCase) SetRoleParent(roleID, parentID string) (*Role, error) {
+	role, err := u.rbacRepo.GetRoleByID(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if parentID != "" {
+		if parentID == roleID {
+			return nil, errors.New(errors.RoleHierarchyCycle)
+		}
+
+		if _, err := u.rbacRepo.GetRoleByID(parentID); err != nil {
+			return nil, err
+		}
+
+		// The new parent must not already inherit from this role
+		ancestors, err := u.rbacRepo.GetRoleAncestors(parentID)
+		if err != nil {
+			return nil, err
+		}
+		for _, ancestor := range ancestors {
+			if ancestor.ID == roleID {
+				return nil, errors.New(errors.RoleHierarchyCycle)
+			}
+		}
+
+		// Inherited permissions are resolved MaxRoleDepth levels up, so the deepest role below this
+		// one must stay within that many ancestors
+		height, err := u.rbacRepo.GetRoleHeight(roleID)
+		if err != nil {
+			return nil, err
+		}
+		if len(ancestors)+1+height > MaxRoleDepth {
+			return nil, errors.New(errors.RoleHierarchyTooDeep)
+		}
+	}
+
+	if err := u.rbacRepo.SetRoleParent(roleID, parentID); err != nil {
+		return nil, err
+	}
+
+	role.ParentID = parentID
+	return role, nil
+}
+
+func (u *rbacUseCase) GetRoleAncestors(roleID string) ([]Role, error) {
+	return u.rbacRepo.GetRoleAncestors(roleID)
+}
+
 // ==================== Permission Operations ====================
 
 func (u *rbacUseCase) GetPermissions() ([]Permission, error) {
 	return u.rbacRepo.GetPermissions()
 }
 
+func (u *rbacUseCase) CreatePermission(name, resource, action, description string) (*Permission, error) {
+	if name != PermissionName(resource, action) {
+		return nil, errors.New(errors.PermissionNameMismatch)
+	}
+
+	if _, err := u.rbacRepo.GetPermissionByName(name); err == nil {
+		return nil, errors.New(errors.PermissionExists)
+	}
+
+	permission := &Permission{
+		Name:        name,
+		Resource:    resource,
+		Action:      action,
+		Description: description,
+	}
+
+	if err := u.rbacRepo.CreatePermission(permission); err != nil {
+		return nil, err
+	}
+
+	return permission, nil
+}
+
+// SeedPermissions creates the declared permissions that do not exist yet and returns the created
+// ones. Declared permissions are referenced by name in code, so they are stored as built-in ones,
+// and existing declared permissions are marked built-in as well.
+func (u *rbacUseCase) SeedPermissions(permissions []PermissionSpec) ([]Permission, error) {
+	stored, err := u.rbacRepo.GetPermissions()
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]Permission, len(stored))
+	for _, permission := range stored {
+		existing[permission.Name] = permission
+	}
+
+	var created []Permission
+	for _, spec := range permissions {
+		if permission, ok := existing[spec.Name]; ok {
+			if !permission.IsSystem {
+				if err := u.rbacRepo.MarkPermissionSystem(permission.ID); err != nil {
+					return nil, err
+				}
+				permission.IsSystem = true
+				existing[spec.Name] = permission
+			}
+			continue
+		}
+
+		resource, action, _ := splitPermissionName(spec.Name)
+		if spec.Name != PermissionName(resource, action) {
+			return nil, errors.New(errors.PermissionNameMismatch)
+		}
+		permission := &Permission{
+			Name:        spec.Name,
+			Resource:    resource,
+			Action:      action,
+			Description: spec.Description,
+			IsSystem:    true,
+		}
+		if err := u.rbacRepo.CreatePermission(permission); err != nil {
+			return nil, err
+		}
+		existing[spec.Name] = *permission
+		created = append(created, *permission)
+	}
+	return created, nil
+}
+
+func (u *rbacUseCase) UpdatePermission(id, name, resource, action, description string) (*Permission, error) {
+	permission, err := u.rbacRepo.GetPermissionByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	renamed := (name != "" && name != permission.Name) ||
+		(resource != "" && resource != permission.Resource) ||
+		(action != "" && action != permission.Action)
+
+	// Built-in permissions are referenced by name in code; only their description may change
+	if renamed && permission.IsSystem {
+		return nil, errors.New(errors.SystemPermissionProtected)
+	}
+
+	if name != "" {
+		permission.Name = name
+	}
+	if resource != "" {
+		permission.Resource = resource
+	}
+	if action != "" {
+		permission.Action = action
+	}
+	if description != "" {
+		permission.Description = description
+	}
+
+	if permission.Name != PermissionName(permission.Resource, permission.Action) {
+		return nil, errors.New(errors.PermissionNameMismatch)
+	}
+
+	if err := u.rbacRepo.UpdatePermission(permission); err != nil {
+		return nil, err
+	}
+
+	return permission, nil
+}
+
+func (u *rbacUseCase) DeletePermission(id string) error {
+	permission, err := u.rbacRepo.GetPermissionByID(id)
+	if err != nil {
+		return err
+	}
+
+	if permission.IsSystem {
+		return errors.New(errors.SystemPermissionProtected)
+	}
+
+	return u.rbacRepo.DeletePermission(id)
+}
+
 // ==================== User-Role Operations ====================
 
 func (u *rbacUseCase) GetUserRoles(userID string) ([]Role, error) {
 	return u.rbacRepo.GetUserRoles(userID)
 }
 
-func (u *rbacUseCase) AssignRoleToUser(userID, roleID string) error {
+// AssignRoleToUser grants the role globally within the validity window; a zero validity is permanent
+func (u *rbacUseCase) AssignRoleToUser(userID, roleID string, validity RoleValidity) error {
+	if validity.Until != nil {
+		if !validity.Until.After(time.Now()) || (validity.From != nil && !validity.Until.After(*validity.From)) {
+			return errors.New(errors.InvalidRoleValidity)
+		}
+	}
+
 	// Verify role exists
 	if _, err := u.rbacRepo.GetRoleByID(roleID); err != nil {
 		return err
 	}
 
-	return u.rbacRepo.AssignRoleToUser(userID, roleID)
+	if err := u.requireSeparationOfDuty(userID, "", roleID); err != nil {
+		return err
+	}
+
+	return u.rbacRepo.AssignRoleToUser(userID, roleID, validity)
+}
+
+// AssignRolesByName grants each named role to the user; roles the user already has are kept
+func (u *rbacUseCase) AssignRolesByName(userID string, roleNames []string) error {
+	for _, name := range roleNames {
+		role, err := u.rbacRepo.GetRoleByName(name)
+		if err != nil {
+			return err
+		}
+
+		if err := u.requireSeparationOfDuty(userID, "", role.ID); err != nil {
+			return err
+		}
+		if err := u.rbacRepo.AssignRoleToUser(userID, role.ID, RoleValidity{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RemoveRolesByName removes the user's direct global assignment of each named role; roles the
+// user does not hold, or holds only through a group, are skipped
+func (u *rbacUseCase) RemoveRolesByName(userID string, roleNames []string) error {
+	roles, err := u.rbacRepo.GetUserRoles(userID)
+	if err != nil {
+		return err
+	}
+
+	for _, role := range roles {
+		if role.Group != "" || !slices.Contains(roleNames, role.Name) {
+			continue
+		}
+		if err := u.rbacRepo.RemoveRoleFromUser(userID, role.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (u *rbacUseCase) RemoveRoleFromUser(userID, roleID string) error {
 	return u.rbacRepo.RemoveRoleFromUser(userID, roleID)
 }
 
+// SweepExpiredRoles deletes assignments past their valid_until and returns how many users lost a role
+func (u *rbacUseCase) SweepExpiredRoles() (int, error) {
+	userIDs, err := u.rbacRepo.DeleteExpiredUserRoles()
+	if err != nil {
+		return 0, err
+	}
+	return len(userIDs), nil
+}
+
+// ==================== Tenant-Scoped User-Role Operations ====================
+
+func (u *rbacUseCase) GetUserRolesInTenant(userID, organizationID string) ([]Role, error) {
+	return u.rbacRepo.GetUserRolesInTenant(userID, organizationID)
+}
+
+func (u *rbacUseCase) AssignRoleToUserInTenant(userID, roleID, organizationID string) error {
+	// Verify role exists
+	if _, err := u.rbacRepo.GetRoleByID(roleID); err != nil {
+		return err
+	}
+
+	if err := u.requireSeparationOfDuty(userID, organizationID, roleID); err != nil {
+		return err
+	}
+
+	return u.rbacRepo.AssignRoleToUserInTenant(userID, roleID, organizationID)
+}
+
+func (u *rbacUseCase) RemoveRoleFromUserInTenant(userID, roleID, organizationID string) error {
+	return u.rbacRepo.RemoveRoleFromUserInTenant(userID, roleID, organizationID)
+}
+
+// ==================== Group Operations ====================
+
+func (u *rbacUseCase) GetGroups() ([]Group, error) {
+	return u.rbacRepo.GetGroups()
+}
+
+func (u *rbacUseCase) GetGroupByID(id string) (*Group, error) {
+	return u.rbacRepo.GetGroupByID(id)
+}
+
+func (u *rbacUseCase) CreateGroup(name, description string) (*Group, error) {
+	group := &Group{
+		Name:        name,
+		Description: description,
+	}
+
+	if err := u.rbacRepo.CreateGroup(group); err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+func (u *rbacUseCase) UpdateGroup(id, name, description string) (*Group, error) {
+	group, err := u.rbacRepo.GetGroupByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if name != "" {
+		group.Name = name
+	}
+	if description != "" {
+		group.Description = description
+	}
+
+	if err := u.rbacRepo.UpdateGroup(group); err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+func (u *rbacUseCase) DeleteGroup(id string) error {
+	return u.rbacRepo.DeleteGroup(id)
+}
+
+func (u *rbacUseCase) GetGroupMembers(groupID string) ([]GroupMember, error) {
+	// Verify group exists
+	if _, err := u.rbacRepo.GetGroupByID(groupID); err != nil {
+		return nil, err
+	}
+
+	return u.rbacRepo.GetGroupMembers(groupID)
+}
+
+func (u *rbacUseCase) AddUserToGroup(groupID, userID string) error {
+	// Verify group exists
+	if _, err := u.rbacRepo.GetGroupByID(groupID); err != nil {
+		return err
+	}
+
+	roles, err := u.rbacRepo.GetGroupRoles(groupID)
+	if err != nil {
+		return err
+	}
+	roleIDs := make([]string, len(roles))
+	for i, role := range roles {
+		roleIDs[i] = role.ID
+	}
+	if err := u.requireSeparationOfDuty(userID, "", roleIDs...); err != nil {
+		return err
+	}
+
+	return u.rbacRepo.AddUserToGroup(groupID, userID)
+}
+
+func (u *rbacUseCase) RemoveUserFromGroup(groupID, userID string) error {
+	return u.rbacRepo.RemoveUserFromGroup(groupID, userID)
+}
+
+func (u *rbacUseCase) GetUserGroups(userID string) ([]Group, error) {
+	return u.rbacRepo.GetUserGroups(userID)
+}
+
+func (u *rbacUseCase) GetGroupRoles(groupID string) ([]Role, error) {
+	// Verify group exists
+	if _, err := u.rbacRepo.GetGroupByID(groupID); err != nil {
+		return nil, err
+	}
+
+	return u.rbacRepo.GetGroupRoles(groupID)
+}
+
+func (u *rbacUseCase) AssignRoleToGroup(groupID, roleID string) error {
+	// Verify group exists
+	if _, err := u.rbacRepo.GetGroupByID(groupID); err != nil {
+		return err
+	}
+
+	// Verify role exists
+	if _, err := u.rbacRepo.GetRoleByID(roleID); err != nil {
+		return err
+	}
+
+	members, err := u.rbacRepo.GetGroupMembers(groupID)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		if err := u.requireSeparationOfDuty(member.UserID, "", roleID); err != nil {
+			return err
+		}
+	}
+
+	return u.rbacRepo.AssignRoleToGroup(groupID, roleID)
+}
+
+func (u *rbacUseCase) RemoveRoleFromGroup(groupID, roleID string) error {
+	return u.rbacRepo.RemoveRoleFromGroup(groupID, roleID)
+}
+
 // ==================== Role-Permission Operations ====================
 
 func (u *rbacUseCase) GetRolePermissions(roleID string) ([]Permission, error) {
@@ -132,49 +517,847 @@ func (u *rbacUseCase) RemovePermissionFromRole(roleID, permissionID string) erro
 	return u.rbacRepo.RemovePermissionFromRole(roleID, permissionID)
 }
 
-// ==================== Permission Checking ====================
+// ==================== Deny Operations ====================
 
-func (u *rbacUseCase) CheckUserRole(userID string, roles ...string) (bool, error) {
-	userRoles, err := u.rbacRepo.GetUserRoles(userID)
-	if err != nil {
-		return false, err
+func (u *rbacUseCase) GetRoleDenies(roleID string) ([]Permission, error) {
+	// Verify role exists
+	if _, err := u.rbacRepo.GetRoleByID(roleID); err != nil {
+		return nil, err
 	}
 
-	roleMap := make(map[string]bool)
-	for _, role := range userRoles {
-		roleMap[role.Name] = true
+	return u.rbacRepo.GetRoleDenies(roleID)
+}
+
+// AddDenyToRole refuses the permission to every holder of the role or a role inheriting from it,
+// even when another of their roles grants it
+func (u *rbacUseCase) AddDenyToRole(roleID, permissionID string) error {
+	// Verify role exists
+	if _, err := u.rbacRepo.GetRoleByID(roleID); err != nil {
+		return err
 	}
 
-	for _, required := range roles {
-		if roleMap[required] {
-			return true, nil
-		}
+	// Verify permission exists
+	if _, err := u.rbacRepo.GetPermissionByID(permissionID); err != nil {
+		return err
 	}
 
-	return false, nil
+	return u.rbacRepo.AddDenyToRole(roleID, permissionID)
 }
 
-func (u *rbacUseCase) CheckUserPermission(userID string, permissions ...string) (bool, error) {
-	userPermissions, err := u.rbacRepo.GetUserPermissions(userID)
-	if err != nil {
-		return false, err
-	}
+func (u *rbacUseCase) RemoveDenyFromRole(roleID, permissionID string) error {
+	return u.rbacRepo.RemoveDenyFromRole(roleID, permissionID)
+}
+
+func (u *rbacUseCase) GetUserDenies(userID string) ([]Permission, error) {
+	return u.rbacRepo.GetUserDenies(userID)
+}
 
-	permissionMap := make(map[string]bool)
-	for _, permission := range userPermissions {
-		permissionMap[permission.Name] = true
+// AddDenyToUser refuses the permission to the user whatever roles they hold
+func (u *rbacUseCase) AddDenyToUser(userID, permissionID string) error {
+	// Verify permission exists
+	if _, err := u.rbacRepo.GetPermissionByID(permissionID); err != nil {
+		return err
 	}
 
-	for _, required := range permissions {
-		if permissionMap[required] {
-			return true, nil
+	return u.rbacRepo.AddDenyToUser(userID, permissionID)
+}
+
+func (u *rbacUseCase) RemoveDenyFromUser(userID, permissionID string) error {
+	return u.rbacRepo.RemoveDenyFromUser(userID, permissionID)
+}
+
+func (u *rbacUseCase) GetUserDenyRules(userID, organizationID string) ([]DenyRule, error) {
+	return u.rbacRepo.GetUserDenyRules(userID, organizationID)
+}
+
+// ==================== Separation of Duty Operations ====================
+
+func (u *rbacUseCase) GetRoleConflictSets() ([]RoleConflictSet, error) {
+	return u.rbacRepo.GetRoleConflictSets()
+}
+
+func (u *rbacUseCase) GetRoleConflictSetByID(id string) (*RoleConflictSet, error) {
+	return u.rbacRepo.GetRoleConflictSetByID(id)
+}
+
+// CreateRoleConflictSet makes the roles mutually exclusive. Users who already hold several of
+// them keep their roles and are listed by GetRoleConflictViolations.
+func (u *rbacUseCase) CreateRoleConflictSet(name, description string, roleIDs []string) (*RoleConflictSet, error) {
+	set := &RoleConflictSet{
+		Name:        name,
+		Description: description,
+	}
+	for _, roleID := range roleIDs {
+		role, err := u.rbacRepo.GetRoleByID(roleID)
+		if err != nil {
+			return nil, err
 		}
+		set.Roles = append(set.Roles, *role)
 	}
 
-	return false, nil
+	err := u.rbacRepo.WithTransaction(func(repo RBACRepository) error {
+		return repo.CreateRoleConflictSet(set)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return set, nil
 }
 
-func (u *rbacUseCase) GetUserPermissions(userID string) ([]Permission, error) {
-	return u.rbacRepo.GetUserPermissions(userID)
+func (u *rbacUseCase) DeleteRoleConflictSet(id string) error {
+	return u.rbacRepo.DeleteRoleConflictSet(id)
+}
+
+func (u *rbacUseCase) GetRoleConflictViolations() ([]RoleConflictViolation, error) {
+	return u.rbacRepo.GetRoleConflictViolations()
 }
 
+// requireSeparationOfDuty rejects giving the user the added roles when they would then hold two
+// roles of one conflict set. Held roles are the user's global roles, including those of their
+// groups, plus those in the organization when organizationID is set; roles count together with
+// their ancestors. Conflicts that existed before the change do not block it.
+func (u *rbacUseCase) requireSeparationOfDuty(userID, organizationID string, added ...string) error {
+	sets, err := u.rbacRepo.GetRoleConflictSets()
+	if err != nil || len(sets) == 0 {
+		return err
+	}
+
+	held, err := u.rbacRepo.GetUserRoles(userID)
+	if err != nil {
+		return err
+	}
+	if organizationID != "" {
+		tenantRoles, err := u.rbacRepo.GetUserRolesInTenant(userID, organizationID)
+		if err != nil {
+			return err
+		}
+		held = append(held, tenantRoles...)
+	}
+	heldIDs := make([]string, len(held))
+	for i, role := range held {
+		heldIDs[i] = role.ID
+	}
+
+	before, err := u.withAncestors(heldIDs)
+	if err != nil {
+		return err
+	}
+	after, err := u.withAncestors(added)
+	if err != nil {
+		return err
+	}
+
+	var problems []errors.ValidationErrorDetails
+	for _, set := range sets {
+		var gained, names []string
+		for _, role := range set.Roles {
+			if after[role.ID] && !before[role.ID] {
+				gained = append(gained, role.Name)
+			}
+			if after[role.ID] || before[role.ID] {
+				names = append(names, role.Name)
+			}
+		}
+		if len(gained) > 0 && len(names) > 1 {
+			problems = append(problems, errors.ValidationErrorDetails{
+				Field:   "role_id",
+				Message: fmt.Sprintf("roles %s of conflict set %q are mutually exclusive", strings.Join(names, ", "), set.Name),
+			})
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.NewWithDetails(errors.SeparationOfDutyViolation, problems)
+	}
+	return nil
+}
+
+// withAncestors returns the IDs of the roles and of every role they inherit from
+func (u *rbacUseCase) withAncestors(roleIDs []string) (map[string]bool, error) {
+	roles := make(map[string]bool, len(roleIDs))
+	for _, roleID := range roleIDs {
+		roles[roleID] = true
+		ancestors, err := u.rbacRepo.GetRoleAncestors(roleID)
+		if err != nil {
+			return nil, err
+		}
+		for _, ancestor := range ancestors {
+			roles[ancestor.ID] = true
+		}
+	}
+	return roles, nil
+}
+
+// ==================== Delegated Administration Operations ====================
+
+func (u *rbacUseCase) GetRoleManagers(roleID string) ([]Role, error) {
+	// Verify role exists
+	if _, err := u.rbacRepo.GetRoleByID(roleID); err != nil {
+		return nil, err
+	}
+
+	return u.rbacRepo.GetRoleManagers(roleID)
+}
+
+// SetRoleManagers replaces the roles whose holders may manage the role; an empty list leaves it
+// to super admins
+func (u *rbacUseCase) SetRoleManagers(roleID string, managerRoleIDs []string) ([]Role, error) {
+	for _, id := range append([]string{roleID}, managerRoleIDs...) {
+		if _, err := u.rbacRepo.GetRoleByID(id); err != nil {
+			return nil, err
+		}
+	}
+
+	err := u.rbacRepo.WithTransaction(func(repo RBACRepository) error {
+		return repo.SetRoleManagers(roleID, managerRoleIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return u.rbacRepo.GetRoleManagers(roleID)
+}
+
+// GetManageableRoles lists the roles managed by a role the user holds globally, directly,
+// through a group or by inheritance
+func (u *rbacUseCase) GetManageableRoles(managerID string) ([]Role, error) {
+	held, err := u.rbacRepo.GetUserRoles(managerID)
+	if err != nil || len(held) == 0 {
+		return nil, err
+	}
+	heldIDs := make([]string, len(held))
+	for i, role := range held {
+		heldIDs[i] = role.ID
+	}
+
+	effective, err := u.withAncestors(heldIDs)
+	if err != nil {
+		return nil, err
+	}
+	managerRoleIDs := make([]string, 0, len(effective))
+	for id := range effective {
+		managerRoleIDs = append(managerRoleIDs, id)
+	}
+	sort.Strings(managerRoleIDs)
+
+	return u.rbacRepo.GetManagedRoles(managerRoleIDs)
+}
+
+// AssignManagedRole grants a role the manager may manage and whose permissions they all hold;
+// the assignment is otherwise checked like AssignRoleToUser
+func (u *rbacUseCase) AssignManagedRole(managerID, userID, roleID string, validity RoleValidity) error {
+	if err := u.requireRoleManager(managerID, roleID); err != nil {
+		return err
+	}
+	if err := u.requireNoEscalation(managerID, roleID); err != nil {
+		return err
+	}
+
+	return u.actingAs(managerID).AssignRoleToUser(userID, roleID, validity)
+}
+
+func (u *rbacUseCase) RemoveManagedRole(managerID, userID, roleID string) error {
+	if err := u.requireRoleManager(managerID, roleID); err != nil {
+		return err
+	}
+
+	return u.actingAs(managerID).rbacRepo.RemoveRoleFromUser(userID, roleID)
+}
+
+// requireRoleManager rejects users holding none of the role's manager roles
+func (u *rbacUseCase) requireRoleManager(managerID, roleID string) error {
+	// Verify role exists
+	if _, err := u.rbacRepo.GetRoleByID(roleID); err != nil {
+		return err
+	}
+
+	manageable, err := u.GetManageableRoles(managerID)
+	if err != nil {
+		return err
+	}
+	for _, role := range manageable {
+		if role.ID == roleID {
+			return nil
+		}
+	}
+	return errors.New(errors.RoleNotManageable)
+}
+
+// requireNoEscalation is the privilege-escalation guard: every permission the role grants,
+// including inherited ones, must be authorized for the manager, and none may cover a permission
+// denied to them, so delegated admins can never hand out more than they have
+func (u *rbacUseCase) requireNoEscalation(managerID, roleID string) error {
+	granted, denies, err := u.effectivePermissions(managerID, "")
+	if err != nil {
+		return err
+	}
+	denied := denyPatterns(denies)
+
+	roles, err := u.withAncestors([]string{roleID})
+	if err != nil {
+		return err
+	}
+	roleIDs := make([]string, 0, len(roles))
+	for id := range roles {
+		roleIDs = append(roleIDs, id)
+	}
+	sort.Strings(roleIDs)
+
+	var problems []errors.ValidationErrorDetails
+	reported := make(map[string]bool)
+	for _, id := range roleIDs {
+		permissions, err := u.rbacRepo.GetRolePermissions(id)
+		if err != nil {
+			return err
+		}
+		for _, permission := range permissions {
+			if reported[permission.Name] || holdsAll(granted, denied, permission.Name) {
+				continue
+			}
+			reported[permission.Name] = true
+			problems = append(problems, errors.ValidationErrorDetails{
+				Field:   "role_id",
+				Message: fmt.Sprintf("permission %q is not held by the assigner", permission.Name),
+			})
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.NewWithDetails(errors.PrivilegeEscalation, problems)
+	}
+	return nil
+}
+
+// holdsAll reports whether everything the permission pattern covers is granted and not denied.
+// Patterns are compared segment by segment, so a wildcard is only covered by one at least as broad.
+func holdsAll(granted, denied []string, pattern string) bool {
+	if _, ok := Authorize(granted, denied, pattern); !ok {
+		return false
+	}
+	for _, deny := range denied {
+		if MatchPermission(pattern, deny) {
+			return false
+		}
+	}
+	return true
+}
+
+// ==================== Permission Checking ====================
+
+func (u *rbacUseCase) CheckUserRole(userID string, roles ...string) (bool, error) {
+	userRoles, err := u.rbacRepo.GetUserRoles(userID)
+	if err != nil {
+		return false, err
+	}
+
+	roleMap := make(map[string]bool)
+	for _, role := range userRoles {
+		roleMap[role.Name] = true
+	}
+
+	for _, required := range roles {
+		if roleMap[required] {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// CheckUserPermission reports whether any of the required permissions is covered by a
+// permission the user holds and by none of the deny rules that apply to them; granted and
+// denied permissions may be wildcard patterns
+func (u *rbacUseCase) CheckUserPermission(userID string, permissions ...string) (bool, error) {
+	granted, denies, err := u.effectivePermissions(userID, "")
+	if err != nil {
+		return false, err
+	}
+
+	return authorizesAny(granted, denies, permissions), nil
+}
+
+// CheckUserPermissionInTenant is CheckUserPermission evaluated with the roles the user holds
+// globally plus those assigned inside the organization
+func (u *rbacUseCase) CheckUserPermissionInTenant(userID, organizationID string, permissions ...string) (bool, error) {
+	granted, denies, err := u.effectivePermissions(userID, organizationID)
+	if err != nil {
+		return false, err
+	}
+
+	return authorizesAny(granted, denies, permissions), nil
+}
+
+// effectivePermissions loads the names of the user's granted permissions and the deny rules that
+// apply to them, including the roles held inside the organization when organizationID is set
+func (u *rbacUseCase) effectivePermissions(userID, organizationID string) ([]string, []DenyRule, error) {
+	var userPermissions []Permission
+	var err error
+	if organizationID != "" {
+		userPermissions, err = u.rbacRepo.GetUserPermissionsInTenant(userID, organizationID)
+	} else {
+		userPermissions, err = u.rbacRepo.GetUserPermissions(userID)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	denies, err := u.rbacRepo.GetUserDenyRules(userID, organizationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return permissionNames(userPermissions), denies, nil
+}
+
+func authorizesAny(granted []string, denies []DenyRule, required []string) bool {
+	denied := denyPatterns(denies)
+	for _, permission := range required {
+		if _, ok := Authorize(granted, denied, permission); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// firedDeny returns the deny rule refusing the required permission, preferring the most specific
+// pattern; rules are listed with per-user overrides first
+func firedDeny(denies []DenyRule, required string) (*DenyRule, bool) {
+	pattern, ok := BestMatch(denyPatterns(denies), required)
+	if !ok {
+		return nil, false
+	}
+	for _, rule := range denies {
+		if rule.Pattern == pattern {
+			return &rule, true
+		}
+	}
+	return nil, false
+}
+
+func (u *rbacUseCase) GetUserPermissions(userID string) ([]Permission, error) {
+	return u.rbacRepo.GetUserPermissions(userID)
+}
+
+func (u *rbacUseCase) GetUserPermissionsInTenant(userID, organizationID string) ([]Permission, error) {
+	return u.rbacRepo.GetUserPermissionsInTenant(userID, organizationID)
+}
+
+// CheckPermissions decides every permission separately against the user's global roles and,
+// when organizationID is set, the roles assigned inside that organization
+func (u *rbacUseCase) CheckPermissions(userID, organizationID string, permissions []string) ([]PermissionDecision, error) {
+	granted, denies, err := u.effectivePermissions(userID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	decisions := make([]PermissionDecision, len(permissions))
+	for i, permission := range permissions {
+		decisions[i] = PermissionDecision{Permission: permission}
+		if rule, denied := firedDeny(denies, permission); denied {
+			decisions[i].Deny = rule
+			continue
+		}
+		decisions[i].MatchedBy, decisions[i].Allowed = BestMatch(granted, permission)
+	}
+	return decisions, nil
+}
+
+// ExplainPermissions walks every role's inheritance chain. An allowed permission, or one refused
+// by a deny rule, lists the user's roles that grant it; a permission that is simply not granted
+// lists the roles that would grant it if assigned.
+func (u *rbacUseCase) ExplainPermissions(userID, organizationID string, permissions []string) ([]PermissionDecision, error) {
+	decisions, err := u.CheckPermissions(userID, organizationID, permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	// Role ID -> organization of the assignment, empty for global assignments
+	assigned := make(map[string]string)
+	// Role ID -> group the role is held through, for roles not assigned directly
+	viaGroup := make(map[string]string)
+	globalRoles, err := u.rbacRepo.GetUserRoles(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range globalRoles {
+		assigned[role.ID] = ""
+		viaGroup[role.ID] = role.Group
+	}
+	if organizationID != "" {
+		tenantRoles, err := u.rbacRepo.GetUserRolesInTenant(userID, organizationID)
+		if err != nil {
+			return nil, err
+		}
+		for _, role := range tenantRoles {
+			if _, ok := assigned[role.ID]; !ok {
+				assigned[role.ID] = organizationID
+			}
+		}
+	}
+
+	roles, err := u.rbacRepo.GetRoles()
+	if err != nil {
+		return nil, err
+	}
+	// Assigned roles come first, each group sorted by name as returned
+	sort.SliceStable(roles, func(i, j int) bool {
+		_, iAssigned := assigned[roles[i].ID]
+		_, jAssigned := assigned[roles[j].ID]
+		return iAssigned && !jAssigned
+	})
+
+	chains := make(map[string][]Role, len(roles))
+	rolePermissions := make(map[string][]string, len(roles))
+	for _, role := range roles {
+		ancestors, err := u.rbacRepo.GetRoleAncestors(role.ID)
+		if err != nil {
+			return nil, err
+		}
+		chains[role.ID] = append([]Role{role}, ancestors...)
+
+		held, err := u.rbacRepo.GetRolePermissions(role.ID)
+		if err != nil {
+			return nil, err
+		}
+		rolePermissions[role.ID] = permissionNames(held)
+	}
+
+	for i := range decisions {
+		for _, role := range roles {
+			organization, isAssigned := assigned[role.ID]
+			if (decisions[i].Allowed || decisions[i].Deny != nil) && !isAssigned {
+				continue
+			}
+			grant, ok := explainGrant(chains[role.ID], rolePermissions, decisions[i].Permission)
+			if !ok {
+				continue
+			}
+			grant.Assigned = isAssigned
+			grant.OrganizationID = organization
+			grant.Group = viaGroup[role.ID]
+			decisions[i].Grants = append(decisions[i].Grants, grant)
+		}
+	}
+	return decisions, nil
+}
+
+// TokenAuthorization returns the names of the user's global roles and the compacted permissions
+// and deny patterns of their global roles plus, when organizationID is set, the roles held in
+// that organization, as embedded in access tokens by security.JWTManager
+func (u *rbacUseCase) TokenAuthorization(userID, organizationID string) ([]string, []string, []string, error) {
+	roles, err := u.rbacRepo.GetUserRoles(userID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	roleNames := make([]string, len(roles))
+	for i, role := range roles {
+		roleNames[i] = role.Name
+	}
+
+	granted, denies, err := u.effectivePermissions(userID, organizationID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return roleNames, CompactPermissions(granted), CompactPermissions(denyPatterns(denies)), nil
+}
+
+// ==================== Policy Document Operations ====================
+
+// errDryRun rolls back a dry-run transaction after every change was made
+var errDryRun = fmt.Errorf("rbac: dry run")
+
+// ExportPolicy describes every role, permission, role-permission mapping and role deny, sorted by name
+func (u *rbacUseCase) ExportPolicy() (*PolicyDocument, error) {
+	document, _, err := u.currentPolicy()
+	return document, err
+}
+
+// ApplyPolicy reconciles roles, permissions and mappings with the document in one transaction,
+// going through the same checks as the individual endpoints
+func (u *rbacUseCase) ApplyPolicy(document *PolicyDocument, options ApplyOptions) (*PolicyPlan, error) {
+	if problems := document.Validate(); len(problems) > 0 {
+		return nil, errors.NewWithDetails(errors.InvalidPolicyDocument, problems)
+	}
+
+	plan := &PolicyPlan{DryRun: options.DryRun}
+	err := u.rbacRepo.WithTransaction(func(repo RBACRepository) error {
+		tx := &rbacUseCase{rbacRepo: repo, actor: u.actor}
+
+		current, systemPermissions, err := tx.currentPolicy()
+		if err != nil {
+			return err
+		}
+
+		plan.Changes = diffPolicy(current, document, options.Prune, func(kind, name string) bool {
+			if kind == ChangeKindRole {
+				return IsBuiltInRole(name)
+			}
+			return systemPermissions[name]
+		})
+
+		if err := tx.applyChanges(plan.Changes); err != nil {
+			return err
+		}
+		if options.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && err != errDryRun {
+		return nil, err
+	}
+
+	if plan.Changes == nil {
+		plan.Changes = []PolicyChange{}
+	}
+	return plan, nil
+}
+
+// currentPolicy exports the stored state along with the names of built-in permissions
+func (u *rbacUseCase) currentPolicy() (*PolicyDocument, map[string]bool, error) {
+	permissions, err := u.rbacRepo.GetPermissions()
+	if err != nil {
+		return nil, nil, err
+	}
+	roles, err := u.rbacRepo.GetRoles()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	document := &PolicyDocument{
+		Permissions: make([]PermissionSpec, len(permissions)),
+		Roles:       make([]RoleSpec, len(roles)),
+	}
+	systemPermissions := make(map[string]bool)
+	for i, permission := range permissions {
+		document.Permissions[i] = PermissionSpec{Name: permission.Name, Description: permission.Description}
+		if permission.IsSystem {
+			systemPermissions[permission.Name] = true
+		}
+	}
+
+	roleNames := make(map[string]string, len(roles))
+	for _, role := range roles {
+		roleNames[role.ID] = role.Name
+	}
+	for i, role := range roles {
+		held, err := u.rbacRepo.GetRolePermissions(role.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		names := permissionNames(held)
+		sort.Strings(names)
+
+		denied, err := u.rbacRepo.GetRoleDenies(role.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		denies := permissionNames(denied)
+		sort.Strings(denies)
+
+		document.Roles[i] = RoleSpec{
+			Name:        role.Name,
+			Description: role.Description,
+			Parent:      roleNames[role.ParentID],
+			Permissions: names,
+			Deny:        denies,
+		}
+	}
+
+	sort.Slice(document.Permissions, func(i, j int) bool { return document.Permissions[i].Name < document.Permissions[j].Name })
+	sort.Slice(document.Roles, func(i, j int) bool { return document.Roles[i].Name < document.Roles[j].Name })
+	return document, systemPermissions, nil
+}
+
+// applyChanges makes the planned changes, which arrive in applyOrder
+func (u *rbacUseCase) applyChanges(changes []PolicyChange) error {
+	detached := false
+	for _, change := range changes {
+		if change.Kind == ChangeKindRoleParent && !detached {
+			// Detach every re-parented role first, so reshaping the hierarchy never passes
+			// through a temporary cycle
+			if err := u.detachRoles(changes); err != nil {
+				return err
+			}
+			detached = true
+		}
+
+		if err := u.applyChange(change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *rbacUseCase) detachRoles(changes []PolicyChange) error {
+	for _, change := range changes {
+		if change.Kind != ChangeKindRoleParent || change.Detail == "" {
+			continue
+		}
+		role, err := u.rbacRepo.GetRoleByName(change.Name)
+		if err != nil {
+			return err
+		}
+		if _, err := u.SetRoleParent(role.ID, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *rbacUseCase) applyChange(change PolicyChange) error {
+	switch change.Kind {
+	case ChangeKindPermission:
+		if change.Action == ChangeCreate {
+			resource, action, _ := splitPermissionName(change.Name)
+			_, err := u.CreatePermission(change.Name, resource, action, change.Detail)
+			return err
+		}
+
+		permission, err := u.rbacRepo.GetPermissionByName(change.Name)
+		if err != nil {
+			return err
+		}
+		if change.Action == ChangeDelete {
+			return u.DeletePermission(permission.ID)
+		}
+		_, err = u.UpdatePermission(permission.ID, "", "", "", change.Detail)
+		return err
+
+	case ChangeKindRole:
+		if change.Action == ChangeCreate {
+			_, err := u.CreateRole(change.Name, change.Detail)
+			return err
+		}
+
+		role, err := u.rbacRepo.GetRoleByName(change.Name)
+		if err != nil {
+			return err
+		}
+		if change.Action == ChangeDelete {
+			return u.DeleteRole(role.ID)
+		}
+		_, err = u.UpdateRole(role.ID, "", change.Detail)
+		return err
+
+	case ChangeKindRoleParent:
+		role, err := u.rbacRepo.GetRoleByName(change.Name)
+		if err != nil {
+			return err
+		}
+		parentID := ""
+		if change.Detail != "" {
+			parent, err := u.rbacRepo.GetRoleByName(change.Detail)
+			if err != nil {
+				return err
+			}
+			parentID = parent.ID
+		}
+		_, err = u.SetRoleParent(role.ID, parentID)
+		return err
+
+	case ChangeKindRolePermission:
+		role, err := u.rbacRepo.GetRoleByName(change.Name)
+		if err != nil {
+			return err
+		}
+		permission, err := u.rbacRepo.GetPermissionByName(change.Detail)
+		if err != nil {
+			return err
+		}
+		if change.Action == ChangeDelete {
+			return u.RemovePermissionFromRole(role.ID, permission.ID)
+		}
+		return u.AssignPermissionToRole(role.ID, permission.ID)
+
+	case ChangeKindRoleDeny:
+		role, err := u.rbacRepo.GetRoleByName(change.Name)
+		if err != nil {
+			return err
+		}
+		permission, err := u.rbacRepo.GetPermissionByName(change.Detail)
+		if err != nil {
+			return err
+		}
+		if change.Action == ChangeDelete {
+			return u.RemoveDenyFromRole(role.ID, permission.ID)
+		}
+		return u.AddDenyToRole(role.ID, permission.ID)
+	}
+
+	return errors.New(errors.InternalServerError)
+}
+
+// ==================== Change History Operations ====================
+
+func (u *rbacUseCase) WithActor(actor ChangeActor) RBACUseCase {
+	return &rbacUseCase{rbacRepo: u.rbacRepo.WithActor(actor), actor: actor}
+}
+
+// actingAs records changes as made by the user unless the use case already names an actor
+func (u *rbacUseCase) actingAs(userID string) *rbacUseCase {
+	if u.actor != (ChangeActor{}) {
+		return u
+	}
+	return u.WithActor(ChangeActor{UserID: userID}).(*rbacUseCase)
+}
+
+func (u *rbacUseCase) GetChanges(filter ChangeFilter) ([]ChangeRecord, int64, error) {
+	return u.rbacRepo.GetChanges(filter)
+}
+
+func (u *rbacUseCase) GetPermissionsAt(userID, organizationID string, at time.Time) (*PermissionSnapshot, error) {
+	snapshots, err := u.permissionSnapshots(userID, organizationID, at)
+	if err != nil {
+		return nil, err
+	}
+	return &snapshots[0], nil
+}
+
+// DiffPermissions compares what the user could do at from with what they could do at to
+func (u *rbacUseCase) DiffPermissions(userID, organizationID string, from, to time.Time) (*PermissionDiff, error) {
+	snapshots, err := u.permissionSnapshots(userID, organizationID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PermissionDiff{
+		From:        snapshots[0],
+		To:          snapshots[1],
+		Roles:       diffNames(snapshots[0].Roles, snapshots[1].Roles),
+		Permissions: diffNames(snapshots[0].Permissions, snapshots[1].Permissions),
+		Denies:      diffNames(snapshots[0].Denies, snapshots[1].Denies),
+	}, nil
+}
+
+// permissionSnapshots rewinds the current state through the recorded changes. The state is read
+// before the changes, so a change committed in between is undone against a state that lacks it,
+// which leaves the same rows as undoing it against one that has it.
+func (u *rbacUseCase) permissionSnapshots(userID, organizationID string, times ...time.Time) ([]PermissionSnapshot, error) {
+	tables, err := u.rbacRepo.GetHistoryState(userID)
+	if err != nil {
+		return nil, err
+	}
+	state, err := newHistoryState(tables)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.InternalServerError)
+	}
+
+	earliest := times[0]
+	for _, at := range times[1:] {
+		if at.Before(earliest) {
+			earliest = at
+		}
+	}
+	changes, err := u.rbacRepo.GetUserChangesSince(userID, earliest)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := reconstructPermissions(state, changes, userID, organizationID, times...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.InternalServerError)
+	}
+	return snapshots, nil
+}
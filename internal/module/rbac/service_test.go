@@ -0,0 +1,1659 @@
+package rbac
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	"boilerplate-be/internal/shared/enum"
+	apperrors "boilerplate-be/internal/shared/errors"
+
+	"github.com/google/uuid"
+)
+
+// MockRBACRepository implements RBACRepository in memory for testing
+type MockRBACRepository struct {
+	roles           map[string]*Role
+	permissions     map[string]*Permission
+	userRoles       map[string]map[string]RoleValidity
+	tenantRoles     map[string]map[string]map[string]bool // organization -> user -> role
+	rolePermissions map[string]map[string]bool
+	groups          map[string]*Group
+	groupMembers    map[string]map[string]bool // group -> user
+	groupRoles      map[string]map[string]bool // group -> role
+	roleDenies      map[string]map[string]bool // role -> permission
+	userDenies      map[string]map[string]bool // user -> permission
+	conflictSets    map[string]*RoleConflictSet
+	roleManagers    map[string]map[string]bool // role -> manager role
+	changes         []ChangeRecord             // recorded history, oldest first; set by tests
+}
+
+func NewMockRBACRepository() *MockRBACRepository {
+	return &MockRBACRepository{
+		roles:           make(map[string]*Role),
+		permissions:     make(map[string]*Permission),
+		userRoles:       make(map[string]map[string]RoleValidity),
+		tenantRoles:     make(map[string]map[string]map[string]bool),
+		rolePermissions: make(map[string]map[string]bool),
+		groups:          make(map[string]*Group),
+		groupMembers:    make(map[string]map[string]bool),
+		groupRoles:      make(map[string]map[string]bool),
+		roleDenies:      make(map[string]map[string]bool),
+		userDenies:      make(map[string]map[string]bool),
+		conflictSets:    make(map[string]*RoleConflictSet),
+		roleManagers:    make(map[string]map[string]bool),
+	}
+}
+
+func (m *MockRBACRepository) addRole(name string) *Role {
+	role := &Role{ID: uuid.New().String(), Name: name}
+	m.roles[role.ID] = role
+	return role
+}
+
+func (m *MockRBACRepository) addPermission(name, resource, action string, system bool) *Permission {
+	permission := &Permission{ID: uuid.New().String(), Name: name, Resource: resource, Action: action, IsSystem: system}
+	m.permissions[permission.ID] = permission
+	return permission
+}
+
+func (m *MockRBACRepository) GetRoles() ([]Role, error) {
+	var roles []Role
+	for _, role := range m.roles {
+		roles = append(roles, *role)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+func (m *MockRBACRepository) GetRoleByID(id string) (*Role, error) {
+	if role, ok := m.roles[id]; ok {
+		copied := *role
+		return &copied, nil
+	}
+	return nil, apperrors.New(apperrors.ResourceNotFound)
+}
+
+func (m *MockRBACRepository) GetRoleByName(name string) (*Role, error) {
+	for _, role := range m.roles {
+		if role.Name == name {
+			copied := *role
+			return &copied, nil
+		}
+	}
+	return nil, apperrors.New(apperrors.ResourceNotFound)
+}
+
+func (m *MockRBACRepository) CreateRole(role *Role) error {
+	role.ID = uuid.New().String()
+	m.roles[role.ID] = role
+	return nil
+}
+
+func (m *MockRBACRepository) UpdateRole(role *Role) error {
+	if _, ok := m.roles[role.ID]; !ok {
+		return apperrors.New(apperrors.ResourceNotFound)
+	}
+	m.roles[role.ID] = role
+	return nil
+}
+
+func (m *MockRBACRepository) DeleteRole(id string) error {
+	if _, ok := m.roles[id]; !ok {
+		return apperrors.New(apperrors.ResourceNotFound)
+	}
+	delete(m.roles, id)
+	delete(m.rolePermissions, id)
+	for _, roles := range m.userRoles {
+		delete(roles, id)
+	}
+	return nil
+}
+
+func (m *MockRBACRepository) SetRoleParent(roleID, parentID string) error {
+	role, ok := m.roles[roleID]
+	if !ok {
+		return apperrors.New(apperrors.ResourceNotFound)
+	}
+	role.ParentID = parentID
+	return nil
+}
+
+func (m *MockRBACRepository) GetRoleAncestors(roleID string) ([]Role, error) {
+	var ancestors []Role
+	role, ok := m.roles[roleID]
+	for depth := 0; ok && role.ParentID != "" && depth < MaxRoleDepth; depth++ {
+		role, ok = m.roles[role.ParentID]
+		if ok {
+			ancestors = append(ancestors, *role)
+		}
+	}
+	return ancestors, nil
+}
+
+func (m *MockRBACRepository) GetRoleHeight(roleID string) (int, error) {
+	height := 0
+	for _, role := range m.roles {
+		ancestors, _ := m.GetRoleAncestors(role.ID)
+		for depth, ancestor := range ancestors {
+			if ancestor.ID == roleID {
+				height = max(height, depth+1)
+				break
+			}
+		}
+	}
+	return height, nil
+}
+
+func (m *MockRBACRepository) GetPermissions() ([]Permission, error) {
+	var permissions []Permission
+	for _, permission := range m.permissions {
+		permissions = append(permissions, *permission)
+	}
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i].Name < permissions[j].Name })
+	return permissions, nil
+}
+
+func (m *MockRBACRepository) GetPermissionByID(id string) (*Permission, error) {
+	if permission, ok := m.permissions[id]; ok {
+		copied := *permission
+		return &copied, nil
+	}
+	return nil, apperrors.New(apperrors.ResourceNotFound)
+}
+
+func (m *MockRBACRepository) GetPermissionByName(name string) (*Permission, error) {
+	for _, permission := range m.permissions {
+		if permission.Name == name {
+			copied := *permission
+			return &copied, nil
+		}
+	}
+	return nil, apperrors.New(apperrors.ResourceNotFound)
+}
+
+func (m *MockRBACRepository) CreatePermission(permission *Permission) error {
+	if _, err := m.GetPermissionByName(permission.Name); err == nil {
+		return apperrors.New(apperrors.PermissionExists)
+	}
+	permission.ID = uuid.New().String()
+	copied := *permission
+	m.permissions[permission.ID] = &copied
+	return nil
+}
+
+func (m *MockRBACRepository) UpdatePermission(permission *Permission) error {
+	if _, ok := m.permissions[permission.ID]; !ok {
+		return apperrors.New(apperrors.ResourceNotFound)
+	}
+	if existing, err := m.GetPermissionByName(permission.Name); err == nil && existing.ID != permission.ID {
+		return apperrors.New(apperrors.PermissionExists)
+	}
+	copied := *permission
+	m.permissions[permission.ID] = &copied
+	return nil
+}
+
+func (m *MockRBACRepository) MarkPermissionSystem(id string) error {
+	permission, ok := m.permissions[id]
+	if !ok {
+		return apperrors.New(apperrors.ResourceNotFound)
+	}
+	permission.IsSystem = true
+	return nil
+}
+
+func (m *MockRBACRepository) DeletePermission(id string) error {
+	if _, ok := m.permissions[id]; !ok {
+		return apperrors.New(apperrors.ResourceNotFound)
+	}
+	delete(m.permissions, id)
+	for _, permissions := range m.rolePermissions {
+		delete(permissions, id)
+	}
+	return nil
+}
+
+func (m *MockRBACRepository) GetUserRoles(userID string) ([]Role, error) {
+	now := time.Now()
+	var roles []Role
+	for roleID := range m.activeUserRoles(userID) {
+		role, ok := m.roles[roleID]
+		if !ok {
+			continue
+		}
+		held := *role
+		if validity, direct := m.userRoles[userID][roleID]; direct && validity.ActiveAt(now) {
+			held.Group = ""
+		} else {
+			held.Group = m.groupOf(userID, roleID)
+		}
+		roles = append(roles, held)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+// activeUserRoles returns the user's global role IDs whose validity includes now, plus the
+// roles of their groups
+func (m *MockRBACRepository) activeUserRoles(userID string) map[string]bool {
+	now := time.Now()
+	active := make(map[string]bool)
+	for roleID, validity := range m.userRoles[userID] {
+		if validity.ActiveAt(now) {
+			active[roleID] = true
+		}
+	}
+	for groupID, members := range m.groupMembers {
+		if members[userID] {
+			for roleID := range m.groupRoles[groupID] {
+				active[roleID] = true
+			}
+		}
+	}
+	return active
+}
+
+// groupOf returns the name of a group through which the user holds the role
+func (m *MockRBACRepository) groupOf(userID, roleID string) string {
+	var names []string
+	for groupID, members := range m.groupMembers {
+		if members[userID] && m.groupRoles[groupID][roleID] {
+			names = append(names, m.groups[groupID].Name)
+		}
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return ""
+	}
+	return names[0]
+}
+
+func (m *MockRBACRepository) AssignRoleToUser(userID, roleID string, validity RoleValidity) error {
+	if m.userRoles[userID] == nil {
+		m.userRoles[userID] = make(map[string]RoleValidity)
+	}
+	m.userRoles[userID][roleID] = validity
+	return nil
+}
+
+func (m *MockRBACRepository) DeleteExpiredUserRoles() ([]string, error) {
+	now := time.Now()
+	var userIDs []string
+	for userID, roles := range m.userRoles {
+		expired := false
+		for roleID, validity := range roles {
+			if validity.Until != nil && !now.Before(*validity.Until) {
+				delete(roles, roleID)
+				expired = true
+			}
+		}
+		if expired {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	return userIDs, nil
+}
+
+func (m *MockRBACRepository) RemoveRoleFromUser(userID, roleID string) error {
+	delete(m.userRoles[userID], roleID)
+	return nil
+}
+
+func (m *MockRBACRepository) GetUserRolesInTenant(userID, organizationID string) ([]Role, error) {
+	var roles []Role
+	for roleID := range m.tenantRoles[organizationID][userID] {
+		if role, ok := m.roles[roleID]; ok {
+			roles = append(roles, *role)
+		}
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+func (m *MockRBACRepository) AssignRoleToUserInTenant(userID, roleID, organizationID string) error {
+	if m.tenantRoles[organizationID] == nil {
+		m.tenantRoles[organizationID] = make(map[string]map[string]bool)
+	}
+	if m.tenantRoles[organizationID][userID] == nil {
+		m.tenantRoles[organizationID][userID] = make(map[string]bool)
+	}
+	m.tenantRoles[organizationID][userID][roleID] = true
+	return nil
+}
+
+func (m *MockRBACRepository) RemoveRoleFromUserInTenant(userID, roleID, organizationID string) error {
+	delete(m.tenantRoles[organizationID][userID], roleID)
+	return nil
+}
+
+func (m *MockRBACRepository) GetGroups() ([]Group, error) {
+	var groups []Group
+	for _, group := range m.groups {
+		groups = append(groups, *group)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups, nil
+}
+
+func (m *MockRBACRepository) GetGroupByID(id string) (*Group, error) {
+	if group, ok := m.groups[id]; ok {
+		copied := *group
+		return &copied, nil
+	}
+	return nil, apperrors.New(apperrors.ResourceNotFound)
+}
+
+func (m *MockRBACRepository) CreateGroup(group *Group) error {
+	for _, existing := range m.groups {
+		if existing.Name == group.Name {
+			return apperrors.New(apperrors.Conflict)
+		}
+	}
+	group.ID = uuid.New().String()
+	copied := *group
+	m.groups[group.ID] = &copied
+	return nil
+}
+
+func (m *MockRBACRepository) UpdateGroup(group *Group) error {
+	if _, ok := m.groups[group.ID]; !ok {
+		return apperrors.New(apperrors.ResourceNotFound)
+	}
+	copied := *group
+	m.groups[group.ID] = &copied
+	return nil
+}
+
+func (m *MockRBACRepository) DeleteGroup(id string) error {
+	if _, ok := m.groups[id]; !ok {
+		return apperrors.New(apperrors.ResourceNotFound)
+	}
+	delete(m.groups, id)
+	delete(m.groupMembers, id)
+	delete(m.groupRoles, id)
+	return nil
+}
+
+func (m *MockRBACRepository) GetGroupMembers(groupID string) ([]GroupMember, error) {
+	var members []GroupMember
+	for userID := range m.groupMembers[groupID] {
+		members = append(members, GroupMember{UserID: userID})
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].UserID < members[j].UserID })
+	return members, nil
+}
+
+func (m *MockRBACRepository) AddUserToGroup(groupID, userID string) error {
+	if m.groupMembers[groupID] == nil {
+		m.groupMembers[groupID] = make(map[string]bool)
+	}
+	m.groupMembers[groupID][userID] = true
+	return nil
+}
+
+func (m *MockRBACRepository) RemoveUserFromGroup(groupID, userID string) error {
+	delete(m.groupMembers[groupID], userID)
+	return nil
+}
+
+func (m *MockRBACRepository) GetUserGroups(userID string) ([]Group, error) {
+	var groups []Group
+	for groupID, members := range m.groupMembers {
+		if members[userID] {
+			groups = append(groups, *m.groups[groupID])
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups, nil
+}
+
+func (m *MockRBACRepository) GetGroupRoles(groupID string) ([]Role, error) {
+	var roles []Role
+	for roleID := range m.groupRoles[groupID] {
+		if role, ok := m.roles[roleID]; ok {
+			roles = append(roles, *role)
+		}
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+func (m *MockRBACRepository) AssignRoleToGroup(groupID, roleID string) error {
+	if m.groupRoles[groupID] == nil {
+		m.groupRoles[groupID] = make(map[string]bool)
+	}
+	m.groupRoles[groupID][roleID] = true
+	return nil
+}
+
+func (m *MockRBACRepository) RemoveRoleFromGroup(groupID, roleID string) error {
+	delete(m.groupRoles[groupID], roleID)
+	return nil
+}
+
+func (m *MockRBACRepository) HasRole(userID, roleName string) (bool, error) {
+	roles, _ := m.GetUserRoles(userID)
+	for _, role := range roles {
+		if role.Name == roleName {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *MockRBACRepository) GetRolePermissions(roleID string) ([]Permission, error) {
+	var permissions []Permission
+	for permissionID := range m.rolePermissions[roleID] {
+		if permission, ok := m.permissions[permissionID]; ok {
+			permissions = append(permissions, *permission)
+		}
+	}
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i].Name < permissions[j].Name })
+	return permissions, nil
+}
+
+func (m *MockRBACRepository) AssignPermissionToRole(roleID, permissionID string) error {
+	if m.rolePermissions[roleID] == nil {
+		m.rolePermissions[roleID] = make(map[string]bool)
+	}
+	m.rolePermissions[roleID][permissionID] = true
+	return nil
+}
+
+func (m *MockRBACRepository) RemovePermissionFromRole(roleID, permissionID string) error {
+	delete(m.rolePermissions[roleID], permissionID)
+	return nil
+}
+
+func (m *MockRBACRepository) GetRoleDenies(roleID string) ([]Permission, error) {
+	var permissions []Permission
+	for permissionID := range m.roleDenies[roleID] {
+		if permission, ok := m.permissions[permissionID]; ok {
+			permissions = append(permissions, *permission)
+		}
+	}
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i].Name < permissions[j].Name })
+	return permissions, nil
+}
+
+func (m *MockRBACRepository) AddDenyToRole(roleID, permissionID string) error {
+	if m.roleDenies[roleID] == nil {
+		m.roleDenies[roleID] = make(map[string]bool)
+	}
+	m.roleDenies[roleID][permissionID] = true
+	return nil
+}
+
+func (m *MockRBACRepository) RemoveDenyFromRole(roleID, permissionID string) error {
+	delete(m.roleDenies[roleID], permissionID)
+	return nil
+}
+
+func (m *MockRBACRepository) GetUserDenies(userID string) ([]Permission, error) {
+	var permissions []Permission
+	for permissionID := range m.userDenies[userID] {
+		if permission, ok := m.permissions[permissionID]; ok {
+			permissions = append(permissions, *permission)
+		}
+	}
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i].Name < permissions[j].Name })
+	return permissions, nil
+}
+
+func (m *MockRBACRepository) AddDenyToUser(userID, permissionID string) error {
+	if m.userDenies[userID] == nil {
+		m.userDenies[userID] = make(map[string]bool)
+	}
+	m.userDenies[userID][permissionID] = true
+	return nil
+}
+
+func (m *MockRBACRepository) RemoveDenyFromUser(userID, permissionID string) error {
+	delete(m.userDenies[userID], permissionID)
+	return nil
+}
+
+func (m *MockRBACRepository) GetUserDenyRules(userID, organizationID string) ([]DenyRule, error) {
+	assigned := m.activeUserRoles(userID)
+	for roleID := range m.tenantRoles[organizationID][userID] {
+		assigned[roleID] = true
+	}
+
+	var rules []DenyRule
+	for permissionID := range m.userDenies[userID] {
+		rules = append(rules, DenyRule{Pattern: m.permissions[permissionID].Name})
+	}
+	for roleID := range m.effectiveRoles(assigned) {
+		for permissionID := range m.roleDenies[roleID] {
+			rules = append(rules, DenyRule{Pattern: m.permissions[permissionID].Name, Role: m.roles[roleID].Name})
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Pattern != rules[j].Pattern {
+			return rules[i].Pattern < rules[j].Pattern
+		}
+		return rules[i].Role < rules[j].Role
+	})
+	return rules, nil
+}
+
+func (m *MockRBACRepository) GetRoleConflictSets() ([]RoleConflictSet, error) {
+	var sets []RoleConflictSet
+	for _, set := range m.conflictSets {
+		sets = append(sets, *set)
+	}
+	sort.Slice(sets, func(i, j int) bool { return sets[i].Name < sets[j].Name })
+	return sets, nil
+}
+
+func (m *MockRBACRepository) GetRoleConflictSetByID(id string) (*RoleConflictSet, error) {
+	if set, ok := m.conflictSets[id]; ok {
+		copied := *set
+		return &copied, nil
+	}
+	return nil, apperrors.New(apperrors.ResourceNotFound)
+}
+
+func (m *MockRBACRepository) CreateRoleConflictSet(set *RoleConflictSet) error {
+	for _, existing := range m.conflictSets {
+		if existing.Name == set.Name {
+			return apperrors.New(apperrors.Conflict)
+		}
+	}
+	set.ID = uuid.New().String()
+	m.conflictSets[set.ID] = set
+	return nil
+}
+
+func (m *MockRBACRepository) DeleteRoleConflictSet(id string) error {
+	if _, ok := m.conflictSets[id]; !ok {
+		return apperrors.New(apperrors.ResourceNotFound)
+	}
+	delete(m.conflictSets, id)
+	return nil
+}
+
+func (m *MockRBACRepository) GetRoleConflictViolations() ([]RoleConflictViolation, error) {
+	users := make(map[string]bool)
+	for userID := range m.userRoles {
+		users[userID] = true
+	}
+	for _, members := range m.groupMembers {
+		for userID := range members {
+			users[userID] = true
+		}
+	}
+
+	sets, _ := m.GetRoleConflictSets()
+	var violations []RoleConflictViolation
+	for _, set := range sets {
+		for userID := range users {
+			held := m.effectiveRoles(m.activeUserRoles(userID))
+			var names []string
+			for _, role := range set.Roles {
+				if held[role.ID] {
+					names = append(names, role.Name)
+				}
+			}
+			if len(names) > 1 {
+				sort.Strings(names)
+				violations = append(violations, RoleConflictViolation{ConflictSet: set.Name, UserID: userID, Roles: names})
+			}
+		}
+	}
+	return violations, nil
+}
+
+func (m *MockRBACRepository) GetRoleManagers(roleID string) ([]Role, error) {
+	var roles []Role
+	for managerRoleID := range m.roleManagers[roleID] {
+		if role, ok := m.roles[managerRoleID]; ok {
+			roles = append(roles, *role)
+		}
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+func (m *MockRBACRepository) SetRoleManagers(roleID string, managerRoleIDs []string) error {
+	m.roleManagers[roleID] = make(map[string]bool)
+	for _, managerRoleID := range managerRoleIDs {
+		m.roleManagers[roleID][managerRoleID] = true
+	}
+	return nil
+}
+
+func (m *MockRBACRepository) GetManagedRoles(managerRoleIDs []string) ([]Role, error) {
+	var roles []Role
+	for roleID, managers := range m.roleManagers {
+		for _, managerRoleID := range managerRoleIDs {
+			if managers[managerRoleID] {
+				roles = append(roles, *m.roles[roleID])
+				break
+			}
+		}
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+func (m *MockRBACRepository) GetUserPermissions(userID string) ([]Permission, error) {
+	return m.permissionsOf(m.activeUserRoles(userID)), nil
+}
+
+func (m *MockRBACRepository) GetUserPermissionsInTenant(userID, organizationID string) ([]Permission, error) {
+	assigned := m.activeUserRoles(userID)
+	for roleID := range m.tenantRoles[organizationID][userID] {
+		assigned[roleID] = true
+	}
+	return m.permissionsOf(assigned), nil
+}
+
+// effectiveRoles returns the assigned roles plus all of their ancestors
+func (m *MockRBACRepository) effectiveRoles(assigned map[string]bool) map[string]bool {
+	effective := make(map[string]bool)
+	for roleID := range assigned {
+		effective[roleID] = true
+		ancestors, _ := m.GetRoleAncestors(roleID)
+		for _, ancestor := range ancestors {
+			effective[ancestor.ID] = true
+		}
+	}
+	return effective
+}
+
+func (m *MockRBACRepository) permissionsOf(assigned map[string]bool) []Permission {
+	seen := make(map[string]bool)
+	var permissions []Permission
+	for roleID := range m.effectiveRoles(assigned) {
+		for permissionID := range m.rolePermissions[roleID] {
+			if permission, ok := m.permissions[permissionID]; ok && !seen[permissionID] {
+				seen[permissionID] = true
+				permissions = append(permissions, *permission)
+			}
+		}
+	}
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i].Name < permissions[j].Name })
+	return permissions
+}
+
+func (m *MockRBACRepository) HasPermission(userID, permissionName string) (bool, error) {
+	permissions, _ := m.GetUserPermissions(userID)
+	for _, permission := range permissions {
+		if permission.Name == permissionName {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// WithTransaction restores the repository's state when fn fails, like a rollback
+func (m *MockRBACRepository) WithTransaction(fn func(repo RBACRepository) error) error {
+	snapshot := m.clone()
+	if err := fn(m); err != nil {
+		*m = *snapshot
+		return err
+	}
+	return nil
+}
+
+func (m *MockRBACRepository) clone() *MockRBACRepository {
+	copied := NewMockRBACRepository()
+	for id, role := range m.roles {
+		role := *role
+		copied.roles[id] = &role
+	}
+	for id, permission := range m.permissions {
+		permission := *permission
+		copied.permissions[id] = &permission
+	}
+	for userID, roles := range m.userRoles {
+		copied.userRoles[userID] = make(map[string]RoleValidity)
+		for roleID, validity := range roles {
+			copied.userRoles[userID][roleID] = validity
+		}
+	}
+	for orgID, users := range m.tenantRoles {
+		copied.tenantRoles[orgID] = make(map[string]map[string]bool)
+		for userID, roles := range users {
+			copied.tenantRoles[orgID][userID] = make(map[string]bool)
+			for roleID := range roles {
+				copied.tenantRoles[orgID][userID][roleID] = true
+			}
+		}
+	}
+	for roleID, permissions := range m.rolePermissions {
+		copied.rolePermissions[roleID] = make(map[string]bool)
+		for permissionID := range permissions {
+			copied.rolePermissions[roleID][permissionID] = true
+		}
+	}
+	for id, group := range m.groups {
+		group := *group
+		copied.groups[id] = &group
+	}
+	for groupID, members := range m.groupMembers {
+		copied.groupMembers[groupID] = make(map[string]bool)
+		for userID := range members {
+			copied.groupMembers[groupID][userID] = true
+		}
+	}
+	for groupID, roles := range m.groupRoles {
+		copied.groupRoles[groupID] = make(map[string]bool)
+		for roleID := range roles {
+			copied.groupRoles[groupID][roleID] = true
+		}
+	}
+	for roleID, permissions := range m.roleDenies {
+		copied.roleDenies[roleID] = make(map[string]bool)
+		for permissionID := range permissions {
+			copied.roleDenies[roleID][permissionID] = true
+		}
+	}
+	for userID, permissions := range m.userDenies {
+		copied.userDenies[userID] = make(map[string]bool)
+		for permissionID := range permissions {
+			copied.userDenies[userID][permissionID] = true
+		}
+	}
+	for id, set := range m.conflictSets {
+		set := *set
+		copied.conflictSets[id] = &set
+	}
+	for roleID, managers := range m.roleManagers {
+		copied.roleManagers[roleID] = make(map[string]bool)
+		for managerRoleID := range managers {
+			copied.roleManagers[roleID][managerRoleID] = true
+		}
+	}
+	copied.changes = append([]ChangeRecord(nil), m.changes...)
+	return copied
+}
+
+// changedUser returns the user_id of the changed row, like COALESCE(new_row, old_row)->>'user_id'
+func changedUser(change ChangeRecord) string {
+	row := change.NewRow
+	if string(row) == "null" {
+		row = change.OldRow
+	}
+	var owner struct {
+		UserID string `json:"user_id"`
+	}
+	_ = json.Unmarshal(row, &owner)
+	return owner.UserID
+}
+
+func (m *MockRBACRepository) WithActor(actor ChangeActor) RBACRepository {
+	return m
+}
+
+func (m *MockRBACRepository) GetChanges(filter ChangeFilter) ([]ChangeRecord, int64, error) {
+	matched := []ChangeRecord{}
+	for i := len(m.changes) - 1; i >= 0; i-- {
+		change := m.changes[i]
+		if (filter.Table == "" || change.Table == filter.Table) &&
+			(filter.ActorID == "" || change.ActorID == filter.ActorID) &&
+			(filter.UserID == "" || changedUser(change) == filter.UserID) {
+			matched = append(matched, change)
+		}
+	}
+	start := min((filter.Page-1)*filter.PageSize, len(matched))
+	end := min(start+filter.PageSize, len(matched))
+	return matched[start:end], int64(len(matched)), nil
+}
+
+// GetHistoryState encodes the mock's maps as the rows of the tables they stand for
+func (m *MockRBACRepository) GetHistoryState(userID string) (map[string][]json.RawMessage, error) {
+	state := make(map[string][]json.RawMessage)
+	add := func(table string, row map[string]interface{}) {
+		data, _ := json.Marshal(row)
+		state[table] = append(state[table], data)
+	}
+	for _, role := range m.roles {
+		add("roles", map[string]interface{}{"id": role.ID, "name": role.Name, "parent_id": nullableID(role.ParentID)})
+	}
+	for _, permission := range m.permissions {
+		add("permissions", map[string]interface{}{"id": permission.ID, "name": permission.Name})
+	}
+	for roleID, validity := range m.userRoles[userID] {
+		add("user_roles", map[string]interface{}{
+			"user_id": userID, "role_id": roleID, "organization_id": nil,
+			"valid_from": validity.From, "valid_until": validity.Until,
+		})
+	}
+	for orgID, users := range m.tenantRoles {
+		for roleID := range users[userID] {
+			add("user_roles", map[string]interface{}{"user_id": userID, "role_id": roleID, "organization_id": orgID})
+		}
+	}
+	for groupID, members := range m.groupMembers {
+		if members[userID] {
+			add("group_members", map[string]interface{}{"group_id": groupID, "user_id": userID})
+		}
+	}
+	for table, links := range map[string]map[string]map[string]bool{
+		"role_permissions":       m.rolePermissions,
+		"role_permission_denies": m.roleDenies,
+	} {
+		for roleID, permissions := range links {
+			for permissionID := range permissions {
+				add(table, map[string]interface{}{"role_id": roleID, "permission_id": permissionID})
+			}
+		}
+	}
+	for groupID, roles := range m.groupRoles {
+		for roleID := range roles {
+			add("group_roles", map[string]interface{}{"group_id": groupID, "role_id": roleID})
+		}
+	}
+	for permissionID := range m.userDenies[userID] {
+		add("user_permission_denies", map[string]interface{}{"user_id": userID, "permission_id": permissionID})
+	}
+	return state, nil
+}
+
+func (m *MockRBACRepository) GetUserChangesSince(userID string, since time.Time) ([]ChangeRecord, error) {
+	var changes []ChangeRecord
+	for _, change := range m.changes {
+		if _, ok := historyKeys[change.Table]; !ok || !change.ChangedAt.After(since) {
+			continue
+		}
+		if isUserHistoryTable(change.Table) && changedUser(change) != userID {
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+func assertErrorCode(t *testing.T, err error, want enum.ErrorCode) {
+	t.Helper()
+	if want == enum.Success {
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		return
+	}
+	appErr, ok := apperrors.IsAppError(err)
+	if !ok {
+		t.Fatalf("expected error %s, got %v", want, err)
+	}
+	if appErr.Code != want {
+		t.Fatalf("expected error %s, got %s", want, appErr.Code)
+	}
+}
+
+func TestRBACService_CreatePermission(t *testing.T) {
+	tests := []struct {
+		name     string
+		permName string
+		resource string
+		action   string
+		wantCode enum.ErrorCode
+	}{
+		{"valid permission", "reports:export", "reports", "export", enum.Success},
+		{"name does not match resource and action", "reports:download", "reports", "export", apperrors.PermissionNameMismatch},
+		{"duplicate name", "users:read", "users", "read", apperrors.PermissionExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockRBACRepository()
+			repo.addPermission("users:read", "users", "read", true)
+			useCase := NewRBACUseCase(repo)
+
+			permission, err := useCase.CreatePermission(tt.permName, tt.resource, tt.action, "")
+			assertErrorCode(t, err, tt.wantCode)
+			if err == nil && (permission.ID == "" || permission.IsSystem) {
+				t.Errorf("expected a stored custom permission, got %+v", permission)
+			}
+		})
+	}
+}
+
+func TestRBACService_UpdatePermission(t *testing.T) {
+	repo := NewMockRBACRepository()
+	system := repo.addPermission("users:read", "users", "read", true)
+	custom := repo.addPermission("reports:export", "reports", "export", false)
+	useCase := NewRBACUseCase(repo)
+
+	t.Run("system permission description can change", func(t *testing.T) {
+		permission, err := useCase.UpdatePermission(system.ID, "", "", "", "View users")
+		assertErrorCode(t, err, enum.Success)
+		if permission.Description != "View users" {
+			t.Errorf("expected description to be updated, got %q", permission.Description)
+		}
+	})
+
+	t.Run("system permission cannot be renamed", func(t *testing.T) {
+		_, err := useCase.UpdatePermission(system.ID, "users:list", "users", "list", "")
+		assertErrorCode(t, err, apperrors.SystemPermissionProtected)
+	})
+
+	t.Run("partial rename must keep name consistent", func(t *testing.T) {
+		_, err := useCase.UpdatePermission(custom.ID, "", "", "download", "")
+		assertErrorCode(t, err, apperrors.PermissionNameMismatch)
+	})
+
+	t.Run("custom permission can be renamed", func(t *testing.T) {
+		permission, err := useCase.UpdatePermission(custom.ID, "reports:download", "", "download", "")
+		assertErrorCode(t, err, enum.Success)
+		if permission.Action != "download" {
+			t.Errorf("expected action download, got %q", permission.Action)
+		}
+	})
+}
+
+func TestRBACService_DeletePermission(t *testing.T) {
+	repo := NewMockRBACRepository()
+	system := repo.addPermission("users:read", "users", "read", true)
+	custom := repo.addPermission("reports:export", "reports", "export", false)
+	role := repo.addRole("analyst")
+	_ = repo.AssignPermissionToRole(role.ID, custom.ID)
+	useCase := NewRBACUseCase(repo)
+
+	assertErrorCode(t, useCase.DeletePermission(system.ID), apperrors.SystemPermissionProtected)
+	assertErrorCode(t, useCase.DeletePermission(custom.ID), enum.Success)
+	assertErrorCode(t, useCase.DeletePermission(custom.ID), apperrors.ResourceNotFound)
+
+	permissions, _ := useCase.GetRolePermissions(role.ID)
+	if len(permissions) != 0 {
+		t.Errorf("expected deleted permission to be removed from role, got %v", permissions)
+	}
+}
+
+func TestRBACService_SetRoleParent_MaxDepth(t *testing.T) {
+	repo := NewMockRBACRepository()
+	useCase := NewRBACUseCase(repo)
+
+	// chain builds roles each inheriting from the previous one and returns them top first
+	chain := func(prefix string, n int) []*Role {
+		roles := make([]*Role, n)
+		for i := range roles {
+			roles[i] = repo.addRole(fmt.Sprintf("%s_%d", prefix, i))
+			if i > 0 {
+				_, err := useCase.SetRoleParent(roles[i].ID, roles[i-1].ID)
+				assertErrorCode(t, err, enum.Success)
+			}
+		}
+		return roles
+	}
+	upper := chain("upper", 20)
+	lower := chain("lower", 13)
+
+	// lower's deepest role would have 19 + 1 + 12 ancestors
+	_, err := useCase.SetRoleParent(lower[0].ID, upper[len(upper)-1].ID)
+	assertErrorCode(t, err, enum.Success)
+
+	deepest := lower[len(lower)-1]
+	if ancestors, _ := useCase.GetRoleAncestors(deepest.ID); len(ancestors) != MaxRoleDepth {
+		t.Fatalf("expected %d ancestors, got %d", MaxRoleDepth, len(ancestors))
+	}
+
+	t.Run("one level deeper is rejected", func(t *testing.T) {
+		leaf := repo.addRole("leaf")
+		_, err := useCase.SetRoleParent(leaf.ID, deepest.ID)
+		assertErrorCode(t, err, apperrors.RoleHierarchyTooDeep)
+
+		_, err = useCase.SetRoleParent(leaf.ID, lower[len(lower)-2].ID)
+		assertErrorCode(t, err, enum.Success)
+	})
+
+	t.Run("moving a subtree below a deeper parent is rejected", func(t *testing.T) {
+		extra := repo.addRole("extra")
+		_, err := useCase.SetRoleParent(extra.ID, upper[len(upper)-1].ID)
+		assertErrorCode(t, err, enum.Success)
+
+		_, err = useCase.SetRoleParent(lower[0].ID, extra.ID)
+		assertErrorCode(t, err, apperrors.RoleHierarchyTooDeep)
+		if role, _ := repo.GetRoleByID(lower[0].ID); role.ParentID != upper[len(upper)-1].ID {
+			t.Error("expected the rejected parent not to be stored")
+		}
+	})
+}
+
+func TestRBACService_SetRoleParent(t *testing.T) {
+	repo := NewMockRBACRepository()
+	user := repo.addRole("user")
+	admin := repo.addRole("admin")
+	superAdmin := repo.addRole("super_admin")
+	useCase := NewRBACUseCase(repo)
+
+	_, err := useCase.SetRoleParent(admin.ID, user.ID)
+	assertErrorCode(t, err, enum.Success)
+	_, err = useCase.SetRoleParent(superAdmin.ID, admin.ID)
+	assertErrorCode(t, err, enum.Success)
+
+	t.Run("self parent is a cycle", func(t *testing.T) {
+		_, err := useCase.SetRoleParent(admin.ID, admin.ID)
+		assertErrorCode(t, err, apperrors.RoleHierarchyCycle)
+	})
+
+	t.Run("descendant parent is a cycle", func(t *testing.T) {
+		_, err := useCase.SetRoleParent(user.ID, superAdmin.ID)
+		assertErrorCode(t, err, apperrors.RoleHierarchyCycle)
+	})
+
+	t.Run("unknown parent", func(t *testing.T) {
+		_, err := useCase.SetRoleParent(user.ID, uuid.New().String())
+		assertErrorCode(t, err, apperrors.ResourceNotFound)
+	})
+
+	t.Run("ancestors are nearest first", func(t *testing.T) {
+		ancestors, _ := useCase.GetRoleAncestors(superAdmin.ID)
+		if len(ancestors) != 2 || ancestors[0].ID != admin.ID || ancestors[1].ID != user.ID {
+			t.Errorf("expected [admin user], got %v", ancestors)
+		}
+	})
+
+	t.Run("clearing the parent", func(t *testing.T) {
+		role, err := useCase.SetRoleParent(superAdmin.ID, "")
+		assertErrorCode(t, err, enum.Success)
+		if role.ParentID != "" {
+			t.Errorf("expected parent to be cleared, got %q", role.ParentID)
+		}
+	})
+}
+
+func TestRBACService_InheritedPermissions(t *testing.T) {
+	repo := NewMockRBACRepository()
+	user := repo.addRole("user")
+	admin := repo.addRole("admin")
+	superAdmin := repo.addRole("super_admin")
+	profileRead := repo.addPermission("profile:read", "profile", "read", true)
+	usersDelete := repo.addPermission("users:delete", "users", "delete", true)
+	_ = repo.AssignPermissionToRole(user.ID, profileRead.ID)
+	_ = repo.AssignPermissionToRole(admin.ID, usersDelete.ID)
+	useCase := NewRBACUseCase(repo)
+
+	_, _ = useCase.SetRoleParent(admin.ID, user.ID)
+	_, _ = useCase.SetRoleParent(superAdmin.ID, admin.ID)
+	_ = repo.AssignRoleToUser("root", superAdmin.ID, RoleValidity{})
+	_ = repo.AssignRoleToUser("member", user.ID, RoleValidity{})
+
+	tests := []struct {
+		userID     string
+		permission string
+		want       bool
+	}{
+		{"root", "profile:read", true},
+		{"root", "users:delete", true},
+		{"member", "profile:read", true},
+		{"member", "users:delete", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.userID+" "+tt.permission, func(t *testing.T) {
+			got, err := useCase.CheckUserPermission(tt.userID, tt.permission)
+			assertErrorCode(t, err, enum.Success)
+			if got != tt.want {
+				t.Errorf("CheckUserPermission(%s, %s) = %v, want %v", tt.userID, tt.permission, got, tt.want)
+			}
+		})
+	}
+
+	// A permission granted to an ancestor later is picked up without touching descendants
+	reportsRead := repo.addPermission("reports:read", "reports", "read", false)
+	_ = useCase.AssignPermissionToRole(user.ID, reportsRead.ID)
+	if got, _ := useCase.CheckUserPermission("root", "reports:read"); !got {
+		t.Error("expected super_admin to inherit a permission newly granted to user")
+	}
+}
+
+func TestRBACService_TenantScopedRoles(t *testing.T) {
+	repo := NewMockRBACRepository()
+	user := repo.addRole("user")
+	orgAdmin := repo.addRole(RoleOrgAdmin)
+	profileRead := repo.addPermission("profile:read", "profile", "read", true)
+	membersWrite := repo.addPermission("orgs:members:write", "orgs:members", "write", true)
+	_ = repo.AssignPermissionToRole(user.ID, profileRead.ID)
+	_ = repo.AssignPermissionToRole(orgAdmin.ID, membersWrite.ID)
+	useCase := NewRBACUseCase(repo)
+
+	_ = useCase.AssignRoleToUser("alice", user.ID, RoleValidity{})
+	assertErrorCode(t, useCase.AssignRoleToUserInTenant("alice", orgAdmin.ID, "org-a"), enum.Success)
+	assertErrorCode(t, useCase.AssignRoleToUserInTenant("alice", "missing", "org-a"), enum.ResourceNotFound)
+
+	tests := []struct {
+		name           string
+		organizationID string
+		permission     string
+		want           bool
+	}{
+		{"global role applies in tenant", "org-a", "profile:read", true},
+		{"tenant role applies in its tenant", "org-a", "orgs:members:write", true},
+		{"tenant role does not leak to another tenant", "org-b", "orgs:members:write", false},
+		{"global role applies in another tenant", "org-b", "profile:read", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := useCase.CheckUserPermissionInTenant("alice", tt.organizationID, tt.permission)
+			assertErrorCode(t, err, enum.Success)
+			if got != tt.want {
+				t.Errorf("CheckUserPermissionInTenant(%s, %s) = %v, want %v", tt.organizationID, tt.permission, got, tt.want)
+			}
+		})
+	}
+
+	// Tenant assignments are invisible to global checks and listings
+	if got, _ := useCase.CheckUserPermission("alice", "orgs:members:write"); got {
+		t.Error("expected tenant-scoped role to be ignored outside a tenant")
+	}
+	if roles, _ := useCase.GetUserRoles("alice"); len(roles) != 1 || roles[0].Name != "user" {
+		t.Errorf("expected only the global role, got %v", roles)
+	}
+
+	_ = useCase.RemoveRoleFromUserInTenant("alice", orgAdmin.ID, "org-a")
+	if got, _ := useCase.CheckUserPermissionInTenant("alice", "org-a", "orgs:members:write"); got {
+		t.Error("expected removed tenant role to stop granting permissions")
+	}
+}
+
+func TestRBACService_GroupRoles(t *testing.T) {
+	repo := NewMockRBACRepository()
+	user := repo.addRole("user")
+	deployer := repo.addRole("deployer")
+	profileRead := repo.addPermission("profile:read", "profile", "read", true)
+	deploy := repo.addPermission("deploy:run", "deploy", "run", false)
+	_ = repo.AssignPermissionToRole(user.ID, profileRead.ID)
+	_ = repo.AssignPermissionToRole(deployer.ID, deploy.ID)
+	useCase := NewRBACUseCase(repo)
+
+	group, err := useCase.CreateGroup("engineering", "Engineering department")
+	assertErrorCode(t, err, enum.Success)
+	_, err = useCase.CreateGroup("engineering", "")
+	assertErrorCode(t, err, enum.Conflict)
+
+	assertErrorCode(t, useCase.AssignRoleToGroup(group.ID, deployer.ID), enum.Success)
+	assertErrorCode(t, useCase.AssignRoleToGroup(group.ID, "missing"), enum.ResourceNotFound)
+	assertErrorCode(t, useCase.AddUserToGroup("missing", "alice"), enum.ResourceNotFound)
+
+	_ = useCase.AssignRoleToUser("alice", user.ID, RoleValidity{})
+	if got, _ := useCase.CheckUserPermission("alice", "deploy:run"); got {
+		t.Fatal("expected no group permissions before joining")
+	}
+
+	assertErrorCode(t, useCase.AddUserToGroup(group.ID, "alice"), enum.Success)
+	for _, permission := range []string{"profile:read", "deploy:run"} {
+		if got, _ := useCase.CheckUserPermission("alice", permission); !got {
+			t.Errorf("expected direct and group roles to be combined, %s denied", permission)
+		}
+	}
+	roles, _ := useCase.GetUserRoles("alice")
+	if len(roles) != 2 || roles[0].Name != "deployer" || roles[0].Group != "engineering" || roles[1].Group != "" {
+		t.Errorf("expected deployer through engineering and user assigned directly, got %+v", roles)
+	}
+
+	// A role held directly and through a group is reported as the direct assignment
+	_ = useCase.AssignRoleToUser("alice", deployer.ID, RoleValidity{})
+	if roles, _ := useCase.GetUserRoles("alice"); roles[0].Group != "" {
+		t.Errorf("expected the direct assignment to win, got group %q", roles[0].Group)
+	}
+	_ = useCase.RemoveRoleFromUser("alice", deployer.ID)
+
+	decisions, _ := useCase.ExplainPermissions("alice", "", []string{"deploy:run"})
+	if grants := decisions[0].Grants; len(grants) != 1 || grants[0].Group != "engineering" || !grants[0].Assigned {
+		t.Errorf("expected the grant to name the group, got %+v", grants)
+	}
+
+	assertErrorCode(t, useCase.RemoveUserFromGroup(group.ID, "alice"), enum.Success)
+	if got, _ := useCase.CheckUserPermission("alice", "deploy:run"); got {
+		t.Error("expected leaving the group to revoke its roles")
+	}
+	if got, _ := useCase.CheckUserPermission("alice", "profile:read"); !got {
+		t.Error("expected direct roles to be kept after leaving the group")
+	}
+}
+
+func TestRBACService_RemoveRolesByName(t *testing.T) {
+	repo := NewMockRBACRepository()
+	admin := repo.addRole("admin")
+	deployer := repo.addRole("deployer")
+	user := repo.addRole("user")
+	useCase := NewRBACUseCase(repo)
+
+	group, _ := useCase.CreateGroup("engineering", "")
+	_ = useCase.AssignRoleToGroup(group.ID, deployer.ID)
+	_ = useCase.AddUserToGroup(group.ID, "alice")
+	_ = useCase.AssignRolesByName("alice", []string{admin.Name, user.Name})
+
+	assertErrorCode(t, useCase.RemoveRolesByName("alice", []string{"admin", "deployer", "missing"}), enum.Success)
+
+	roles, _ := useCase.GetUserRoles("alice")
+	var names []string
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	if !slices.Equal(names, []string{"deployer", "user"}) {
+		t.Errorf("expected admin removed and the group's deployer kept, got %v", names)
+	}
+}
+
+func TestRBACService_TimeBoundRoles(t *testing.T) {
+	repo := NewMockRBACRepository()
+	oncall := repo.addRole("oncall")
+	deploy := repo.addPermission("deploys:create", "deploys", "create", false)
+	_ = repo.AssignPermissionToRole(oncall.ID, deploy.ID)
+	useCase := NewRBACUseCase(repo)
+
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	soon := now.Add(time.Hour)
+	later := now.Add(2 * time.Hour)
+
+	t.Run("rejects invalid windows", func(t *testing.T) {
+		assertErrorCode(t, useCase.AssignRoleToUser("u", oncall.ID, RoleValidity{Until: &past}), enum.InvalidRoleValidity)
+		assertErrorCode(t, useCase.AssignRoleToUser("u", oncall.ID, RoleValidity{From: &later, Until: &soon}), enum.InvalidRoleValidity)
+	})
+
+	tests := []struct {
+		name     string
+		userID   string
+		validity RoleValidity
+		want     bool
+	}{
+		{"permanent", "permanent", RoleValidity{}, true},
+		{"active window", "active", RoleValidity{From: &past, Until: &soon}, true},
+		{"not yet started", "future", RoleValidity{From: &soon, Until: &later}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertErrorCode(t, useCase.AssignRoleToUser(tt.userID, oncall.ID, tt.validity), enum.Success)
+
+			hasRole, _ := useCase.CheckUserRole(tt.userID, "oncall")
+			hasPermission, _ := useCase.CheckUserPermission(tt.userID, "deploys:create")
+			if hasRole != tt.want || hasPermission != tt.want {
+				t.Errorf("role = %v, permission = %v, want %v", hasRole, hasPermission, tt.want)
+			}
+		})
+	}
+
+	t.Run("expired grants are ignored and swept", func(t *testing.T) {
+		// Written directly; the use case refuses windows that already ended
+		_ = repo.AssignRoleToUser("expired", oncall.ID, RoleValidity{Until: &past})
+
+		if hasRole, _ := useCase.CheckUserRole("expired", "oncall"); hasRole {
+			t.Error("expected expired grant to be ignored")
+		}
+
+		swept, err := useCase.SweepExpiredRoles()
+		assertErrorCode(t, err, enum.Success)
+		if swept != 1 {
+			t.Errorf("expected 1 user swept, got %d", swept)
+		}
+		if _, ok := repo.userRoles["expired"][oncall.ID]; ok {
+			t.Error("expected expired assignment to be deleted")
+		}
+		if _, ok := repo.userRoles["active"][oncall.ID]; !ok {
+			t.Error("expected active assignment to survive the sweep")
+		}
+	})
+}
+
+func TestRBACService_ApplyPolicy(t *testing.T) {
+	repo := NewMockRBACRepository()
+	superAdmin := repo.addRole("super_admin")
+	repo.addRole("user")
+	legacy := repo.addRole("legacy")
+	all := repo.addPermission("*:*", "*", "*", true)
+	old := repo.addPermission("old:read", "old", "read", false)
+	_ = repo.AssignPermissionToRole(superAdmin.ID, all.ID)
+	_ = repo.AssignPermissionToRole(legacy.ID, old.ID)
+	useCase := NewRBACUseCase(repo)
+
+	document := &PolicyDocument{
+		Permissions: []PermissionSpec{{Name: "reports:read", Description: "View reports"}, {Name: "reports:export"}},
+		Roles: []RoleSpec{
+			{Name: "user", Permissions: []string{"reports:read"}},
+			{Name: "analyst", Parent: "user", Permissions: []string{"reports:export"}},
+		},
+	}
+
+	t.Run("dry run changes nothing", func(t *testing.T) {
+		plan, err := useCase.ApplyPolicy(document, ApplyOptions{Prune: true, DryRun: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !plan.DryRun || len(plan.Changes) == 0 {
+			t.Errorf("expected a dry-run plan with changes, got %+v", plan)
+		}
+		if _, err := repo.GetRoleByName("analyst"); err == nil {
+			t.Error("expected dry run not to create roles")
+		}
+		if _, err := repo.GetRoleByName("legacy"); err != nil {
+			t.Error("expected dry run not to delete roles")
+		}
+	})
+
+	t.Run("apply with prune", func(t *testing.T) {
+		if _, err := useCase.ApplyPolicy(document, ApplyOptions{Prune: true}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		exported, _ := useCase.ExportPolicy()
+		var roles []string
+		for _, role := range exported.Roles {
+			roles = append(roles, role.Name)
+		}
+		// Built-in roles and permissions survive pruning even when missing from the document
+		if want := []string{"analyst", "super_admin", "user"}; !slices.Equal(roles, want) {
+			t.Errorf("roles = %v, want %v", roles, want)
+		}
+		if _, err := repo.GetPermissionByName("*:*"); err != nil {
+			t.Error("expected built-in permission to survive pruning")
+		}
+		if _, err := repo.GetPermissionByName("old:read"); err == nil {
+			t.Error("expected undeclared permission to be pruned")
+		}
+
+		if has, _ := useCase.CheckUserPermission("nobody", "reports:read"); has {
+			t.Error("expected permission check for an unassigned user to fail")
+		}
+		analyst, _ := repo.GetRoleByName("analyst")
+		_ = repo.AssignRoleToUser("ana", analyst.ID, RoleValidity{})
+		if has, _ := useCase.CheckUserPermission("ana", "reports:read", "reports:export"); !has {
+			t.Error("expected analyst to hold its own and inherited report permissions")
+		}
+	})
+
+	t.Run("apply is idempotent", func(t *testing.T) {
+		plan, err := useCase.ApplyPolicy(document, ApplyOptions{Prune: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(plan.Changes) != 0 {
+			t.Errorf("expected no changes on re-apply, got %v", plan.Changes)
+		}
+	})
+
+	t.Run("failed apply rolls back", func(t *testing.T) {
+		cyclic := &PolicyDocument{
+			Permissions: document.Permissions,
+			Roles: []RoleSpec{
+				{Name: "user", Parent: "analyst", Permissions: []string{"reports:read"}},
+				{Name: "analyst", Parent: "user", Permissions: []string{"reports:export"}},
+				{Name: "auditor"},
+			},
+		}
+		_, err := useCase.ApplyPolicy(cyclic, ApplyOptions{})
+		assertErrorCode(t, err, enum.RoleHierarchyCycle)
+		if _, err := repo.GetRoleByName("auditor"); err == nil {
+			t.Error("expected the role created before the failure to be rolled back")
+		}
+	})
+
+	t.Run("invalid document", func(t *testing.T) {
+		invalid := &PolicyDocument{Roles: []RoleSpec{{Name: "x", Permissions: []string{"missing:read"}}}}
+		_, err := useCase.ApplyPolicy(invalid, ApplyOptions{})
+		assertErrorCode(t, err, enum.InvalidPolicyDocument)
+	})
+}
+
+func TestRBACService_ExplainPermissions(t *testing.T) {
+	repo := NewMockRBACRepository()
+	user := repo.addRole("user")
+	admin := repo.addRole("admin")
+	superAdmin := repo.addRole("super_admin")
+	orgAdmin := repo.addRole(RoleOrgAdmin)
+	profileRead := repo.addPermission("profile:read", "profile", "read", true)
+	usersAll := repo.addPermission("users:*", "users", "*", false)
+	all := repo.addPermission("*:*", "*", "*", true)
+	membersWrite := repo.addPermission("orgs:members:write", "orgs:members", "write", true)
+	_ = repo.AssignPermissionToRole(user.ID, profileRead.ID)
+	_ = repo.AssignPermissionToRole(admin.ID, usersAll.ID)
+	_ = repo.AssignPermissionToRole(superAdmin.ID, all.ID)
+	_ = repo.AssignPermissionToRole(orgAdmin.ID, membersWrite.ID)
+	_ = repo.SetRoleParent(admin.ID, user.ID)
+	useCase := NewRBACUseCase(repo)
+
+	_ = useCase.AssignRoleToUser("alice", admin.ID, RoleValidity{})
+	_ = useCase.AssignRoleToUserInTenant("alice", orgAdmin.ID, "org-a")
+
+	decisions, err := useCase.CheckPermissions("alice", "", []string{"profile:read", "users:delete", "roles:write"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !decisions[0].Allowed || !decisions[1].Allowed || decisions[2].Allowed {
+		t.Fatalf("unexpected decisions: %+v", decisions)
+	}
+	if decisions[1].MatchedBy != "users:*" || decisions[0].Grants != nil {
+		t.Errorf("expected users:* match without grants, got %+v", decisions)
+	}
+
+	decisions, err = useCase.ExplainPermissions("alice", "", []string{"profile:read", "roles:write"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// An allowed permission names the assigned role and the ancestor that holds it
+	if grants := decisions[0].Grants; len(grants) != 1 ||
+		grants[0].Role != "admin" || !slices.Equal(grants[0].Path, []string{"admin", "user"}) ||
+		grants[0].Pattern != "profile:read" || !grants[0].Assigned {
+		t.Errorf("unexpected grants for profile:read: %+v", grants)
+	}
+
+	// A denied permission names the roles that would grant it
+	if grants := decisions[1].Grants; len(grants) != 1 ||
+		grants[0].Role != "super_admin" || grants[0].Pattern != "*:*" || grants[0].Assigned {
+		t.Errorf("unexpected grants for roles:write: %+v", grants)
+	}
+
+	// Inside the organization, tenant-scoped roles grant and are reported with the organization
+	decisions, _ = useCase.ExplainPermissions("alice", "org-a", []string{"orgs:members:write"})
+	if grants := decisions[0].Grants; !decisions[0].Allowed || len(grants) != 1 ||
+		grants[0].Role != RoleOrgAdmin || grants[0].OrganizationID != "org-a" {
+		t.Errorf("unexpected tenant decision: %+v", decisions[0])
+	}
+}
+
+func TestRBACService_DenyRules(t *testing.T) {
+	repo := NewMockRBACRepository()
+	user := repo.addRole("user")
+	admin := repo.addRole("admin")
+	auditor := repo.addRole("auditor")
+	profileAll := repo.addPermission("profile:*", "profile", "*", true)
+	usersAll := repo.addPermission("users:*", "users", "*", false)
+	usersDelete := repo.addPermission("users:delete", "users", "delete", false)
+	writeAll := repo.addPermission("*:write", "*", "write", false)
+	_ = repo.AssignPermissionToRole(user.ID, profileAll.ID)
+	_ = repo.AssignPermissionToRole(admin.ID, usersAll.ID)
+	_ = repo.SetRoleParent(admin.ID, user.ID)
+	useCase := NewRBACUseCase(repo)
+
+	_ = useCase.AssignRoleToUser("alice", admin.ID, RoleValidity{})
+	_ = useCase.AssignRoleToUser("bob", admin.ID, RoleValidity{})
+	_ = useCase.AssignRoleToUser("carol", admin.ID, RoleValidity{})
+	_ = useCase.AssignRoleToUser("carol", auditor.ID, RoleValidity{})
+
+	// A role deny overrides the role's own wildcard grant for every holder
+	assertErrorCode(t, useCase.AddDenyToRole(admin.ID, "missing"), enum.ResourceNotFound)
+	assertErrorCode(t, useCase.AddDenyToRole(admin.ID, usersDelete.ID), enum.Success)
+	if allowed, _ := useCase.CheckUserPermission("alice", "users:delete"); allowed {
+		t.Error("expected the admin deny to override users:*")
+	}
+	if allowed, _ := useCase.CheckUserPermission("alice", "users:delete", "users:read"); !allowed {
+		t.Error("expected any-of checks to pass on a permission that is not denied")
+	}
+
+	// A per-user override applies to that user only, whatever roles they hold
+	assertErrorCode(t, useCase.AddDenyToUser("bob", writeAll.ID), enum.Success)
+	if allowed, _ := useCase.CheckUserPermission("bob", "profile:write"); allowed {
+		t.Error("expected bob's override to deny profile:write")
+	}
+	if allowed, _ := useCase.CheckUserPermission("alice", "profile:write"); !allowed {
+		t.Error("expected alice to keep profile:write")
+	}
+
+	// A deny attached to any held role applies even when another role grants the permission
+	_ = useCase.AddDenyToRole(auditor.ID, usersAll.ID)
+	if allowed, _ := useCase.CheckUserPermission("carol", "users:read"); allowed {
+		t.Error("expected the auditor deny to override the admin grant")
+	}
+
+	// Explaining names the deny rule that fired and the grants it overrides
+	decisions, err := useCase.ExplainPermissions("bob", "", []string{"users:delete", "profile:write", "users:read"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d := decisions[0]; d.Allowed || d.Deny == nil || d.Deny.Role != "admin" || d.Deny.Pattern != "users:delete" ||
+		len(d.Grants) != 1 || d.Grants[0].Role != "admin" || !d.Grants[0].Assigned {
+		t.Errorf("unexpected decision for users:delete: %+v", d)
+	}
+	if d := decisions[1]; d.Allowed || d.Deny == nil || d.Deny.Role != "" || d.Deny.Pattern != "*:write" {
+		t.Errorf("unexpected decision for profile:write: %+v", d)
+	}
+	if d := decisions[2]; !d.Allowed || d.Deny != nil || d.MatchedBy != "users:*" {
+		t.Errorf("unexpected decision for users:read: %+v", d)
+	}
+
+	// Tokens carry the compacted deny patterns next to the permissions
+	_, permissions, denies, _ := useCase.TokenAuthorization("bob", "")
+	if !slices.Equal(permissions, []string{"profile:*", "users:*"}) || !slices.Equal(denies, []string{"*:write", "users:delete"}) {
+		t.Errorf("unexpected token authorization: %v, %v", permissions, denies)
+	}
+
+	// Removing the deny restores the grant
+	_ = useCase.RemoveDenyFromRole(admin.ID, usersDelete.ID)
+	if allowed, _ := useCase.CheckUserPermission("alice", "users:delete"); !allowed {
+		t.Error("expected users:delete after removing the deny")
+	}
+}
+
+func TestRBACService_SeparationOfDuty(t *testing.T) {
+	repo := NewMockRBACRepository()
+	creator := repo.addRole("payments_creator")
+	approver := repo.addRole("payments_approver")
+	seniorApprover := repo.addRole("senior_approver")
+	auditor := repo.addRole("auditor")
+	_ = repo.SetRoleParent(seniorApprover.ID, approver.ID)
+	useCase := NewRBACUseCase(repo)
+
+	_ = useCase.AssignRoleToUser("alice", creator.ID, RoleValidity{})
+	_ = useCase.AssignRoleToUser("bob", approver.ID, RoleValidity{})
+	_ = useCase.AssignRoleToUser("bob", creator.ID, RoleValidity{}) // held before the constraint
+
+	_, err := useCase.CreateRoleConflictSet("payments", "", []string{creator.ID, "missing"})
+	assertErrorCode(t, err, enum.ResourceNotFound)
+	set, err := useCase.CreateRoleConflictSet("payments", "Create or approve, never both", []string{creator.ID, approver.ID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = useCase.CreateRoleConflictSet("payments", "", []string{creator.ID, auditor.ID})
+	assertErrorCode(t, err, enum.Conflict)
+
+	tests := []struct {
+		name   string
+		userID string
+		roleID string
+		want   enum.ErrorCode
+	}{
+		{"conflicting role", "alice", approver.ID, enum.SeparationOfDutyViolation},
+		{"role inheriting a conflicting role", "alice", seniorApprover.ID, enum.SeparationOfDutyViolation},
+		{"unrelated role", "alice", auditor.ID, enum.Success},
+		{"re-assigning a held role", "alice", creator.ID, enum.Success},
+		{"existing violation does not block unrelated roles", "bob", auditor.ID, enum.Success},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertErrorCode(t, useCase.AssignRoleToUser(tt.userID, tt.roleID, RoleValidity{}), tt.want)
+		})
+	}
+
+	// Groups cannot be used to combine the roles either
+	group, _ := useCase.CreateGroup("approvers", "")
+	_ = useCase.AssignRoleToGroup(group.ID, approver.ID)
+	assertErrorCode(t, useCase.AddUserToGroup(group.ID, "alice"), enum.SeparationOfDutyViolation)
+	_ = useCase.AddUserToGroup(group.ID, "carol")
+	assertErrorCode(t, useCase.AssignRoleToGroup(group.ID, creator.ID), enum.SeparationOfDutyViolation)
+
+	violations, _ := useCase.GetRoleConflictViolations()
+	if len(violations) != 1 || violations[0].UserID != "bob" || violations[0].ConflictSet != "payments" ||
+		!slices.Equal(violations[0].Roles, []string{"payments_approver", "payments_creator"}) {
+		t.Errorf("unexpected violations: %+v", violations)
+	}
+
+	// Without the constraint the assignment goes through
+	_ = useCase.DeleteRoleConflictSet(set.ID)
+	assertErrorCode(t, useCase.AssignRoleToUser("alice", approver.ID, RoleValidity{}), enum.Success)
+}
+
+func TestRBACService_DelegatedAdministration(t *testing.T) {
+	repo := NewMockRBACRepository()
+	teamLead := repo.addRole("team_lead")
+	seniorLead := repo.addRole("senior_lead")
+	support := repo.addRole("support")
+	supportPlus := repo.addRole("support_plus")
+	billing := repo.addRole("billing")
+	billingViewer := repo.addRole("billing_viewer")
+	_ = repo.SetRoleParent(seniorLead.ID, teamLead.ID)
+	_ = repo.SetRoleParent(supportPlus.ID, support.ID)
+	_ = repo.SetRoleParent(billingViewer.ID, billing.ID)
+
+	ticketsAll := repo.addPermission("tickets:*", "tickets", "*", false)
+	ticketsRead := repo.addPermission("tickets:read", "tickets", "read", false)
+	ticketsWrite := repo.addPermission("tickets:write", "tickets", "write", false)
+	usersDelete := repo.addPermission("users:delete", "users", "delete", false)
+	invoicesRead := repo.addPermission("invoices:read", "invoices", "read", false)
+	_ = repo.AssignPermissionToRole(teamLead.ID, ticketsAll.ID)
+	_ = repo.AssignPermissionToRole(support.ID, ticketsRead.ID)
+	_ = repo.AssignPermissionToRole(support.ID, ticketsWrite.ID)
+	_ = repo.AssignPermissionToRole(supportPlus.ID, usersDelete.ID)
+	_ = repo.AssignPermissionToRole(billing.ID, invoicesRead.ID)
+
+	_ = repo.AssignRoleToUser("lead", teamLead.ID, RoleValidity{})
+	_ = repo.AssignRoleToUser("senior", seniorLead.ID, RoleValidity{})
+	useCase := NewRBACUseCase(repo)
+
+	_, err := useCase.SetRoleManagers(support.ID, []string{"missing"})
+	assertErrorCode(t, err, enum.ResourceNotFound)
+	for _, role := range []*Role{support, supportPlus, billing, billingViewer} {
+		if _, err := useCase.SetRoleManagers(role.ID, []string{teamLead.ID}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// Managers inherit the right to manage from their ancestors
+	for _, managerID := range []string{"lead", "senior"} {
+		roles, _ := useCase.GetManageableRoles(managerID)
+		if len(roles) != 4 || roles[0].Name != "billing" || roles[3].Name != "support_plus" {
+			t.Errorf("unexpected manageable roles for %s: %v", managerID, roles)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		managerID string
+		roleID    string
+		want      enum.ErrorCode
+	}{
+		{"managed role within the manager's permissions", "lead", support.ID, enum.Success},
+		{"manager by inheritance", "senior", support.ID, enum.Success},
+		{"role granting a permission the manager lacks", "lead", billing.ID, enum.PrivilegeEscalation},
+		{"role adding a permission the manager lacks", "lead", supportPlus.ID, enum.PrivilegeEscalation},
+		{"role inheriting a permission the manager lacks", "lead", billingViewer.ID, enum.PrivilegeEscalation},
+		{"unmanaged role", "lead", teamLead.ID, enum.RoleNotManageable},
+		{"user holding no manager role", "bob", support.ID, enum.RoleNotManageable},
+		{"unknown role", "lead", "missing", enum.ResourceNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertErrorCode(t, useCase.AssignManagedRole(tt.managerID, "bob", tt.roleID, RoleValidity{}), tt.want)
+		})
+	}
+
+	// A permission denied to the manager cannot be handed out either
+	_ = repo.AddDenyToUser("lead", ticketsWrite.ID)
+	assertErrorCode(t, useCase.AssignManagedRole("lead", "carol", support.ID, RoleValidity{}), enum.PrivilegeEscalation)
+
+	assertErrorCode(t, useCase.RemoveManagedRole("bob", "bob", support.ID), enum.RoleNotManageable)
+	assertErrorCode(t, useCase.RemoveManagedRole("lead", "bob", support.ID), enum.Success)
+	if roles, _ := repo.GetUserRoles("bob"); len(roles) != 0 {
+		t.Errorf("expected bob to hold no roles, got %v", roles)
+	}
+}
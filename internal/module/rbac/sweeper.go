@@ -0,0 +1,49 @@
+package rbac
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// ExpirySweeper periodically deletes role assignments past their valid_until. Expired grants
+// are already ignored by every lookup; sweeping keeps user_roles small and drops stale caches.
+type ExpirySweeper struct {
+	useCase  RBACUseCase
+	interval time.Duration
+}
+
+// NewExpirySweeper creates a sweeper that runs every interval
+func NewExpirySweeper(useCase RBACUseCase, interval time.Duration) *ExpirySweeper {
+	return &ExpirySweeper{
+		useCase:  useCase,
+		interval: interval,
+	}
+}
+
+// Run sweeps immediately and then on every tick until ctx is cancelled
+func (s *ExpirySweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.sweep()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *ExpirySweeper) sweep() {
+	users, err := s.useCase.WithActor(SystemActor("expiry-sweeper")).SweepExpiredRoles()
+	if err != nil {
+		log.Printf("rbac: sweeping expired role assignments failed: %v", err)
+		return
+	}
+	if users > 0 {
+		log.Printf("rbac: removed expired role assignments from %d user(s)", users)
+	}
+}
@@ -0,0 +1,60 @@
+package users
+
+import (
+	"boilerplate-be/internal/module/auth"
+	"boilerplate-be/internal/module/rbac"
+)
+
+// UserRepository defines the data access layer for administering accounts
+type UserRepository interface {
+	// List returns one page of the users matching the filter and how many match in total
+	List(filter UserFilter) ([]auth.User, int64, error)
+	GetByID(id string) (*auth.User, error)
+	Create(user *auth.User) error
+	// Update stores the name, username, email and password hash
+	Update(user *auth.User) error
+	// Delete removes the account; its roles, memberships and sessions go with it
+	Delete(id string) error
+
+	// WithActor returns a repository whose changes to role assignments are recorded as made by the actor
+	WithActor(actor rbac.ChangeActor) UserRepository
+}
+
+// UserUseCase defines the business logic for administering accounts. Changing or deleting an
+// account requires holding every permission it holds, so admins cannot take over accounts more
+// privileged than their own.
+type UserUseCase interface {
+	ListUsers(filter UserFilter) ([]auth.User, int64, error)
+	GetUser(id string) (*UserDetail, error)
+	// CreateUser creates the account with the default role, like sign-up does; the account is
+	// removed again if the role cannot be granted
+	CreateUser(input UserInput) (*auth.User, error)
+	UpdateUser(actorID, id string, input UserInput) (*auth.User, error)
+	DeleteUser(actorID, id string) error
+
+	// Bulk operations apply to each user separately and report per-user failures
+	BulkDelete(actorID string, ids []string) (*BulkResult, error)
+	// BulkAssignRole and BulkRemoveRole follow delegated administration: the actor must
+	// manage the role, see rbac.RBACUseCase.AssignManagedRole
+	BulkAssignRole(actorID, roleID string, ids []string, validity rbac.RoleValidity) (*BulkResult, error)
+	BulkRemoveRole(actorID, roleID string, ids []string) (*BulkResult, error)
+
+	// WithActor returns a use case whose changes to role assignments are recorded as made by the actor
+	WithActor(actor rbac.ChangeActor) UserUseCase
+}
+
+// RoleManager reads and changes users' global roles and permissions, e.g. rbac.RBACUseCase
+type RoleManager interface {
+	GetUserRoles(userID string) ([]rbac.Role, error)
+	GetUserPermissions(userID string) ([]rbac.Permission, error)
+	GetUserDenyRules(userID, organizationID string) ([]rbac.DenyRule, error)
+	AssignRolesByName(userID string, roleNames []string) error
+	AssignManagedRole(managerID, userID, roleID string, validity rbac.RoleValidity) error
+	RemoveManagedRole(managerID, userID, roleID string) error
+	WithActor(actor rbac.ChangeActor) rbac.RBACUseCase
+}
+
+// SessionRevoker ends every session of a user, e.g. security.TokenManager
+type SessionRevoker interface {
+	RevokeAllUserTokens(userID string) error
+}
@@ -0,0 +1,61 @@
+package users
+
+import (
+	"time"
+
+	"boilerplate-be/internal/module/auth"
+	"boilerplate-be/internal/module/rbac"
+	"boilerplate-be/internal/shared/errors"
+)
+
+// Fields ListUsers can sort by
+const (
+	SortByName      = "name"
+	SortByUsername  = "username"
+	SortByEmail     = "email"
+	SortByCreatedAt = "created_at"
+	SortByUpdatedAt = "updated_at"
+)
+
+// UserFilter selects, orders and pages the users returned by ListUsers; zero values do not filter
+type UserFilter struct {
+	// Search matches part of the name, username or email, ignoring case
+	Search string
+	// Role is the name of a global role held directly or through a group
+	Role          string
+	PhoneVerified *bool
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	// SortBy is one of the SortBy constants; users are sorted by creation time when it is empty
+	SortBy     string
+	Descending bool
+	Page       int
+	PageSize   int
+}
+
+// UserInput holds what an administrator sets on an account. On update an empty username or
+// password leaves the stored one unchanged.
+type UserInput struct {
+	Name     string
+	Username string
+	Email    string
+	Password string
+}
+
+// UserDetail is an account with the global roles it holds
+type UserDetail struct {
+	User  auth.User
+	Roles []rbac.Role
+}
+
+// BulkResult reports the users a bulk operation changed and why it failed for the others
+type BulkResult struct {
+	Succeeded []string
+	Failed    []BulkFailure
+}
+
+// BulkFailure is the error a bulk operation met for one user
+type BulkFailure struct {
+	UserID string
+	Error  errors.AppError
+}
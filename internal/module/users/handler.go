@@ -0,0 +1,346 @@
+package users
+
+import (
+	"time"
+
+	"boilerplate-be/internal/module/auth"
+	"boilerplate-be/internal/module/rbac"
+	"boilerplate-be/internal/shared/errors"
+	"boilerplate-be/internal/shared/response"
+	"boilerplate-be/internal/shared/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type UserHandler struct {
+	userUseCase UserUseCase
+}
+
+// NewUserHandler creates a new user administration handler
+func NewUserHandler(userUseCase UserUseCase) *UserHandler {
+	return &UserHandler{
+		userUseCase: userUseCase,
+	}
+}
+
+// useCase records the role assignment changes made through it as made by the caller, in the
+// current request
+func (h *UserHandler) useCase(c *fiber.Ctx) UserUseCase {
+	return h.userUseCase.WithActor(rbac.RequestActor(c))
+}
+
+// ==================== User Endpoints ====================
+
+// ListUsers godoc
+// @Summary      List users
+// @Description  Returns a page of users matching the filters; role matches global roles held directly or through a group (requires users:read)
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page            query     int     false  "Page number"  default(1)
+// @Param        page_size       query     int     false  "Page size"    default(20)
+// @Param        search          query     string  false  "Part of the name, username or email"
+// @Param        role            query     string  false  "Role name"
+// @Param        phone_verified  query     bool    false  "Only users with, or without, a verified phone"
+// @Param        created_after   query     string  false  "Created on or after (YYYY-MM-DD)"
+// @Param        created_before  query     string  false  "Created before (YYYY-MM-DD)"
+// @Param        sort_by         query     string  false  "Sort field"  Enums(name, username, email, created_at, updated_at)  default(created_at)
+// @Param        sort_order      query     string  false  "Sort order"  Enums(asc, desc)  default(desc)
+// @Success      200             {object}  docs.PaginatedResponse{data=[]docs.UserResponse}
+// @Failure      400             {object}  docs.ErrorResponse
+// @Failure      401             {object}  docs.ErrorResponse
+// @Failure      403             {object}  docs.ErrorResponse
+// @Router       /users [get]
+func (h *UserHandler) ListUsers(c *fiber.Ctx) error {
+	var req ListUsersRequest
+	if err := c.QueryParser(&req); err != nil {
+		appErr := errors.New(errors.InvalidRequest)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		validationErrors := validator.FormatValidationErrorForResponseBilingual(err)
+		appErr := errors.NewWithDetails(errors.ValidationFailed, validationErrors)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	filter := UserFilter{
+		Search:     req.Search,
+		Role:       req.Role,
+		SortBy:     req.SortBy,
+		Descending: req.SortOrder != "asc",
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+	}
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize == 0 {
+		filter.PageSize = 20
+	}
+	if req.PhoneVerified != "" {
+		verified := req.PhoneVerified == "true"
+		filter.PhoneVerified = &verified
+	}
+	// Validated as dates above
+	if req.CreatedAfter != "" {
+		after, _ := time.Parse(time.DateOnly, req.CreatedAfter)
+		filter.CreatedAfter = &after
+	}
+	if req.CreatedBefore != "" {
+		before, _ := time.Parse(time.DateOnly, req.CreatedBefore)
+		filter.CreatedBefore = &before
+	}
+
+	users, total, err := h.userUseCase.ListUsers(filter)
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(response.CreateErrorResponse(c, errors.New(errors.InternalServerError)))
+	}
+
+	meta := response.NewMetaResponse(int64(filter.Page), int64(filter.PageSize), total)
+
+	return c.JSON(response.CreatePaginatedResponse(
+		c, "Daftar user berhasil diambil", "Users retrieved successfully", ToUserResponses(users), meta,
+	))
+}
+
+// GetUser godoc
+// @Summary      Get user details
+// @Description  Returns a user with the global roles they hold (requires users:read)
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "User ID"
+// @Success      200  {object}  docs.SuccessResponse{data=docs.UserDetailResponse}
+// @Failure      401  {object}  docs.ErrorResponse
+// @Failure      403  {object}  docs.ErrorResponse
+// @Failure      404  {object}  docs.ErrorResponse
+// @Router       /users/{id} [get]
+func (h *UserHandler) GetUser(c *fiber.Ctx) error {
+	detail, err := h.userUseCase.GetUser(c.Params("id"))
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(response.CreateErrorResponse(c, errors.New(errors.InternalServerError)))
+	}
+
+	return c.JSON(response.CreateSuccessResponse(
+		c, "Detail user berhasil diambil", "User retrieved successfully", ToUserDetailResponse(detail),
+	))
+}
+
+// CreateUser godoc
+// @Summary      Create a user
+// @Description  Creates an account holding the default role (requires users:write)
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      docs.CreateUserRequest  true  "User data"
+// @Success      201   {object}  docs.SuccessResponse{data=docs.UserResponse}
+// @Failure      400   {object}  docs.ErrorResponse
+// @Failure      401   {object}  docs.ErrorResponse
+// @Failure      403   {object}  docs.ErrorResponse
+// @Failure      409   {object}  docs.ErrorResponse
+// @Failure      422   {object}  docs.ErrorResponse
+// @Router       /users [post]
+func (h *UserHandler) CreateUser(c *fiber.Ctx) error {
+	var req CreateUserRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.CreateErrorResponse(c, errors.New(errors.InvalidRequestBody)))
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		validationErrors := validator.FormatValidationErrorForResponseBilingual(err)
+		appErr := errors.NewWithDetails(errors.ValidationFailed, validationErrors)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	user, err := h.useCase(c).CreateUser(UserInput{
+		Name:     req.Name,
+		Username: req.Username,
+		Email:    req.Email,
+		Password: req.Password,
+	})
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(response.CreateErrorResponse(c, errors.New(errors.InternalServerError)))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.CreateSuccessResponse(
+		c, "User berhasil dibuat", "User created successfully", auth.ToUserResponse(user), fiber.StatusCreated,
+	))
+}
+
+// UpdateUser godoc
+// @Summary      Update a user
+// @Description  Updates an account; setting a password ends the user's sessions. Fails with PRIVILEGE_ESCALATION for users holding a permission the caller lacks (requires users:write)
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      string                  true  "User ID"
+// @Param        body  body      docs.UpdateUserRequest  true  "User data"
+// @Success      200   {object}  docs.SuccessResponse{data=docs.UserResponse}
+// @Failure      400   {object}  docs.ErrorResponse
+// @Failure      401   {object}  docs.ErrorResponse
+// @Failure      403   {object}  docs.ErrorResponse
+// @Failure      404   {object}  docs.ErrorResponse
+// @Failure      409   {object}  docs.ErrorResponse
+// @Failure      422   {object}  docs.ErrorResponse
+// @Router       /users/{id} [put]
+func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
+	actorID := c.Locals("user_id").(string)
+
+	var req UpdateUserRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.CreateErrorResponse(c, errors.New(errors.InvalidRequestBody)))
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		validationErrors := validator.FormatValidationErrorForResponseBilingual(err)
+		appErr := errors.NewWithDetails(errors.ValidationFailed, validationErrors)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	user, err := h.useCase(c).UpdateUser(actorID, c.Params("id"), UserInput{
+		Name:     req.Name,
+		Username: req.Username,
+		Email:    req.Email,
+		Password: req.Password,
+	})
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(response.CreateErrorResponse(c, errors.New(errors.InternalServerError)))
+	}
+
+	return c.JSON(response.CreateSuccessResponse(
+		c, "User berhasil diperbarui", "User updated successfully", auth.ToUserResponse(user),
+	))
+}
+
+// DeleteUser godoc
+// @Summary      Delete a user
+// @Description  Deletes an account with its role assignments and memberships and ends its sessions; callers cannot delete themselves or users holding a permission they lack (requires users:delete)
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "User ID"
+// @Success      200  {object}  docs.SuccessResponse
+// @Failure      401  {object}  docs.ErrorResponse
+// @Failure      403  {object}  docs.ErrorResponse
+// @Failure      404  {object}  docs.ErrorResponse
+// @Router       /users/{id} [delete]
+func (h *UserHandler) DeleteUser(c *fiber.Ctx) error {
+	actorID := c.Locals("user_id").(string)
+
+	if err := h.useCase(c).DeleteUser(actorID, c.Params("id")); err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(response.CreateErrorResponse(c, errors.New(errors.InternalServerError)))
+	}
+
+	return c.JSON(response.CreateSuccessResponse(
+		c, "User berhasil dihapus", "User deleted successfully", nil,
+	))
+}
+
+// ==================== Bulk Endpoints ====================
+
+// BulkDeleteUsers godoc
+// @Summary      Delete several users
+// @Description  Deletes each user as DELETE /users/{id} would and reports the users it failed for (requires users:delete)
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      docs.BulkDeleteRequest  true  "User IDs"
+// @Success      200   {object}  docs.SuccessResponse{data=docs.BulkResultResponse}
+// @Failure      400   {object}  docs.ErrorResponse
+// @Failure      401   {object}  docs.ErrorResponse
+// @Failure      403   {object}  docs.ErrorResponse
+// @Router       /users/bulk-delete [post]
+func (h *UserHandler) BulkDeleteUsers(c *fiber.Ctx) error {
+	actorID := c.Locals("user_id").(string)
+
+	var req BulkDeleteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.CreateErrorResponse(c, errors.New(errors.InvalidRequestBody)))
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		validationErrors := validator.FormatValidationErrorForResponseBilingual(err)
+		appErr := errors.NewWithDetails(errors.ValidationFailed, validationErrors)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	result, err := h.useCase(c).BulkDelete(actorID, req.UserIDs)
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(response.CreateErrorResponse(c, errors.New(errors.InternalServerError)))
+	}
+
+	return c.JSON(response.CreateSuccessResponse(
+		c, "Penghapusan user selesai diproses", "Bulk user deletion processed", ToBulkResultResponse(result),
+	))
+}
+
+// BulkUserRoles godoc
+// @Summary      Assign or remove a role for several users
+// @Description  Assigns the role to, or removes it from, each user through delegated administration, so the caller must manage the role; reports the users it failed for (requires users:write)
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      docs.BulkRoleRequest  true  "Action, role and user IDs"
+// @Success      200   {object}  docs.SuccessResponse{data=docs.BulkResultResponse}
+// @Failure      400   {object}  docs.ErrorResponse
+// @Failure      401   {object}  docs.ErrorResponse
+// @Failure      403   {object}  docs.ErrorResponse
+// @Router       /users/bulk-roles [post]
+func (h *UserHandler) BulkUserRoles(c *fiber.Ctx) error {
+	actorID := c.Locals("user_id").(string)
+
+	var req BulkRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.CreateErrorResponse(c, errors.New(errors.InvalidRequestBody)))
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		validationErrors := validator.FormatValidationErrorForResponseBilingual(err)
+		appErr := errors.NewWithDetails(errors.ValidationFailed, validationErrors)
+		return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+	}
+
+	var result *BulkResult
+	var err error
+	if req.Action == "assign" {
+		result, err = h.useCase(c).BulkAssignRole(actorID, req.RoleID, req.UserIDs, rbac.RoleValidity{Until: req.ValidUntil})
+	} else {
+		result, err = h.useCase(c).BulkRemoveRole(actorID, req.RoleID, req.UserIDs)
+	}
+	if err != nil {
+		if appErr, ok := errors.IsAppError(err); ok {
+			return c.Status(appErr.StatusCode).JSON(response.CreateErrorResponse(c, appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(response.CreateErrorResponse(c, errors.New(errors.InternalServerError)))
+	}
+
+	return c.JSON(response.CreateSuccessResponse(
+		c, "Perubahan role user selesai diproses", "Bulk role change processed", ToBulkResultResponse(result),
+	))
+}
@@ -0,0 +1,12 @@
+package users
+
+import "boilerplate-be/internal/module/rbac"
+
+// RegisterPermissions declares the permissions guarding user administration
+func RegisterPermissions(registry *rbac.PermissionRegistry) {
+	registry.Declare("users",
+		rbac.PermissionSpec{Name: "users:read", Description: "View user list and details"},
+		rbac.PermissionSpec{Name: "users:write", Description: "Create and update users"},
+		rbac.PermissionSpec{Name: "users:delete", Description: "Delete users"},
+	)
+}
@@ -0,0 +1,243 @@
+package users
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"boilerplate-be/internal/database"
+	"boilerplate-be/internal/module/auth"
+	"boilerplate-be/internal/module/rbac"
+	"boilerplate-be/internal/shared/errors"
+	"boilerplate-be/internal/shared/security"
+	"boilerplate-be/internal/shared/utils"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type userRepository struct {
+	db          *sql.DB
+	txManager   *database.TxManager
+	cacheHelper *utils.CacheHelper
+	versions    *security.PermissionVersions
+	// actor is recorded with every change to role assignments; zero when unknown
+	actor rbac.ChangeActor
+}
+
+// NewUserRepository creates a new user repository; deleting a user bumps their permission
+// version so tokens embedding their roles stop working at once
+func NewUserRepository(db *sql.DB, cacheHelper *utils.CacheHelper, versions *security.PermissionVersions) UserRepository {
+	return &userRepository{
+		db:          db,
+		txManager:   database.NewTxManager(db),
+		cacheHelper: cacheHelper,
+		versions:    versions,
+	}
+}
+
+// WithActor returns a copy of the repository recording the role assignments its deletes
+// cascade to as removed by the actor
+func (r *userRepository) WithActor(actor rbac.ChangeActor) UserRepository {
+	copied := *r
+	copied.actor = actor
+	return &copied
+}
+
+// sortColumns maps the sortable fields to their columns
+var sortColumns = map[string]string{
+	SortByName:      "u.name",
+	SortByUsername:  "u.username",
+	SortByEmail:     "u.email",
+	SortByCreatedAt: "u.created_at",
+	SortByUpdatedAt: "u.updated_at",
+}
+
+// likeEscaper escapes the LIKE wildcards in a search term
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (r *userRepository) List(filter UserFilter) ([]auth.User, int64, error) {
+	var conditions []string
+	var args []interface{}
+	param := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Search != "" {
+		term := param("%" + likeEscaper.Replace(filter.Search) + "%")
+		conditions = append(conditions, fmt.Sprintf("(u.name ILIKE %[1]s OR u.username ILIKE %[1]s OR u.email ILIKE %[1]s)", term))
+	}
+	if filter.Role != "" {
+		role := param(filter.Role)
+		conditions = append(conditions, `u.id IN (
+			SELECT ur.user_id FROM user_roles ur
+			INNER JOIN roles r ON r.id = ur.role_id
+			WHERE r.name = `+role+` AND ur.organization_id IS NULL
+				AND (ur.valid_from IS NULL OR ur.valid_from <= NOW())
+				AND (ur.valid_until IS NULL OR ur.valid_until > NOW())
+			UNION
+			SELECT gm.user_id FROM group_members gm
+			INNER JOIN group_roles gr ON gr.group_id = gm.group_id
+			INNER JOIN roles r ON r.id = gr.role_id
+			WHERE r.name = `+role+`
+		)`)
+	}
+	if filter.PhoneVerified != nil {
+		if *filter.PhoneVerified {
+			conditions = append(conditions, "u.phone_verified_at IS NOT NULL")
+		} else {
+			conditions = append(conditions, "u.phone_verified_at IS NULL")
+		}
+	}
+	if filter.CreatedAfter != nil {
+		conditions = append(conditions, "u.created_at >= "+param(*filter.CreatedAfter))
+	}
+	if filter.CreatedBefore != nil {
+		conditions = append(conditions, "u.created_at < "+param(*filter.CreatedBefore))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM users u `+where, args...).Scan(&total); err != nil {
+		return nil, 0, errors.Wrap(err, errors.DatabaseQueryFailed)
+	}
+
+	column, ok := sortColumns[filter.SortBy]
+	if !ok {
+		column = sortColumns[SortByCreatedAt]
+	}
+	direction := "ASC"
+	if filter.Descending {
+		direction = "DESC"
+	}
+
+	// The ID breaks ties so pages neither repeat nor skip users
+	query := fmt.Sprintf(
+		`SELECT %s FROM users u %s ORDER BY %s %s NULLS LAST, u.id %s LIMIT %s OFFSET %s`,
+		auth.UserColumns, where, column, direction, direction,
+		param(filter.PageSize), param((filter.Page-1)*filter.PageSize),
+	)
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, errors.DatabaseQueryFailed)
+	}
+	defer rows.Close()
+
+	users := []auth.User{}
+	for rows.Next() {
+		user, err := auth.ScanUser(rows)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, errors.DatabaseScanFailed)
+		}
+		users = append(users, user)
+	}
+
+	return users, total, nil
+}
+
+func (r *userRepository) GetByID(id string) (*auth.User, error) {
+	query := `SELECT ` + auth.UserColumns + ` FROM users u WHERE u.id = $1`
+	user, err := auth.ScanUser(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New(errors.AccountNotFound)
+		}
+		return nil, errors.Wrap(err, errors.DatabaseQueryFailed)
+	}
+	return &user, nil
+}
+
+func (r *userRepository) Create(user *auth.User) error {
+	id, _ := uuid.NewV7()
+	user.ID = id.String()
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+
+	query := `
+		INSERT INTO users (id, name, username, email, password, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7)
+	`
+	_, err := r.db.Exec(query, user.ID, user.Name, user.Username, user.Email, user.Password, user.CreatedAt, user.UpdatedAt)
+	if err != nil {
+		if uniqueErr := uniqueViolation(err); uniqueErr != nil {
+			return uniqueErr
+		}
+		return errors.Wrap(err, errors.DatabaseInsertFailed)
+	}
+
+	return nil
+}
+
+func (r *userRepository) Update(user *auth.User) error {
+	user.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE users
+		SET name = $2, username = NULLIF($3, ''), email = $4, password = $5, updated_at = $6
+		WHERE id = $1
+	`
+	result, err := r.db.Exec(query, user.ID, user.Name, user.Username, user.Email, user.Password, user.UpdatedAt)
+	if err != nil {
+		if uniqueErr := uniqueViolation(err); uniqueErr != nil {
+			return uniqueErr
+		}
+		return errors.Wrap(err, errors.DatabaseUpdateFailed)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return errors.New(errors.AccountNotFound)
+	}
+
+	if err := r.cacheHelper.InvalidateUserCache(context.Background(), user.ID); err != nil {
+		return errors.Wrap(err, errors.CacheError)
+	}
+	return nil
+}
+
+func (r *userRepository) Delete(id string) error {
+	err := r.txManager.WithTransaction(context.Background(), func(ctx context.Context) error {
+		exec := database.GetExecutor(ctx, r.db)
+		if err := r.actor.Apply(ctx, exec); err != nil {
+			return err
+		}
+
+		// Roles, group and organization memberships, denies and login history cascade
+		result, err := exec.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, id)
+		if err != nil {
+			return errors.Wrap(err, errors.DatabaseDeleteFailed)
+		}
+
+		rowsAffected, _ := result.RowsAffected()
+		if rowsAffected == 0 {
+			return errors.New(errors.AccountNotFound)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_ = r.versions.Bump(id)
+	_ = r.cacheHelper.InvalidateUserCache(context.Background(), id)
+	return nil
+}
+
+// uniqueViolation maps a duplicate username or email to its error, or returns nil
+func uniqueViolation(err error) error {
+	pqErr, ok := err.(*pq.Error)
+	if !ok || pqErr.Code.Name() != "unique_violation" {
+		return nil
+	}
+	if pqErr.Constraint == auth.UsernameUniqueIndex {
+		return errors.New(errors.UsernameExists)
+	}
+	return errors.New(errors.EmailExists)
+}
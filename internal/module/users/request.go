@@ -0,0 +1,47 @@
+package users
+
+import "time"
+
+// ListUsersRequest holds the query parameters of the user listing; dates are YYYY-MM-DD
+type ListUsersRequest struct {
+	Page          int    `query:"page" validate:"omitempty,min=1"`
+	PageSize      int    `query:"page_size" validate:"omitempty,min=1,max=100"`
+	Search        string `query:"search" validate:"max=100"`
+	Role          string `query:"role" validate:"max=100"`
+	PhoneVerified string `query:"phone_verified" validate:"omitempty,oneof=true false"`
+	CreatedAfter  string `query:"created_after" validate:"omitempty,datetime=2006-01-02"`
+	CreatedBefore string `query:"created_before" validate:"omitempty,datetime=2006-01-02"`
+	SortBy        string `query:"sort_by" validate:"omitempty,oneof=name username email created_at updated_at"`
+	SortOrder     string `query:"sort_order" validate:"omitempty,oneof=asc desc"`
+}
+
+// CreateUserRequest is the request body for creating an account
+type CreateUserRequest struct {
+	Name     string `json:"name" validate:"required,min=2,max=100"`
+	Username string `json:"username" validate:"omitempty,username"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,min=6,max=100"`
+}
+
+// UpdateUserRequest is the request body for updating an account; an empty username or password
+// is left unchanged
+type UpdateUserRequest struct {
+	Name     string `json:"name" validate:"required,min=2,max=100"`
+	Username string `json:"username" validate:"omitempty,username"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"omitempty,min=6,max=100"`
+}
+
+// BulkDeleteRequest is the request body for deleting several accounts
+type BulkDeleteRequest struct {
+	UserIDs []string `json:"user_ids" validate:"required,min=1,max=100,unique,dive,uuid"`
+}
+
+// BulkRoleRequest is the request body for assigning a role to, or removing it from, several
+// users; valid_until only applies to assignments
+type BulkRoleRequest struct {
+	Action     string     `json:"action" validate:"required,oneof=assign remove"`
+	RoleID     string     `json:"role_id" validate:"required,uuid"`
+	UserIDs    []string   `json:"user_ids" validate:"required,min=1,max=100,unique,dive,uuid"`
+	ValidUntil *time.Time `json:"valid_until"`
+}
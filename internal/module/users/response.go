@@ -0,0 +1,58 @@
+package users
+
+import (
+	"boilerplate-be/internal/module/auth"
+	"boilerplate-be/internal/module/rbac"
+)
+
+// UserDetailResponse is an account with the global roles it holds
+type UserDetailResponse struct {
+	auth.UserResponse
+	Roles []rbac.RoleResponse `json:"roles"`
+}
+
+// BulkResultResponse lists the users a bulk operation changed and the errors met for the others
+type BulkResultResponse struct {
+	Succeeded []string              `json:"succeeded"`
+	Failed    []BulkFailureResponse `json:"failed"`
+}
+
+// BulkFailureResponse is the error a bulk operation met for one user
+type BulkFailureResponse struct {
+	UserID    string `json:"user_id"`
+	ErrorCode int    `json:"error_code"`
+	Error     string `json:"error"`
+}
+
+// ToUserResponses converts slice of auth.User to slice of auth.UserResponse
+func ToUserResponses(users []auth.User) []auth.UserResponse {
+	result := make([]auth.UserResponse, len(users))
+	for i, user := range users {
+		result[i] = auth.ToUserResponse(&user)
+	}
+	return result
+}
+
+// ToUserDetailResponse converts UserDetail to UserDetailResponse
+func ToUserDetailResponse(detail *UserDetail) UserDetailResponse {
+	return UserDetailResponse{
+		UserResponse: auth.ToUserResponse(&detail.User),
+		Roles:        rbac.ToRoleResponses(detail.Roles),
+	}
+}
+
+// ToBulkResultResponse converts BulkResult to BulkResultResponse
+func ToBulkResultResponse(result *BulkResult) BulkResultResponse {
+	failed := make([]BulkFailureResponse, len(result.Failed))
+	for i, failure := range result.Failed {
+		failed[i] = BulkFailureResponse{
+			UserID:    failure.UserID,
+			ErrorCode: failure.Error.Code.Value(),
+			Error:     failure.Error.Code.String(),
+		}
+	}
+	return BulkResultResponse{
+		Succeeded: result.Succeeded,
+		Failed:    failed,
+	}
+}
@@ -0,0 +1,230 @@
+package users
+
+import (
+	"fmt"
+	"strings"
+
+	"boilerplate-be/internal/module/auth"
+	"boilerplate-be/internal/module/rbac"
+	"boilerplate-be/internal/shared/errors"
+	"boilerplate-be/internal/shared/security"
+)
+
+type userUseCase struct {
+	userRepo UserRepository
+	roles    RoleManager
+	sessions SessionRevoker
+}
+
+// NewUserUseCase creates a new user administration use case
+func NewUserUseCase(userRepo UserRepository, roles RoleManager, sessions SessionRevoker) UserUseCase {
+	return &userUseCase{
+		userRepo: userRepo,
+		roles:    roles,
+		sessions: sessions,
+	}
+}
+
+func (u *userUseCase) WithActor(actor rbac.ChangeActor) UserUseCase {
+	return &userUseCase{
+		userRepo: u.userRepo.WithActor(actor),
+		roles:    u.roles.WithActor(actor),
+		sessions: u.sessions,
+	}
+}
+
+// ==================== User Operations ====================
+
+func (u *userUseCase) ListUsers(filter UserFilter) ([]auth.User, int64, error) {
+	return u.userRepo.List(filter)
+}
+
+func (u *userUseCase) GetUser(id string) (*UserDetail, error) {
+	user, err := u.userRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := u.roles.GetUserRoles(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserDetail{User: *user, Roles: roles}, nil
+}
+
+func (u *userUseCase) CreateUser(input UserInput) (*auth.User, error) {
+	username := strings.TrimSpace(input.Username)
+	if auth.IsReservedUsername(username) {
+		return nil, errors.New(errors.InvalidUsername)
+	}
+
+	hashedPassword, err := security.HashPassword(input.Password)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.PasswordHashFailed)
+	}
+
+	user := &auth.User{
+		Name:     input.Name,
+		Username: username,
+		Email:    input.Email,
+		Password: hashedPassword,
+	}
+	if err := u.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+
+	// An account without the default role would lack what every user holds, so undo the creation
+	if err := u.roles.AssignRolesByName(user.ID, []string{auth.DefaultRole}); err != nil {
+		_ = u.userRepo.Delete(user.ID)
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// UpdateUser changes the account; setting a password ends the user's sessions
+func (u *userUseCase) UpdateUser(actorID, id string, input UserInput) (*auth.User, error) {
+	user, err := u.userRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.requireOutranks(actorID, id); err != nil {
+		return nil, err
+	}
+
+	username := strings.TrimSpace(input.Username)
+	if username != "" && !strings.EqualFold(username, user.Username) && auth.IsReservedUsername(username) {
+		return nil, errors.New(errors.InvalidUsername)
+	}
+
+	user.Name = input.Name
+	user.Email = input.Email
+	if username != "" {
+		user.Username = username
+	}
+	if input.Password != "" {
+		if user.Password, err = security.HashPassword(input.Password); err != nil {
+			return nil, errors.Wrap(err, errors.PasswordHashFailed)
+		}
+	}
+
+	if err := u.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	if input.Password != "" {
+		if err := u.sessions.RevokeAllUserTokens(id); err != nil {
+			return nil, errors.Wrap(err, errors.CacheDeleteFailed)
+		}
+	}
+
+	return user, nil
+}
+
+// DeleteUser deletes the account and ends its sessions; admins cannot delete themselves
+func (u *userUseCase) DeleteUser(actorID, id string) error {
+	if actorID == id {
+		return errors.New(errors.Forbidden)
+	}
+	if _, err := u.userRepo.GetByID(id); err != nil {
+		return err
+	}
+	if err := u.requireOutranks(actorID, id); err != nil {
+		return err
+	}
+
+	if err := u.userRepo.Delete(id); err != nil {
+		return err
+	}
+
+	if err := u.sessions.RevokeAllUserTokens(id); err != nil {
+		return errors.Wrap(err, errors.CacheDeleteFailed)
+	}
+	return nil
+}
+
+// requireOutranks rejects changes to a user holding a permission the actor does not hold, or
+// holds but is denied
+func (u *userUseCase) requireOutranks(actorID, userID string) error {
+	if actorID == userID {
+		return nil
+	}
+
+	held, err := u.roles.GetUserPermissions(actorID)
+	if err != nil {
+		return err
+	}
+	granted := make([]string, len(held))
+	for i, permission := range held {
+		granted[i] = permission.Name
+	}
+	denies, err := u.roles.GetUserDenyRules(actorID, "")
+	if err != nil {
+		return err
+	}
+	denied := make([]string, len(denies))
+	for i, rule := range denies {
+		denied[i] = rule.Pattern
+	}
+
+	target, err := u.roles.GetUserPermissions(userID)
+	if err != nil {
+		return err
+	}
+
+	var problems []errors.ValidationErrorDetails
+	for _, permission := range target {
+		if _, ok := rbac.Authorize(granted, denied, permission.Name); !ok {
+			problems = append(problems, errors.ValidationErrorDetails{
+				Field:   "id",
+				Message: fmt.Sprintf("user holds permission %q which you do not", permission.Name),
+			})
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.NewWithDetails(errors.PrivilegeEscalation, problems)
+	}
+	return nil
+}
+
+// ==================== Bulk Operations ====================
+
+func (u *userUseCase) BulkDelete(actorID string, ids []string) (*BulkResult, error) {
+	return bulk(ids, func(id string) error {
+		return u.DeleteUser(actorID, id)
+	})
+}
+
+func (u *userUseCase) BulkAssignRole(actorID, roleID string, ids []string, validity rbac.RoleValidity) (*BulkResult, error) {
+	return bulk(ids, func(id string) error {
+		return u.roles.AssignManagedRole(actorID, id, roleID, validity)
+	})
+}
+
+func (u *userUseCase) BulkRemoveRole(actorID, roleID string, ids []string) (*BulkResult, error) {
+	return bulk(ids, func(id string) error {
+		return u.roles.RemoveManagedRole(actorID, id, roleID)
+	})
+}
+
+// bulk applies the operation to each user in turn. Application errors are reported per user;
+// any other error, such as a lost database connection, stops the operation.
+func bulk(ids []string, operation func(id string) error) (*BulkResult, error) {
+	result := &BulkResult{Succeeded: []string{}, Failed: []BulkFailure{}}
+	for _, id := range ids {
+		err := operation(id)
+		if err == nil {
+			result.Succeeded = append(result.Succeeded, id)
+			continue
+		}
+
+		appErr, ok := errors.IsAppError(err)
+		if !ok || appErr.StatusCode >= 500 {
+			return nil, err
+		}
+		result.Failed = append(result.Failed, BulkFailure{UserID: id, Error: appErr})
+	}
+	return result, nil
+}
@@ -0,0 +1,352 @@
+package users
+
+import (
+	stderrors "errors"
+	"slices"
+	"sort"
+	"testing"
+
+	"boilerplate-be/internal/module/auth"
+	"boilerplate-be/internal/module/rbac"
+	"boilerplate-be/internal/shared/enum"
+	apperrors "boilerplate-be/internal/shared/errors"
+
+	"github.com/google/uuid"
+)
+
+// MockUserRepository implements UserRepository in memory for testing
+type MockUserRepository struct {
+	users     map[string]*auth.User
+	actor     rbac.ChangeActor
+	deletedBy map[string]rbac.ChangeActor // user -> actor of the deletion
+}
+
+func NewMockUserRepository() *MockUserRepository {
+	return &MockUserRepository{
+		users:     make(map[string]*auth.User),
+		deletedBy: make(map[string]rbac.ChangeActor),
+	}
+}
+
+func (m *MockUserRepository) WithActor(actor rbac.ChangeActor) UserRepository {
+	copied := *m
+	copied.actor = actor
+	return &copied
+}
+
+func (m *MockUserRepository) List(filter UserFilter) ([]auth.User, int64, error) {
+	var users []auth.User
+	for _, user := range m.users {
+		users = append(users, *user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Name < users[j].Name })
+	return users, int64(len(users)), nil
+}
+
+func (m *MockUserRepository) GetByID(id string) (*auth.User, error) {
+	if user, ok := m.users[id]; ok {
+		copied := *user
+		return &copied, nil
+	}
+	return nil, apperrors.New(apperrors.AccountNotFound)
+}
+
+func (m *MockUserRepository) Create(user *auth.User) error {
+	for _, existing := range m.users {
+		if existing.Email == user.Email {
+			return apperrors.New(apperrors.EmailExists)
+		}
+	}
+	user.ID = uuid.New().String()
+	m.users[user.ID] = user
+	return nil
+}
+
+func (m *MockUserRepository) Update(user *auth.User) error {
+	if _, ok := m.users[user.ID]; !ok {
+		return apperrors.New(apperrors.AccountNotFound)
+	}
+	m.users[user.ID] = user
+	return nil
+}
+
+func (m *MockUserRepository) Delete(id string) error {
+	if _, ok := m.users[id]; !ok {
+		return apperrors.New(apperrors.AccountNotFound)
+	}
+	delete(m.users, id)
+	m.deletedBy[id] = m.actor
+	return nil
+}
+
+func (m *MockUserRepository) addUser(name string) *auth.User {
+	user := &auth.User{ID: uuid.New().String(), Name: name, Email: name + "@example.com"}
+	m.users[user.ID] = user
+	return user
+}
+
+// mockRoles implements RoleManager; users hold permission names directly and managed role
+// operations fail unless the role is in manageable. Embedded RBACUseCase methods are not called.
+type mockRoles struct {
+	rbac.RBACUseCase
+	permissions map[string][]string         // user -> permission names
+	denies      map[string][]string         // user -> denied patterns
+	assigned    map[string][]string         // user -> role names or IDs
+	assignedBy  map[string]rbac.ChangeActor // user -> actor of the last grant by name
+	manageable  map[string]bool
+	actor       rbac.ChangeActor
+	failWith    error
+}
+
+func (m *mockRoles) WithActor(actor rbac.ChangeActor) rbac.RBACUseCase {
+	copied := *m
+	copied.actor = actor
+	return &copied
+}
+
+func newMockRoles() *mockRoles {
+	return &mockRoles{
+		permissions: make(map[string][]string),
+		denies:      make(map[string][]string),
+		assigned:    make(map[string][]string),
+		assignedBy:  make(map[string]rbac.ChangeActor),
+		manageable:  make(map[string]bool),
+	}
+}
+
+func (m *mockRoles) GetUserRoles(userID string) ([]rbac.Role, error) {
+	var roles []rbac.Role
+	for _, name := range m.assigned[userID] {
+		roles = append(roles, rbac.Role{Name: name})
+	}
+	return roles, nil
+}
+
+func (m *mockRoles) GetUserPermissions(userID string) ([]rbac.Permission, error) {
+	var permissions []rbac.Permission
+	for _, name := range m.permissions[userID] {
+		permissions = append(permissions, rbac.Permission{Name: name})
+	}
+	return permissions, nil
+}
+
+func (m *mockRoles) GetUserDenyRules(userID, organizationID string) ([]rbac.DenyRule, error) {
+	var rules []rbac.DenyRule
+	for _, pattern := range m.denies[userID] {
+		rules = append(rules, rbac.DenyRule{Pattern: pattern})
+	}
+	return rules, nil
+}
+
+func (m *mockRoles) AssignRolesByName(userID string, roleNames []string) error {
+	if m.failWith != nil {
+		return m.failWith
+	}
+	m.assigned[userID] = append(m.assigned[userID], roleNames...)
+	m.assignedBy[userID] = m.actor
+	return nil
+}
+
+func (m *mockRoles) AssignManagedRole(managerID, userID, roleID string, validity rbac.RoleValidity) error {
+	if m.failWith != nil {
+		return m.failWith
+	}
+	if !m.manageable[roleID] {
+		return apperrors.New(apperrors.RoleNotManageable)
+	}
+	m.assigned[userID] = append(m.assigned[userID], roleID)
+	return nil
+}
+
+func (m *mockRoles) RemoveManagedRole(managerID, userID, roleID string) error {
+	if !m.manageable[roleID] {
+		return apperrors.New(apperrors.RoleNotManageable)
+	}
+	m.assigned[userID] = slices.DeleteFunc(m.assigned[userID], func(id string) bool { return id == roleID })
+	return nil
+}
+
+// mockSessions implements SessionRevoker, recording whose sessions were ended
+type mockSessions struct {
+	revoked []string
+}
+
+func (m *mockSessions) RevokeAllUserTokens(userID string) error {
+	m.revoked = append(m.revoked, userID)
+	return nil
+}
+
+func assertErrorCode(t *testing.T, err error, want enum.ErrorCode) {
+	t.Helper()
+	if want == enum.Success {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return
+	}
+	appErr, ok := apperrors.IsAppError(err)
+	if !ok || appErr.Code != want {
+		t.Fatalf("expected error code %v, got %v", want, err)
+	}
+}
+
+func TestUserService_CreateUser(t *testing.T) {
+	repo := NewMockUserRepository()
+	roles := newMockRoles()
+	useCase := NewUserUseCase(repo, roles, &mockSessions{})
+
+	user, err := useCase.CreateUser(UserInput{Name: "Alice", Username: " alice ", Email: "alice@example.com", Password: "secret123"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user.Username != "alice" || user.Password == "secret123" {
+		t.Errorf("expected a trimmed username and hashed password, got %q and %q", user.Username, user.Password)
+	}
+	if !slices.Equal(roles.assigned[user.ID], []string{auth.DefaultRole}) {
+		t.Errorf("expected the default role, got %v", roles.assigned[user.ID])
+	}
+
+	_, err = useCase.CreateUser(UserInput{Name: "Admin", Username: "admin", Email: "admin@example.com", Password: "secret123"})
+	assertErrorCode(t, err, enum.InvalidUsername)
+	_, err = useCase.CreateUser(UserInput{Name: "Alice", Email: "alice@example.com", Password: "secret123"})
+	assertErrorCode(t, err, enum.EmailExists)
+
+	detail, err := useCase.GetUser(user.ID)
+	if err != nil || detail.User.Email != "alice@example.com" || len(detail.Roles) != 1 {
+		t.Errorf("unexpected detail %+v, %v", detail, err)
+	}
+	_, err = useCase.GetUser("missing")
+	assertErrorCode(t, err, enum.AccountNotFound)
+
+	t.Run("failed role grant removes the account", func(t *testing.T) {
+		roles.failWith = stderrors.New("connection reset")
+		defer func() { roles.failWith = nil }()
+
+		_, err := useCase.CreateUser(UserInput{Name: "Bob", Email: "bob@example.com", Password: "secret123"})
+		if err == nil {
+			t.Fatal("expected the role grant error")
+		}
+		for _, user := range repo.users {
+			if user.Email == "bob@example.com" {
+				t.Error("expected the account to be deleted")
+			}
+		}
+	})
+}
+
+func TestUserService_WithActor(t *testing.T) {
+	repo := NewMockUserRepository()
+	roles := newMockRoles()
+	useCase := NewUserUseCase(repo, roles, &mockSessions{})
+
+	admin := repo.addUser("admin")
+	roles.permissions[admin.ID] = []string{"users:*"}
+
+	actor := rbac.ChangeActor{UserID: admin.ID, RequestID: "req-1"}
+	acting := useCase.WithActor(actor)
+
+	user, err := acting.CreateUser(UserInput{Name: "Alice", Email: "alice@example.com", Password: "secret123"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := roles.assignedBy[user.ID]; got != actor {
+		t.Errorf("expected the default role grant to be recorded as made by %+v, got %+v", actor, got)
+	}
+
+	assertErrorCode(t, acting.DeleteUser(admin.ID, user.ID), enum.Success)
+	if got := repo.deletedBy[user.ID]; got != actor {
+		t.Errorf("expected the cascaded deletes to be recorded as made by %+v, got %+v", actor, got)
+	}
+}
+
+func TestUserService_CannotChangeMorePrivilegedUsers(t *testing.T) {
+	repo := NewMockUserRepository()
+	roles := newMockRoles()
+	sessions := &mockSessions{}
+	useCase := NewUserUseCase(repo, roles, sessions)
+
+	admin := repo.addUser("admin")
+	superAdmin := repo.addUser("root")
+	member := repo.addUser("member")
+	roles.permissions[admin.ID] = []string{"users:*", "profile:*"}
+	roles.permissions[superAdmin.ID] = []string{"*:*"}
+	roles.permissions[member.ID] = []string{"profile:read", "users:delete"}
+
+	input := UserInput{Name: "Renamed", Email: "renamed@example.com"}
+	tests := []struct {
+		name   string
+		target string
+		want   enum.ErrorCode
+	}{
+		{"less privileged user", member.ID, enum.Success},
+		{"themself", admin.ID, enum.Success},
+		{"more privileged user", superAdmin.ID, enum.PrivilegeEscalation},
+		{"missing user", "missing", enum.AccountNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := useCase.UpdateUser(admin.ID, tt.target, input)
+			assertErrorCode(t, err, tt.want)
+		})
+	}
+	if len(sessions.revoked) != 0 {
+		t.Errorf("expected no sessions ended without a password change, got %v", sessions.revoked)
+	}
+
+	// A denied permission does not count as held
+	roles.denies[admin.ID] = []string{"users:delete"}
+	_, err := useCase.UpdateUser(admin.ID, member.ID, input)
+	assertErrorCode(t, err, enum.PrivilegeEscalation)
+	roles.denies[admin.ID] = nil
+
+	// Setting a password ends the user's sessions
+	input.Password = "new-secret"
+	user, err := useCase.UpdateUser(admin.ID, member.ID, input)
+	assertErrorCode(t, err, enum.Success)
+	if user.Password == "new-secret" || !slices.Equal(sessions.revoked, []string{member.ID}) {
+		t.Errorf("expected a hashed password and ended sessions, got %q and %v", user.Password, sessions.revoked)
+	}
+
+	assertErrorCode(t, useCase.DeleteUser(admin.ID, admin.ID), enum.Forbidden)
+	assertErrorCode(t, useCase.DeleteUser(admin.ID, superAdmin.ID), enum.PrivilegeEscalation)
+	assertErrorCode(t, useCase.DeleteUser(admin.ID, member.ID), enum.Success)
+	if _, ok := repo.users[member.ID]; ok {
+		t.Error("expected member to be deleted")
+	}
+}
+
+func TestUserService_BulkOperations(t *testing.T) {
+	repo := NewMockUserRepository()
+	roles := newMockRoles()
+	useCase := NewUserUseCase(repo, roles, &mockSessions{})
+
+	admin := repo.addUser("admin")
+	alice := repo.addUser("alice")
+	bob := repo.addUser("bob")
+	roles.permissions[admin.ID] = []string{"users:*"}
+
+	result, err := useCase.BulkDelete(admin.ID, []string{alice.ID, admin.ID, "missing"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(result.Succeeded, []string{alice.ID}) || len(result.Failed) != 2 ||
+		result.Failed[0].Error.Code != enum.Forbidden || result.Failed[1].Error.Code != enum.AccountNotFound {
+		t.Errorf("unexpected bulk delete result: %+v", result)
+	}
+
+	roles.manageable["support"] = true
+	result, _ = useCase.BulkAssignRole(admin.ID, "support", []string{bob.ID}, rbac.RoleValidity{})
+	if !slices.Equal(result.Succeeded, []string{bob.ID}) || !slices.Contains(roles.assigned[bob.ID], "support") {
+		t.Errorf("unexpected bulk assign result: %+v", result)
+	}
+	result, _ = useCase.BulkRemoveRole(admin.ID, "billing", []string{bob.ID})
+	if len(result.Failed) != 1 || result.Failed[0].Error.Code != enum.RoleNotManageable {
+		t.Errorf("expected unmanaged roles to fail, got %+v", result)
+	}
+
+	// Errors other than application errors stop the operation
+	roles.failWith = stderrors.New("connection reset")
+	if _, err := useCase.BulkAssignRole(admin.ID, "support", []string{bob.ID}, rbac.RoleValidity{}); err == nil {
+		t.Error("expected the bulk operation to fail")
+	}
+}
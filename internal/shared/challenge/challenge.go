@@ -0,0 +1,46 @@
+// Package challenge protects endpoints from automated abuse by asking clients to solve a
+// challenge once risk thresholds are crossed. A server-side proof-of-work verifier is built
+// in; hosted captcha providers plug in through HostedCaptchaVerifier.
+package challenge
+
+import (
+	"context"
+	"time"
+)
+
+// Request headers carrying the client's answer
+const (
+	HeaderToken    = "X-Challenge-Token"
+	HeaderSolution = "X-Challenge-Solution"
+)
+
+// Challenge types returned to clients
+const (
+	TypeProofOfWork   = "proof_of_work"
+	TypeHostedCaptcha = "captcha"
+)
+
+// Challenge describes what the client has to solve
+type Challenge struct {
+	Type string `json:"type"`
+	// Token identifies a proof-of-work challenge and is sent back with the solution
+	Token string `json:"token,omitempty"`
+	// Difficulty is the number of leading zero bits required in sha256(token + ":" + solution)
+	Difficulty int `json:"difficulty,omitempty"`
+	// SiteKey is the public key a hosted captcha widget is rendered with
+	SiteKey   string    `json:"site_key,omitempty"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// Verifier issues challenges and checks the client's answer.
+// For hosted captchas the token is the provider response and solution is unused.
+type Verifier interface {
+	Issue(ctx context.Context, clientIP string) (*Challenge, error)
+	Verify(ctx context.Context, token, solution, clientIP string) (bool, error)
+}
+
+// Supported providers for the challenge configuration
+const (
+	ProviderProofOfWork = "pow"
+	ProviderHosted      = "hosted"
+)
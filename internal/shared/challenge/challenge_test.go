@@ -0,0 +1,176 @@
+package challenge
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"boilerplate-be/internal/database"
+	"boilerplate-be/internal/shared/errors"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedis(t *testing.T) (*database.RedisClient, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := &database.RedisClient{Client: redis.NewClient(&redis.Options{Addr: mr.Addr()})}
+	t.Cleanup(func() { client.Close() })
+	return client, mr
+}
+
+func TestProofOfWorkVerifier(t *testing.T) {
+	client, _ := newTestRedis(t)
+	verifier := NewProofOfWorkVerifier(client, ProofOfWorkConfig{KeyPrefix: "challenge:pow", Difficulty: 8, TTL: time.Minute})
+	ctx := context.Background()
+
+	t.Run("solved challenge verifies once", func(t *testing.T) {
+		challenge, err := verifier.Issue(ctx, "203.0.113.10")
+		if err != nil {
+			t.Fatalf("failed to issue challenge: %v", err)
+		}
+		if challenge.Type != TypeProofOfWork || challenge.Difficulty != 8 || challenge.Token == "" {
+			t.Fatalf("unexpected challenge: %+v", challenge)
+		}
+
+		solution := Solve(challenge.Token, challenge.Difficulty)
+		if ok, err := verifier.Verify(ctx, challenge.Token, solution, "203.0.113.10"); err != nil || !ok {
+			t.Fatalf("expected valid solution, got %v, %v", ok, err)
+		}
+		if ok, _ := verifier.Verify(ctx, challenge.Token, solution, "203.0.113.10"); ok {
+			t.Error("challenge token must be single use")
+		}
+	})
+
+	t.Run("wrong solution consumes the challenge", func(t *testing.T) {
+		challenge, _ := verifier.Issue(ctx, "203.0.113.10")
+		wrong := "not-a-solution"
+		for leadingZeroBits(proofOfWorkHash(challenge.Token, wrong)) >= 8 {
+			wrong += "x"
+		}
+
+		if ok, _ := verifier.Verify(ctx, challenge.Token, wrong, "203.0.113.10"); ok {
+			t.Error("expected wrong solution to be rejected")
+		}
+		if ok, _ := verifier.Verify(ctx, challenge.Token, Solve(challenge.Token, 8), "203.0.113.10"); ok {
+			t.Error("expected challenge to be consumed after a wrong answer")
+		}
+	})
+
+	t.Run("concurrent replays verify once", func(t *testing.T) {
+		challenge, _ := verifier.Issue(ctx, "203.0.113.10")
+		solution := Solve(challenge.Token, challenge.Difficulty)
+
+		var wg sync.WaitGroup
+		var passed atomic.Int32
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if ok, _ := verifier.Verify(ctx, challenge.Token, solution, "203.0.113.10"); ok {
+					passed.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if got := passed.Load(); got != 1 {
+			t.Errorf("expected exactly one concurrent verification to pass, got %d", got)
+		}
+	})
+
+	t.Run("solution from another IP is rejected", func(t *testing.T) {
+		challenge, _ := verifier.Issue(ctx, "203.0.113.10")
+		solution := Solve(challenge.Token, challenge.Difficulty)
+
+		if ok, _ := verifier.Verify(ctx, challenge.Token, solution, "198.51.100.7"); ok {
+			t.Error("expected a challenge solved for another IP to be rejected")
+		}
+		if ok, _ := verifier.Verify(ctx, challenge.Token, solution, "203.0.113.10"); ok {
+			t.Error("expected the challenge to be consumed by the rejected attempt")
+		}
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		if ok, _ := verifier.Verify(ctx, "unknown", "1", ""); ok {
+			t.Error("expected unknown token to be rejected")
+		}
+	})
+}
+
+func TestHostedCaptchaVerifier(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("secret") == "server-secret" && r.Form.Get("response") == "good-token" {
+			w.Write([]byte(`{"success": true}`))
+			return
+		}
+		w.Write([]byte(`{"success": false}`))
+	}))
+	defer server.Close()
+
+	verifier := NewHostedCaptchaVerifier(HostedCaptchaConfig{
+		VerifyURL: server.URL, Secret: "server-secret", SiteKey: "site-key", Timeout: time.Second,
+	})
+	ctx := context.Background()
+
+	challenge, _ := verifier.Issue(ctx, "")
+	if challenge.Type != TypeHostedCaptcha || challenge.SiteKey != "site-key" {
+		t.Errorf("unexpected challenge: %+v", challenge)
+	}
+	if ok, err := verifier.Verify(ctx, "good-token", "", "203.0.113.10"); err != nil || !ok {
+		t.Errorf("expected good token to verify, got %v, %v", ok, err)
+	}
+	if ok, _ := verifier.Verify(ctx, "bad-token", "", "203.0.113.10"); ok {
+		t.Error("expected bad token to be rejected")
+	}
+}
+
+func TestGuard(t *testing.T) {
+	client, mr := newTestRedis(t)
+	verifier := NewProofOfWorkVerifier(client, ProofOfWorkConfig{KeyPrefix: "challenge:pow", Difficulty: 4, TTL: time.Minute})
+	tracker := NewRiskTracker(client, "challenge:risk", 10*time.Minute)
+	guard := NewGuard(verifier, tracker, map[string]int{ScopeLogin: 2})
+	ctx := context.Background()
+	ip := "203.0.113.10"
+
+	assertCode := func(t *testing.T, err error, want errors.AppError) {
+		t.Helper()
+		appErr, ok := errors.IsAppError(err)
+		if !ok || appErr.Code != want.Code {
+			t.Fatalf("expected %s, got %v", want.Code, err)
+		}
+	}
+
+	if err := guard.Check(ctx, ScopeLogin, ip, "", ""); err != nil {
+		t.Fatalf("expected no challenge below threshold, got %v", err)
+	}
+	if err := guard.Check(ctx, ScopeRegister, ip, "", ""); err != nil {
+		t.Fatalf("expected scope without threshold to pass, got %v", err)
+	}
+
+	guard.Record(ctx, ScopeLogin, ip)
+	guard.Record(ctx, ScopeLogin, ip)
+
+	assertCode(t, guard.Check(ctx, ScopeLogin, ip, "", ""), errors.New(errors.ChallengeRequired))
+	assertCode(t, guard.Check(ctx, ScopeLogin, ip, "bogus", "1"), errors.New(errors.ChallengeFailed))
+
+	challenge, _ := guard.Issue(ctx, ip)
+	if err := guard.Check(ctx, ScopeLogin, ip, challenge.Token, Solve(challenge.Token, challenge.Difficulty)); err != nil {
+		t.Errorf("expected solved challenge to pass, got %v", err)
+	}
+
+	if err := guard.Check(ctx, ScopeLogin, "198.51.100.7", "", ""); err != nil {
+		t.Errorf("expected other clients to be unaffected, got %v", err)
+	}
+
+	mr.FastForward(11 * time.Minute)
+	if err := guard.Check(ctx, ScopeLogin, ip, "", ""); err != nil {
+		t.Errorf("expected risk count to expire with the window, got %v", err)
+	}
+}
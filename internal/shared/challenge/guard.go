@@ -0,0 +1,123 @@
+package challenge
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"boilerplate-be/internal/database"
+	"boilerplate-be/internal/shared/errors"
+)
+
+// Scopes tracked separately by the risk tracker
+const (
+	ScopeLogin    = "login"
+	ScopeRegister = "register"
+)
+
+// RiskTracker counts risky events per scope and client IP within a sliding window
+type RiskTracker struct {
+	*database.RedisHelper
+	keyPrefix string
+	window    time.Duration
+}
+
+func NewRiskTracker(client *database.RedisClient, keyPrefix string, window time.Duration) *RiskTracker {
+	return &RiskTracker{
+		RedisHelper: database.NewRedisHelper(client),
+		keyPrefix:   keyPrefix,
+		window:      window,
+	}
+}
+
+// Record counts one event; the window starts with the first event
+func (t *RiskTracker) Record(ctx context.Context, scope, clientIP string) error {
+	key := t.buildKey(scope, clientIP)
+	count, err := t.Increment(ctx, key)
+	if err != nil {
+		return err
+	}
+	if count == 1 {
+		return t.Expire(ctx, key, t.window)
+	}
+	return nil
+}
+
+// Count returns the number of events recorded in the current window
+func (t *RiskTracker) Count(ctx context.Context, scope, clientIP string) (int, error) {
+	value, err := t.Get(ctx, t.buildKey(scope, clientIP))
+	if err != nil || value == "" {
+		return 0, err
+	}
+	var count int
+	if _, err := fmt.Sscan(value, &count); err != nil {
+		return 0, errors.Wrap(err, errors.CacheRetrieveFailed)
+	}
+	return count, nil
+}
+
+func (t *RiskTracker) buildKey(scope, clientIP string) string {
+	return fmt.Sprintf("%s:%s:%s", t.keyPrefix, scope, clientIP)
+}
+
+// Guard requires a solved challenge once a client's risk count for a scope reaches its threshold
+type Guard struct {
+	verifier   Verifier
+	tracker    *RiskTracker
+	thresholds map[string]int
+}
+
+// NewGuard creates a guard; a scope without a threshold is never challenged and a
+// threshold of 0 challenges every request
+func NewGuard(verifier Verifier, tracker *RiskTracker, thresholds map[string]int) *Guard {
+	return &Guard{
+		verifier:   verifier,
+		tracker:    tracker,
+		thresholds: thresholds,
+	}
+}
+
+// Issue creates a new challenge for the client
+func (g *Guard) Issue(ctx context.Context, clientIP string) (*Challenge, error) {
+	challenge, err := g.verifier.Issue(ctx, clientIP)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ExternalServiceError)
+	}
+	return challenge, nil
+}
+
+// Check returns ChallengeRequired when a challenge is due but missing and ChallengeFailed
+// when the presented answer is wrong
+func (g *Guard) Check(ctx context.Context, scope, clientIP, token, solution string) error {
+	threshold, ok := g.thresholds[scope]
+	if !ok {
+		return nil
+	}
+
+	count, err := g.tracker.Count(ctx, scope, clientIP)
+	if err != nil {
+		return err
+	}
+	if count < threshold {
+		return nil
+	}
+
+	if token == "" {
+		return errors.New(errors.ChallengeRequired)
+	}
+
+	valid, err := g.verifier.Verify(ctx, token, solution, clientIP)
+	if err != nil {
+		return errors.Wrap(err, errors.ExternalServiceError)
+	}
+	if !valid {
+		return errors.New(errors.ChallengeFailed)
+	}
+
+	return nil
+}
+
+// Record counts a risky event (a failed login, a registration) for the client
+func (g *Guard) Record(ctx context.Context, scope, clientIP string) error {
+	return g.tracker.Record(ctx, scope, clientIP)
+}
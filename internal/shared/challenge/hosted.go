@@ -0,0 +1,83 @@
+package challenge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HostedCaptchaVerifier checks responses from hosted captcha widgets that implement the common
+// "siteverify" protocol (reCAPTCHA, hCaptcha, Cloudflare Turnstile): the response token, secret
+// and client IP are posted as a form and the provider answers with {"success": bool}.
+type HostedCaptchaVerifier struct {
+	verifyURL  string
+	secret     string
+	siteKey    string
+	httpClient *http.Client
+}
+
+type HostedCaptchaConfig struct {
+	VerifyURL string
+	Secret    string
+	SiteKey   string
+	Timeout   time.Duration
+}
+
+func NewHostedCaptchaVerifier(config HostedCaptchaConfig) *HostedCaptchaVerifier {
+	return &HostedCaptchaVerifier{
+		verifyURL:  config.VerifyURL,
+		secret:     config.Secret,
+		siteKey:    config.SiteKey,
+		httpClient: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// Issue returns the site key; the widget itself creates the challenge on the client
+func (v *HostedCaptchaVerifier) Issue(ctx context.Context, clientIP string) (*Challenge, error) {
+	return &Challenge{
+		Type:    TypeHostedCaptcha,
+		SiteKey: v.siteKey,
+	}, nil
+}
+
+func (v *HostedCaptchaVerifier) Verify(ctx context.Context, token, solution, clientIP string) (bool, error) {
+	if token == "" {
+		return false, nil
+	}
+
+	form := url.Values{}
+	form.Set("secret", v.secret)
+	form.Set("response", token)
+	if clientIP != "" {
+		form.Set("remoteip", clientIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("captcha verification request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("captcha provider returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, fmt.Errorf("failed to decode captcha response: %w", err)
+	}
+
+	return result.Success, nil
+}
@@ -0,0 +1,111 @@
+package challenge
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/bits"
+	"time"
+
+	"boilerplate-be/internal/database"
+)
+
+// ProofOfWorkVerifier issues hashcash-style challenges: the client must find a solution such
+// that sha256(token + ":" + solution) starts with Difficulty zero bits. Tokens live in Redis,
+// are single use and only accepted from the IP they were issued to, so solved challenges cannot
+// be farmed on one host and spent from another. No external service is needed.
+type ProofOfWorkVerifier struct {
+	*database.RedisHelper
+	keyPrefix  string
+	difficulty int
+	ttl        time.Duration
+}
+
+type ProofOfWorkConfig struct {
+	KeyPrefix  string
+	Difficulty int
+	TTL        time.Duration
+}
+
+func NewProofOfWorkVerifier(client *database.RedisClient, config ProofOfWorkConfig) *ProofOfWorkVerifier {
+	return &ProofOfWorkVerifier{
+		RedisHelper: database.NewRedisHelper(client),
+		keyPrefix:   config.KeyPrefix,
+		difficulty:  config.Difficulty,
+		ttl:         config.TTL,
+	}
+}
+
+func (v *ProofOfWorkVerifier) Issue(ctx context.Context, clientIP string) (*Challenge, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate challenge token: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+
+	if err := v.SetWithTTL(ctx, v.buildKey(token), issuedTo(clientIP), v.ttl); err != nil {
+		return nil, err
+	}
+
+	return &Challenge{
+		Type:       TypeProofOfWork,
+		Token:      token,
+		Difficulty: v.difficulty,
+		ExpiresAt:  time.Now().Add(v.ttl),
+	}, nil
+}
+
+func (v *ProofOfWorkVerifier) Verify(ctx context.Context, token, solution, clientIP string) (bool, error) {
+	if token == "" || solution == "" {
+		return false, nil
+	}
+
+	// Consume the token atomically whatever the outcome: a challenge can neither be brute-forced
+	// across requests nor replayed by concurrent ones, which find it already consumed
+	stored, err := v.GetAndDelete(ctx, v.buildKey(token))
+	if err != nil {
+		return false, err
+	}
+	if stored == "" || stored != issuedTo(clientIP) {
+		return false, nil
+	}
+
+	return leadingZeroBits(proofOfWorkHash(token, solution)) >= v.difficulty, nil
+}
+
+func (v *ProofOfWorkVerifier) buildKey(token string) string {
+	return fmt.Sprintf("%s:%s", v.keyPrefix, token)
+}
+
+// issuedTo is the value stored with a token; it is never empty, which marks a missing token
+func issuedTo(clientIP string) string {
+	return "ip:" + clientIP
+}
+
+func proofOfWorkHash(token, solution string) [sha256.Size]byte {
+	return sha256.Sum256([]byte(token + ":" + solution))
+}
+
+func leadingZeroBits(hash [sha256.Size]byte) int {
+	count := 0
+	for _, b := range hash {
+		if b != 0 {
+			return count + bits.LeadingZeros8(b)
+		}
+		count += 8
+	}
+	return count
+}
+
+// Solve finds a solution for a proof-of-work challenge. It exists for tests and reference
+// clients; real clients solve challenges themselves.
+func Solve(token string, difficulty int) string {
+	for nonce := 0; ; nonce++ {
+		solution := fmt.Sprint(nonce)
+		if leadingZeroBits(proofOfWorkHash(token, solution)) >= difficulty {
+			return solution
+		}
+	}
+}
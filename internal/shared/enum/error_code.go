@@ -22,6 +22,9 @@ const (
 	InvalidToken         ErrorCode = -1010
 	TokenExpired         ErrorCode = -1011
 	RateLimitExceeded    ErrorCode = -1012
+	ChallengeRequired    ErrorCode = -1013
+	ChallengeFailed      ErrorCode = -1014
+	TokenOutdated        ErrorCode = -1015
 
 	// User/Account Errors (1100-1199)
 	UsernameExists     ErrorCode = -1100
@@ -34,11 +37,32 @@ const (
 	AccountLocked      ErrorCode = -1107
 	AccountNotVerified ErrorCode = -1108
 	PasswordTooWeak    ErrorCode = -1109
+	PhoneExists        ErrorCode = -1110
+	PhoneNotVerified   ErrorCode = -1111
+	InvalidOTP         ErrorCode = -1112
+	OTPExpired         ErrorCode = -1113
+	OTPTooManyAttempts ErrorCode = -1114
+	AccountNotLinked   ErrorCode = -1115
 
 	// File Handling Errors (1200-1299)
 	FileSizeExceeded ErrorCode = -1200
 	InvalidFileType  ErrorCode = -1201
 
+	// RBAC Errors (1300-1399)
+	PermissionExists          ErrorCode = -1300
+	PermissionNameMismatch    ErrorCode = -1301
+	SystemPermissionProtected ErrorCode = -1302
+	RoleHierarchyCycle        ErrorCode = -1303
+	NotOrganizationMember     ErrorCode = -1304
+	InvalidRoleValidity       ErrorCode = -1305
+	RoleNotRequestable        ErrorCode = -1306
+	AccessRequestNotPending   ErrorCode = -1307
+	InvalidPolicyDocument     ErrorCode = -1308
+	SeparationOfDutyViolation ErrorCode = -1309
+	RoleNotManageable         ErrorCode = -1310
+	PrivilegeEscalation       ErrorCode = -1311
+	RoleHierarchyTooDeep      ErrorCode = -1312
+
 	// Server Errors (5000-5099)
 	InternalServerError  ErrorCode = -5000
 	DatabaseError        ErrorCode = -5001
@@ -97,6 +121,9 @@ func (ec ErrorCode) String() string {
 		InvalidToken:         "INVALID_TOKEN",
 		TokenExpired:         "TOKEN_EXPIRED",
 		RateLimitExceeded:    "RATE_LIMIT_EXCEEDED",
+		ChallengeRequired:    "CHALLENGE_REQUIRED",
+		ChallengeFailed:      "CHALLENGE_FAILED",
+		TokenOutdated:        "TOKEN_OUTDATED",
 
 		// User/Account Errors
 		UsernameExists:     "USERNAME_EXISTS",
@@ -109,6 +136,12 @@ func (ec ErrorCode) String() string {
 		AccountLocked:      "ACCOUNT_LOCKED",
 		AccountNotVerified: "ACCOUNT_NOT_VERIFIED",
 		PasswordTooWeak:    "PASSWORD_TOO_WEAK",
+		PhoneExists:        "PHONE_EXISTS",
+		PhoneNotVerified:   "PHONE_NOT_VERIFIED",
+		InvalidOTP:         "INVALID_OTP",
+		OTPExpired:         "OTP_EXPIRED",
+		OTPTooManyAttempts: "OTP_TOO_MANY_ATTEMPTS",
+		AccountNotLinked:   "ACCOUNT_NOT_LINKED",
 
 		// Server Errors
 		InternalServerError:  "INTERNAL_SERVER_ERROR",
@@ -143,6 +176,21 @@ func (ec ErrorCode) String() string {
 		FileSizeExceeded: "FILE_SIZE_EXCEEDED",
 		InvalidFileType:  "INVALID_FILE_TYPE",
 
+		// RBAC Errors
+		PermissionExists:          "PERMISSION_EXISTS",
+		PermissionNameMismatch:    "PERMISSION_NAME_MISMATCH",
+		SystemPermissionProtected: "SYSTEM_PERMISSION_PROTECTED",
+		RoleHierarchyCycle:        "ROLE_HIERARCHY_CYCLE",
+		NotOrganizationMember:     "NOT_ORGANIZATION_MEMBER",
+		InvalidRoleValidity:       "INVALID_ROLE_VALIDITY",
+		RoleNotRequestable:        "ROLE_NOT_REQUESTABLE",
+		AccessRequestNotPending:   "ACCESS_REQUEST_NOT_PENDING",
+		InvalidPolicyDocument:     "INVALID_POLICY_DOCUMENT",
+		SeparationOfDutyViolation: "SEPARATION_OF_DUTY_VIOLATION",
+		RoleNotManageable:         "ROLE_NOT_MANAGEABLE",
+		PrivilegeEscalation:       "PRIVILEGE_ESCALATION",
+		RoleHierarchyTooDeep:      "ROLE_HIERARCHY_TOO_DEEP",
+
 		// File Storage Service
 		FileStorageError: "FILE_STORAGE_ERROR",
 	}
@@ -174,6 +222,9 @@ func (ec ErrorCode) MessageID() string {
 		InvalidToken:         "Token tidak valid",
 		TokenExpired:         "Token sudah kedaluwarsa",
 		RateLimitExceeded:    "Batas permintaan terlampaui",
+		ChallengeRequired:    "Verifikasi tantangan diperlukan",
+		ChallengeFailed:      "Verifikasi tantangan gagal",
+		TokenOutdated:        "Hak akses telah berubah, perbarui token Anda",
 
 		// User/Account Errors
 		UsernameExists:     "Username sudah digunakan",
@@ -186,6 +237,12 @@ func (ec ErrorCode) MessageID() string {
 		AccountLocked:      "Akun Anda terkunci.",
 		AccountNotVerified: "Akun Anda belum diverifikasi.",
 		PasswordTooWeak:    "Password terlalu lemah.",
+		PhoneExists:        "Nomor telepon sudah digunakan",
+		PhoneNotVerified:   "Nomor telepon belum diverifikasi",
+		InvalidOTP:         "Kode OTP tidak valid",
+		OTPExpired:         "Kode OTP sudah kedaluwarsa atau belum diminta",
+		OTPTooManyAttempts: "Terlalu banyak percobaan kode OTP, silakan minta kode baru",
+		AccountNotLinked:   "Email sudah dipakai akun lokal yang belum ditautkan ke direktori",
 
 		// Server Errors
 		InternalServerError:  "Terjadi kesalahan pada server",
@@ -220,6 +277,21 @@ func (ec ErrorCode) MessageID() string {
 		FileSizeExceeded: "Ukuran file melebihi batas yang diizinkan.",
 		InvalidFileType:  "Tipe file tidak valid.",
 
+		// RBAC Errors
+		PermissionExists:          "Permission sudah ada",
+		PermissionNameMismatch:    "Nama permission harus berformat resource:action",
+		SystemPermissionProtected: "Permission bawaan sistem tidak dapat diubah atau dihapus",
+		RoleHierarchyCycle:        "Parent role akan membentuk siklus pada hierarki role",
+		NotOrganizationMember:     "Pengguna bukan anggota organisasi ini",
+		InvalidRoleValidity:       "valid_until harus setelah valid_from dan di masa depan",
+		RoleNotRequestable:        "Role ini tidak memiliki approver sehingga tidak dapat diminta",
+		AccessRequestNotPending:   "Permintaan akses sudah diproses",
+		InvalidPolicyDocument:     "Dokumen kebijakan RBAC tidak valid",
+		SeparationOfDutyViolation: "Role bertentangan dengan role lain milik user (pemisahan tugas)",
+		RoleNotManageable:         "Anda tidak berwenang mengelola role ini",
+		PrivilegeEscalation:       "Role memberikan permission yang tidak Anda miliki",
+		RoleHierarchyTooDeep:      "Parent role akan membuat hierarki role terlalu dalam",
+
 		// File Storage Service
 		FileStorageError: "Gagal menyimpan file.",
 	}
@@ -251,6 +323,9 @@ func (ec ErrorCode) MessageEN() string {
 		InvalidToken:         "Invalid token",
 		TokenExpired:         "Token expired",
 		RateLimitExceeded:    "Rate limit exceeded",
+		ChallengeRequired:    "Challenge verification required",
+		ChallengeFailed:      "Challenge verification failed",
+		TokenOutdated:        "Permissions have changed, refresh your token",
 
 		// User/Account Errors
 		UsernameExists:     "Username already exists",
@@ -263,6 +338,12 @@ func (ec ErrorCode) MessageEN() string {
 		AccountLocked:      "Your account is locked.",
 		AccountNotVerified: "Your account has not been verified.",
 		PasswordTooWeak:    "Password is too weak.",
+		PhoneExists:        "Phone number already exists",
+		PhoneNotVerified:   "Phone number has not been verified",
+		InvalidOTP:         "Invalid OTP code",
+		OTPExpired:         "OTP code has expired or was not requested",
+		OTPTooManyAttempts: "Too many OTP attempts, please request a new code",
+		AccountNotLinked:   "Email belongs to a local account that is not linked to the directory",
 
 		// Server Errors
 		InternalServerError:  "Internal server error",
@@ -297,6 +378,21 @@ func (ec ErrorCode) MessageEN() string {
 		FileSizeExceeded: "File size exceeds the allowed limit.",
 		InvalidFileType:  "Invalid file type.",
 
+		// RBAC Errors
+		PermissionExists:          "Permission already exists",
+		PermissionNameMismatch:    "Permission name must be resource:action",
+		SystemPermissionProtected: "Built-in permissions cannot be modified or deleted",
+		RoleHierarchyCycle:        "Parent role would create a cycle in the role hierarchy",
+		NotOrganizationMember:     "User is not a member of this organization",
+		InvalidRoleValidity:       "valid_until must be after valid_from and in the future",
+		RoleNotRequestable:        "This role has no approvers and cannot be requested",
+		AccessRequestNotPending:   "Access request has already been resolved",
+		InvalidPolicyDocument:     "RBAC policy document is invalid",
+		SeparationOfDutyViolation: "Role conflicts with another role of the user (separation of duty)",
+		RoleNotManageable:         "You are not allowed to manage this role",
+		PrivilegeEscalation:       "Role grants permissions you do not hold",
+		RoleHierarchyTooDeep:      "Parent role would make the role hierarchy too deep",
+
 		// File Storage Service
 		FileStorageError: "Failed to store file.",
 	}
@@ -13,24 +13,32 @@ func (ec ErrorCode) HTTPStatus() int {
 		InvalidFormat, ValidationFailed:
 		return http.StatusBadRequest
 
-	case InvalidCredentials, Unauthorized, InvalidToken, TokenExpired:
+	case InvalidCredentials, Unauthorized, InvalidToken, TokenExpired, TokenOutdated:
 		return http.StatusUnauthorized
 
-	case Forbidden:
+	case Forbidden, ChallengeFailed, SystemPermissionProtected, NotOrganizationMember,
+		RoleNotManageable, PrivilegeEscalation:
 		return http.StatusForbidden
 
 	case ResourceNotFound, NoDataFound, DataNotFound, AccountNotFound:
 		return http.StatusNotFound
 
-	case Conflict, UsernameExists, EmailExists:
+	case Conflict, UsernameExists, EmailExists, PhoneExists, AccountNotLinked, PermissionExists,
+		AccessRequestNotPending, SeparationOfDutyViolation:
 		return http.StatusConflict
 
-	case InvalidUsername, InvalidEmail, PasswordMismatch, AccountInactive:
+	case InvalidUsername, InvalidEmail, PasswordMismatch, AccountInactive,
+		PhoneNotVerified, InvalidOTP, OTPExpired, PermissionNameMismatch,
+		RoleHierarchyCycle, RoleHierarchyTooDeep, InvalidRoleValidity, RoleNotRequestable,
+		InvalidPolicyDocument:
 		return http.StatusUnprocessableEntity
 
-	case RateLimitExceeded:
+	case RateLimitExceeded, OTPTooManyAttempts:
 		return http.StatusTooManyRequests
 
+	case ChallengeRequired:
+		return http.StatusPreconditionRequired
+
 	// Server Errors (500-599)
 	case InternalServerError, DatabaseError, CacheError, ConfigurationError,
 		DatabaseConnectionFailed, DatabaseQueryFailed, DatabaseInsertFailed,
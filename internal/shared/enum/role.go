@@ -1,21 +0,0 @@
-package enum
-
-type UserRole string
-
-const (
-	UserRoleAdmin UserRole = "admin"
-	UserRoleUser  UserRole = "user"
-)
-
-func (r UserRole) String() string {
-	return string(r)
-}
-
-func (r UserRole) IsValid() bool {
-	switch r {
-	case UserRoleAdmin, UserRoleUser:
-		return true
-	default:
-		return false
-	}
-}
@@ -26,6 +26,9 @@ const (
 	InvalidToken         = enum.InvalidToken
 	TokenExpired         = enum.TokenExpired
 	RateLimitExceeded    = enum.RateLimitExceeded
+	ChallengeRequired    = enum.ChallengeRequired
+	ChallengeFailed      = enum.ChallengeFailed
+	TokenOutdated        = enum.TokenOutdated
 
 	// User/Account Errors
 	UsernameExists     = enum.UsernameExists
@@ -38,11 +41,32 @@ const (
 	AccountLocked      = enum.AccountLocked
 	AccountNotVerified = enum.AccountNotVerified
 	PasswordTooWeak    = enum.PasswordTooWeak
+	PhoneExists        = enum.PhoneExists
+	PhoneNotVerified   = enum.PhoneNotVerified
+	InvalidOTP         = enum.InvalidOTP
+	OTPExpired         = enum.OTPExpired
+	OTPTooManyAttempts = enum.OTPTooManyAttempts
+	AccountNotLinked   = enum.AccountNotLinked
 
 	// File Handling Errors
 	FileSizeExceeded = enum.FileSizeExceeded
 	InvalidFileType  = enum.InvalidFileType
 
+	// RBAC Errors
+	PermissionExists          = enum.PermissionExists
+	PermissionNameMismatch    = enum.PermissionNameMismatch
+	SystemPermissionProtected = enum.SystemPermissionProtected
+	RoleHierarchyCycle        = enum.RoleHierarchyCycle
+	NotOrganizationMember     = enum.NotOrganizationMember
+	InvalidRoleValidity       = enum.InvalidRoleValidity
+	RoleNotRequestable        = enum.RoleNotRequestable
+	AccessRequestNotPending   = enum.AccessRequestNotPending
+	InvalidPolicyDocument     = enum.InvalidPolicyDocument
+	SeparationOfDutyViolation = enum.SeparationOfDutyViolation
+	RoleNotManageable         = enum.RoleNotManageable
+	PrivilegeEscalation       = enum.PrivilegeEscalation
+	RoleHierarchyTooDeep      = enum.RoleHierarchyTooDeep
+
 	// Server Errors
 	InternalServerError  = enum.InternalServerError
 	DatabaseError        = enum.DatabaseError
@@ -0,0 +1,85 @@
+// Package geoip resolves IP addresses to geographic locations using a local
+// MaxMind-format (mmdb) database such as GeoLite2-City or GeoLite2-Country.
+package geoip
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Location is the geographic information resolved for an IP address
+type Location struct {
+	CountryCode string `json:"country_code,omitempty"`
+	Country     string `json:"country,omitempty"`
+	City        string `json:"city,omitempty"`
+}
+
+// Locator resolves IP addresses to locations
+type Locator interface {
+	Lookup(ip string) (*Location, error)
+	Close() error
+}
+
+// record mirrors the subset of the GeoLite2/GeoIP2 schema we read
+type record struct {
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Country struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+}
+
+// New opens the MaxMind-format database at path.
+// An empty path returns a locator that never resolves anything, so geo lookup stays optional.
+func New(path string) (Locator, error) {
+	if path == "" {
+		return noopLocator{}, nil
+	}
+
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open geoip database: %w", err)
+	}
+
+	return &maxMindLocator{reader: reader}, nil
+}
+
+type maxMindLocator struct {
+	reader *maxminddb.Reader
+}
+
+func (l *maxMindLocator) Lookup(ip string) (*Location, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return nil, fmt.Errorf("invalid ip address: %q", ip)
+	}
+
+	var rec record
+	if err := l.reader.Lookup(parsed, &rec); err != nil {
+		return nil, err
+	}
+
+	return &Location{
+		CountryCode: rec.Country.ISOCode,
+		Country:     rec.Country.Names["en"],
+		City:        rec.City.Names["en"],
+	}, nil
+}
+
+func (l *maxMindLocator) Close() error {
+	return l.reader.Close()
+}
+
+type noopLocator struct{}
+
+func (noopLocator) Lookup(string) (*Location, error) {
+	return &Location{}, nil
+}
+
+func (noopLocator) Close() error {
+	return nil
+}
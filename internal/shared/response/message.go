@@ -18,6 +18,30 @@ var (
 		ID: "Token berhasil diperbarui",
 		EN: "Token refreshed successfully",
 	}
+	MsgOrganizationSwitched = BilingualMessage{
+		ID: "Organisasi aktif berhasil diganti",
+		EN: "Active organization switched successfully",
+	}
+	MsgChallengeIssued = BilingualMessage{
+		ID: "Tantangan berhasil dibuat",
+		EN: "Challenge issued successfully",
+	}
+	MsgOTPSent = BilingualMessage{
+		ID: "Kode verifikasi telah dikirim",
+		EN: "Verification code has been sent",
+	}
+	MsgPhoneVerified = BilingualMessage{
+		ID: "Nomor telepon berhasil diverifikasi",
+		EN: "Phone number verified successfully",
+	}
+	MsgUsernameAvailability = BilingualMessage{
+		ID: "Ketersediaan username berhasil diperiksa",
+		EN: "Username availability checked successfully",
+	}
+	MsgLoginHistoryRetrieve = BilingualMessage{
+		ID: "Riwayat login berhasil diambil",
+		EN: "Login history retrieved successfully",
+	}
 
 	// Profile messages
 	MsgProfileRetrieve = BilingualMessage{
@@ -0,0 +1,239 @@
+package response
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// FieldTag is the struct tag restricting who sees a response field. Its value lists permissions,
+// any of which makes the field visible; "self" also shows it to the record's owner, whose user ID
+// is the field tagged "owner". Hidden fields are zeroed, so restricted fields must be omitempty.
+//
+//	ID    string `json:"id" authz:"owner"`
+//	Email string `json:"email,omitempty" authz:"self,users:read"`
+const FieldTag = "authz"
+
+const (
+	fieldOwner = "owner"
+	fieldSelf  = "self"
+)
+
+// Viewer is the caller a response is redacted for
+type Viewer interface {
+	UserID() string
+	HasPermission(permission string) bool
+}
+
+var viewerResolver func(c *fiber.Ctx) Viewer
+
+// SetViewerResolver installs the hook resolving the caller of a request; success responses are
+// redacted for the viewer it returns, and not at all when it returns nil
+func SetViewerResolver(resolve func(c *fiber.Ctx) Viewer) {
+	viewerResolver = resolve
+}
+
+// redactFor redacts data for the caller of c when a viewer resolver is installed
+func redactFor(c *fiber.Ctx, data interface{}) interface{} {
+	if viewerResolver == nil || data == nil || !restricted(reflect.TypeOf(data)) {
+		return data
+	}
+	viewer := viewerResolver(c)
+	if viewer == nil {
+		return data
+	}
+	return Redact(data, viewer)
+}
+
+// Redact returns a copy of data with the fields the viewer may not see zeroed, looking into
+// nested structs, pointers, slices, arrays and maps. Data without restricted fields is returned
+// as is.
+func Redact(data interface{}, viewer Viewer) interface{} {
+	if data == nil || !restricted(reflect.TypeOf(data)) {
+		return data
+	}
+	return redactValue(reflect.ValueOf(data), viewer).Interface()
+}
+
+// fieldRule is a restricted field and the permissions that reveal it
+type fieldRule struct {
+	index       int
+	self        bool
+	permissions []string
+}
+
+// structPlan describes how to redact one struct type
+type structPlan struct {
+	owner  int // index of the owner field, or -1
+	rules  []fieldRule
+	nested []int // exported fields whose types contain restricted fields
+}
+
+var (
+	plans        sync.Map // reflect.Type -> *structPlan
+	restrictions sync.Map // reflect.Type -> bool
+)
+
+// restricted reports whether values of the type can contain restricted fields
+func restricted(t reflect.Type) bool {
+	if cached, ok := restrictions.Load(t); ok {
+		return cached.(bool)
+	}
+	result := containsRestricted(t, make(map[reflect.Type]bool))
+	restrictions.Store(t, result)
+	return result
+}
+
+func containsRestricted(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return containsRestricted(t.Elem(), visiting)
+	case reflect.Struct:
+		if visiting[t] {
+			return false
+		}
+		visiting[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() && !field.Anonymous {
+				continue
+			}
+			if _, ok := field.Tag.Lookup(FieldTag); ok || containsRestricted(field.Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// planFor parses the struct's tags once; misconfigured tags are programming errors and panic
+func planFor(t reflect.Type) *structPlan {
+	if cached, ok := plans.Load(t); ok {
+		return cached.(*structPlan)
+	}
+
+	plan := &structPlan{owner: -1}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			// JSON promotes the fields of unexported embedded structs, but they cannot be redacted
+			if field.Anonymous && containsRestricted(field.Type, make(map[reflect.Type]bool)) {
+				panic(fmt.Sprintf("response: embedded %s in %s must be exported", field.Type.Name(), t.Name()))
+			}
+			continue
+		}
+
+		tag, ok := field.Tag.Lookup(FieldTag)
+		switch {
+		case !ok:
+			if restricted(field.Type) {
+				plan.nested = append(plan.nested, i)
+			}
+		case tag == fieldOwner:
+			if field.Type.Kind() != reflect.String {
+				panic(fmt.Sprintf("response: owner field %s.%s must be a string", t.Name(), field.Name))
+			}
+			plan.owner = i
+		default:
+			if !strings.Contains(field.Tag.Get("json"), ",omitempty") {
+				panic(fmt.Sprintf("response: restricted field %s.%s must be omitempty", t.Name(), field.Name))
+			}
+			rule := fieldRule{index: i}
+			for _, name := range strings.Split(tag, ",") {
+				if name = strings.TrimSpace(name); name == fieldSelf {
+					rule.self = true
+				} else if name != "" {
+					rule.permissions = append(rule.permissions, name)
+				}
+			}
+			plan.rules = append(plan.rules, rule)
+		}
+	}
+
+	for _, rule := range plan.rules {
+		if rule.self && plan.owner < 0 {
+			panic(fmt.Sprintf("response: %s has self fields but no owner field", t.Name()))
+		}
+	}
+
+	plans.Store(t, plan)
+	return plan
+}
+
+func redactValue(v reflect.Value, viewer Viewer) reflect.Value {
+	if !restricted(v.Type()) {
+		return v
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type().Elem())
+		copied.Elem().Set(redactValue(v.Elem(), viewer))
+		return copied
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(redactValue(v.Index(i), viewer))
+		}
+		return copied
+
+	case reflect.Array:
+		copied := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(redactValue(v.Index(i), viewer))
+		}
+		return copied
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), redactValue(iter.Value(), viewer))
+		}
+		return copied
+
+	case reflect.Struct:
+		plan := planFor(v.Type())
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+
+		isOwner := plan.owner >= 0 && v.Field(plan.owner).String() == viewer.UserID()
+		for _, rule := range plan.rules {
+			if !visible(rule, isOwner, viewer) {
+				field := copied.Field(rule.index)
+				field.Set(reflect.Zero(field.Type()))
+			}
+		}
+		for _, i := range plan.nested {
+			copied.Field(i).Set(redactValue(v.Field(i), viewer))
+		}
+		return copied
+	}
+
+	return v
+}
+
+func visible(rule fieldRule, isOwner bool, viewer Viewer) bool {
+	if rule.self && isOwner {
+		return true
+	}
+	for _, permission := range rule.permissions {
+		if viewer.HasPermission(permission) {
+			return true
+		}
+	}
+	return false
+}
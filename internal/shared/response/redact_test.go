@@ -0,0 +1,157 @@
+package response
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Profile is exported so profileDetail can embed it
+type Profile struct {
+	ID    string `json:"id" authz:"owner"`
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty" authz:"self,users:read"`
+	Notes string `json:"notes,omitempty" authz:"audit:read"`
+}
+
+type profileDetail struct {
+	Profile
+	Manager  *Profile           `json:"manager,omitempty"`
+	Reports  []Profile          `json:"reports"`
+	ByTeam   map[string]Profile `json:"by_team"`
+	Internal Profile            `json:"-"`
+}
+
+type plain struct {
+	Name string `json:"name"`
+}
+
+// staticViewer holds a fixed set of permissions
+type staticViewer struct {
+	id          string
+	permissions map[string]bool
+}
+
+func (v staticViewer) UserID() string                       { return v.id }
+func (v staticViewer) HasPermission(permission string) bool { return v.permissions[permission] }
+
+func TestRedact(t *testing.T) {
+	alice := Profile{ID: "alice", Name: "Alice", Email: "alice@example.com", Notes: "hired 2020"}
+	bob := Profile{ID: "bob", Name: "Bob", Email: "bob@example.com", Notes: "on leave"}
+
+	tests := []struct {
+		name      string
+		viewer    staticViewer
+		wantEmail string
+		wantNotes string
+	}{
+		{"owner sees self fields", staticViewer{id: "alice"}, "alice@example.com", ""},
+		{"permission reveals fields", staticViewer{id: "carol", permissions: map[string]bool{"users:read": true, "audit:read": true}}, "alice@example.com", "hired 2020"},
+		{"others see neither", staticViewer{id: "carol"}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Redact(alice, tt.viewer).(Profile)
+			if got.Name != "Alice" || got.Email != tt.wantEmail || got.Notes != tt.wantNotes {
+				t.Errorf("unexpected redaction: %+v", got)
+			}
+		})
+	}
+
+	// Nested values are redacted per record, the original is left untouched
+	detail := &profileDetail{
+		Profile: alice,
+		Manager: &bob,
+		Reports: []Profile{bob},
+		ByTeam:  map[string]Profile{"ops": bob},
+	}
+	got := Redact(detail, staticViewer{id: "bob"}).(*profileDetail)
+	if got.Email != "" || got.Manager.Email != "bob@example.com" || got.Reports[0].Notes != "" || got.ByTeam["ops"].Email != "bob@example.com" {
+		t.Errorf("unexpected nested redaction: %+v", got)
+	}
+	if detail.Email != "alice@example.com" || bob.Notes != "on leave" {
+		t.Error("expected the original data to be unchanged")
+	}
+
+	// Data without restricted fields is returned as is
+	unrestricted := &plain{Name: "x"}
+	if Redact(unrestricted, staticViewer{}) != unrestricted {
+		t.Error("expected unrestricted data to be returned as is")
+	}
+}
+
+func TestRedact_MisconfiguredTags(t *testing.T) {
+	type notOmitted struct {
+		Email string `json:"email" authz:"users:read"`
+	}
+	type noOwner struct {
+		Email string `json:"email,omitempty" authz:"self"`
+	}
+	type hiddenEmbed struct {
+		noOwner
+	}
+
+	for name, data := range map[string]interface{}{
+		"not omitempty":      notOmitted{},
+		"self without owner": noOwner{},
+		"unexported embed":   hiddenEmbed{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected a panic")
+				}
+			}()
+			Redact(data, staticViewer{})
+		})
+	}
+}
+
+func TestCreateSuccessResponse_Redacts(t *testing.T) {
+	SetViewerResolver(func(c *fiber.Ctx) Viewer {
+		if id := c.Get("X-User"); id != "" {
+			return staticViewer{id: id}
+		}
+		return nil
+	})
+	defer SetViewerResolver(nil)
+
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		data := []Profile{{ID: "alice", Name: "Alice", Email: "alice@example.com"}}
+		return c.JSON(CreateSuccessResponse(c, "ok", "ok", data))
+	})
+
+	tests := []struct {
+		user      string
+		wantEmail bool
+	}{
+		{"alice", true},
+		{"bob", false},
+		{"", true}, // no viewer, nothing redacted
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-User", tt.user)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("failed to execute request: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+
+		var decoded struct {
+			Data []map[string]interface{} `json:"data"`
+		}
+		if err := json.Unmarshal(body, &decoded); err != nil {
+			t.Fatalf("invalid response %s: %v", body, err)
+		}
+		_, hasEmail := decoded.Data[0]["email"]
+		if hasEmail != tt.wantEmail || !strings.Contains(string(body), `"name":"Alice"`) {
+			t.Errorf("viewer %q: unexpected response %s", tt.user, body)
+		}
+	}
+}
@@ -93,6 +93,7 @@ func CreateErrorResponse(c *fiber.Ctx, err errors.AppError) BaseResponse {
 	return resp
 }
 
+// CreateSuccessResponse wraps data, with the fields the caller may not see removed (see FieldTag)
 func CreateSuccessResponse(c *fiber.Ctx, messageID, messageEN string, data interface{}, statusCode ...int) BaseResponse {
 	lang := getLanguageFromHeader(c)
 	code := fiber.StatusOK
@@ -104,11 +105,12 @@ func CreateSuccessResponse(c *fiber.Ctx, messageID, messageEN string, data inter
 		Status:    true,
 		Code:      code,
 		Message:   getMessageByLanguage(messageID, messageEN, lang),
-		Data:      data,
+		Data:      redactFor(c, data),
 		Timestamp: time.Now(),
 	}
 }
 
+// CreatePaginatedResponse wraps a page of data, redacted like CreateSuccessResponse
 func CreatePaginatedResponse(c *fiber.Ctx, messageID, messageEN string, data interface{}, meta *MetaResponse, statusCode ...int) BaseResponse {
 	lang := getLanguageFromHeader(c)
 	code := fiber.StatusOK
@@ -120,8 +122,25 @@ func CreatePaginatedResponse(c *fiber.Ctx, messageID, messageEN string, data int
 		Status:    true,
 		Code:      code,
 		Message:   getMessageByLanguage(messageID, messageEN, lang),
-		Data:      data,
+		Data:      redactFor(c, data),
 		Meta:      meta,
 		Timestamp: time.Now(),
 	}
 }
+
+// NewMetaResponse builds pagination metadata for the given page, page size and total item count
+func NewMetaResponse(page, pageSize, total int64) *MetaResponse {
+	totalPage := int64(0)
+	if pageSize > 0 {
+		totalPage = (total + pageSize - 1) / pageSize
+	}
+
+	return &MetaResponse{
+		Page:      page,
+		PageSize:  pageSize,
+		Total:     total,
+		TotalPage: totalPage,
+		IsNext:    page < totalPage,
+		IsBack:    page > 1,
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"boilerplate-be/internal/database"
-	"boilerplate-be/internal/shared/enum"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
@@ -16,16 +15,54 @@ type JWTManager struct {
 	secretKey     string
 	expiry        time.Duration
 	refreshExpiry time.Duration
+
+	// Set by EmbedAuthorization; nil keeps access tokens free of roles and permissions
+	authorization AuthorizationSource
+	versions      PermissionVersionSource
+}
+
+// AuthorizationSource resolves the roles, permissions and denied permissions embedded in access
+// tokens, e.g. rbac.RBACUseCase
+type AuthorizationSource interface {
+	TokenAuthorization(userID, organizationID string) (roles []string, permissions []string, denies []string, err error)
+}
+
+// PermissionVersionSource reports the user's current permission version, e.g. PermissionVersions
+type PermissionVersionSource interface {
+	Current(userID string) (int64, error)
 }
 
+// Claims carry no role of their own: roles and permissions come from RBAC, either embedded
+// below or looked up per request
 type Claims struct {
-	UserID    string        `json:"user_id"`
-	Email     string        `json:"email"`
-	Role      enum.UserRole `json:"role"`
-	TokenType string        `json:"token_type"` // "access" or "refresh"
+	UserID    string `json:"user_id"`
+	Email     string `json:"email"`
+	TokenType string `json:"token_type"` // "access" or "refresh"
+
+	// OrganizationID is the active tenant; empty means no tenant is selected
+	OrganizationID string `json:"org_id,omitempty"`
+
+	// Set on access tokens when authorization is embedded. Roles are the user's global roles;
+	// Permissions and Denies cover the global roles plus those held in OrganizationID, and
+	// Denies override Permissions.
+	EmbedsAuthorization bool     `json:"authz,omitempty"`
+	Roles               []string `json:"roles,omitempty"`
+	Permissions         []string `json:"perms,omitempty"`
+	Denies              []string `json:"deny,omitempty"`
+	PermissionVersion   int64    `json:"pv,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// TokenOption sets optional claims on generated tokens
+type TokenOption func(*Claims)
+
+// WithOrganization scopes the token to an organization
+func WithOrganization(organizationID string) TokenOption {
+	return func(c *Claims) {
+		c.OrganizationID = organizationID
+	}
+}
+
 func NewJWTManager(secretKey string, expiry time.Duration) *JWTManager {
 	return &JWTManager{
 		secretKey:     secretKey,
@@ -38,15 +75,22 @@ func (j *JWTManager) SetRefreshExpiry(expiry time.Duration) {
 	j.refreshExpiry = expiry
 }
 
-func (j *JWTManager) GenerateTokenPair(userID string, email string, role enum.UserRole) (string, string, error) {
+// EmbedAuthorization makes access tokens carry the user's roles, permissions and permission
+// version, so requests can be authorized from the token alone until the version changes
+func (j *JWTManager) EmbedAuthorization(source AuthorizationSource, versions PermissionVersionSource) {
+	j.authorization = source
+	j.versions = versions
+}
+
+func (j *JWTManager) GenerateTokenPair(userID string, email string, opts ...TokenOption) (string, string, error) {
 	// Generate access token
-	accessToken, err := j.generateToken(userID, email, role, "access", j.expiry)
+	accessToken, err := j.generateToken(userID, email, "access", j.expiry, opts...)
 	if err != nil {
 		return "", "", err
 	}
 
 	// Generate refresh token
-	refreshToken, err := j.generateToken(userID, email, role, "refresh", j.refreshExpiry)
+	refreshToken, err := j.generateToken(userID, email, "refresh", j.refreshExpiry, opts...)
 	if err != nil {
 		return "", "", err
 	}
@@ -54,15 +98,14 @@ func (j *JWTManager) GenerateTokenPair(userID string, email string, role enum.Us
 	return accessToken, refreshToken, nil
 }
 
-func (j *JWTManager) GenerateToken(userID string, email string, role enum.UserRole) (string, error) {
-	return j.generateToken(userID, email, role, "access", j.expiry)
+func (j *JWTManager) GenerateToken(userID string, email string, opts ...TokenOption) (string, error) {
+	return j.generateToken(userID, email, "access", j.expiry, opts...)
 }
 
-func (j *JWTManager) generateToken(userID string, email string, role enum.UserRole, tokenType string, expiry time.Duration) (string, error) {
+func (j *JWTManager) generateToken(userID string, email string, tokenType string, expiry time.Duration, opts ...TokenOption) (string, error) {
 	claims := &Claims{
 		UserID:    userID,
 		Email:     email,
-		Role:      role,
 		TokenType: tokenType,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
@@ -71,11 +114,55 @@ func (j *JWTManager) generateToken(userID string, email string, role enum.UserRo
 			ID:        uuid.New().String(),
 		},
 	}
+	for _, opt := range opts {
+		opt(claims)
+	}
+
+	if tokenType == "access" && j.authorization != nil {
+		if err := j.embedAuthorization(claims); err != nil {
+			return "", err
+		}
+	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(j.secretKey))
 }
 
+// embedAuthorization reads the version before the permissions, so a change made in between
+// leaves the token outdated rather than silently missing the change
+func (j *JWTManager) embedAuthorization(claims *Claims) error {
+	version, err := j.versions.Current(claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	roles, permissions, denies, err := j.authorization.TokenAuthorization(claims.UserID, claims.OrganizationID)
+	if err != nil {
+		return err
+	}
+
+	claims.EmbedsAuthorization = true
+	claims.Roles = roles
+	claims.Permissions = permissions
+	claims.Denies = denies
+	claims.PermissionVersion = version
+	return nil
+}
+
+// IsAuthorizationCurrent reports whether the permission version embedded in the claims is still
+// the user's current one; claims without embedded authorization are always current
+func (j *JWTManager) IsAuthorizationCurrent(claims *Claims) (bool, error) {
+	if !claims.EmbedsAuthorization {
+		return true, nil
+	}
+
+	version, err := j.versions.Current(claims.UserID)
+	if err != nil {
+		return false, err
+	}
+	return version == claims.PermissionVersion, nil
+}
+
 func (j *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 	claims := &Claims{}
 
@@ -91,6 +178,14 @@ func (j *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 		return nil, jwt.ErrTokenInvalidClaims
 	}
 
+	// Embedded authorization cannot be kept current once embedding is turned off
+	if j.authorization == nil {
+		claims.EmbedsAuthorization = false
+		claims.Roles = nil
+		claims.Permissions = nil
+		claims.Denies = nil
+	}
+
 	return claims, nil
 }
 
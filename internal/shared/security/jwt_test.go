@@ -3,8 +3,6 @@ package security
 import (
 	"testing"
 	"time"
-
-	"boilerplate-be/internal/shared/enum"
 )
 
 func TestJWTManager_GenerateToken(t *testing.T) {
@@ -14,35 +12,31 @@ func TestJWTManager_GenerateToken(t *testing.T) {
 		name    string
 		userID  string
 		email   string
-		role    enum.UserRole
 		wantErr bool
 	}{
 		{
 			name:    "Generate valid token",
 			userID:  "user-123",
 			email:   "test@example.com",
-			role:    enum.UserRoleUser,
 			wantErr: false,
 		},
 		{
-			name:    "Generate token with admin role",
+			name:    "Generate token for another user",
 			userID:  "admin-123",
 			email:   "admin@example.com",
-			role:    enum.UserRoleAdmin,
 			wantErr: false,
 		},
 		{
 			name:    "Empty user ID",
 			userID:  "",
 			email:   "test@example.com",
-			role:    enum.UserRoleUser,
 			wantErr: false, // JWT allows empty claims
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			token, err := jwtManager.GenerateToken(tt.userID, tt.email, tt.role)
+			token, err := jwtManager.GenerateToken(tt.userID, tt.email)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GenerateToken() error = %v, wantErr %v", err, tt.wantErr)
@@ -59,7 +53,7 @@ func TestJWTManager_GenerateToken(t *testing.T) {
 func TestJWTManager_GenerateTokenPair(t *testing.T) {
 	jwtManager := NewJWTManager("test-secret-key-for-testing-purposes", 24*time.Hour)
 
-	accessToken, refreshToken, err := jwtManager.GenerateTokenPair("user-123", "test@example.com", enum.UserRoleUser)
+	accessToken, refreshToken, err := jwtManager.GenerateTokenPair("user-123", "test@example.com")
 
 	if err != nil {
 		t.Fatalf("GenerateTokenPair() error = %v", err)
@@ -82,7 +76,7 @@ func TestJWTManager_ValidateToken(t *testing.T) {
 	jwtManager := NewJWTManager("test-secret-key-for-testing-purposes", 24*time.Hour)
 
 	// Generate a valid token first
-	validToken, err := jwtManager.GenerateToken("user-123", "test@example.com", enum.UserRoleUser)
+	validToken, err := jwtManager.GenerateToken("user-123", "test@example.com")
 	if err != nil {
 		t.Fatalf("Failed to generate token: %v", err)
 	}
@@ -138,7 +132,7 @@ func TestJWTManager_TokenExpiry(t *testing.T) {
 	// Create JWT manager with very short expiry
 	jwtManager := NewJWTManager("test-secret", 1*time.Millisecond)
 
-	token, err := jwtManager.GenerateToken("user-123", "test@example.com", enum.UserRoleUser)
+	token, err := jwtManager.GenerateToken("user-123", "test@example.com")
 	if err != nil {
 		t.Fatalf("Failed to generate token: %v", err)
 	}
@@ -153,6 +147,66 @@ func TestJWTManager_TokenExpiry(t *testing.T) {
 	}
 }
 
+// staticAuthorization serves fixed roles, permissions and denies and a settable permission version
+type staticAuthorization struct {
+	version int64
+}
+
+func (s *staticAuthorization) TokenAuthorization(userID, organizationID string) ([]string, []string, []string, error) {
+	if organizationID != "" {
+		return []string{"user"}, []string{"orgs:read", "profile:*"}, []string{"profile:delete"}, nil
+	}
+	return []string{"user"}, []string{"profile:*"}, nil, nil
+}
+
+func (s *staticAuthorization) Current(userID string) (int64, error) {
+	return s.version, nil
+}
+
+func TestJWTManager_EmbedAuthorization(t *testing.T) {
+	source := &staticAuthorization{version: 3}
+	jwtManager := NewJWTManager("test-secret", time.Hour)
+	jwtManager.EmbedAuthorization(source, source)
+
+	accessToken, refreshToken, err := jwtManager.GenerateTokenPair("user-123", "test@example.com", WithOrganization("org-a"))
+	if err != nil {
+		t.Fatalf("Failed to generate tokens: %v", err)
+	}
+
+	claims, err := jwtManager.ValidateToken(accessToken)
+	if err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	if !claims.EmbedsAuthorization || claims.PermissionVersion != 3 || len(claims.Roles) != 1 || len(claims.Permissions) != 2 ||
+		len(claims.Denies) != 1 {
+		t.Errorf("expected embedded tenant authorization at version 3, got %+v", claims)
+	}
+
+	// Refresh tokens never carry permissions
+	refreshClaims, _ := jwtManager.ValidateToken(refreshToken)
+	if refreshClaims.EmbedsAuthorization || refreshClaims.Permissions != nil {
+		t.Errorf("expected refresh token without authorization, got %+v", refreshClaims)
+	}
+
+	if current, _ := jwtManager.IsAuthorizationCurrent(claims); !current {
+		t.Error("expected authorization to be current")
+	}
+	source.version++
+	if current, _ := jwtManager.IsAuthorizationCurrent(claims); current {
+		t.Error("expected authorization to be outdated after the version changed")
+	}
+
+	// A manager that no longer embeds ignores what earlier tokens carry
+	plain := NewJWTManager("test-secret", time.Hour)
+	claims, _ = plain.ValidateToken(accessToken)
+	if claims.EmbedsAuthorization || claims.Permissions != nil || claims.Denies != nil {
+		t.Errorf("expected embedded authorization to be dropped, got %+v", claims)
+	}
+	if current, _ := plain.IsAuthorizationCurrent(claims); !current {
+		t.Error("expected claims without authorization to be current")
+	}
+}
+
 func TestHashPassword(t *testing.T) {
 	tests := []struct {
 		name     string
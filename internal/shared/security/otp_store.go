@@ -0,0 +1,172 @@
+package security
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+
+	"boilerplate-be/internal/database"
+	"boilerplate-be/internal/shared/errors"
+)
+
+// OTP purposes; codes issued for one purpose cannot be used for another
+const (
+	OTPPurposePhoneVerification = "phone_verification"
+	OTPPurposeLogin             = "login"
+)
+
+// OTPManager issues one-time codes and verifies them with a per-code attempt limit.
+// Only a hash of each code is stored in Redis.
+type OTPManager struct {
+	*database.RedisHelper
+	keyPrefix      string
+	length         int
+	ttl            time.Duration
+	maxAttempts    int
+	resendInterval time.Duration
+}
+
+type OTPManagerConfig struct {
+	KeyPrefix      string
+	Length         int
+	TTL            time.Duration
+	MaxAttempts    int
+	ResendInterval time.Duration
+}
+
+func NewOTPManager(client *database.RedisClient) *OTPManager {
+	return NewOTPManagerWithConfig(client, OTPManagerConfig{
+		KeyPrefix:      "otp",
+		Length:         6,
+		TTL:            5 * time.Minute,
+		MaxAttempts:    5,
+		ResendInterval: time.Minute,
+	})
+}
+
+func NewOTPManagerWithConfig(client *database.RedisClient, config OTPManagerConfig) *OTPManager {
+	return &OTPManager{
+		RedisHelper:    database.NewRedisHelper(client),
+		keyPrefix:      config.KeyPrefix,
+		length:         config.Length,
+		ttl:            config.TTL,
+		maxAttempts:    config.MaxAttempts,
+		resendInterval: config.ResendInterval,
+	}
+}
+
+// Generate issues a new code for subject, replacing any previous one and resetting its attempts.
+// A new code cannot be requested again within the resend interval.
+func (om *OTPManager) Generate(purpose, subject string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cooldownKey := om.buildKey("cooldown", purpose, subject)
+	if om.resendInterval > 0 {
+		waiting, err := om.Exists(ctx, cooldownKey)
+		if err != nil {
+			return "", err
+		}
+		if waiting {
+			return "", errors.New(errors.RateLimitExceeded)
+		}
+	}
+
+	code, err := generateNumericCode(om.length)
+	if err != nil {
+		return "", errors.Wrap(err, errors.TokenGenerationFailed)
+	}
+
+	if err := om.Delete(ctx, om.buildKey("attempts", purpose, subject)); err != nil {
+		return "", err
+	}
+	if err := om.SetWithTTL(ctx, om.buildKey("code", purpose, subject), hashOTP(subject, code), om.ttl); err != nil {
+		return "", err
+	}
+	if om.resendInterval > 0 {
+		if err := om.SetWithTTL(ctx, cooldownKey, "1", om.resendInterval); err != nil {
+			return "", err
+		}
+	}
+
+	return code, nil
+}
+
+// Verify checks code against the one issued for subject. A correct code is consumed atomically,
+// so concurrent requests cannot redeem it twice; once the attempt limit is reached the code is
+// discarded and a new one must be requested.
+func (om *OTPManager) Verify(purpose, subject, code string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	codeKey := om.buildKey("code", purpose, subject)
+	attemptsKey := om.buildKey("attempts", purpose, subject)
+
+	stored, err := om.Get(ctx, codeKey)
+	if err != nil {
+		return err
+	}
+	if stored == "" {
+		return errors.New(errors.OTPExpired)
+	}
+
+	attempts, err := om.Increment(ctx, attemptsKey)
+	if err != nil {
+		return err
+	}
+	if attempts == 1 {
+		if err := om.Expire(ctx, attemptsKey, om.ttl); err != nil {
+			return err
+		}
+	}
+	if int(attempts) > om.maxAttempts {
+		if err := om.Delete(ctx, codeKey, attemptsKey); err != nil {
+			return err
+		}
+		return errors.New(errors.OTPTooManyAttempts)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(stored), []byte(hashOTP(subject, code))) != 1 {
+		return errors.New(errors.InvalidOTP)
+	}
+
+	// Only the request that deletes the code redeems it; a concurrent one already did
+	consumed, err := om.DeleteIfEqual(ctx, codeKey, stored)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return errors.New(errors.OTPExpired)
+	}
+	return om.Delete(ctx, attemptsKey)
+}
+
+func (om *OTPManager) GetTTL() time.Duration {
+	return om.ttl
+}
+
+func (om *OTPManager) buildKey(kind, purpose, subject string) string {
+	return fmt.Sprintf("%s:%s:%s:%s", om.keyPrefix, kind, purpose, subject)
+}
+
+func hashOTP(subject, code string) string {
+	sum := sha256.Sum256([]byte(subject + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateNumericCode(length int) (string, error) {
+	code := make([]byte, length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		code[i] = byte('0' + n.Int64())
+	}
+	return string(code), nil
+}
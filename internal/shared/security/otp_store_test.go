@@ -0,0 +1,118 @@
+package security
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"boilerplate-be/internal/database"
+	"boilerplate-be/internal/shared/enum"
+	"boilerplate-be/internal/shared/errors"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestOTPManager(t *testing.T, config OTPManagerConfig) (*OTPManager, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := &database.RedisClient{Client: redis.NewClient(&redis.Options{Addr: mr.Addr()})}
+	t.Cleanup(func() { client.Close() })
+	return NewOTPManagerWithConfig(client, config), mr
+}
+
+func assertErrorCode(t *testing.T, err error, want enum.ErrorCode) {
+	t.Helper()
+	appErr, ok := errors.IsAppError(err)
+	if !ok || appErr.Code != want {
+		t.Fatalf("expected %s, got %v", want, err)
+	}
+}
+
+func TestOTPManager_GenerateAndVerify(t *testing.T) {
+	otp, _ := newTestOTPManager(t, OTPManagerConfig{
+		KeyPrefix: "otp", Length: 6, TTL: time.Minute, MaxAttempts: 3,
+	})
+
+	code, err := otp.Generate(OTPPurposeLogin, "+6281234567890")
+	if err != nil {
+		t.Fatalf("failed to generate code: %v", err)
+	}
+	if len(code) != 6 {
+		t.Errorf("expected 6 digit code, got %q", code)
+	}
+
+	t.Run("wrong purpose is rejected", func(t *testing.T) {
+		assertErrorCode(t, otp.Verify(OTPPurposePhoneVerification, "+6281234567890", code), errors.OTPExpired)
+	})
+
+	t.Run("correct code is accepted once", func(t *testing.T) {
+		if err := otp.Verify(OTPPurposeLogin, "+6281234567890", code); err != nil {
+			t.Fatalf("expected code to verify, got %v", err)
+		}
+		assertErrorCode(t, otp.Verify(OTPPurposeLogin, "+6281234567890", code), errors.OTPExpired)
+	})
+}
+
+func TestOTPManager_ConcurrentVerifyRedeemsOnce(t *testing.T) {
+	// A high attempt limit, so only redemption decides the outcome
+	otp, _ := newTestOTPManager(t, OTPManagerConfig{
+		KeyPrefix: "otp", Length: 6, TTL: time.Minute, MaxAttempts: 100,
+	})
+	code, _ := otp.Generate(OTPPurposeLogin, "+6281234567890")
+
+	var wg sync.WaitGroup
+	var passed atomic.Int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := otp.Verify(OTPPurposeLogin, "+6281234567890", code); err == nil {
+				passed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := passed.Load(); got != 1 {
+		t.Errorf("expected exactly one concurrent verification to pass, got %d", got)
+	}
+}
+
+func TestOTPManager_AttemptLimit(t *testing.T) {
+	otp, _ := newTestOTPManager(t, OTPManagerConfig{
+		KeyPrefix: "otp", Length: 6, TTL: time.Minute, MaxAttempts: 2,
+	})
+
+	code, err := otp.Generate(OTPPurposeLogin, "+6281234567890")
+	if err != nil {
+		t.Fatalf("failed to generate code: %v", err)
+	}
+
+	assertErrorCode(t, otp.Verify(OTPPurposeLogin, "+6281234567890", "000000x"), errors.InvalidOTP)
+	assertErrorCode(t, otp.Verify(OTPPurposeLogin, "+6281234567890", "000000x"), errors.InvalidOTP)
+	assertErrorCode(t, otp.Verify(OTPPurposeLogin, "+6281234567890", code), errors.OTPTooManyAttempts)
+	assertErrorCode(t, otp.Verify(OTPPurposeLogin, "+6281234567890", code), errors.OTPExpired)
+}
+
+func TestOTPManager_ExpiryAndResendInterval(t *testing.T) {
+	otp, mr := newTestOTPManager(t, OTPManagerConfig{
+		KeyPrefix: "otp", Length: 6, TTL: time.Minute, MaxAttempts: 3, ResendInterval: 30 * time.Second,
+	})
+
+	code, err := otp.Generate(OTPPurposeLogin, "+6281234567890")
+	if err != nil {
+		t.Fatalf("failed to generate code: %v", err)
+	}
+
+	_, err = otp.Generate(OTPPurposeLogin, "+6281234567890")
+	assertErrorCode(t, err, errors.RateLimitExceeded)
+
+	mr.FastForward(2 * time.Minute)
+	assertErrorCode(t, otp.Verify(OTPPurposeLogin, "+6281234567890", code), errors.OTPExpired)
+
+	if _, err := otp.Generate(OTPPurposeLogin, "+6281234567890"); err != nil {
+		t.Errorf("expected new code after resend interval, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"boilerplate-be/internal/database"
+)
+
+// PermissionVersions keeps a per-user counter in Redis that is bumped whenever the user's roles
+// or permissions may have changed. Access tokens embedding permissions carry the version they
+// were issued at and are rejected once it no longer matches.
+type PermissionVersions struct {
+	*database.RedisHelper
+	keyPrefix string
+	ttl       time.Duration
+}
+
+// NewPermissionVersions creates the store; ttl must be at least the access token expiry and the
+// lifetime of anything cached under a version. A counter is created from the current time in
+// nanoseconds, so one that expired and is created again never repeats an earlier version.
+func NewPermissionVersions(client *database.RedisClient, ttl time.Duration) *PermissionVersions {
+	return &PermissionVersions{
+		RedisHelper: database.NewRedisHelper(client),
+		keyPrefix:   "permission_version",
+		ttl:         ttl,
+	}
+}
+
+// Current returns the user's version; users whose permissions never changed are at 0
+func (v *PermissionVersions) Current(userID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	value, err := v.Get(ctx, v.buildKey(userID))
+	if err != nil || value == "" {
+		return 0, err
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// Bump moves every given user to a new version, outdating their access tokens
+func (v *PermissionVersions) Bump(userIDs ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, userID := range userIDs {
+		key := v.buildKey(userID)
+		base := strconv.FormatInt(time.Now().UnixNano(), 10)
+		if _, err := v.SetIfAbsent(ctx, key, base, v.ttl); err != nil {
+			return err
+		}
+		if _, err := v.Increment(ctx, key); err != nil {
+			return err
+		}
+		if err := v.Expire(ctx, key, v.ttl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *PermissionVersions) buildKey(userID string) string {
+	return fmt.Sprintf("%s:%s", v.keyPrefix, userID)
+}
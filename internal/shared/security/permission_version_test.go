@@ -0,0 +1,44 @@
+package security
+
+import (
+	"testing"
+	"time"
+
+	"boilerplate-be/internal/database"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestPermissionVersions_NeverReusedAfterExpiry(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := &database.RedisClient{Client: redis.NewClient(&redis.Options{Addr: mr.Addr()})}
+	t.Cleanup(func() { client.Close() })
+	versions := NewPermissionVersions(client, time.Hour)
+
+	if version, err := versions.Current("user-1"); err != nil || version != 0 {
+		t.Fatalf("expected version 0 before any change, got %d, %v", version, err)
+	}
+
+	if err := versions.Bump("user-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	old, _ := versions.Current("user-1")
+
+	// The counter expires and is created again by the next change
+	mr.FastForward(2 * time.Hour)
+	if err := versions.Bump("user-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	current, _ := versions.Current("user-1")
+	if current <= old {
+		t.Fatalf("expected a version above %d, got %d", old, current)
+	}
+
+	jwtManager := NewJWTManager("test-secret", time.Hour)
+	jwtManager.EmbedAuthorization(nil, versions)
+	claims := &Claims{UserID: "user-1", EmbedsAuthorization: true, PermissionVersion: old}
+	if ok, err := jwtManager.IsAuthorizationCurrent(claims); err != nil || ok {
+		t.Errorf("expected a token from the expired counter to be outdated, got %v, %v", ok, err)
+	}
+}
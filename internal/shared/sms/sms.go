@@ -0,0 +1,76 @@
+// Package sms delivers text messages through a pluggable gateway.
+// Only development gateways ship here; production providers implement SMSSender.
+package sms
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Supported drivers for New
+const (
+	DriverConsole = "console"
+	DriverFile    = "file"
+)
+
+// SMSSender delivers a text message to an E.164 phone number
+type SMSSender interface {
+	Send(to, message string) error
+}
+
+// New returns the sender for driver. The file driver appends every message to filePath.
+func New(driver, filePath string) (SMSSender, error) {
+	switch driver {
+	case "", DriverConsole:
+		return NewConsoleSender(), nil
+	case DriverFile:
+		if filePath == "" {
+			return nil, fmt.Errorf("sms file driver requires a file path")
+		}
+		return NewFileSender(filePath), nil
+	default:
+		return nil, fmt.Errorf("unknown sms driver: %q", driver)
+	}
+}
+
+// ConsoleSender writes messages to the application log instead of sending them
+type ConsoleSender struct{}
+
+func NewConsoleSender() *ConsoleSender {
+	return &ConsoleSender{}
+}
+
+func (s *ConsoleSender) Send(to, message string) error {
+	log.Printf("sms: to=%s message=%q", to, message)
+	return nil
+}
+
+// FileSender appends messages to a file, one per line, so local tools and tests can read them
+type FileSender struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileSender(path string) *FileSender {
+	return &FileSender{path: path}
+}
+
+func (s *FileSender) Send(to, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open sms file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := fmt.Fprintf(file, "%s\t%s\t%s\n", time.Now().Format(time.RFC3339), to, message); err != nil {
+		return fmt.Errorf("failed to write sms file: %w", err)
+	}
+
+	return nil
+}
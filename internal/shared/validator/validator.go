@@ -3,6 +3,7 @@ package validator
 import (
 	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
@@ -10,6 +11,20 @@ import (
 
 var validate *validator.Validate
 
+// usernamePattern allows letters and digits separated by single '.', '_' or '-', starting with a letter
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9]*(?:[._-][a-zA-Z0-9]+)*$`)
+
+// permissionSegmentPattern matches a permission action or a single resource segment; * is a wildcard
+var permissionSegmentPattern = regexp.MustCompile(`^(\*|[a-z][a-z0-9_-]*)$`)
+
+// slugPattern matches lowercase words of letters and digits joined by single hyphens
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+const (
+	UsernameMinLength = 3
+	UsernameMaxLength = 30
+)
+
 type ValidationMessageConfig struct {
 	ID map[string]string
 	EN map[string]string
@@ -34,6 +49,8 @@ var MessageConfig = &ValidationMessageConfig{
 		"url":       "%s harus berupa URL yang valid",
 		"uuid":      "%s harus berupa UUID yang valid",
 		"datetime":  "%s harus berupa format tanggal yang valid",
+		"required_without": "%s harus diisi jika %s kosong",
+		"e164":      "%s harus berupa nomor telepon format E.164, contoh +6281234567890",
 		"default":   "%s tidak valid",
 	},
 	EN: map[string]string{
@@ -54,6 +71,8 @@ var MessageConfig = &ValidationMessageConfig{
 		"url":       "%s must be a valid URL",
 		"uuid":      "%s must be a valid UUID",
 		"datetime":  "%s must be a valid datetime format",
+		"required_without": "%s is required when %s is empty",
+		"e164":      "%s must be an E.164 phone number, e.g. +6281234567890",
 		"default":   "%s is invalid",
 	},
 }
@@ -80,6 +99,54 @@ func init() {
 		}
 		return name
 	})
+
+	validate.RegisterValidation("username", validateUsername)
+	MessageConfig.ID["username"] = "%s harus 3-30 karakter, diawali huruf, dan hanya berisi huruf, angka, '.', '_' atau '-'"
+	MessageConfig.EN["username"] = "%s must be 3-30 characters, start with a letter, and contain only letters, digits, '.', '_' or '-'"
+
+	validate.RegisterValidation("permission_segment", validatePermissionSegment)
+	MessageConfig.ID["permission_segment"] = "%s harus berupa '*' atau diawali huruf kecil dan hanya berisi huruf kecil, angka, '_' atau '-'"
+	MessageConfig.EN["permission_segment"] = "%s must be '*' or start with a lowercase letter and contain only lowercase letters, digits, '_' or '-'"
+
+	validate.RegisterValidation("permission_resource", validatePermissionResource)
+	MessageConfig.ID["permission_resource"] = "%s harus berupa satu atau lebih segmen dipisah ':', masing-masing '*' atau huruf kecil, angka, '_' atau '-'"
+	MessageConfig.EN["permission_resource"] = "%s must be one or more ':'-separated segments, each '*' or lowercase letters, digits, '_' or '-'"
+
+	validate.RegisterValidation("slug", validateSlug)
+	MessageConfig.ID["slug"] = "%s hanya boleh berisi huruf kecil dan angka yang dipisah satu '-'"
+	MessageConfig.EN["slug"] = "%s must contain only lowercase letters and digits separated by single '-'"
+}
+
+// validateUsername checks the username format: length and allowed characters
+func validateUsername(fl validator.FieldLevel) bool {
+	return IsValidUsername(fl.Field().String())
+}
+
+// IsValidUsername reports whether username satisfies the username format rule
+func IsValidUsername(username string) bool {
+	if len(username) < UsernameMinLength || len(username) > UsernameMaxLength {
+		return false
+	}
+	return usernamePattern.MatchString(username)
+}
+
+// validatePermissionSegment checks a permission action
+func validatePermissionSegment(fl validator.FieldLevel) bool {
+	return permissionSegmentPattern.MatchString(fl.Field().String())
+}
+
+// validatePermissionResource checks a possibly nested permission resource such as orgs:billing
+func validatePermissionResource(fl validator.FieldLevel) bool {
+	for _, segment := range strings.Split(fl.Field().String(), ":") {
+		if !permissionSegmentPattern.MatchString(segment) {
+			return false
+		}
+	}
+	return true
+}
+
+func validateSlug(fl validator.FieldLevel) bool {
+	return slugPattern.MatchString(fl.Field().String())
 }
 
 func ValidateStruct(s interface{}) error {
@@ -236,6 +303,8 @@ func GetValidationMessage(field, tag, param, language string) string {
 		return fmt.Sprintf(template, field, param)
 	case "eqfield":
 		return fmt.Sprintf(template, field, param)
+	case "required_without":
+		return fmt.Sprintf(template, field, strings.ToLower(param))
 	case "oneof":
 		return fmt.Sprintf(template, field, strings.Replace(param, " ", ", ", -1))
 	default:
@@ -206,3 +206,130 @@ func TestValidateUUID(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateUsername(t *testing.T) {
+	type UsernameInput struct {
+		Username string `json:"username" validate:"required,username"`
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		wantErr  bool
+	}{
+		{"Simple", "john", false},
+		{"With digits", "john42", false},
+		{"With separators", "john.doe_99-x", false},
+		{"Mixed case", "JohnDoe", false},
+		{"Too short", "jo", true},
+		{"Too long", "abcdefghijklmnopqrstuvwxyz12345", true},
+		{"Starts with digit", "1john", true},
+		{"Starts with separator", "_john", true},
+		{"Ends with separator", "john.", true},
+		{"Consecutive separators", "john..doe", true},
+		{"Contains space", "john doe", true},
+		{"Contains at sign", "john@doe", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateStruct(UsernameInput{Username: tt.username})
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateStruct(%q) error = %v, wantErr %v", tt.username, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePermissionSegment(t *testing.T) {
+	type PermissionInput struct {
+		Resource string `json:"resource" validate:"required,permission_segment"`
+	}
+
+	tests := []struct {
+		name     string
+		resource string
+		wantErr  bool
+	}{
+		{"Simple", "users", false},
+		{"With underscore", "login_events", false},
+		{"With hyphen", "api-keys", false},
+		{"With digits", "reports2", false},
+		{"Wildcard", "*", false},
+		{"Partial wildcard", "user*", true},
+		{"Uppercase", "Users", true},
+		{"Starts with digit", "2fa", true},
+		{"Contains colon", "users:read", true},
+		{"Contains space", "user roles", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateStruct(PermissionInput{Resource: tt.resource})
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateStruct(%q) error = %v, wantErr %v", tt.resource, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePermissionResource(t *testing.T) {
+	type PermissionInput struct {
+		Resource string `json:"resource" validate:"required,permission_resource"`
+	}
+
+	tests := []struct {
+		name     string
+		resource string
+		wantErr  bool
+	}{
+		{"Single segment", "users", false},
+		{"Nested", "orgs:billing", false},
+		{"Nested wildcard", "orgs:*", false},
+		{"Wildcard", "*", false},
+		{"Empty segment", "orgs::billing", true},
+		{"Trailing separator", "orgs:", true},
+		{"Uppercase segment", "orgs:Billing", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateStruct(PermissionInput{Resource: tt.resource})
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateStruct(%q) error = %v, wantErr %v", tt.resource, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSlug(t *testing.T) {
+	type OrganizationInput struct {
+		Slug string `json:"slug" validate:"required,slug"`
+	}
+
+	tests := []struct {
+		name    string
+		slug    string
+		wantErr bool
+	}{
+		{"Single word", "acme", false},
+		{"Hyphenated", "acme-corp-2", false},
+		{"Uppercase", "Acme", true},
+		{"Leading hyphen", "-acme", true},
+		{"Double hyphen", "acme--corp", true},
+		{"Underscore", "acme_corp", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateStruct(OrganizationInput{Slug: tt.slug})
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateStruct(%q) error = %v, wantErr %v", tt.slug, err, tt.wantErr)
+			}
+		})
+	}
+}
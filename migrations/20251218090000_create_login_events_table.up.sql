@@ -0,0 +1,21 @@
+-- Login history: every login attempt, successful or not
+CREATE TABLE IF NOT EXISTS login_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    identifier VARCHAR(255) NOT NULL,
+    method VARCHAR(20) NOT NULL,
+    success BOOLEAN NOT NULL,
+    failure_reason VARCHAR(50),
+    ip_address INET,
+    user_agent TEXT,
+    country_code CHAR(2),
+    country VARCHAR(100),
+    city VARCHAR(100),
+    is_suspicious BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_events_user_id_created_at ON login_events(user_id, created_at DESC);
+
+-- Used to detect logins from a never-seen IP/user-agent pair
+CREATE INDEX IF NOT EXISTS idx_login_events_source ON login_events(user_id, ip_address, user_agent) WHERE success;
@@ -0,0 +1,4 @@
+-- Optional username, unique regardless of case
+ALTER TABLE users ADD COLUMN IF NOT EXISTS username VARCHAR(30);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username_lower ON users(LOWER(username));
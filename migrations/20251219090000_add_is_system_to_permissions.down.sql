@@ -0,0 +1 @@
+ALTER TABLE permissions DROP COLUMN IF EXISTS is_system;
@@ -0,0 +1,10 @@
+-- Built-in permissions are referenced by code and cannot be changed through the API
+ALTER TABLE permissions ADD COLUMN IF NOT EXISTS is_system BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE permissions SET is_system = TRUE
+WHERE name IN (
+    'users:read', 'users:write', 'users:delete',
+    'roles:read', 'roles:write', 'roles:delete',
+    'permissions:read', 'permissions:assign',
+    'profile:read', 'profile:write'
+);
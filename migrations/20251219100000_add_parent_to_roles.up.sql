@@ -0,0 +1,11 @@
+-- A role inherits every permission granted to its ancestors
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES roles(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_roles_parent_id ON roles(parent_id);
+
+-- Default hierarchy: super_admin -> admin -> user
+UPDATE roles SET parent_id = (SELECT id FROM roles WHERE name = 'user')
+WHERE name = 'admin' AND parent_id IS NULL;
+
+UPDATE roles SET parent_id = (SELECT id FROM roles WHERE name = 'admin')
+WHERE name = 'super_admin' AND parent_id IS NULL;
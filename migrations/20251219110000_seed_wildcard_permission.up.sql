@@ -0,0 +1,9 @@
+-- *:* matches every permission, so super_admin covers permissions created after this migration
+INSERT INTO permissions (name, resource, action, description, is_system) VALUES
+    ('*:*', '*', '*', 'Every action on every resource', TRUE)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name = 'super_admin' AND p.name = '*:*'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,14 @@
+DELETE FROM roles WHERE name = 'org_admin';
+DELETE FROM permissions WHERE resource IN ('orgs', 'orgs:members', 'orgs:roles');
+
+-- Tenant-scoped assignments cannot be represented without organization_id
+DELETE FROM user_roles WHERE organization_id IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_user_roles_tenant;
+DROP INDEX IF EXISTS idx_user_roles_global;
+ALTER TABLE user_roles DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE user_roles ADD PRIMARY KEY (user_id, role_id);
+
+DROP TABLE IF EXISTS organization_members;
+DROP TRIGGER IF EXISTS update_organizations_updated_at ON organizations;
+DROP TABLE IF EXISTS organizations;
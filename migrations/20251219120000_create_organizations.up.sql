@@ -0,0 +1,49 @@
+-- Organizations (tenants) own their members and tenant-scoped role assignments
+CREATE TABLE IF NOT EXISTS organizations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    slug VARCHAR(100) UNIQUE NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_organizations_updated_at
+    BEFORE UPDATE ON organizations
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS organization_members (
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_members_user_id ON organization_members(user_id);
+
+-- A NULL organization_id is a global assignment; otherwise the role applies only inside that tenant
+ALTER TABLE user_roles ADD COLUMN IF NOT EXISTS organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE;
+ALTER TABLE user_roles DROP CONSTRAINT IF EXISTS user_roles_pkey;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_roles_global ON user_roles(user_id, role_id) WHERE organization_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_roles_tenant ON user_roles(organization_id, user_id, role_id) WHERE organization_id IS NOT NULL;
+
+-- Tenant administration permissions, granted inside a tenant through org_admin
+INSERT INTO permissions (name, resource, action, description, is_system) VALUES
+    ('orgs:read', 'orgs', 'read', 'View organization details', TRUE),
+    ('orgs:update', 'orgs', 'update', 'Rename an organization', TRUE),
+    ('orgs:delete', 'orgs', 'delete', 'Delete an organization', TRUE),
+    ('orgs:members:read', 'orgs:members', 'read', 'List organization members', TRUE),
+    ('orgs:members:write', 'orgs:members', 'write', 'Add and remove organization members', TRUE),
+    ('orgs:roles:assign', 'orgs:roles', 'assign', 'Assign roles within an organization', TRUE)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO roles (name, description, parent_id)
+SELECT 'org_admin', 'Administers a single organization', id FROM roles WHERE name = 'user'
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name = 'org_admin' AND p.resource IN ('orgs', 'orgs:members', 'orgs:roles')
+ON CONFLICT DO NOTHING;
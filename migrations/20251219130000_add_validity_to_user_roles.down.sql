@@ -0,0 +1,7 @@
+-- Expired grants would become permanent without valid_until
+DELETE FROM user_roles WHERE valid_until IS NOT NULL AND valid_until <= CURRENT_TIMESTAMP;
+
+DROP INDEX IF EXISTS idx_user_roles_valid_until;
+ALTER TABLE user_roles DROP CONSTRAINT IF EXISTS chk_user_roles_validity;
+ALTER TABLE user_roles DROP COLUMN IF EXISTS valid_until;
+ALTER TABLE user_roles DROP COLUMN IF EXISTS valid_from;
@@ -0,0 +1,9 @@
+-- A role assignment is active from valid_from (NULL = immediately) until valid_until (NULL = forever)
+ALTER TABLE user_roles ADD COLUMN IF NOT EXISTS valid_from TIMESTAMP WITH TIME ZONE;
+ALTER TABLE user_roles ADD COLUMN IF NOT EXISTS valid_until TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE user_roles ADD CONSTRAINT chk_user_roles_validity
+    CHECK (valid_from IS NULL OR valid_until IS NULL OR valid_until > valid_from);
+
+-- Used by the expiry sweeper and cache TTL lookups
+CREATE INDEX IF NOT EXISTS idx_user_roles_valid_until ON user_roles(valid_until) WHERE valid_until IS NOT NULL;
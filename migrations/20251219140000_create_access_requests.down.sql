@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS update_access_requests_updated_at ON access_requests;
+DROP TABLE IF EXISTS access_requests;
+DROP TABLE IF EXISTS role_approvers;
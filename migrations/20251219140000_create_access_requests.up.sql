@@ -0,0 +1,34 @@
+-- Users allowed to approve requests for a role; a role without approvers cannot be requested
+CREATE TABLE IF NOT EXISTS role_approvers (
+    role_id UUID REFERENCES roles(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (role_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_role_approvers_user_id ON role_approvers(user_id);
+
+-- Requests move from pending to exactly one of approved, denied or cancelled
+CREATE TABLE IF NOT EXISTS access_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    requester_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    justification TEXT NOT NULL,
+    valid_until TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CONSTRAINT chk_access_requests_status CHECK (status IN ('pending', 'approved', 'denied', 'cancelled')),
+    reviewer_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    review_note TEXT,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_access_requests_updated_at
+    BEFORE UPDATE ON access_requests
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- At most one open request per user and role
+CREATE UNIQUE INDEX IF NOT EXISTS idx_access_requests_pending ON access_requests(requester_id, role_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_access_requests_role_status ON access_requests(role_id, status);
@@ -0,0 +1,11 @@
+-- Restore the legacy column from RBAC; the carried-over role assignments are kept because
+-- they cannot be told apart from ones granted later
+CREATE TYPE user_role AS ENUM ('admin', 'user');
+ALTER TABLE users ADD COLUMN role user_role NOT NULL DEFAULT 'user';
+
+UPDATE users SET role = 'admin'
+WHERE id IN (
+    SELECT ur.user_id FROM user_roles ur
+    JOIN roles r ON r.id = ur.role_id
+    WHERE ur.organization_id IS NULL AND r.name IN ('admin', 'super_admin')
+);
@@ -0,0 +1,9 @@
+-- RBAC is the only source of roles: carry each users.role value over to the global RBAC role
+-- of the same name, then drop the column and its enum type
+INSERT INTO user_roles (user_id, role_id)
+SELECT u.id, r.id FROM users u
+JOIN roles r ON r.name = u.role::text
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE users DROP COLUMN IF EXISTS role;
+DROP TYPE IF EXISTS user_role;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS group_roles;
+DROP TABLE IF EXISTS group_members;
+DROP TRIGGER IF EXISTS update_groups_updated_at ON groups;
+DROP TABLE IF EXISTS groups;
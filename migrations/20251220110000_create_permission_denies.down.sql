@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_permission_denies;
+DROP TABLE IF EXISTS role_permission_denies;
@@ -0,0 +1,19 @@
+-- Deny rules override any grant of a matching permission; a role's denies apply to every holder
+-- of the role or a role inheriting from it
+CREATE TABLE IF NOT EXISTS role_permission_denies (
+    role_id UUID REFERENCES roles(id) ON DELETE CASCADE,
+    permission_id UUID REFERENCES permissions(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+-- Per-user overrides deny a permission to one user whatever roles they hold
+CREATE TABLE IF NOT EXISTS user_permission_denies (
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    permission_id UUID REFERENCES permissions(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, permission_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_role_permission_denies_permission_id ON role_permission_denies(permission_id);
+CREATE INDEX IF NOT EXISTS idx_user_permission_denies_permission_id ON user_permission_denies(permission_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS role_conflict_set_roles;
+DROP TABLE IF EXISTS role_conflict_sets;
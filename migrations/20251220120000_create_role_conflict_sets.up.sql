@@ -0,0 +1,15 @@
+-- Static separation of duty: a user may hold at most one role of each conflict set
+CREATE TABLE IF NOT EXISTS role_conflict_sets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) UNIQUE NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS role_conflict_set_roles (
+    set_id UUID REFERENCES role_conflict_sets(id) ON DELETE CASCADE,
+    role_id UUID REFERENCES roles(id) ON DELETE CASCADE,
+    PRIMARY KEY (set_id, role_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_role_conflict_set_roles_role_id ON role_conflict_set_roles(role_id);
@@ -0,0 +1,9 @@
+-- Delegated administration: holders of a manager role may assign and remove the managed role
+CREATE TABLE IF NOT EXISTS role_managers (
+    role_id UUID REFERENCES roles(id) ON DELETE CASCADE,
+    manager_role_id UUID REFERENCES roles(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (role_id, manager_role_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_role_managers_manager_role_id ON role_managers(manager_role_id);
@@ -0,0 +1,15 @@
+DROP TRIGGER IF EXISTS record_role_managers_changes ON role_managers;
+DROP TRIGGER IF EXISTS record_role_conflict_set_roles_changes ON role_conflict_set_roles;
+DROP TRIGGER IF EXISTS record_role_conflict_sets_changes ON role_conflict_sets;
+DROP TRIGGER IF EXISTS record_group_roles_changes ON group_roles;
+DROP TRIGGER IF EXISTS record_group_members_changes ON group_members;
+DROP TRIGGER IF EXISTS record_groups_changes ON groups;
+DROP TRIGGER IF EXISTS record_user_permission_denies_changes ON user_permission_denies;
+DROP TRIGGER IF EXISTS record_user_roles_changes ON user_roles;
+DROP TRIGGER IF EXISTS record_role_permission_denies_changes ON role_permission_denies;
+DROP TRIGGER IF EXISTS record_role_permissions_changes ON role_permissions;
+DROP TRIGGER IF EXISTS record_permissions_changes ON permissions;
+DROP TRIGGER IF EXISTS record_roles_changes ON roles;
+DROP TABLE IF EXISTS rbac_changes;
+DROP FUNCTION IF EXISTS reject_rbac_change_rewrite();
+DROP FUNCTION IF EXISTS record_rbac_change();
@@ -0,0 +1,72 @@
+-- Append-only history of every RBAC mutation. Triggers record the row before and after each
+-- change, cascaded deletes included; the application names the actor and request through the
+-- transaction-local settings rbac.actor_id and rbac.request_id.
+CREATE TABLE IF NOT EXISTS rbac_changes (
+    id BIGSERIAL PRIMARY KEY,
+    table_name VARCHAR(63) NOT NULL,
+    action VARCHAR(10) NOT NULL,
+    old_row JSONB,
+    new_row JSONB,
+    -- No foreign key: the history outlives deleted users
+    actor_id UUID,
+    request_id VARCHAR(100),
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_rbac_changes_changed_at ON rbac_changes(changed_at);
+CREATE INDEX IF NOT EXISTS idx_rbac_changes_actor_id ON rbac_changes(actor_id);
+CREATE INDEX IF NOT EXISTS idx_rbac_changes_user_id ON rbac_changes((COALESCE(new_row, old_row)->>'user_id'));
+
+CREATE OR REPLACE FUNCTION record_rbac_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO rbac_changes (table_name, action, old_row, new_row, actor_id, request_id)
+    VALUES (
+        TG_TABLE_NAME,
+        TG_OP,
+        CASE WHEN TG_OP <> 'INSERT' THEN to_jsonb(OLD) END,
+        CASE WHEN TG_OP <> 'DELETE' THEN to_jsonb(NEW) END,
+        NULLIF(current_setting('rbac.actor_id', true), '')::uuid,
+        -- Request IDs may come from the X-Request-ID header, so overlong ones are cut
+        NULLIF(left(current_setting('rbac.request_id', true), 100), '')
+    );
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION reject_rbac_change_rewrite()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'rbac_changes is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER rbac_changes_append_only
+    BEFORE UPDATE OR DELETE ON rbac_changes
+    FOR EACH ROW
+    EXECUTE FUNCTION reject_rbac_change_rewrite();
+
+CREATE TRIGGER record_roles_changes AFTER INSERT OR UPDATE OR DELETE ON roles
+    FOR EACH ROW EXECUTE FUNCTION record_rbac_change();
+CREATE TRIGGER record_permissions_changes AFTER INSERT OR UPDATE OR DELETE ON permissions
+    FOR EACH ROW EXECUTE FUNCTION record_rbac_change();
+CREATE TRIGGER record_role_permissions_changes AFTER INSERT OR UPDATE OR DELETE ON role_permissions
+    FOR EACH ROW EXECUTE FUNCTION record_rbac_change();
+CREATE TRIGGER record_role_permission_denies_changes AFTER INSERT OR UPDATE OR DELETE ON role_permission_denies
+    FOR EACH ROW EXECUTE FUNCTION record_rbac_change();
+CREATE TRIGGER record_user_roles_changes AFTER INSERT OR UPDATE OR DELETE ON user_roles
+    FOR EACH ROW EXECUTE FUNCTION record_rbac_change();
+CREATE TRIGGER record_user_permission_denies_changes AFTER INSERT OR UPDATE OR DELETE ON user_permission_denies
+    FOR EACH ROW EXECUTE FUNCTION record_rbac_change();
+CREATE TRIGGER record_groups_changes AFTER INSERT OR UPDATE OR DELETE ON groups
+    FOR EACH ROW EXECUTE FUNCTION record_rbac_change();
+CREATE TRIGGER record_group_members_changes AFTER INSERT OR UPDATE OR DELETE ON group_members
+    FOR EACH ROW EXECUTE FUNCTION record_rbac_change();
+CREATE TRIGGER record_group_roles_changes AFTER INSERT OR UPDATE OR DELETE ON group_roles
+    FOR EACH ROW EXECUTE FUNCTION record_rbac_change();
+CREATE TRIGGER record_role_conflict_sets_changes AFTER INSERT OR UPDATE OR DELETE ON role_conflict_sets
+    FOR EACH ROW EXECUTE FUNCTION record_rbac_change();
+CREATE TRIGGER record_role_conflict_set_roles_changes AFTER INSERT OR UPDATE OR DELETE ON role_conflict_set_roles
+    FOR EACH ROW EXECUTE FUNCTION record_rbac_change();
+CREATE TRIGGER record_role_managers_changes AFTER INSERT OR UPDATE OR DELETE ON role_managers
+    FOR EACH ROW EXECUTE FUNCTION record_rbac_change();
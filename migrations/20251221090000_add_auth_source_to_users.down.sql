@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_users_external_id;
+
+ALTER TABLE users DROP COLUMN IF EXISTS external_id;
+ALTER TABLE users DROP COLUMN IF EXISTS auth_source;